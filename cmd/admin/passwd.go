@@ -44,6 +44,7 @@ func getAdmin(ctx *context.Context) (uuid.UUID, error) {
 
 func resetPassword(ctx *context.Context, password string) error {
 	var successMsg string
+	var updated bool
 	schema := ctx.Config.Database.Schema
 	tx, err := ctx.DB.Begin()
 	if err != nil {
@@ -74,8 +75,9 @@ func resetPassword(ctx *context.Context, password string) error {
 		successMsg = "Administrador '" + ctx.Config.AdminUsername + "' foi criado com sucesso."
 		adminId = uuid.New()
 		insert := fmt.Sprintf(
-			`INSERT INTO %s.%s (%s, %s, %s, %s, %s)
-			VALUES (:user_id, :username, :name, :password, :updated_at)`,
+			`INSERT INTO %s.%s (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+			VALUES (:user_id, :username, :name, :password, :updated_at, :must_change, :active,
+			        :valid_from, :valid_until, :last_login_at, :email_notifications)`,
 			schema.Name,
 			schema.UserTable.Name,
 			schema.UserTable.Columns.UserId,
@@ -83,6 +85,12 @@ func resetPassword(ctx *context.Context, password string) error {
 			schema.UserTable.Columns.Name,
 			schema.UserTable.Columns.Password,
 			schema.UserTable.Columns.UpdatedAt,
+			schema.UserTable.Columns.MustChangePassword,
+			schema.UserTable.Columns.Active,
+			schema.UserTable.Columns.ValidFrom,
+			schema.UserTable.Columns.ValidUntil,
+			schema.UserTable.Columns.LastLoginAt,
+			schema.UserTable.Columns.EmailNotifications,
 		)
 
 		// Criação
@@ -93,6 +101,12 @@ func resetPassword(ctx *context.Context, password string) error {
 			sql.Named("name", ctx.Config.AdminName),
 			sql.Named("password", hash),
 			sql.Named("updated_at", ts),
+			sql.Named("must_change", 0),
+			sql.Named("active", 1),
+			sql.Named("valid_from", 0),
+			sql.Named("valid_until", 0),
+			sql.Named("last_login_at", 0),
+			sql.Named("email_notifications", 1),
 		)
 		if err != nil {
 			ctx.Logger.Error("Erro ao criar usuário.", zap.Error(err))
@@ -103,12 +117,13 @@ func resetPassword(ctx *context.Context, password string) error {
 		successMsg = "Administrador '" + ctx.Config.AdminUsername + "' foi atualizado com sucesso."
 		update := fmt.Sprintf(
 			`UPDATE %s.%s
-			SET %s = :password, %s = :updated_at
+			SET %s = :password, %s = :updated_at, %s = 0
 			WHERE %s = :user_id`,
 			schema.Name,
 			schema.UserTable.Name,
 			schema.UserTable.Columns.Password,
 			schema.UserTable.Columns.UpdatedAt,
+			schema.UserTable.Columns.MustChangePassword,
 			schema.UserTable.Columns.UserId,
 		)
 
@@ -123,6 +138,7 @@ func resetPassword(ctx *context.Context, password string) error {
 			ctx.Logger.Error("Erro ao atualizar usuário.", zap.Error(err))
 			return fmt.Errorf("não foi possível atualizar usuário")
 		}
+		updated = true
 	}
 
 	// Commit
@@ -130,6 +146,13 @@ func resetPassword(ctx *context.Context, password string) error {
 		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
 		return fmt.Errorf("não foi possível confirmar transação")
 	}
+
+	// Encerrar as sessões do administrador após a troca de senha
+	if updated {
+		if revokeErr := app.RevokeUserSessions(ctx, adminId); revokeErr != nil {
+			return revokeErr
+		}
+	}
 	ctx.Logger.Info(successMsg)
 	return nil
 }
@@ -175,16 +198,24 @@ func main() {
 	ok := false
 
 	for !ok {
-		fmt.Print("\nNova senha do administrador: (>= 4 caracteres) ")
+		fmt.Printf("\nNova senha do administrador: (>= %d caracteres) ", cfg.PasswordPolicy.MinLength)
 		bytePw, err := term.ReadPassword(int(syscall.Stdin))
 		if err != nil {
 			fmt.Print("Senha não pode ser vazia. Finalizando.")
 			return
 		}
 		newPassword = string(bytePw)
-		if len(newPassword) < 4 {
-			fmt.Print("Senha não pode ter menos de 4 caracteres. Finalizando.")
-			return
+
+		// Validar política de senhas
+		var policyErr *app.PasswordPolicyError
+		err = app.ValidatePassword(cfg.PasswordPolicy, cfg.AdminUsername, newPassword)
+		if errors.As(err, &policyErr) {
+			fmt.Println("\nA senha não atende à política de senhas:")
+			for _, v := range policyErr.Violations {
+				fmt.Printf("  - %s\n", v)
+			}
+			fmt.Println("Tente novamente.")
+			continue
 		}
 
 		// Confirmar senha
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+// DeactivateExpiredAccounts desativa as contas cujo período de validade
+// terminou (ex.: fim do patrocínio), registrando cada desativação na
+// auditoria e publicando-a aos webhooks, com o sistema (uuid.Nil) como
+// autor.
+func DeactivateExpiredAccounts(ctx *context.Context) {
+	deactivated, err := app.DeactivateExpiredUsers(ctx)
+	if err != nil {
+		ctx.Logger.Error("Erro ao desativar contas expiradas", zap.Error(err))
+		return
+	}
+
+	for _, userId := range deactivated {
+		entry := app.AuditData{
+			ActorId:    uuid.Nil,
+			Action:     app.AuditUpdate,
+			EntityType: handlers.User.String(),
+			EntityId:   userId,
+			Diff:       handlers.MetadataDiff(map[string]bool{"active": true}, map[string]bool{"active": false}),
+		}
+		if err = app.CreateAuditEntry(ctx, entry); err != nil {
+			ctx.Logger.Error(
+				"Desativação não registrada na auditoria",
+				zap.String("user_id", userId.String()),
+				zap.Error(err),
+			)
+		}
+		app.PublishEvent(ctx, app.WebhookEvent{
+			Name:     app.EventUserUpdated,
+			ActorId:  uuid.Nil,
+			EntityId: userId,
+			Data:     map[string]any{"active": false},
+		})
+	}
+	if len(deactivated) > 0 {
+		ctx.Logger.Info("Contas expiradas desativadas", zap.Int("count", len(deactivated)))
+	}
+}
+
+// StartAccountExpiryJob executa DeactivateExpiredAccounts na inicialização e,
+// em seguida, a cada Config.AccountExpiryInterval minutos. O intervalo é
+// relido a cada execução, acompanhando as mudanças nas configurações.
+func StartAccountExpiryJob(ctx *context.Context) {
+	go func() {
+		for {
+			DeactivateExpiredAccounts(ctx)
+			time.Sleep(time.Duration(ctx.Config.AccountExpiryInterval) * time.Minute)
+		}
+	}()
+}
@@ -8,7 +8,13 @@ import (
 	"agros_arquivos_patrocinadoras/pkg/app/config"
 	"agros_arquivos_patrocinadoras/pkg/app/context"
 	"agros_arquivos_patrocinadoras/pkg/app/db"
+	"agros_arquivos_patrocinadoras/pkg/app/events"
+	"agros_arquivos_patrocinadoras/pkg/app/keyring"
+	"agros_arquivos_patrocinadoras/pkg/app/lockout"
 	"agros_arquivos_patrocinadoras/pkg/app/logger"
+	"agros_arquivos_patrocinadoras/pkg/app/mailer"
+	"agros_arquivos_patrocinadoras/pkg/app/oidc"
+	"agros_arquivos_patrocinadoras/pkg/app/webhook"
 	"database/sql"
 	"fmt"
 	"os"
@@ -16,6 +22,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
@@ -66,11 +73,14 @@ func Serve(e *echo.Echo, ctx *context.Context) {
 // durante a execução da aplicação, permitindo que o usuário decida se deseja
 // finalizar ou continuar a execução.
 //
+// Antes de finalizar, entrega as notificações por e-mail ainda agrupadas.
+//
 // Parâmetros:
 //   - c: canal para capturar os sinais enviados ao processo.
-//   - logr: instância do logger usada para registrar informações e avisos
-//     durante o manuseio do sinal.
-func handleSIGINT(c chan os.Signal, logr *zap.Logger) {
+//   - ctx: contexto da aplicação, contendo o logger usado para registrar
+//     informações e avisos durante o manuseio do sinal e o mailer.Batcher.
+func handleSIGINT(c chan os.Signal, ctx *context.Context) {
+	logr := ctx.Logger
 	for sig := range c {
 		if sig == syscall.SIGINT {
 			logr.Warn("SIGINT recebido")
@@ -80,6 +90,7 @@ func handleSIGINT(c chan os.Signal, logr *zap.Logger) {
 			_, err := fmt.Scanln(&i)
 			if err == nil && strings.ToUpper(i) == "S" {
 				logr.Info("Finalizando a aplicação")
+				ctx.Mailer.Flush()
 				os.Exit(0)
 			}
 			logr.Info("SIGINT interrompido")
@@ -98,11 +109,6 @@ func main() {
 	// Logger
 	logr := logger.CreateLogger()
 
-	// Handler para SIGINT (^C)
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	go handleSIGINT(c, logr)
-
 	logr.Info("Iniciando aplicação")
 
 	// Configurações
@@ -123,11 +129,22 @@ func main() {
 		}
 	}(dataBase)
 
+	// Chaves dos tokens JWT
+	keys, err := keyring.Load(cfg.JwtKeys)
+	if err != nil {
+		logr.Fatal("Erro ao carregar chaves JWT", zap.Error(err))
+	}
+
 	// Contexto da aplicação
 	ctx := &context.Context{
-		Logger: logr,
-		Config: cfg,
-		DB:     dataBase,
+		Logger:   logr,
+		Config:   cfg,
+		DB:       dataBase,
+		Lockout:  lockout.New(),
+		Oidc:     oidc.New(nil),
+		Keyring:  keys,
+		Events:   events.NewBroker(events.DefaultBuffer),
+		Webhooks: webhook.NewQueue(cfg.Webhooks.Workers, cfg.Webhooks.QueueSize),
 	}
 
 	// Obter Id do administrador
@@ -137,6 +154,26 @@ func main() {
 	}
 	ctx.AdminId = adminId
 
+	// Criar papéis padrão
+	if err = app.EnsureDefaultRoles(ctx); err != nil {
+		logr.Fatal("Erro ao criar papéis padrão", zap.Error(err))
+	}
+
+	// Desativação periódica das contas com validade encerrada
+	StartAccountExpiryJob(ctx)
+
+	// Notificações por e-mail dos arquivos, agrupadas por usuário
+	ctx.Mailer = mailer.NewBatcher(
+		time.Duration(cfg.Smtp.BatchWindow)*time.Second,
+		func(key string, events []mailer.Event) { app.SendFileDigest(ctx, key, events) },
+	)
+
+	// Handler para SIGINT (^C), após o mailer.Batcher para que as
+	// notificações pendentes sejam entregues ao finalizar
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	go handleSIGINT(c, ctx)
+
 	// Canal para reiniciar o servidor
 	restartChan := make(chan bool)
 
@@ -164,6 +201,9 @@ func main() {
 			<-restartChan
 			logr.Warn("Reiniciando o servidor")
 
+			// Encerra os fluxos de eventos, que manteriam as conexões ativas
+			ctx.Events.UnsubscribeAll()
+
 			// Faz shutdown do servidor atual
 			if err = e.Shutdown(nil); err != nil {
 				logr.Error("Erro ao encerrar servidor", zap.Error(err))
@@ -2,6 +2,7 @@ package main
 
 import (
 	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
 	"agros_arquivos_patrocinadoras/pkg/handlers"
 	"encoding/json"
 	"github.com/labstack/echo/v4"
@@ -10,6 +11,7 @@ import (
 	"go.uber.org/zap/zapcore"
 	"golang.org/x/time/rate"
 	"net/http"
+	"slices"
 )
 
 // ContextMiddleware é o middleware para implementar context.Context como
@@ -23,6 +25,96 @@ func ContextMiddleware(ctx *context.Context) echo.MiddlewareFunc {
 	}
 }
 
+// PermissionMiddleware é o middleware que restringe uma rota aos usuários
+// cujos papéis concedem a permissão informada.
+func PermissionMiddleware(perm string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !auth.HasPermission(c, perm) {
+				return c.JSON(http.StatusUnauthorized, handlers.UnauthorizedMessage)
+			}
+			return next(c)
+		}
+	}
+}
+
+// PasswordChangeMiddleware é o middleware que bloqueia os usuários obrigados
+// a trocar a senha, exceto nas rotas informadas (ex.: a própria troca).
+func PasswordChangeMiddleware(allowed ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := auth.GetClaims(c)
+			if err == nil && claims.MustChangePassword && !slices.Contains(allowed, c.Path()) {
+				return c.JSON(http.StatusForbidden, handlers.PasswordChangeRequiredMessage)
+			}
+			return next(c)
+		}
+	}
+}
+
+// TotpEnrollMiddleware é o middleware que bloqueia os usuários obrigados a
+// cadastrar o segundo fator (Totp.EnforceRoles), exceto nas rotas informadas
+// (ex.: o próprio cadastro).
+func TotpEnrollMiddleware(allowed ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := auth.GetClaims(c)
+			if err == nil && claims.TotpEnrollRequired && !slices.Contains(allowed, c.Path()) {
+				return c.JSON(http.StatusForbidden, handlers.TotpEnrollRequiredMessage)
+			}
+			return next(c)
+		}
+	}
+}
+
+// ApiKeyMiddleware é o middleware que autentica as requisições com o
+// cabeçalho auth.HeaderApiKey, alternativo ao JWT, e as restringe ao escopo
+// da chave. As chaves de API não são aceitas nas rotas informadas (ex.: a
+// sessão e os dados do próprio usuário).
+func ApiKeyMiddleware(denied ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			raw := c.Request().Header.Get(auth.HeaderApiKey)
+			if raw == "" {
+				return next(c)
+			}
+			if slices.Contains(denied, c.Path()) {
+				return c.JSON(http.StatusForbidden, handlers.ApiKeyNotAllowedMessage)
+			}
+			token, err := auth.ParseApiKey(c, raw)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, handlers.InvalidApiKeyMessage)
+			}
+			c.Set("user", token)
+			if !auth.ApiKeyInScope(c) {
+				return c.JSON(http.StatusForbidden, handlers.ApiKeyScopeMessage)
+			}
+			return next(c)
+		}
+	}
+}
+
+// EventsTicketMiddleware é o middleware que autentica a rota informada (o
+// fluxo de eventos) pelo ticket do parâmetro auth.QueryEventsTicket,
+// alternativo ao JWT, pois o EventSource do navegador não envia o cabeçalho
+// Authorization.
+func EventsTicketMiddleware(path string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			raw := c.QueryParam(auth.QueryEventsTicket)
+			if raw == "" || c.Path() != path {
+				return next(c)
+			}
+			token, err := auth.ParseEventsTicket(c, raw)
+			if err != nil {
+				return c.JSON(http.StatusUnauthorized, handlers.InvalidEventsTicketMessage)
+			}
+			c.Set("user", token)
+			return next(c)
+		}
+	}
+}
+
 // ConfigMiddleware configura os middlewares a serem utilizados pelo servidor.
 func ConfigMiddleware(e *echo.Echo, ctx *context.Context) {
 	ctx.Logger.Info("Configurando middlewares")
@@ -33,6 +125,7 @@ func ConfigMiddleware(e *echo.Echo, ctx *context.Context) {
 		AllowMethods: []string{
 			echo.GET,
 			echo.POST,
+			echo.PUT,
 			echo.PATCH,
 			echo.DELETE,
 			echo.HEAD,
@@ -43,6 +136,12 @@ func ConfigMiddleware(e *echo.Echo, ctx *context.Context) {
 			echo.HeaderOrigin,
 			echo.HeaderContentType,
 			echo.HeaderAccept,
+			handlers.HeaderIfMatch,
+			handlers.HeaderCSRFToken,
+		},
+		ExposeHeaders: []string{
+			echo.HeaderContentDisposition,
+			handlers.HeaderETag,
 		},
 	}
 
@@ -81,39 +180,55 @@ func ConfigMiddleware(e *echo.Echo, ctx *context.Context) {
 		// Implementar app.AppWrapper
 		ContextMiddleware(ctx),
 		// Middleware para capturar requisições e respostas
-		middleware.BodyDump(func(c echo.Context, reqBody, resBody []byte) {
-			// Filtrar campos para não aparecer nos logs
-			filtered := make([][]byte, 2)
-			bodies := [][]byte{reqBody, resBody}
-			for j, body := range bodies {
-				var i interface{}
-				if err := json.Unmarshal(body, &i); err != nil {
-					filtered[j] = []byte("")
-				}
+		middleware.BodyDumpWithConfig(middleware.BodyDumpConfig{
+			// O fluxo de eventos não termina e não deve ser acumulado
+			Skipper: func(c echo.Context) bool {
+				return c.Path() == "/auth/events"
+			},
+			Handler: func(c echo.Context, reqBody, resBody []byte) {
+				// Filtrar campos para não aparecer nos logs
+				filtered := make([][]byte, 2)
+				bodies := [][]byte{reqBody, resBody}
+				for j, body := range bodies {
+					var i interface{}
+					if err := json.Unmarshal(body, &i); err != nil {
+						filtered[j] = []byte("")
+					}
 
-				if m, ok := i.(map[string]interface{}); ok {
-					delete(m, "password")
-					delete(m, "content")
-					delete(m, "blob")
-				} else {
-					filtered[j] = []byte("")
-				}
+					if m, ok := i.(map[string]interface{}); ok {
+						delete(m, "password")
+						delete(m, "current_password")
+						delete(m, "new_password")
+						delete(m, "content")
+						delete(m, "blob")
+						delete(m, "token")
+						delete(m, "refresh_token")
+						delete(m, "mfa_token")
+						delete(m, "code")
+						delete(m, "secret")
+						delete(m, "uri")
+						delete(m, "recovery_codes")
+						delete(m, "key")
+					} else {
+						filtered[j] = []byte("")
+					}
 
-				if payload, err := json.Marshal(i); err == nil {
-					filtered[j] = payload
-				} else {
-					filtered[j] = []byte("")
+					if payload, err := json.Marshal(i); err == nil {
+						filtered[j] = payload
+					} else {
+						filtered[j] = []byte("")
+					}
 				}
-			}
 
-			handlers.LogHTTPDetails(
-				c,
-				zapcore.InfoLevel,
-				"HTTP request-response",
-				zap.Int("status", c.Response().Status),
-				zap.String("request_body", string(filtered[0])),
-				zap.String("response_body", string(filtered[1])),
-			)
+				handlers.LogHTTPDetails(
+					c,
+					zapcore.InfoLevel,
+					"HTTP request-response",
+					zap.Int("status", c.Response().Status),
+					zap.String("request_body", string(filtered[0])),
+					zap.String("response_body", string(filtered[1])),
+				)
+			},
 		}),
 		// Limitações de requisições IP/segundo
 		middleware.RateLimiter(
@@ -124,6 +239,25 @@ func ConfigMiddleware(e *echo.Echo, ctx *context.Context) {
 		middleware.Recover(),
 		// CORS
 		middleware.CORSWithConfig(corsConfig),
+		// CSRF (double-submit), no modo de autenticação por cookies. O login
+		// e a renovação emitem um novo token CSRF (handlers.issueTokens); a
+		// redefinição de senha e o segundo fator do login são autenticados
+		// pelos próprios tokens, assim como as requisições com chave de API.
+		middleware.CSRFWithConfig(middleware.CSRFConfig{
+			Skipper: func(c echo.Context) bool {
+				switch c.Path() {
+				case "/login", "/login/totp", "/refresh", "/password-reset":
+					return true
+				}
+				return !ctx.Config.CookieAuth || c.Request().Header.Get(auth.HeaderApiKey) != ""
+			},
+			TokenLookup:    "header:" + handlers.HeaderCSRFToken,
+			CookieName:     handlers.CookieCSRF,
+			CookiePath:     "/",
+			CookieMaxAge:   ctx.Config.RefreshExpires * 60,
+			CookieSecure:   ctx.Config.CookieAuth || ctx.Config.EnableTLS,
+			CookieSameSite: http.SameSiteStrictMode,
+		}),
 		// Sistema de arquivos estáticos
 		middleware.StaticWithConfig(middleware.StaticConfig{
 			Filesystem: http.Dir("web/dist/"),
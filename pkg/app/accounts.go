@@ -0,0 +1,250 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAccountInactive indica uma conta desativada.
+	ErrAccountInactive = errors.New("conta desativada")
+	// ErrAccountExpired indica uma conta fora do período de validade.
+	ErrAccountExpired = errors.New("conta fora do período de validade")
+	// ErrInvalidValidity indica um período de validade cujo fim não é
+	// posterior ao início.
+	ErrInvalidValidity = errors.New("período de validade inválido")
+)
+
+// userColumns retorna as colunas, separadas por vírgula, lidas por scanUser.
+func userColumns(schema *config.Schema) string {
+	uc := &schema.UserTable.Columns
+	return strings.Join([]string{
+		uc.UserId,
+		uc.Username,
+		uc.Name,
+		uc.UpdatedAt,
+		uc.MustChangePassword,
+		uc.Email,
+		uc.Phone,
+		uc.Active,
+		uc.ValidFrom,
+		uc.ValidUntil,
+		uc.LastLoginAt,
+		uc.EmailNotifications,
+	}, ",")
+}
+
+// scanUser lê um usuário de uma linha com as colunas de userColumns. Colunas
+// nulas, como as de usuários criados antes dos dados de contato e da
+// validade, resultam em conta ativa, sem contato, sem validade e com as
+// notificações por e-mail habilitadas.
+func scanUser(row interface{ Scan(...any) error }) (db.UserModel, error) {
+	var user db.UserModel
+	var mustChange int
+	var email, phone sql.NullString
+	var active, validFrom, validUntil, lastLogin, notifications sql.NullInt64
+	err := row.Scan(
+		&user.UserId,
+		&user.Username,
+		&user.Name,
+		&user.UpdatedAt,
+		&mustChange,
+		&email,
+		&phone,
+		&active,
+		&validFrom,
+		&validUntil,
+		&lastLogin,
+		&notifications,
+	)
+	if err != nil {
+		return user, err
+	}
+	user.MustChangePassword = mustChange != 0
+	user.Email, user.Phone = email.String, phone.String
+	user.Active = !active.Valid || active.Int64 != 0
+	user.ValidFrom, user.ValidUntil = validFrom.Int64, validUntil.Int64
+	user.LastLoginAt = lastLogin.Int64
+	user.EmailNotifications = !notifications.Valid || notifications.Int64 != 0
+	return user, nil
+}
+
+// derefInt64 retorna o valor de um inteiro opcional ou zero, se nulo.
+func derefInt64(i *int64) int64 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+// ValidateValidity valida o período de validade de uma conta, em tempo Unix.
+// Zero indica início ou fim indefinido.
+//
+// Parâmetros:
+//   - from: início da validade.
+//   - until: fim da validade.
+//
+// Retorno:
+//   - error: ErrInvalidValidity ou nil caso o período seja válido.
+func ValidateValidity(from, until int64) error {
+	if from < 0 || until < 0 || (from != 0 && until != 0 && until <= from) {
+		return ErrInvalidValidity
+	}
+	return nil
+}
+
+// CheckAccount verifica se a conta de um usuário permite o acesso: ativa e
+// dentro do período de validade. O administrador configurado (AdminId) tem
+// sempre acesso.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo o Id do administrador.
+//   - user: usuário a ser verificado.
+//
+// Retorno:
+//   - error: ErrAccountInactive, ErrAccountExpired ou nil caso o acesso seja
+//     permitido.
+func CheckAccount(ctx *context.Context, user db.UserModel) error {
+	if user.UserId == ctx.AdminId.String() {
+		return nil
+	}
+	if !user.Active {
+		return ErrAccountInactive
+	}
+	now := time.Now().Unix()
+	if (user.ValidFrom != 0 && now < user.ValidFrom) || (user.ValidUntil != 0 && now >= user.ValidUntil) {
+		return ErrAccountExpired
+	}
+	return nil
+}
+
+// IsUserActive verifica se a conta de um usuário permite o acesso
+// (CheckAccount).
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//
+// Retorno:
+//   - bool: true caso o acesso seja permitido.
+//   - error: erro caso não seja possível obter o usuário.
+func IsUserActive(ctx *context.Context, userId uuid.UUID) (bool, error) {
+	user, err := QueryUserById(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+	return CheckAccount(ctx, user) == nil, nil
+}
+
+// recordLogin registra o último login de um usuário, usando exec
+// (ctx.DB.Exec ou tx.Exec) para que o registro possa fazer parte de outra
+// transação. A versão do usuário não é alterada.
+func recordLogin(
+	ctx *context.Context,
+	exec func(string, ...any) (sql.Result, error),
+	userId uuid.UUID,
+	at time.Time,
+) error {
+	schema := &ctx.Config.Database.Schema
+	update := fmt.Sprintf(
+		`UPDATE %s.%s SET %s = :last_login_at WHERE %s = :user_id`,
+		schema.Name,
+		schema.UserTable.Name,
+		schema.UserTable.Columns.LastLoginAt,
+		schema.UserTable.Columns.UserId,
+	)
+	_, err := exec(update, sql.Named("last_login_at", at.Unix()), sql.Named("user_id", userId.String()))
+	if err != nil {
+		ctx.Logger.Error("Erro ao registrar último login.", zap.Error(err))
+		return fmt.Errorf("não foi possível registrar último login")
+	}
+	return nil
+}
+
+// DeactivateExpiredUsers desativa as contas ativas cujo período de validade
+// terminou (ex.: fim do patrocínio) e encerra suas sessões. Cada conta é
+// desativada em sua própria transação, portanto a falha em uma conta não
+// impede a desativação das demais.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//
+// Retorno:
+//   - []uuid.UUID: os usuários desativados.
+//   - error: erro caso a consulta das contas falhe.
+func DeactivateExpiredUsers(ctx *context.Context) ([]uuid.UUID, error) {
+	// Query
+	schema := &ctx.Config.Database.Schema
+	uc := &schema.UserTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s, %s FROM %s.%s
+		WHERE %s = 1 AND %s > 0 AND %s <= :now AND %s <> :admin_id`,
+		uc.UserId,
+		uc.UpdatedAt,
+		schema.Name,
+		schema.UserTable.Name,
+		uc.Active,
+		uc.ValidUntil,
+		uc.ValidUntil,
+		uc.UserId,
+	)
+
+	// Obtenção das contas expiradas
+	rows, err := ctx.DB.Query(
+		query,
+		sql.Named("now", time.Now().Unix()),
+		sql.Named("admin_id", ctx.AdminId.String()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao buscar contas expiradas.", zap.Error(err))
+		return nil, fmt.Errorf("não foi possível buscar contas expiradas")
+	}
+	type expired struct {
+		userId  uuid.UUID
+		version int64
+	}
+	var accounts []expired
+	for rows.Next() {
+		var userId string
+		var e expired
+		if err = rows.Scan(&userId, &e.version); err != nil {
+			closeRows(ctx, rows)
+			ctx.Logger.Error("Erro ao obter conta expirada.", zap.Error(err))
+			return nil, fmt.Errorf("não foi possível buscar contas expiradas")
+		}
+		if e.userId, err = uuid.Parse(userId); err == nil {
+			accounts = append(accounts, e)
+		}
+	}
+	closeRows(ctx, rows)
+
+	// Desativação
+	var deactivated []uuid.UUID
+	for _, a := range accounts {
+		inactive := false
+		err = UpdateUser(ctx, a.userId, a.version, UserData{Active: &inactive})
+		if errors.Is(err, ErrVersionConflict) {
+			// Alterada após a consulta; verificada na próxima execução
+			continue
+		} else if err != nil {
+			ctx.Logger.Error(
+				"Conta expirada não desativada.",
+				zap.String("user_id", a.userId.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		deactivated = append(deactivated, a.userId)
+	}
+	return deactivated, nil
+}
@@ -0,0 +1,367 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// ApiKeyPrefix é o prefixo das chaves de API, que facilita sua identificação
+// em varreduras de segredos.
+const ApiKeyPrefix = "agk_"
+
+// apiKeyPrefixLen é o tamanho do prefixo armazenado e exibido de cada chave.
+const apiKeyPrefixLen = len(ApiKeyPrefix) + 8
+
+// Tipos dos itens do escopo de uma chave de API.
+const (
+	apiKeyScopePermission = "permission"
+	apiKeyScopeUser       = "user"
+	apiKeyScopeCategory   = "category"
+)
+
+// ErrInvalidApiKey indica uma chave de API inexistente, revogada ou
+// expirada.
+var ErrInvalidApiKey = errors.New("chave de API inválida")
+
+// CreateApiKey cria uma chave de API para clientes de máquina, com as
+// permissões e o escopo de usuários e categorias informados. Apenas o hash
+// da chave é armazenado; a chave é retornada somente aqui.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: nome, permissões, escopo e expiração da chave.
+//   - createdBy: identificador do administrador que criou a chave.
+//
+// Retorno:
+//   - ApiKeyCreatedData: a chave gerada, com seu identificador e prefixo.
+//   - error: ErrInvalidPermission, caso alguma permissão seja desconhecida
+//     ou nenhuma seja informada, ou erro caso não seja possível criar a
+//     chave.
+func CreateApiKey(ctx *context.Context, p ApiKeyData, createdBy uuid.UUID) (ApiKeyCreatedData, error) {
+	var res ApiKeyCreatedData
+	if len(p.Permissions) == 0 || !validPermissions(p.Permissions) {
+		return res, ErrInvalidPermission
+	}
+
+	// Geração do UUID e da chave
+	keyId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar UUID")
+	}
+	token, err := newToken()
+	if err != nil {
+		ctx.Logger.Error("Erro ao gerar chave de API.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível gerar chave de API")
+	}
+	res = ApiKeyCreatedData{KeyId: keyId, Key: ApiKeyPrefix + token}
+	res.Prefix = res.Key[:apiKeyPrefixLen]
+	var expiresAt int64
+	if !p.ExpiresAt.IsZero() {
+		expiresAt = p.ExpiresAt.Unix()
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Criação
+	schema := &ctx.Config.Database.Schema
+	kc := &schema.ApiKeyTable.Columns
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s, %s, %s)
+		VALUES (:key_id, :name, :key_prefix, :key_hash, :created_by, :created_at, :expires_at, 0)`,
+		schema.Name,
+		schema.ApiKeyTable.Name,
+		kc.KeyId,
+		kc.Name,
+		kc.KeyPrefix,
+		kc.KeyHash,
+		kc.CreatedBy,
+		kc.CreatedAt,
+		kc.ExpiresAt,
+		kc.LastUsedAt,
+	)
+	_, err = tx.Exec(
+		insert,
+		sql.Named("key_id", keyId.String()),
+		sql.Named("name", p.Name),
+		sql.Named("key_prefix", res.Prefix),
+		sql.Named("key_hash", hashToken(res.Key)),
+		sql.Named("created_by", createdBy.String()),
+		sql.Named("created_at", time.Now().Unix()),
+		sql.Named("expires_at", expiresAt),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar chave de API.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar chave de API")
+	}
+
+	// Escopo
+	scope := make([][2]string, 0, len(p.Permissions)+len(p.Users)+len(p.Categories))
+	for _, perm := range p.Permissions {
+		scope = append(scope, [2]string{apiKeyScopePermission, perm})
+	}
+	for _, userId := range p.Users {
+		scope = append(scope, [2]string{apiKeyScopeUser, userId.String()})
+	}
+	for _, categId := range p.Categories {
+		scope = append(scope, [2]string{apiKeyScopeCategory, categId.String()})
+	}
+	sc := &schema.ApiKeyScopeTable.Columns
+	insert = fmt.Sprintf(
+		`INSERT INTO %s.%s (%s, %s, %s) VALUES (:key_id, :kind, :value)`,
+		schema.Name,
+		schema.ApiKeyScopeTable.Name,
+		sc.KeyId,
+		sc.Kind,
+		sc.Value,
+	)
+	for _, item := range scope {
+		_, err = tx.Exec(
+			insert,
+			sql.Named("key_id", keyId.String()),
+			sql.Named("kind", item[0]),
+			sql.Named("value", item[1]),
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao inserir escopo da chave de API.", zap.Error(err))
+			return res, fmt.Errorf("não foi possível inserir escopo da chave de API")
+		}
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return res, fmt.Errorf("não foi possível confirmar transação")
+	}
+	return res, nil
+}
+
+// queryApiKeys recupera as chaves de API que atendem à condição informada,
+// com seus escopos.
+func queryApiKeys(ctx *context.Context, where string, args ...any) ([]db.ApiKeyModel, error) {
+	var keys []db.ApiKeyModel
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	kc := &schema.ApiKeyTable.Columns
+	sc := &schema.ApiKeyScopeTable.Columns
+	query := fmt.Sprintf(
+		`SELECT k.%s, k.%s, k.%s, k.%s, k.%s, k.%s, k.%s, s.%s, s.%s
+		FROM %s.%s k
+		LEFT JOIN %s.%s s ON s.%s = k.%s
+		%s
+		ORDER BY k.%s, k.%s, s.%s, s.%s`,
+		kc.KeyId,
+		kc.Name,
+		kc.KeyPrefix,
+		kc.CreatedBy,
+		kc.CreatedAt,
+		kc.ExpiresAt,
+		kc.LastUsedAt,
+		sc.Kind,
+		sc.Value,
+		schema.Name,
+		schema.ApiKeyTable.Name,
+		schema.Name,
+		schema.ApiKeyScopeTable.Name,
+		sc.KeyId,
+		kc.KeyId,
+		where,
+		kc.CreatedAt,
+		kc.KeyId,
+		sc.Kind,
+		sc.Value,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, args...)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar chaves de API.", zap.Error(err))
+		return keys, fmt.Errorf("não foi possível obter as chaves de API")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas, agrupando o escopo por chave
+	for rows.Next() {
+		var k db.ApiKeyModel
+		var kind, value sql.NullString
+		err = rows.Scan(
+			&k.KeyId,
+			&k.Name,
+			&k.Prefix,
+			&k.CreatedBy,
+			&k.CreatedAt,
+			&k.ExpiresAt,
+			&k.LastUsedAt,
+			&kind,
+			&value,
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao obter chave de API.", zap.Error(err))
+			return keys, fmt.Errorf("não foi possível obter todas as chaves de API")
+		}
+		if n := len(keys); n == 0 || keys[n-1].KeyId != k.KeyId {
+			k.Permissions, k.Users, k.Categories = []string{}, []string{}, []string{}
+			keys = append(keys, k)
+		}
+		last := &keys[len(keys)-1]
+		switch kind.String {
+		case apiKeyScopePermission:
+			last.Permissions = append(last.Permissions, value.String)
+		case apiKeyScopeUser:
+			last.Users = append(last.Users, value.String)
+		case apiKeyScopeCategory:
+			last.Categories = append(last.Categories, value.String)
+		}
+	}
+	return keys, nil
+}
+
+// QueryAllApiKeys recupera todas as chaves de API, com seus escopos.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//
+// Retorno:
+//   - []db.ApiKeyModel: as chaves encontradas.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryAllApiKeys(ctx *context.Context) ([]db.ApiKeyModel, error) {
+	return queryApiKeys(ctx, "")
+}
+
+// QueryApiKeyById recupera uma chave de API, com seu escopo.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - keyId: identificador da chave.
+//
+// Retorno:
+//   - db.ApiKeyModel: a chave encontrada.
+//   - error: ErrEntityNotFound ou erro caso a consulta falhe.
+func QueryApiKeyById(ctx *context.Context, keyId uuid.UUID) (db.ApiKeyModel, error) {
+	schema := &ctx.Config.Database.Schema
+	keys, err := queryApiKeys(
+		ctx,
+		fmt.Sprintf("WHERE k.%s = :key_id", schema.ApiKeyTable.Columns.KeyId),
+		sql.Named("key_id", keyId.String()),
+	)
+	if err != nil {
+		return db.ApiKeyModel{}, err
+	} else if len(keys) == 0 {
+		return db.ApiKeyModel{}, ErrEntityNotFound
+	}
+	return keys[0], nil
+}
+
+// DeleteApiKey revoga uma chave de API, excluindo-a com seu escopo.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - keyId: identificador da chave.
+//
+// Retorno:
+//   - error: ErrEntityNotFound ou erro caso não seja possível excluir a
+//     chave.
+func DeleteApiKey(ctx *context.Context, keyId uuid.UUID) error {
+	if _, err := QueryApiKeyById(ctx, keyId); err != nil {
+		return err
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Delete queries, do escopo para a chave
+	schema := &ctx.Config.Database.Schema
+	tables := []struct{ name, column string }{
+		{schema.ApiKeyScopeTable.Name, schema.ApiKeyScopeTable.Columns.KeyId},
+		{schema.ApiKeyTable.Name, schema.ApiKeyTable.Columns.KeyId},
+	}
+	for _, t := range tables {
+		del := fmt.Sprintf("DELETE FROM %s.%s WHERE %s = :key_id", schema.Name, t.name, t.column)
+		if _, err = tx.Exec(del, sql.Named("key_id", keyId.String())); err != nil {
+			ctx.Logger.Error("Erro ao excluir chave de API.", zap.Error(err))
+			return fmt.Errorf("não foi possível excluir chave de API")
+		}
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return fmt.Errorf("não foi possível confirmar transação")
+	}
+	return nil
+}
+
+// AuthenticateApiKey obtém a chave de API válida correspondente à chave
+// recebida e registra seu último uso, no máximo uma vez por
+// lastSeenInterval.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - key: chave recebida na requisição.
+//
+// Retorno:
+//   - db.ApiKeyModel: a chave autenticada, com seu escopo.
+//   - error: ErrInvalidApiKey ou erro caso a consulta falhe.
+func AuthenticateApiKey(ctx *context.Context, key string) (db.ApiKeyModel, error) {
+	// Consulta pelo hash da chave
+	now := time.Now()
+	schema := &ctx.Config.Database.Schema
+	kc := &schema.ApiKeyTable.Columns
+	keys, err := queryApiKeys(
+		ctx,
+		fmt.Sprintf("WHERE k.%s = :key_hash", kc.KeyHash),
+		sql.Named("key_hash", hashToken(key)),
+	)
+	if err != nil {
+		return db.ApiKeyModel{}, err
+	} else if len(keys) == 0 || (keys[0].ExpiresAt != 0 && keys[0].ExpiresAt <= now.Unix()) {
+		return db.ApiKeyModel{}, ErrInvalidApiKey
+	}
+
+	// Registro do último uso
+	k := keys[0]
+	if k.LastUsedAt < now.Add(-lastSeenInterval).Unix() {
+		update := fmt.Sprintf(
+			`UPDATE %s.%s SET %s = :now WHERE %s = :key_id`,
+			schema.Name,
+			schema.ApiKeyTable.Name,
+			kc.LastUsedAt,
+			kc.KeyId,
+		)
+		_, err = ctx.DB.Exec(update, sql.Named("now", now.Unix()), sql.Named("key_id", k.KeyId))
+		if err != nil {
+			ctx.Logger.Error("Erro ao registrar uso da chave de API.", zap.Error(err))
+		} else {
+			k.LastUsedAt = now.Unix()
+		}
+	}
+	return k, nil
+}
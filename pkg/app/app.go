@@ -7,6 +7,7 @@ package app
 
 import (
 	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/events"
 	"agros_arquivos_patrocinadoras/pkg/types/db"
 	"database/sql"
 	"fmt"
@@ -14,11 +15,33 @@ import (
 	"github.com/pkg/errors"
 	goora "github.com/sijms/go-ora/v2"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 	"strings"
 	"time"
 )
 
+// ErrVersionConflict indica que a entidade foi alterada por outra requisição
+// desde a versão (UpdatedAt) informada para a alteração ou exclusão.
+var ErrVersionConflict = errors.New("versão da entidade desatualizada")
+
+// nextVersion gera o timestamp da nova versão de uma entidade, garantindo que
+// seja sempre posterior à versão atual, mesmo em alterações no mesmo segundo.
+func nextVersion(version int64) int64 {
+	ts := time.Now().Unix()
+	if ts <= version {
+		ts = version + 1
+	}
+	return ts
+}
+
+// versionClause monta a condição SQL de versão para a coluna de atualização
+// informada. Uma versão zero indica alteração incondicional.
+func versionClause(column string, version int64) (string, []any) {
+	if version == 0 {
+		return "", nil
+	}
+	return " AND " + column + " = :version", []any{sql.Named("version", version)}
+}
+
 func GetAdmin(ctx *context.Context) (uuid.UUID, error) {
 	// Query
 	schema := &ctx.Config.Database.Schema
@@ -78,43 +101,34 @@ func CheckUsername(ctx *context.Context, username string) (bool, error) {
 	return false, fmt.Errorf("nome de usuário já existente")
 }
 
+// QueryLogin verifica as credenciais de login de um usuário com o
+// autenticador configurado (NewAuthenticator) e a situação de sua conta
+// (CheckAccount).
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: nome de usuário e senha informados.
+//
+// Retorno:
+//   - LoginData: os dados do usuário autenticado.
+//   - error: ErrNotAuthenticated, ErrAccountInactive, ErrAccountExpired ou
+//     erro caso não seja possível verificar as credenciais.
 func QueryLogin(ctx *context.Context, p LoginParams) (LoginData, error) {
-	// Query
-	schema := &ctx.Config.Database.Schema
-	query := fmt.Sprintf(
-		`SELECT %s, %s, %s
-		FROM %s.%s
-		WHERE %s = :username`,
-		schema.UserTable.Columns.UserId,
-		schema.UserTable.Columns.Name,
-		schema.UserTable.Columns.Password,
-		schema.Name,
-		schema.UserTable.Name,
-		schema.UserTable.Columns.Username,
-	)
-
-	// Obtenção da linha
-	rows, err := ctx.DB.Query(query, sql.Named("username", p.Username))
+	data, err := NewAuthenticator(ctx).Authenticate(ctx, p)
 	if err != nil {
-		return LoginData{}, fmt.Errorf("usuário não encontrado")
+		return data, err
 	}
-	defer closeRows(ctx, rows)
 
-	// Iterar por cada uma das linhas
-	for rows.Next() {
-		var hash string
-		data := LoginData{}
-		err = rows.Scan(&data.UserId, &data.Name, &hash)
-		if err != nil {
-			continue
-		}
-		err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(p.Password))
-		if err == nil {
-			return data, nil
-		}
+	// Situação da conta
+	user, err := QueryUserById(ctx, data.UserId)
+	if err != nil {
+		return LoginData{}, err
 	}
-
-	return LoginData{}, fmt.Errorf("não autenticado")
+	if err = CheckAccount(ctx, user); err != nil {
+		return LoginData{}, err
+	}
+	return data, nil
 }
 
 func rollback(ctx *context.Context, tx *sql.Tx, err *error) {
@@ -129,6 +143,17 @@ func rollback(ctx *context.Context, tx *sql.Tx, err *error) {
 func CreateUser(ctx *context.Context, p UserData) (uuid.UUID, error) {
 	var err error
 
+	// Validar senha e contato
+	if err = ValidatePassword(ctx.Config.PasswordPolicy, p.Username, p.Password); err != nil {
+		return uuid.Nil, err
+	}
+	if err = ValidateContact(p.Email, p.Phone); err != nil {
+		return uuid.Nil, err
+	}
+	if err = ValidateValidity(derefInt64(p.ValidFrom), derefInt64(p.ValidUntil)); err != nil {
+		return uuid.Nil, err
+	}
+
 	// Checar nome de usuário
 	ok, err := CheckUsername(ctx, p.Username)
 	if !ok {
@@ -157,8 +182,9 @@ func CreateUser(ctx *context.Context, p UserData) (uuid.UUID, error) {
 	schema := &ctx.Config.Database.Schema
 	insert := fmt.Sprintf(
 		`INSERT INTO %s.%s
-  		(%s, %s, %s, %s, %s)
-		VALUES (:user_id, :username, :name, :password, :updated_at)`,
+  		(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+		VALUES (:user_id, :username, :name, :password, :updated_at, :must_change, :email, :phone,
+		        :active, :valid_from, :valid_until, :last_login_at, :email_notifications)`,
 		schema.Name,
 		schema.UserTable.Name,
 		schema.UserTable.Columns.UserId,
@@ -166,6 +192,14 @@ func CreateUser(ctx *context.Context, p UserData) (uuid.UUID, error) {
 		schema.UserTable.Columns.Name,
 		schema.UserTable.Columns.Password,
 		schema.UserTable.Columns.UpdatedAt,
+		schema.UserTable.Columns.MustChangePassword,
+		schema.UserTable.Columns.Email,
+		schema.UserTable.Columns.Phone,
+		schema.UserTable.Columns.Active,
+		schema.UserTable.Columns.ValidFrom,
+		schema.UserTable.Columns.ValidUntil,
+		schema.UserTable.Columns.LastLoginAt,
+		schema.UserTable.Columns.EmailNotifications,
 	)
 
 	// Criptografar senha
@@ -182,6 +216,14 @@ func CreateUser(ctx *context.Context, p UserData) (uuid.UUID, error) {
 		sql.Named("name", p.Name),
 		sql.Named("password", hash),
 		sql.Named("updated_at", ts),
+		sql.Named("must_change", boolToInt(p.MustChangePassword != nil && *p.MustChangePassword)),
+		sql.Named("email", derefString(p.Email)),
+		sql.Named("phone", derefString(p.Phone)),
+		sql.Named("active", boolToInt(p.Active == nil || *p.Active)),
+		sql.Named("valid_from", derefInt64(p.ValidFrom)),
+		sql.Named("valid_until", derefInt64(p.ValidUntil)),
+		sql.Named("last_login_at", 0),
+		sql.Named("email_notifications", boolToInt(p.EmailNotifications == nil || *p.EmailNotifications)),
 	)
 	if err != nil {
 		ctx.Logger.Error("Erro ao criar usuário.", zap.Error(err))
@@ -193,6 +235,7 @@ func CreateUser(ctx *context.Context, p UserData) (uuid.UUID, error) {
 		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
 		return uuid.Nil, fmt.Errorf("não foi possível confirmar transação")
 	}
+	publishChange(ctx, events.EntityUser, events.ActionCreate, userId)
 	return userId, nil
 }
 
@@ -247,6 +290,7 @@ func CreateCategory(ctx *context.Context, p CategData) (uuid.UUID, error) {
 		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
 		return uuid.Nil, fmt.Errorf("não foi possível confirmar transação")
 	}
+	publishChange(ctx, events.EntityCategory, events.ActionCreate, categId)
 	return categId, nil
 }
 
@@ -310,6 +354,7 @@ func CreateFile(ctx *context.Context, p FileData) (uuid.UUID, error) {
 		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
 		return uuid.Nil, fmt.Errorf("não foi possível confirmar transação")
 	}
+	publishChange(ctx, events.EntityFile, events.ActionCreate, fileId)
 	return fileId, nil
 }
 
@@ -333,8 +378,8 @@ func closeRows(ctx *context.Context, rows *sql.Rows) {
 //     e o zap.Logger para registrar logs de advertência em caso de erro.
 //
 // Retorno:
-//   - []db.UserModel: uma lista de usuários contendo os campos UserId, Username e
-//     UpdatedAt.
+//   - []db.UserModel: uma lista de usuários, com os dados de contato, a
+//     situação da conta (ativa e validade) e o último login.
 //   - error: um erro é retornado caso a query ou o processamento dos resultados
 //     falhe.
 func QueryAllUsers(ctx *context.Context) ([]db.UserModel, error) {
@@ -343,11 +388,8 @@ func QueryAllUsers(ctx *context.Context) ([]db.UserModel, error) {
 	// Query
 	schema := &ctx.Config.Database.Schema
 	query := fmt.Sprintf(
-		`SELECT %s,%s,%s,%s FROM %s.%s WHERE %s <> :admin_id`,
-		schema.UserTable.Columns.UserId,
-		schema.UserTable.Columns.Username,
-		schema.UserTable.Columns.Name,
-		schema.UserTable.Columns.UpdatedAt,
+		`SELECT %s FROM %s.%s WHERE %s <> :admin_id`,
+		userColumns(schema),
 		schema.Name,
 		schema.UserTable.Name,
 		schema.UserTable.Columns.UserId,
@@ -362,8 +404,7 @@ func QueryAllUsers(ctx *context.Context) ([]db.UserModel, error) {
 
 	// Iterar por cada uma das linhas
 	for rows.Next() {
-		u := db.UserModel{Password: ""}
-		err = rows.Scan(&u.UserId, &u.Username, &u.Name, &u.UpdatedAt)
+		u, err := scanUser(rows)
 		if err != nil {
 			ctx.Logger.Error("Erro ao obter usuário.", zap.Error(err))
 			return users, fmt.Errorf("não foi possível obter todos os usuários")
@@ -501,26 +542,20 @@ func QueryAllFiles(ctx *context.Context, categId uuid.UUID) ([]db.FileModel, err
 //   - error: retorna um erro caso a execução da consulta ou o
 //     processamento do resultado falhe.
 func QueryUserById(ctx *context.Context, userId uuid.UUID) (db.UserModel, error) {
-	var user db.UserModel
-
 	// Query
 	schema := &ctx.Config.Database.Schema
 	query := fmt.Sprintf(
-		`SELECT %s,%s,%s,%s
+		`SELECT %s
 		FROM %s.%s
 		WHERE %s = :user_id`,
-		schema.UserTable.Columns.UserId,
-		schema.UserTable.Columns.Username,
-		schema.UserTable.Columns.Name,
-		schema.UserTable.Columns.UpdatedAt,
+		userColumns(schema),
 		schema.Name,
 		schema.UserTable.Name,
 		schema.UserTable.Columns.UserId,
 	)
 
 	// Obtenção da linha
-	row := ctx.DB.QueryRow(query, sql.Named("user_id", userId.String()))
-	err := row.Scan(&user.UserId, &user.Username, &user.Name, &user.UpdatedAt)
+	user, err := scanUser(ctx.DB.QueryRow(query, sql.Named("user_id", userId.String())))
 	if err != nil {
 		return user, fmt.Errorf("não foi possível obter usuário")
 	}
@@ -619,7 +654,93 @@ func QueryFileById(ctx *context.Context, fileId uuid.UUID) (db.FileModel, error)
 	return file, nil
 }
 
-func UpdateUser(ctx *context.Context, userId uuid.UUID, p UserData) error {
+// QueryFileInfoById realiza uma consulta ao banco de dados para buscar os
+// metadados de um arquivo pelo seu ID, sem o seu conteúdo.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação contendo informações de configuração e acesso
+//     ao banco de dados.
+//   - fileId: o uuid.UUID do arquivo a ser buscado.
+//
+// Retorno:
+//   - db.FileModel: estrutura contendo os metadados do arquivo encontrado, com
+//     Blob nulo.
+//   - error: retorna um erro caso ocorra falha na execução da consulta ou no
+//     processamento do resultado.
+func QueryFileInfoById(ctx *context.Context, fileId uuid.UUID) (db.FileModel, error) {
+	var file db.FileModel
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	query := fmt.Sprintf(
+		`SELECT %s,%s,%s,%s,%s,%s
+		FROM %s.%s
+		WHERE %s = :file_id`,
+		schema.FileTable.Columns.FileId,
+		schema.FileTable.Columns.CategId,
+		schema.FileTable.Columns.Name,
+		schema.FileTable.Columns.Extension,
+		schema.FileTable.Columns.Mimetype,
+		schema.FileTable.Columns.UpdatedAt,
+		schema.Name,
+		schema.FileTable.Name,
+		schema.FileTable.Columns.FileId,
+	)
+
+	// Obtenção da linha
+	row := ctx.DB.QueryRow(query, sql.Named("file_id", fileId.String()))
+	err := row.Scan(
+		&file.FileId,
+		&file.CategId,
+		&file.Name,
+		&file.Extension,
+		&file.Mimetype,
+		&file.UpdatedAt,
+	)
+	if err != nil {
+		return file, fmt.Errorf("não foi possível obter arquivo")
+	}
+	file.Blob = nil
+	return file, nil
+}
+
+func UpdateUser(ctx *context.Context, userId uuid.UUID, version int64, p UserData) error {
+	// Validar senha, comparando com o nome de usuário novo ou atual
+	if p.Password != "" {
+		username := p.Username
+		if username == "" {
+			user, err := QueryUserById(ctx, userId)
+			if err != nil {
+				return err
+			}
+			username = user.Username
+		}
+		if err := ValidatePassword(ctx.Config.PasswordPolicy, username, p.Password); err != nil {
+			return err
+		}
+	}
+	if err := ValidateContact(p.Email, p.Phone); err != nil {
+		return err
+	}
+
+	// Validar período de validade, combinando com os valores atuais
+	if p.ValidFrom != nil || p.ValidUntil != nil {
+		user, err := QueryUserById(ctx, userId)
+		if err != nil {
+			return err
+		}
+		from, until := user.ValidFrom, user.ValidUntil
+		if p.ValidFrom != nil {
+			from = *p.ValidFrom
+		}
+		if p.ValidUntil != nil {
+			until = *p.ValidUntil
+		}
+		if err = ValidateValidity(from, until); err != nil {
+			return err
+		}
+	}
+
 	// Iniciar uma transação
 	tx, err := ctx.DB.Begin()
 	if err != nil {
@@ -628,7 +749,7 @@ func UpdateUser(ctx *context.Context, userId uuid.UUID, p UserData) error {
 	}
 
 	// Geração do Timestamp
-	ts := time.Now().Unix()
+	ts := nextVersion(version)
 
 	// Agendar rollback em caso de erro
 	defer rollback(ctx, tx, &err)
@@ -655,19 +776,50 @@ func UpdateUser(ctx *context.Context, userId uuid.UUID, p UserData) error {
 		args = append(args, sql.Named("password", hash))
 		set = append(set, schema.UserTable.Columns.Password+" = :password")
 	}
+	if p.MustChangePassword != nil {
+		args = append(args, sql.Named("must_change", boolToInt(*p.MustChangePassword)))
+		set = append(set, schema.UserTable.Columns.MustChangePassword+" = :must_change")
+	}
+	if p.Email != nil {
+		args = append(args, sql.Named("email", *p.Email))
+		set = append(set, schema.UserTable.Columns.Email+" = :email")
+	}
+	if p.Phone != nil {
+		args = append(args, sql.Named("phone", *p.Phone))
+		set = append(set, schema.UserTable.Columns.Phone+" = :phone")
+	}
+	if p.Active != nil {
+		args = append(args, sql.Named("active", boolToInt(*p.Active)))
+		set = append(set, schema.UserTable.Columns.Active+" = :active")
+	}
+	if p.ValidFrom != nil {
+		args = append(args, sql.Named("valid_from", *p.ValidFrom))
+		set = append(set, schema.UserTable.Columns.ValidFrom+" = :valid_from")
+	}
+	if p.ValidUntil != nil {
+		args = append(args, sql.Named("valid_until", *p.ValidUntil))
+		set = append(set, schema.UserTable.Columns.ValidUntil+" = :valid_until")
+	}
+	if p.EmailNotifications != nil {
+		args = append(args, sql.Named("email_notifications", boolToInt(*p.EmailNotifications)))
+		set = append(set, schema.UserTable.Columns.EmailNotifications+" = :email_notifications")
+	}
 	args = append(args, sql.Named("updated_at", ts))
 	set = append(set, schema.UserTable.Columns.UpdatedAt+" = :updated_at")
 
 	// Update query
+	versionCond, versionArgs := versionClause(schema.UserTable.Columns.UpdatedAt, version)
 	update := fmt.Sprintf(`UPDATE %s.%s
 				SET %s
-				WHERE %s = :user_id`,
+				WHERE %s = :user_id%s`,
 		schema.Name,
 		schema.UserTable.Name,
 		strings.Join(set, ","),
 		schema.UserTable.Columns.UserId,
+		versionCond,
 	)
 	args = append(args, sql.Named("user_id", userId.String()))
+	args = append(args, versionArgs...)
 
 	// Atualização
 	res, err := tx.Exec(update, args...)
@@ -678,6 +830,16 @@ func UpdateUser(ctx *context.Context, userId uuid.UUID, p UserData) error {
 		err = fmt.Errorf("mais de uma linha afetada")
 		ctx.Logger.Error("Erro ao atualizar usuário.", zap.Error(err))
 		return fmt.Errorf("não foi possível atualizar usuário")
+	} else if n == 0 && version != 0 {
+		err = ErrVersionConflict
+		return err
+	}
+
+	// Revogar as sessões do usuário após a troca de senha ou a desativação
+	if p.Password != "" || (p.Active != nil && !*p.Active) {
+		if err = revokeSessions(ctx, tx.Exec, true, userId); err != nil {
+			return fmt.Errorf("não foi possível atualizar usuário")
+		}
 	}
 
 	// Confirmar a transação
@@ -685,10 +847,14 @@ func UpdateUser(ctx *context.Context, userId uuid.UUID, p UserData) error {
 		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
 		return fmt.Errorf("erro ao confirmar transação")
 	}
+	publishChange(ctx, events.EntityUser, events.ActionUpdate, userId)
 	return nil
 }
 
-func UpdateCategory(ctx *context.Context, categId uuid.UUID, p CategData) error {
+func UpdateCategory(ctx *context.Context, categId uuid.UUID, version int64, p CategData) error {
+	// Escopo anterior, publicado caso a entidade seja transferida
+	prevUserId, prevCategId := changeScope(ctx, events.EntityCategory, categId)
+
 	// Iniciar uma transação
 	tx, err := ctx.DB.Begin()
 	if err != nil {
@@ -697,7 +863,7 @@ func UpdateCategory(ctx *context.Context, categId uuid.UUID, p CategData) error
 	}
 
 	// Geração do Timestamp
-	ts := time.Now().Unix()
+	ts := nextVersion(version)
 
 	// Checagem dos parâmetros a serem atualizados
 	schema := &ctx.Config.Database.Schema
@@ -718,15 +884,18 @@ func UpdateCategory(ctx *context.Context, categId uuid.UUID, p CategData) error
 	defer rollback(ctx, tx, &err)
 
 	// Update query
+	versionCond, versionArgs := versionClause(schema.CategTable.Columns.UpdatedAt, version)
 	update := fmt.Sprintf(`UPDATE %s.%s
 				SET %s
-				WHERE %s = :categ_id`,
+				WHERE %s = :categ_id%s`,
 		schema.Name,
 		schema.CategTable.Name,
 		strings.Join(set, ","),
 		schema.CategTable.Columns.CategId,
+		versionCond,
 	)
 	args = append(args, sql.Named("categ_id", categId.String()))
+	args = append(args, versionArgs...)
 
 	// Atualização
 	res, err := tx.Exec(update, args...)
@@ -737,6 +906,9 @@ func UpdateCategory(ctx *context.Context, categId uuid.UUID, p CategData) error
 		err = fmt.Errorf("mais de uma linha afetada")
 		ctx.Logger.Error("Erro ao atualizar categoria.", zap.Error(err))
 		return fmt.Errorf("não foi possível atualizar categoria")
+	} else if n == 0 && version != 0 {
+		err = ErrVersionConflict
+		return err
 	}
 
 	// Confirmar a transação
@@ -744,10 +916,14 @@ func UpdateCategory(ctx *context.Context, categId uuid.UUID, p CategData) error
 		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
 		return fmt.Errorf("erro ao confirmar transação")
 	}
+	publishMove(ctx, events.EntityCategory, categId, prevUserId, prevCategId)
 	return nil
 }
 
-func UpdateFile(ctx *context.Context, fileId uuid.UUID, p FileData) error {
+func UpdateFile(ctx *context.Context, fileId uuid.UUID, version int64, p FileData) error {
+	// Escopo anterior, publicado caso a entidade seja transferida
+	prevUserId, prevCategId := changeScope(ctx, events.EntityFile, fileId)
+
 	// Iniciar uma transação
 	tx, err := ctx.DB.Begin()
 	if err != nil {
@@ -756,7 +932,7 @@ func UpdateFile(ctx *context.Context, fileId uuid.UUID, p FileData) error {
 	}
 
 	// Geração do Timestamp
-	ts := time.Now().Unix()
+	ts := nextVersion(version)
 
 	// Checagem dos parâmetros a serem atualizados
 	schema := &ctx.Config.Database.Schema
@@ -784,21 +960,24 @@ func UpdateFile(ctx *context.Context, fileId uuid.UUID, p FileData) error {
 		set = append(set, schema.FileTable.Columns.Blob+" = :blob")
 	}
 	args = append(args, sql.Named("updated_at", ts))
-	set = append(set, schema.CategTable.Columns.UpdatedAt+" = :updated_at")
+	set = append(set, schema.FileTable.Columns.UpdatedAt+" = :updated_at")
 
 	// Agendar rollback em caso de erro
 	defer rollback(ctx, tx, &err)
 
 	// Update query
+	versionCond, versionArgs := versionClause(schema.FileTable.Columns.UpdatedAt, version)
 	update := fmt.Sprintf(`UPDATE %s.%s
 				SET %s
-				WHERE %s = :file_id`,
+				WHERE %s = :file_id%s`,
 		schema.Name,
 		schema.FileTable.Name,
 		strings.Join(set, ","),
 		schema.FileTable.Columns.FileId,
+		versionCond,
 	)
 	args = append(args, sql.Named("file_id", fileId.String()))
+	args = append(args, versionArgs...)
 
 	// Atualização
 	res, err := tx.Exec(update, args...)
@@ -809,6 +988,9 @@ func UpdateFile(ctx *context.Context, fileId uuid.UUID, p FileData) error {
 		err = fmt.Errorf("mais de uma linha afetada")
 		ctx.Logger.Error("Erro ao atualizar arquivo.", zap.Error(err))
 		return fmt.Errorf("não foi possível atualizar arquivo")
+	} else if n == 0 && version != 0 {
+		err = ErrVersionConflict
+		return err
 	}
 
 	// Confirmar a transação
@@ -816,10 +998,14 @@ func UpdateFile(ctx *context.Context, fileId uuid.UUID, p FileData) error {
 		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
 		return fmt.Errorf("erro ao confirmar transação")
 	}
+	publishMove(ctx, events.EntityFile, fileId, prevUserId, prevCategId)
 	return nil
 }
 
-func DeleteUser(ctx *context.Context, userId uuid.UUID) error {
+func DeleteUser(ctx *context.Context, userId uuid.UUID, version int64) error {
+	// Escopo da exclusão, publicada aos assinantes do fluxo de eventos
+	scopeUserId, scopeCategId := changeScope(ctx, events.EntityUser, userId)
+
 	// Iniciar uma transação
 	tx, err := ctx.DB.Begin()
 	if err != nil {
@@ -832,15 +1018,18 @@ func DeleteUser(ctx *context.Context, userId uuid.UUID) error {
 
 	// Delete query
 	schema := &ctx.Config.Database.Schema
+	versionCond, versionArgs := versionClause(schema.UserTable.Columns.UpdatedAt, version)
 	del := fmt.Sprintf(
-		"DELETE FROM %s.%s WHERE %s = :user_id",
+		"DELETE FROM %s.%s WHERE %s = :user_id%s",
 		schema.Name,
 		schema.UserTable.Name,
 		schema.UserTable.Columns.UserId,
+		versionCond,
 	)
+	args := append([]any{sql.Named("user_id", userId.String())}, versionArgs...)
 
 	// Exclusão
-	res, err := tx.Exec(del, sql.Named("user_id", userId.String()))
+	res, err := tx.Exec(del, args...)
 	if err != nil {
 		ctx.Logger.Error("Erro ao excluir usuário.", zap.Error(err))
 		return fmt.Errorf("não foi possível excluir usuário")
@@ -848,6 +1037,14 @@ func DeleteUser(ctx *context.Context, userId uuid.UUID) error {
 		err = fmt.Errorf("mais de uma linha afetada")
 		ctx.Logger.Error("Erro ao excluir usuário.", zap.Error(err))
 		return fmt.Errorf("não foi possível excluir usuário")
+	} else if n == 0 && version != 0 {
+		err = ErrVersionConflict
+		return err
+	}
+
+	// Revogar as sessões do usuário
+	if err = revokeSessions(ctx, tx.Exec, true, userId); err != nil {
+		return fmt.Errorf("não foi possível excluir usuário")
 	}
 
 	// Confirmar a transação
@@ -855,10 +1052,14 @@ func DeleteUser(ctx *context.Context, userId uuid.UUID) error {
 		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
 		return fmt.Errorf("erro ao confirmar transação")
 	}
+	publishScopedChange(ctx, events.EntityUser, events.ActionDelete, userId, scopeUserId, scopeCategId)
 	return nil
 }
 
-func DeleteCategory(ctx *context.Context, categId uuid.UUID) error {
+func DeleteCategory(ctx *context.Context, categId uuid.UUID, version int64) error {
+	// Escopo da exclusão, publicada aos assinantes do fluxo de eventos
+	scopeUserId, scopeCategId := changeScope(ctx, events.EntityCategory, categId)
+
 	// Iniciar uma transação
 	tx, err := ctx.DB.Begin()
 	if err != nil {
@@ -871,16 +1072,19 @@ func DeleteCategory(ctx *context.Context, categId uuid.UUID) error {
 
 	// Delete query
 	schema := &ctx.Config.Database.Schema
+	versionCond, versionArgs := versionClause(schema.CategTable.Columns.UpdatedAt, version)
 	del := fmt.Sprintf(
-		"DELETE FROM %s.%s WHERE %s = :categ_id",
+		"DELETE FROM %s.%s WHERE %s = :categ_id%s",
 		schema.Name,
 		schema.CategTable.Name,
 		schema.CategTable.Columns.CategId,
+		versionCond,
 	)
+	args := append([]any{sql.Named("categ_id", categId.String())}, versionArgs...)
 
 	// Exclusão
 	var res sql.Result
-	res, err = tx.Exec(del, sql.Named("categ_id", categId.String()))
+	res, err = tx.Exec(del, args...)
 	if err != nil {
 		ctx.Logger.Error("Erro ao excluir categoria.", zap.Error(err))
 		return fmt.Errorf("não foi possível excluir categoria")
@@ -888,6 +1092,9 @@ func DeleteCategory(ctx *context.Context, categId uuid.UUID) error {
 		err = fmt.Errorf("mais de uma linha afetada")
 		ctx.Logger.Error("Erro ao excluir categoria.", zap.Error(err))
 		return fmt.Errorf("não foi possível excluir categoria")
+	} else if n == 0 && version != 0 {
+		err = ErrVersionConflict
+		return err
 	}
 
 	// Confirmar a transação
@@ -895,10 +1102,14 @@ func DeleteCategory(ctx *context.Context, categId uuid.UUID) error {
 		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
 		return fmt.Errorf("erro ao confirmar transação")
 	}
+	publishScopedChange(ctx, events.EntityCategory, events.ActionDelete, categId, scopeUserId, scopeCategId)
 	return nil
 }
 
-func DeleteFile(ctx *context.Context, fileId uuid.UUID) error {
+func DeleteFile(ctx *context.Context, fileId uuid.UUID, version int64) error {
+	// Escopo da exclusão, publicada aos assinantes do fluxo de eventos
+	scopeUserId, scopeCategId := changeScope(ctx, events.EntityFile, fileId)
+
 	// Iniciar uma transação
 	tx, err := ctx.DB.Begin()
 	if err != nil {
@@ -911,15 +1122,18 @@ func DeleteFile(ctx *context.Context, fileId uuid.UUID) error {
 
 	// Delete query
 	schema := &ctx.Config.Database.Schema
+	versionCond, versionArgs := versionClause(schema.FileTable.Columns.UpdatedAt, version)
 	del := fmt.Sprintf(
-		"DELETE FROM %s.%s WHERE %s = :file_id",
+		"DELETE FROM %s.%s WHERE %s = :file_id%s",
 		schema.Name,
 		schema.FileTable.Name,
 		schema.FileTable.Columns.FileId,
+		versionCond,
 	)
+	args := append([]any{sql.Named("file_id", fileId.String())}, versionArgs...)
 
 	// Exclusão
-	res, err := tx.Exec(del, sql.Named("file_id", fileId.String()))
+	res, err := tx.Exec(del, args...)
 	if err != nil {
 		ctx.Logger.Error("Erro ao excluir arquivo.", zap.Error(err))
 		return fmt.Errorf("não foi possível excluir arquivo")
@@ -927,6 +1141,9 @@ func DeleteFile(ctx *context.Context, fileId uuid.UUID) error {
 		err = fmt.Errorf("mais de uma linha afetada")
 		ctx.Logger.Error("Erro ao excluir arquivo.", zap.Error(err))
 		return fmt.Errorf("não foi possível excluir arquivo")
+	} else if n == 0 && version != 0 {
+		err = ErrVersionConflict
+		return err
 	}
 
 	// Confirmar a transação
@@ -934,5 +1151,6 @@ func DeleteFile(ctx *context.Context, fileId uuid.UUID) error {
 		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
 		return fmt.Errorf("erro ao confirmar transação")
 	}
+	publishScopedChange(ctx, events.EntityFile, events.ActionDelete, fileId, scopeUserId, scopeCategId)
 	return nil
 }
@@ -0,0 +1,174 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	goora "github.com/sijms/go-ora/v2"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// Ações registradas na auditoria.
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// CreateAuditEntry registra uma ação na auditoria. A tabela de auditoria é
+// somente de inserção, portanto não há operações de alteração ou exclusão.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: dados da ação a ser registrada.
+//
+// Retorno:
+//   - error: erro caso não seja possível registrar a ação.
+func CreateAuditEntry(ctx *context.Context, p AuditData) error {
+	// Geração do UUID e Timestamp
+	ts := time.Now().Unix()
+	auditId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return fmt.Errorf("não foi possível criar UUID")
+	}
+
+	// Insert query
+	schema := &ctx.Config.Database.Schema
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s, %s, %s)
+		VALUES (:audit_id, :actor_id, :action, :entity_type, :entity_id, :diff, :client_ip, :created_at)`,
+		schema.Name,
+		schema.AuditTable.Name,
+		schema.AuditTable.Columns.AuditId,
+		schema.AuditTable.Columns.ActorId,
+		schema.AuditTable.Columns.Action,
+		schema.AuditTable.Columns.EntityType,
+		schema.AuditTable.Columns.EntityId,
+		schema.AuditTable.Columns.Diff,
+		schema.AuditTable.Columns.ClientIp,
+		schema.AuditTable.Columns.CreatedAt,
+	)
+
+	// Criação
+	_, err = ctx.DB.Exec(
+		insert,
+		sql.Named("audit_id", auditId.String()),
+		sql.Named("actor_id", p.ActorId.String()),
+		sql.Named("action", p.Action),
+		sql.Named("entity_type", p.EntityType),
+		sql.Named("entity_id", p.EntityId.String()),
+		sql.Named("diff", goora.Clob{String: p.Diff, Valid: true}),
+		sql.Named("client_ip", p.ClientIp),
+		sql.Named("created_at", ts),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao registrar auditoria.", zap.Error(err))
+		return fmt.Errorf("não foi possível registrar auditoria")
+	}
+	return nil
+}
+
+// QueryAuditEntries recupera os registros da auditoria que atendem aos filtros
+// informados, do mais recente para o mais antigo.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - f: filtros e paginação da consulta.
+//
+// Retorno:
+//   - []db.AuditModel: os registros encontrados.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryAuditEntries(ctx *context.Context, f AuditFilter) ([]db.AuditModel, error) {
+	var entries []db.AuditModel
+
+	// Filtros
+	schema := &ctx.Config.Database.Schema
+	ac := &schema.AuditTable.Columns
+	where, args := periodClause(ac.CreatedAt, f.Period)
+	if f.ActorId != uuid.Nil {
+		where = append(where, ac.ActorId+" = :actor_id")
+		args = append(args, sql.Named("actor_id", f.ActorId.String()))
+	}
+	if f.Action != "" {
+		where = append(where, ac.Action+" = :action")
+		args = append(args, sql.Named("action", f.Action))
+	}
+	if f.EntityType != "" {
+		where = append(where, ac.EntityType+" = :entity_type")
+		args = append(args, sql.Named("entity_type", f.EntityType))
+	}
+	if f.EntityId != uuid.Nil {
+		where = append(where, ac.EntityId+" = :entity_id")
+		args = append(args, sql.Named("entity_id", f.EntityId.String()))
+	}
+	filter := ""
+	if len(where) > 0 {
+		filter = "WHERE " + strings.Join(where, " AND ")
+	}
+	args = append(args, sql.Named("offset", f.Offset), sql.Named("limit", f.Limit))
+
+	// Query
+	query := fmt.Sprintf(
+		`SELECT %s, %s, %s, %s, %s, %s, %s, %s
+		FROM %s.%s
+		%s
+		ORDER BY %s DESC
+		OFFSET :offset ROWS FETCH NEXT :limit ROWS ONLY`,
+		ac.AuditId,
+		ac.ActorId,
+		ac.Action,
+		ac.EntityType,
+		ac.EntityId,
+		ac.Diff,
+		ac.ClientIp,
+		ac.CreatedAt,
+		schema.Name,
+		schema.AuditTable.Name,
+		filter,
+		ac.CreatedAt,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, args...)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar auditoria.", zap.Error(err))
+		return entries, fmt.Errorf("não foi possível obter a auditoria")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var e db.AuditModel
+		var diff string
+		err = rows.Scan(
+			&e.AuditId,
+			&e.ActorId,
+			&e.Action,
+			&e.EntityType,
+			&e.EntityId,
+			&diff,
+			&e.ClientIp,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao obter registro da auditoria.", zap.Error(err))
+			return entries, fmt.Errorf("não foi possível obter toda a auditoria")
+		}
+		if json.Valid([]byte(diff)) {
+			e.Diff = json.RawMessage(diff)
+		} else {
+			e.Diff = json.RawMessage("null")
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,150 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// ErrNotAuthenticated indica credenciais de login inválidas.
+var ErrNotAuthenticated = errors.New("não autenticado")
+
+// Authenticator verifica as credenciais do login de um usuário.
+type Authenticator interface {
+	// Authenticate verifica o nome de usuário e a senha, retornando os dados
+	// do usuário local autenticado ou ErrNotAuthenticated.
+	Authenticate(ctx *context.Context, p LoginParams) (LoginData, error)
+}
+
+// LocalAuthenticator verifica a senha com o hash (argon2id ou bcrypt) da
+// tabela de usuários. É o autenticador padrão. Após um login bem-sucedido, o
+// hash desatualizado é refeito com o algoritmo e os parâmetros configurados.
+type LocalAuthenticator struct{}
+
+// Authenticate implementa Authenticator.
+func (LocalAuthenticator) Authenticate(ctx *context.Context, p LoginParams) (LoginData, error) {
+	// Query
+	schema := &ctx.Config.Database.Schema
+	query := fmt.Sprintf(
+		`SELECT %s, %s, %s, %s
+		FROM %s.%s
+		WHERE %s = :username`,
+		schema.UserTable.Columns.UserId,
+		schema.UserTable.Columns.Name,
+		schema.UserTable.Columns.Password,
+		schema.UserTable.Columns.MustChangePassword,
+		schema.Name,
+		schema.UserTable.Name,
+		schema.UserTable.Columns.Username,
+	)
+
+	// Obtenção da linha
+	rows, err := ctx.DB.Query(query, sql.Named("username", p.Username))
+	if err != nil {
+		return LoginData{}, fmt.Errorf("usuário não encontrado")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var hash string
+		var mustChange int
+		data := LoginData{}
+		err = rows.Scan(&data.UserId, &data.Name, &hash, &mustChange)
+		if err != nil {
+			continue
+		}
+		data.MustChangePassword = mustChange != 0
+		if VerifyPassword(hash, p.Password) {
+			if PasswordNeedsRehash(ctx, hash) {
+				rehashPassword(ctx, data.UserId, hash, p.Password)
+			}
+			return data, nil
+		}
+	}
+
+	return LoginData{}, ErrNotAuthenticated
+}
+
+// NewAuthenticator obtém o autenticador do login configurado: LDAP, quando
+// habilitado, ou a senha local.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração.
+//
+// Retorno:
+//   - Authenticator: o autenticador configurado.
+func NewAuthenticator(ctx *context.Context) Authenticator {
+	if ctx.Config.Ldap.Enabled {
+		return LdapAuthenticator{}
+	}
+	return LocalAuthenticator{}
+}
+
+// queryLoginUser obtém os dados de login de um usuário local pelo nome de
+// usuário, sem verificar a senha.
+func queryLoginUser(ctx *context.Context, username string) (LoginData, error) {
+	schema := &ctx.Config.Database.Schema
+	uc := &schema.UserTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s, %s FROM %s.%s WHERE LOWER(%s) = LOWER(:username)`,
+		uc.UserId,
+		uc.Name,
+		schema.Name,
+		schema.UserTable.Name,
+		uc.Username,
+	)
+
+	var userId string
+	data := LoginData{}
+	row := ctx.DB.QueryRow(query, sql.Named("username", username))
+	if err := row.Scan(&userId, &data.Name); errors.Is(err, sql.ErrNoRows) {
+		return LoginData{}, ErrNotAuthenticated
+	} else if err != nil {
+		ctx.Logger.Error("Erro ao buscar usuário", zap.Error(err))
+		return LoginData{}, fmt.Errorf("não foi possível procurar usuário")
+	}
+
+	var err error
+	if data.UserId, err = uuid.Parse(userId); err != nil {
+		ctx.Logger.Error("Erro ao converter Id do usuário.", zap.Error(err))
+		return LoginData{}, fmt.Errorf("não foi possível converter Id do usuário")
+	}
+	return data, nil
+}
+
+// rehashPassword refaz o hash da senha de um usuário com o algoritmo e os
+// parâmetros configurados. A atualização só ocorre se o hash armazenado não
+// tiver mudado e não altera a versão do usuário. Falhas são apenas
+// registradas, sem impedir o login.
+func rehashPassword(ctx *context.Context, userId uuid.UUID, oldHash, password string) {
+	hash, err := HashPassword(ctx, password)
+	if err != nil {
+		return
+	}
+
+	schema := &ctx.Config.Database.Schema
+	uc := &schema.UserTable.Columns
+	update := fmt.Sprintf(
+		`UPDATE %s.%s SET %s = :password WHERE %s = :user_id AND %s = :old_password`,
+		schema.Name,
+		schema.UserTable.Name,
+		uc.Password,
+		uc.UserId,
+		uc.Password,
+	)
+	_, err = ctx.DB.Exec(
+		update,
+		sql.Named("password", hash),
+		sql.Named("user_id", userId.String()),
+		sql.Named("old_password", oldHash),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao atualizar hash da senha.", zap.Error(err))
+		return
+	}
+	ctx.Logger.Info("Hash da senha atualizado.", zap.String("user_id", userId.String()))
+}
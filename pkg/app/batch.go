@@ -0,0 +1,192 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/events"
+	"database/sql"
+	"fmt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// Operações suportadas em lote.
+const (
+	BatchMove   = "move"
+	BatchRename = "rename"
+	BatchDelete = "delete"
+)
+
+// Entidades suportadas em lote.
+const (
+	BatchFile     = "file"
+	BatchCategory = "category"
+)
+
+// Situações das operações de um lote.
+const (
+	// BatchStatusOk indica que a operação foi efetivada.
+	BatchStatusOk = "ok"
+	// BatchStatusFailed indica a operação que falhou e impediu o lote.
+	BatchStatusFailed = "failed"
+	// BatchStatusRolledBack indica que a operação foi executada, mas desfeita
+	// pela falha de outra operação.
+	BatchStatusRolledBack = "rolled_back"
+	// BatchStatusSkipped indica que a operação não chegou a ser executada.
+	BatchStatusSkipped = "skipped"
+)
+
+var (
+	// ErrBatchFailed indica que o lote não foi efetivado por falha em uma de
+	// suas operações.
+	ErrBatchFailed = errors.New("lote não efetivado")
+	// ErrEntityNotFound indica que a entidade da operação não existe.
+	ErrEntityNotFound = errors.New("entidade não encontrada")
+	// ErrInvalidOperation indica uma operação ou entidade não suportada.
+	ErrInvalidOperation = errors.New("operação inválida")
+)
+
+// batchStatement monta a instrução SQL de uma operação do lote.
+func batchStatement(ctx *context.Context, op BatchOperation) (string, []any, error) {
+	schema := &ctx.Config.Database.Schema
+
+	// Tabela e colunas da entidade
+	var table, idCol, targetCol, nameCol, updatedCol string
+	switch op.Entity {
+	case BatchFile:
+		fc := &schema.FileTable.Columns
+		table, idCol, targetCol = schema.FileTable.Name, fc.FileId, fc.CategId
+		nameCol, updatedCol = fc.Name, fc.UpdatedAt
+	case BatchCategory:
+		cc := &schema.CategTable.Columns
+		table, idCol, targetCol = schema.CategTable.Name, cc.CategId, cc.UserId
+		nameCol, updatedCol = cc.Name, cc.UpdatedAt
+	default:
+		return "", nil, ErrInvalidOperation
+	}
+	versionCond, args := versionClause(updatedCol, op.Version)
+	args = append(args, sql.Named("id", op.Id.String()))
+
+	// Instrução da operação
+	var set string
+	switch op.Op {
+	case BatchMove:
+		set = targetCol + " = :target_id"
+		args = append(args, sql.Named("target_id", op.TargetId.String()))
+	case BatchRename:
+		set = nameCol + " = :name"
+		args = append(args, sql.Named("name", op.Name))
+	case BatchDelete:
+		del := fmt.Sprintf(
+			"DELETE FROM %s.%s WHERE %s = :id%s",
+			schema.Name,
+			table,
+			idCol,
+			versionCond,
+		)
+		return del, args, nil
+	default:
+		return "", nil, ErrInvalidOperation
+	}
+	args = append(args, sql.Named("updated_at", nextVersion(op.Version)))
+	update := fmt.Sprintf(
+		"UPDATE %s.%s SET %s, %s = :updated_at WHERE %s = :id%s",
+		schema.Name,
+		table,
+		set,
+		updatedCol,
+		idCol,
+		versionCond,
+	)
+	return update, args, nil
+}
+
+// ExecuteBatch executa um lote de operações (movimentação, renomeação e
+// exclusão) de arquivos e categorias em uma única transação. O lote é
+// efetivado apenas se todas as operações forem bem-sucedidas; caso contrário,
+// nenhuma alteração é mantida.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - ops: operações do lote, executadas na ordem informada.
+//
+// Retorno:
+//   - []BatchResult: resultado de cada operação, na mesma ordem do lote.
+//   - error: ErrBatchFailed, caso uma das operações falhe, ou erro caso não
+//     seja possível iniciar ou efetivar a transação.
+func ExecuteBatch(ctx *context.Context, ops []BatchOperation) ([]BatchResult, error) {
+	results := make([]BatchResult, len(ops))
+	for i := range results {
+		results[i].Status = BatchStatusSkipped
+	}
+
+	// Escopo anterior das entidades, publicado aos assinantes do fluxo de
+	// eventos (as entidades do lote têm os mesmos nomes das do fluxo)
+	type scope struct{ userId, categId string }
+	scopes := make([]scope, len(ops))
+	for i, op := range ops {
+		scopes[i].userId, scopes[i].categId = changeScope(ctx, op.Entity, op.Id)
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return results, fmt.Errorf("erro ao iniciar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Execução das operações
+	for i, op := range ops {
+		stmt, args, opErr := batchStatement(ctx, op)
+		if opErr == nil {
+			var res sql.Result
+			if res, opErr = tx.Exec(stmt, args...); opErr != nil {
+				ctx.Logger.Error(
+					"Erro ao executar operação do lote.",
+					zap.Int("index", i),
+					zap.String("op", op.Op),
+					zap.String("entity", op.Entity),
+					zap.Error(opErr),
+				)
+				opErr = fmt.Errorf("não foi possível executar a operação")
+			} else if n, _ := res.RowsAffected(); n == 0 && op.Version != 0 {
+				opErr = ErrVersionConflict
+			} else if n == 0 {
+				opErr = ErrEntityNotFound
+			}
+		}
+
+		// Falha: desfazer as operações anteriores
+		if opErr != nil {
+			for j := range i {
+				results[j].Status = BatchStatusRolledBack
+			}
+			results[i] = BatchResult{Status: BatchStatusFailed, Err: opErr}
+			err = ErrBatchFailed
+			return results, err
+		}
+		results[i].Status = BatchStatusOk
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		for i := range results {
+			results[i].Status = BatchStatusRolledBack
+		}
+		return results, fmt.Errorf("erro ao confirmar transação")
+	}
+
+	// Publicação das alterações
+	for i, op := range ops {
+		if op.Op == BatchDelete {
+			publishScopedChange(ctx, op.Entity, events.ActionDelete, op.Id, scopes[i].userId, scopes[i].categId)
+		} else {
+			publishMove(ctx, op.Entity, op.Id, scopes[i].userId, scopes[i].categId)
+		}
+	}
+	return results, nil
+}
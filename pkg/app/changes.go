@@ -0,0 +1,108 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/events"
+	"github.com/google/uuid"
+	"time"
+)
+
+// changeScope obtém o proprietário e a categoria de uma entidade, usados
+// para decidir quais assinantes do fluxo de eventos podem ver a alteração.
+// Nas exclusões, deve ser chamada antes da remoção. Sem assinantes, nada é
+// consultado.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo o fluxo de eventos e a
+//     configuração do banco de dados.
+//   - entity: entidade (events.EntityUser, events.EntityCategory ou
+//     events.EntityFile).
+//   - id: identificador da entidade.
+//
+// Retorno:
+//   - userId: o usuário proprietário (vazio caso não encontrado).
+//   - categId: a categoria da entidade (vazio para usuários).
+func changeScope(ctx *context.Context, entity string, id uuid.UUID) (userId, categId string) {
+	if ctx.Events.Subscribers() == 0 {
+		return "", ""
+	}
+	switch entity {
+	case events.EntityUser:
+		return id.String(), ""
+	case events.EntityFile:
+		file, err := QueryFileInfoById(ctx, id)
+		if err != nil {
+			return "", ""
+		}
+		categId = file.CategId
+	case events.EntityCategory:
+		categId = id.String()
+	}
+	if parsed, err := uuid.Parse(categId); err == nil {
+		if categ, err := QueryCategoryById(ctx, parsed); err == nil {
+			userId = categ.UserId
+		}
+	}
+	return userId, categId
+}
+
+// publishScopedChange publica uma alteração de escopo já conhecido (ex.:
+// obtido por changeScope antes de uma exclusão).
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo o fluxo de eventos.
+//   - entity: entidade alterada.
+//   - action: ação realizada (events.ActionCreate, events.ActionUpdate ou
+//     events.ActionDelete).
+//   - id: identificador da entidade.
+//   - userId: usuário proprietário da entidade.
+//   - categId: categoria da entidade.
+func publishScopedChange(ctx *context.Context, entity, action string, id uuid.UUID, userId, categId string) {
+	ctx.Events.Publish(events.Event{
+		Entity:  entity,
+		Action:  action,
+		Id:      id.String(),
+		UserId:  userId,
+		CategId: categId,
+		At:      time.Now().Unix(),
+	})
+}
+
+// publishChange publica a criação ou alteração de uma entidade ao fluxo de
+// eventos, após a confirmação da transação.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo o fluxo de eventos e a
+//     configuração do banco de dados.
+//   - entity: entidade alterada.
+//   - action: ação realizada (events.ActionCreate ou events.ActionUpdate).
+//   - id: identificador da entidade.
+func publishChange(ctx *context.Context, entity, action string, id uuid.UUID) {
+	if ctx.Events.Subscribers() == 0 {
+		return
+	}
+	userId, categId := changeScope(ctx, entity, id)
+	publishScopedChange(ctx, entity, action, id, userId, categId)
+}
+
+// publishMove publica a alteração de uma entidade que pode ter mudado de
+// proprietário ou de categoria. A alteração também é publicada no escopo
+// anterior, para que o antigo proprietário deixe de exibir a entidade.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo o fluxo de eventos e a
+//     configuração do banco de dados.
+//   - entity: entidade alterada.
+//   - id: identificador da entidade.
+//   - prevUserId: usuário proprietário antes da alteração.
+//   - prevCategId: categoria antes da alteração.
+func publishMove(ctx *context.Context, entity string, id uuid.UUID, prevUserId, prevCategId string) {
+	if ctx.Events.Subscribers() == 0 {
+		return
+	}
+	userId, categId := changeScope(ctx, entity, id)
+	publishScopedChange(ctx, entity, events.ActionUpdate, id, userId, categId)
+	if prevUserId != userId || prevCategId != categId {
+		publishScopedChange(ctx, entity, events.ActionUpdate, id, prevUserId, prevCategId)
+	}
+}
@@ -19,6 +19,76 @@ import (
 // CfgFile é o nome padrão do arquivo de configuração JSON da aplicação.
 const CfgFile = "config.json"
 
+// DefaultRefreshExpires é o tempo de expiração padrão, em minutos, dos
+// refresh tokens (7 dias).
+const DefaultRefreshExpires = 7 * 24 * 60
+
+// DefaultResetExpires é o tempo de expiração padrão, em minutos, dos tokens
+// de redefinição de senha (24 horas).
+const DefaultResetExpires = 24 * 60
+
+// DefaultAccountExpiryInterval é o intervalo padrão, em minutos, da
+// desativação das contas com validade encerrada.
+const DefaultAccountExpiryInterval = 60
+
+// DefaultPasswordMinLength é o número mínimo padrão de caracteres de uma
+// senha.
+const DefaultPasswordMinLength = 4
+
+// DefaultPasswordHash define o algoritmo e os parâmetros padrão do hash das
+// senhas: argon2id com a segunda configuração recomendada pela RFC 9106.
+var DefaultPasswordHash = config.PasswordHash{
+	Algorithm:         "argon2id",
+	Argon2Memory:      64 * 1024,
+	Argon2Iterations:  3,
+	Argon2Parallelism: 4,
+	BcryptCost:        10,
+}
+
+// DefaultTotpIssuer é o emissor padrão exibido nos aplicativos
+// autenticadores.
+const DefaultTotpIssuer = "Agros Arquivos"
+
+// DefaultOidcScopes são os escopos padrão solicitados ao provedor OpenID
+// Connect.
+var DefaultOidcScopes = []string{"openid", "email", "profile"}
+
+// DefaultLdapTimeout é o tempo limite padrão das operações LDAP, em
+// segundos.
+const DefaultLdapTimeout = 10
+
+// DefaultLdapGroupFilter é o filtro padrão da busca dos grupos LDAP do
+// usuário.
+const DefaultLdapGroupFilter = "(member={dn})"
+
+// DefaultSmtpPort é a porta padrão do servidor SMTP.
+const DefaultSmtpPort = 25
+
+// DefaultSmtpTimeout é o tempo limite padrão da conexão SMTP, em segundos.
+const DefaultSmtpTimeout = 10
+
+// DefaultSmtpBatchWindow é a janela padrão, em segundos, de agrupamento das
+// notificações por e-mail.
+const DefaultSmtpBatchWindow = 300
+
+// DefaultWebhooks é a configuração padrão da entrega dos eventos aos
+// webhooks.
+var DefaultWebhooks = config.Webhooks{
+	MaxAttempts: 5,
+	BaseDelay:   2,
+	Timeout:     10,
+	Workers:     4,
+	QueueSize:   256,
+}
+
+// DefaultLockout é a política padrão de bloqueio de login.
+var DefaultLockout = config.Lockout{
+	MaxAttempts:   5,
+	IpMaxAttempts: 20,
+	BaseDelay:     30,
+	MaxDelay:      15 * 60,
+}
+
 // LoadConfig lê e carrega as configurações da aplicação a partir de um arquivo
 // JSON padrão.
 //
@@ -62,5 +132,85 @@ func LoadConfig(logr *zap.Logger) (*config.Config, error) {
 		cfg.Environment = "development"
 	}
 
+	// Valores padrão
+	if cfg.RefreshExpires <= 0 {
+		cfg.RefreshExpires = DefaultRefreshExpires
+	}
+	if cfg.ResetExpires <= 0 {
+		cfg.ResetExpires = DefaultResetExpires
+	}
+	if cfg.AccountExpiryInterval <= 0 {
+		cfg.AccountExpiryInterval = DefaultAccountExpiryInterval
+	}
+	if cfg.Lockout.MaxAttempts <= 0 {
+		cfg.Lockout.MaxAttempts = DefaultLockout.MaxAttempts
+	}
+	if cfg.Lockout.IpMaxAttempts <= 0 {
+		cfg.Lockout.IpMaxAttempts = DefaultLockout.IpMaxAttempts
+	}
+	if cfg.Lockout.BaseDelay <= 0 {
+		cfg.Lockout.BaseDelay = DefaultLockout.BaseDelay
+	}
+	if cfg.Lockout.MaxDelay <= 0 {
+		cfg.Lockout.MaxDelay = DefaultLockout.MaxDelay
+	}
+	if cfg.Totp.Issuer == "" {
+		cfg.Totp.Issuer = DefaultTotpIssuer
+	}
+	if len(cfg.Oidc.Scopes) == 0 {
+		cfg.Oidc.Scopes = DefaultOidcScopes
+	}
+	if cfg.Oidc.PostLoginRedirect == "" {
+		cfg.Oidc.PostLoginRedirect = "/"
+	}
+	if cfg.Ldap.Timeout <= 0 {
+		cfg.Ldap.Timeout = DefaultLdapTimeout
+	}
+	if cfg.Ldap.GroupFilter == "" {
+		cfg.Ldap.GroupFilter = DefaultLdapGroupFilter
+	}
+	if cfg.Smtp.Port <= 0 {
+		cfg.Smtp.Port = DefaultSmtpPort
+	}
+	if cfg.Smtp.Timeout <= 0 {
+		cfg.Smtp.Timeout = DefaultSmtpTimeout
+	}
+	if cfg.Smtp.BatchWindow <= 0 {
+		cfg.Smtp.BatchWindow = DefaultSmtpBatchWindow
+	}
+	if cfg.Webhooks.MaxAttempts <= 0 {
+		cfg.Webhooks.MaxAttempts = DefaultWebhooks.MaxAttempts
+	}
+	if cfg.Webhooks.BaseDelay <= 0 {
+		cfg.Webhooks.BaseDelay = DefaultWebhooks.BaseDelay
+	}
+	if cfg.Webhooks.Timeout <= 0 {
+		cfg.Webhooks.Timeout = DefaultWebhooks.Timeout
+	}
+	if cfg.Webhooks.Workers <= 0 {
+		cfg.Webhooks.Workers = DefaultWebhooks.Workers
+	}
+	if cfg.Webhooks.QueueSize <= 0 {
+		cfg.Webhooks.QueueSize = DefaultWebhooks.QueueSize
+	}
+	if cfg.PasswordPolicy.MinLength <= 0 {
+		cfg.PasswordPolicy.MinLength = DefaultPasswordMinLength
+	}
+	if cfg.PasswordHash.Algorithm == "" {
+		cfg.PasswordHash.Algorithm = DefaultPasswordHash.Algorithm
+	}
+	if cfg.PasswordHash.Argon2Memory == 0 {
+		cfg.PasswordHash.Argon2Memory = DefaultPasswordHash.Argon2Memory
+	}
+	if cfg.PasswordHash.Argon2Iterations == 0 {
+		cfg.PasswordHash.Argon2Iterations = DefaultPasswordHash.Argon2Iterations
+	}
+	if cfg.PasswordHash.Argon2Parallelism == 0 {
+		cfg.PasswordHash.Argon2Parallelism = DefaultPasswordHash.Argon2Parallelism
+	}
+	if cfg.PasswordHash.BcryptCost <= 0 {
+		cfg.PasswordHash.BcryptCost = DefaultPasswordHash.BcryptCost
+	}
+
 	return cfg, nil
 }
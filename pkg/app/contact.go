@@ -0,0 +1,61 @@
+package app
+
+import (
+	"github.com/pkg/errors"
+	"net/mail"
+	"regexp"
+)
+
+var (
+	// ErrInvalidEmail indica um e-mail de contato em formato inválido.
+	ErrInvalidEmail = errors.New("e-mail inválido")
+	// ErrInvalidPhone indica um telefone de contato em formato inválido.
+	ErrInvalidPhone = errors.New("telefone inválido")
+)
+
+// phonePattern aceita telefones com 8 a 15 dígitos, opcionalmente com o
+// prefixo internacional "+" e separadores (espaços, hífens, pontos e
+// parênteses).
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ().-]{6,22}[0-9]$`)
+
+// maxEmailLength é o tamanho máximo de um e-mail (RFC 5321).
+const maxEmailLength = 254
+
+// ValidateContact valida os dados de contato de um usuário. Valores nulos
+// ou vazios são aceitos, pois os dados de contato são opcionais.
+//
+// Parâmetros:
+//   - email: e-mail de contato.
+//   - phone: telefone de contato.
+//
+// Retorno:
+//   - error: ErrInvalidEmail, ErrInvalidPhone ou nil caso os dados sejam
+//     válidos.
+func ValidateContact(email, phone *string) error {
+	if email != nil && *email != "" {
+		addr, err := mail.ParseAddress(*email)
+		if err != nil || addr.Address != *email || len(*email) > maxEmailLength {
+			return ErrInvalidEmail
+		}
+	}
+	if phone != nil && *phone != "" {
+		digits := 0
+		for _, r := range *phone {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		if !phonePattern.MatchString(*phone) || digits < 8 || digits > 15 {
+			return ErrInvalidPhone
+		}
+	}
+	return nil
+}
+
+// derefString retorna o valor de uma string opcional ou vazio, se nula.
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
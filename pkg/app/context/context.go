@@ -4,6 +4,12 @@
 package context
 
 import (
+	"agros_arquivos_patrocinadoras/pkg/app/events"
+	"agros_arquivos_patrocinadoras/pkg/app/keyring"
+	"agros_arquivos_patrocinadoras/pkg/app/lockout"
+	"agros_arquivos_patrocinadoras/pkg/app/mailer"
+	"agros_arquivos_patrocinadoras/pkg/app/oidc"
+	"agros_arquivos_patrocinadoras/pkg/app/webhook"
 	"agros_arquivos_patrocinadoras/pkg/types/config"
 	"database/sql"
 	"github.com/google/uuid"
@@ -25,6 +31,22 @@ type Context struct {
 	// consulta e modificação de dados.
 	DB      *sql.DB
 	AdminId uuid.UUID
+	// Lockout contabiliza as falhas de login e os bloqueios temporários.
+	Lockout *lockout.Tracker
+	// Oidc é o cliente do login único por OpenID Connect.
+	Oidc *oidc.Client
+	// Keyring contém as chaves assimétricas dos tokens JWT. Nulo indica a
+	// assinatura HS256 com o segredo JWT.
+	Keyring *keyring.Keyring
+	// Mailer agrupa as notificações por e-mail dos arquivos novos ou
+	// alterados. Nulo desabilita as notificações.
+	Mailer *mailer.Batcher
+	// Events distribui as alterações das entidades ao fluxo de eventos das
+	// telas. Nulo desabilita o fluxo.
+	Events *events.Broker
+	// Webhooks executa as entregas dos eventos aos webhooks. Nulo desabilita
+	// as entregas em segundo plano.
+	Webhooks *webhook.Queue
 }
 
 // GetContext retorna o contexto da aplicação a partir do contexto da
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/events"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+// CopyCategory copia uma categoria, com todos os seus arquivos, para cada um
+// dos usuários informados, em uma única transação. O conteúdo dos arquivos é
+// copiado pelo próprio banco (INSERT ... SELECT), sem trafegar pela aplicação,
+// permitindo que o armazenamento compartilhe os BLOBs quando configurado para
+// deduplicação (ex.: SecureFiles com DEDUPLICATE).
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - categId: identificador da categoria original.
+//   - userIds: identificadores dos usuários destino das cópias.
+//
+// Retorno:
+//   - []db.CategoryCopy: as categorias e arquivos criados para cada usuário.
+//   - error: erro caso não seja possível realizar alguma das cópias.
+func CopyCategory(ctx *context.Context, categId uuid.UUID, userIds []uuid.UUID) ([]db.CategoryCopy, error) {
+	var copies []db.CategoryCopy
+
+	// Categoria e arquivos originais
+	categ, err := QueryCategoryById(ctx, categId)
+	if err != nil {
+		return copies, err
+	}
+	files, err := QueryAllFiles(ctx, categId)
+	if err != nil {
+		return copies, err
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return copies, fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Insert queries
+	schema := &ctx.Config.Database.Schema
+	cc := &schema.CategTable.Columns
+	fc := &schema.FileTable.Columns
+	insertCateg := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s)
+		VALUES (:categ_id, :user_id, :name, :updated_at)`,
+		schema.Name,
+		schema.CategTable.Name,
+		cc.CategId,
+		cc.UserId,
+		cc.Name,
+		cc.UpdatedAt,
+	)
+	insertFile := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s, %s)
+		SELECT :file_id, :categ_id, %s, %s, %s, %s, :updated_at
+		FROM %s.%s
+		WHERE %s = :src_file_id`,
+		schema.Name,
+		schema.FileTable.Name,
+		fc.FileId,
+		fc.CategId,
+		fc.Name,
+		fc.Extension,
+		fc.Mimetype,
+		fc.Blob,
+		fc.UpdatedAt,
+		fc.Name,
+		fc.Extension,
+		fc.Mimetype,
+		fc.Blob,
+		schema.Name,
+		schema.FileTable.Name,
+		fc.FileId,
+	)
+
+	// Cópia para cada usuário
+	ts := time.Now().Unix()
+	for _, userId := range userIds {
+		var newCategId uuid.UUID
+		if newCategId, err = uuid.NewUUID(); err != nil {
+			ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+			return copies, fmt.Errorf("não foi possível criar UUID")
+		}
+		_, err = tx.Exec(
+			insertCateg,
+			sql.Named("categ_id", newCategId.String()),
+			sql.Named("user_id", userId.String()),
+			sql.Named("name", categ.Name),
+			sql.Named("updated_at", ts),
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao copiar categoria.", zap.Error(err))
+			return copies, fmt.Errorf("não foi possível copiar categoria")
+		}
+
+		c := db.CategoryCopy{
+			UserId:  userId.String(),
+			CategId: newCategId.String(),
+			Files:   make(map[string]string, len(files)),
+		}
+		for _, f := range files {
+			var newFileId uuid.UUID
+			if newFileId, err = uuid.NewUUID(); err != nil {
+				ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+				return copies, fmt.Errorf("não foi possível criar UUID")
+			}
+			_, err = tx.Exec(
+				insertFile,
+				sql.Named("file_id", newFileId.String()),
+				sql.Named("categ_id", newCategId.String()),
+				sql.Named("updated_at", ts),
+				sql.Named("src_file_id", f.FileId),
+			)
+			if err != nil {
+				ctx.Logger.Error("Erro ao copiar arquivo.", zap.Error(err))
+				return copies, fmt.Errorf("não foi possível copiar arquivo")
+			}
+			c.Files[f.FileId] = newFileId.String()
+		}
+		copies = append(copies, c)
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return nil, fmt.Errorf("não foi possível confirmar transação")
+	}
+
+	// Publicação das categorias e arquivos criados
+	for _, c := range copies {
+		categId := uuid.MustParse(c.CategId)
+		publishScopedChange(ctx, events.EntityCategory, events.ActionCreate, categId, c.UserId, c.CategId)
+		for _, fileId := range c.Files {
+			publishScopedChange(ctx, events.EntityFile, events.ActionCreate, uuid.MustParse(fileId), c.UserId, c.CategId)
+		}
+	}
+	return copies, nil
+}
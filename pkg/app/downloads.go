@@ -0,0 +1,429 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+// Intervalos suportados para o agrupamento de downloads por período.
+const (
+	IntervalDay   = "day"
+	IntervalMonth = "month"
+	IntervalYear  = "year"
+)
+
+// periodClause monta as condições SQL para filtrar uma coluna de timestamp
+// pelo intervalo informado.
+//
+// Parâmetros:
+//   - column: nome qualificado da coluna de timestamp.
+//   - p: intervalo de tempo do relatório.
+//
+// Retorno:
+//   - []string: condições SQL a serem unidas por AND.
+//   - []any: argumentos nomeados correspondentes às condições.
+func periodClause(column string, p ReportPeriod) ([]string, []any) {
+	var where []string
+	var args []any
+	if p.From > 0 {
+		where = append(where, column+" >= :period_from")
+		args = append(args, sql.Named("period_from", p.From))
+	}
+	if p.To > 0 {
+		where = append(where, column+" <= :period_to")
+		args = append(args, sql.Named("period_to", p.To))
+	}
+	return where, args
+}
+
+// CreateDownload registra o download de um arquivo por um usuário.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: dados do download a ser registrado.
+//
+// Retorno:
+//   - error: erro caso não seja possível registrar o download.
+func CreateDownload(ctx *context.Context, p DownloadData) error {
+	// Geração do UUID e Timestamp
+	ts := time.Now().Unix()
+	downloadId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return fmt.Errorf("não foi possível criar UUID")
+	}
+
+	// Insert query
+	schema := &ctx.Config.Database.Schema
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s)
+		VALUES (:download_id, :file_id, :user_id, :client_ip, :bytes, :downloaded_at)`,
+		schema.Name,
+		schema.DownloadTable.Name,
+		schema.DownloadTable.Columns.DownloadId,
+		schema.DownloadTable.Columns.FileId,
+		schema.DownloadTable.Columns.UserId,
+		schema.DownloadTable.Columns.ClientIp,
+		schema.DownloadTable.Columns.Bytes,
+		schema.DownloadTable.Columns.DownloadedAt,
+	)
+
+	// Criação
+	_, err = ctx.DB.Exec(
+		insert,
+		sql.Named("download_id", downloadId.String()),
+		sql.Named("file_id", p.FileId.String()),
+		sql.Named("user_id", p.UserId.String()),
+		sql.Named("client_ip", p.ClientIp),
+		sql.Named("bytes", p.Bytes),
+		sql.Named("downloaded_at", ts),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao registrar download.", zap.Error(err))
+		return fmt.Errorf("não foi possível registrar download")
+	}
+	return nil
+}
+
+// QueryDownloadsByFile recupera a quantidade de downloads de cada arquivo no
+// intervalo informado. Arquivos sem downloads no intervalo não são listados.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: intervalo de tempo do relatório.
+//
+// Retorno:
+//   - []db.FileDownloadStats: estatísticas de download por arquivo, ordenadas
+//     pela quantidade de downloads.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryDownloadsByFile(ctx *context.Context, p ReportPeriod) ([]db.FileDownloadStats, error) {
+	var stats []db.FileDownloadStats
+
+	// Filtro do período
+	schema := &ctx.Config.Database.Schema
+	dc := &schema.DownloadTable.Columns
+	where, args := periodClause("d."+dc.DownloadedAt, p)
+	filter := ""
+	if len(where) > 0 {
+		filter = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	// Query
+	query := fmt.Sprintf(
+		`SELECT f.%s, f.%s, c.%s, c.%s, c.%s, COUNT(*), SUM(d.%s), MAX(d.%s)
+		FROM %s.%s d
+		JOIN %s.%s f ON f.%s = d.%s
+		JOIN %s.%s c ON c.%s = f.%s
+		%s
+		GROUP BY f.%s, f.%s, c.%s, c.%s, c.%s
+		ORDER BY COUNT(*) DESC`,
+		schema.FileTable.Columns.FileId,
+		schema.FileTable.Columns.Name,
+		schema.CategTable.Columns.CategId,
+		schema.CategTable.Columns.Name,
+		schema.CategTable.Columns.UserId,
+		dc.Bytes,
+		dc.DownloadedAt,
+		schema.Name,
+		schema.DownloadTable.Name,
+		schema.Name,
+		schema.FileTable.Name,
+		schema.FileTable.Columns.FileId,
+		dc.FileId,
+		schema.Name,
+		schema.CategTable.Name,
+		schema.CategTable.Columns.CategId,
+		schema.FileTable.Columns.CategId,
+		filter,
+		schema.FileTable.Columns.FileId,
+		schema.FileTable.Columns.Name,
+		schema.CategTable.Columns.CategId,
+		schema.CategTable.Columns.Name,
+		schema.CategTable.Columns.UserId,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, args...)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar downloads por arquivo.", zap.Error(err))
+		return stats, fmt.Errorf("não foi possível obter os downloads")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var s db.FileDownloadStats
+		err = rows.Scan(
+			&s.FileId,
+			&s.FileName,
+			&s.CategId,
+			&s.CategName,
+			&s.UserId,
+			&s.Downloads,
+			&s.Bytes,
+			&s.LastDownloadAt,
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao obter downloads do arquivo.", zap.Error(err))
+			return stats, fmt.Errorf("não foi possível obter todos os downloads")
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// QueryDownloadsByUser recupera a quantidade de downloads realizados por cada
+// usuário (patrocinadora) no intervalo informado.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: intervalo de tempo do relatório.
+//
+// Retorno:
+//   - []db.UserDownloadStats: estatísticas de download por usuário, ordenadas
+//     pela quantidade de downloads.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryDownloadsByUser(ctx *context.Context, p ReportPeriod) ([]db.UserDownloadStats, error) {
+	var stats []db.UserDownloadStats
+
+	// Filtro do período
+	schema := &ctx.Config.Database.Schema
+	dc := &schema.DownloadTable.Columns
+	where, args := periodClause("d."+dc.DownloadedAt, p)
+	filter := ""
+	if len(where) > 0 {
+		filter = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	// Query
+	query := fmt.Sprintf(
+		`SELECT u.%s, u.%s, u.%s, COUNT(*), COUNT(DISTINCT d.%s), SUM(d.%s), MAX(d.%s)
+		FROM %s.%s d
+		JOIN %s.%s u ON u.%s = d.%s
+		%s
+		GROUP BY u.%s, u.%s, u.%s
+		ORDER BY COUNT(*) DESC`,
+		schema.UserTable.Columns.UserId,
+		schema.UserTable.Columns.Username,
+		schema.UserTable.Columns.Name,
+		dc.FileId,
+		dc.Bytes,
+		dc.DownloadedAt,
+		schema.Name,
+		schema.DownloadTable.Name,
+		schema.Name,
+		schema.UserTable.Name,
+		schema.UserTable.Columns.UserId,
+		dc.UserId,
+		filter,
+		schema.UserTable.Columns.UserId,
+		schema.UserTable.Columns.Username,
+		schema.UserTable.Columns.Name,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, args...)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar downloads por usuário.", zap.Error(err))
+		return stats, fmt.Errorf("não foi possível obter os downloads")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var s db.UserDownloadStats
+		err = rows.Scan(
+			&s.UserId,
+			&s.Username,
+			&s.Name,
+			&s.Downloads,
+			&s.Files,
+			&s.Bytes,
+			&s.LastDownloadAt,
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao obter downloads do usuário.", zap.Error(err))
+			return stats, fmt.Errorf("não foi possível obter todos os downloads")
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// periodFormats contém, para cada intervalo, o formato do TRUNC (Oracle) e
+// os layouts do rótulo do período (Oracle e Go).
+var periodFormats = map[string][3]string{
+	IntervalDay:   {"DD", "YYYY-MM-DD", "2006-01-02"},
+	IntervalMonth: {"MM", "YYYY-MM", "2006-01"},
+	IntervalYear:  {"YYYY", "YYYY", "2006"},
+}
+
+// localDateExpr converte uma coluna de tempo Unix em uma data (DATE) no fuso
+// horário local do servidor. O deslocamento é inserido como literal, e não
+// como argumento, para que a expressão do SELECT seja idêntica à do GROUP BY.
+func localDateExpr(column string) string {
+	_, offset := time.Now().Zone()
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	return fmt.Sprintf(
+		"CAST(FROM_TZ(CAST(DATE '1970-01-01' + NUMTODSINTERVAL(%s, 'SECOND') AS TIMESTAMP), 'UTC')"+
+			" AT TIME ZONE '%c%02d:%02d' AS DATE)",
+		column,
+		sign,
+		offset/3600,
+		offset%3600/60,
+	)
+}
+
+// QueryDownloadsByPeriod recupera a quantidade de downloads agrupada por
+// período (dia, mês ou ano), no fuso horário local do servidor. O
+// agrupamento é realizado pelo banco de dados.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: intervalo de tempo do relatório.
+//   - interval: agrupamento desejado (IntervalDay, IntervalMonth ou
+//     IntervalYear).
+//
+// Retorno:
+//   - []db.PeriodDownloadStats: estatísticas de download por período, em
+//     ordem cronológica.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryDownloadsByPeriod(
+	ctx *context.Context,
+	p ReportPeriod,
+	interval string,
+) ([]db.PeriodDownloadStats, error) {
+	var stats []db.PeriodDownloadStats
+	formats, ok := periodFormats[interval]
+	if !ok {
+		formats = periodFormats[IntervalDay]
+	}
+
+	// Filtro do período
+	schema := &ctx.Config.Database.Schema
+	dc := &schema.DownloadTable.Columns
+	where, args := periodClause(dc.DownloadedAt, p)
+	filter := ""
+	if len(where) > 0 {
+		filter = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	// Query
+	period := fmt.Sprintf("TRUNC(%s, '%s')", localDateExpr(dc.DownloadedAt), formats[0])
+	query := fmt.Sprintf(
+		`SELECT TO_CHAR(%s, '%s'), COUNT(*), NVL(SUM(%s), 0)
+		FROM %s.%s %s
+		GROUP BY %s
+		ORDER BY %s`,
+		period,
+		formats[1],
+		dc.Bytes,
+		schema.Name,
+		schema.DownloadTable.Name,
+		filter,
+		period,
+		period,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, args...)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar downloads por período.", zap.Error(err))
+		return stats, fmt.Errorf("não foi possível obter os downloads")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var s db.PeriodDownloadStats
+		if err = rows.Scan(&s.Period, &s.Downloads, &s.Bytes); err != nil {
+			ctx.Logger.Error("Erro ao obter downloads do período.", zap.Error(err))
+			return stats, fmt.Errorf("não foi possível obter todos os downloads")
+		}
+		if start, err := time.ParseInLocation(formats[2], s.Period, time.Local); err == nil {
+			s.Start = start.Unix()
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// QueryUndownloadedFiles recupera os arquivos que não foram baixados nenhuma
+// vez no intervalo informado.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: intervalo de tempo do relatório.
+//
+// Retorno:
+//   - []db.FileDownloadStats: arquivos sem downloads, com contadores zerados.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryUndownloadedFiles(ctx *context.Context, p ReportPeriod) ([]db.FileDownloadStats, error) {
+	var stats []db.FileDownloadStats
+
+	// Filtro do período dentro da subconsulta
+	schema := &ctx.Config.Database.Schema
+	dc := &schema.DownloadTable.Columns
+	where, args := periodClause("d."+dc.DownloadedAt, p)
+	where = append([]string{fmt.Sprintf("d.%s = f.%s", dc.FileId, schema.FileTable.Columns.FileId)}, where...)
+
+	// Query
+	query := fmt.Sprintf(
+		`SELECT f.%s, f.%s, c.%s, c.%s, c.%s
+		FROM %s.%s f
+		JOIN %s.%s c ON c.%s = f.%s
+		WHERE NOT EXISTS (SELECT 1 FROM %s.%s d WHERE %s)
+		ORDER BY c.%s, f.%s`,
+		schema.FileTable.Columns.FileId,
+		schema.FileTable.Columns.Name,
+		schema.CategTable.Columns.CategId,
+		schema.CategTable.Columns.Name,
+		schema.CategTable.Columns.UserId,
+		schema.Name,
+		schema.FileTable.Name,
+		schema.Name,
+		schema.CategTable.Name,
+		schema.CategTable.Columns.CategId,
+		schema.FileTable.Columns.CategId,
+		schema.Name,
+		schema.DownloadTable.Name,
+		strings.Join(where, " AND "),
+		schema.CategTable.Columns.UserId,
+		schema.FileTable.Columns.Name,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, args...)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar arquivos sem downloads.", zap.Error(err))
+		return stats, fmt.Errorf("não foi possível obter os arquivos")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var s db.FileDownloadStats
+		err = rows.Scan(&s.FileId, &s.FileName, &s.CategId, &s.CategName, &s.UserId)
+		if err != nil {
+			ctx.Logger.Error("Erro ao obter arquivo.", zap.Error(err))
+			return stats, fmt.Errorf("não foi possível obter todos os arquivos")
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
@@ -0,0 +1,160 @@
+// Package events distribui as alterações das entidades (usuários, categorias
+// e arquivos) aos assinantes conectados, como o fluxo Server-Sent Events das
+// telas de administração e do usuário.
+//
+// O estado é mantido em memória, em um único processo. A publicação nunca
+// bloqueia: os eventos de um assinante lento são descartados quando o seu
+// buffer está cheio. Os métodos de um *Broker nulo descartam os eventos, o
+// que permite usar um contexto sem fluxo de eventos (ex.: testes).
+package events
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Entidades dos eventos.
+const (
+	EntityUser     = "user"
+	EntityCategory = "category"
+	EntityFile     = "file"
+)
+
+// Ações dos eventos.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// DefaultBuffer é o número padrão de eventos retidos para cada assinante.
+const DefaultBuffer = 64
+
+// Event é a criação, alteração ou exclusão de uma entidade.
+type Event struct {
+	// Entity é a entidade alterada (EntityUser, EntityCategory ou
+	// EntityFile).
+	Entity string `json:"entity"`
+	// Action é a ação realizada (ActionCreate, ActionUpdate ou
+	// ActionDelete).
+	Action string `json:"action"`
+	// Id é o identificador da entidade.
+	Id string `json:"id"`
+	// UserId é o usuário proprietário da entidade: o próprio usuário, o
+	// dono da categoria ou o dono da categoria do arquivo.
+	UserId string `json:"user_id,omitempty"`
+	// CategId é a categoria da entidade (categorias e arquivos).
+	CategId string `json:"categ_id,omitempty"`
+	// At é o momento da alteração, em tempo Unix.
+	At int64 `json:"at"`
+}
+
+// Subscription é a assinatura de um cliente, que recebe os eventos em C.
+type Subscription struct {
+	// C recebe os eventos publicados após a assinatura.
+	C       chan Event
+	dropped atomic.Int64
+}
+
+// Dropped retorna o número de eventos descartados por buffer cheio.
+func (s *Subscription) Dropped() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.dropped.Load()
+}
+
+// Broker distribui os eventos publicados a todas as assinaturas ativas.
+type Broker struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	buffer int
+}
+
+// NewBroker cria um distribuidor de eventos.
+//
+// Parâmetros:
+//   - buffer: número de eventos retidos para cada assinante antes do
+//     descarte.
+//
+// Retorno:
+//   - *Broker: o distribuidor, sem assinaturas.
+func NewBroker(buffer int) *Broker {
+	return &Broker{subs: make(map[*Subscription]struct{}), buffer: max(buffer, 1)}
+}
+
+// Subscribe cria uma assinatura. A assinatura deve ser encerrada com
+// Unsubscribe.
+//
+// Retorno:
+//   - *Subscription: a assinatura, ou nil caso o distribuidor seja nulo.
+func (b *Broker) Subscribe() *Subscription {
+	if b == nil {
+		return nil
+	}
+	s := &Subscription{C: make(chan Event, b.buffer)}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Unsubscribe encerra uma assinatura e fecha o seu canal.
+//
+// Parâmetros:
+//   - s: assinatura a ser encerrada.
+func (b *Broker) Unsubscribe(s *Subscription) {
+	if b == nil || s == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.C)
+	}
+}
+
+// UnsubscribeAll encerra todas as assinaturas, o que finaliza os fluxos
+// conectados (ex.: antes do reinício do servidor, que aguarda as conexões
+// ativas).
+func (b *Broker) UnsubscribeAll() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		delete(b.subs, s)
+		close(s.C)
+	}
+}
+
+// Publish entrega um evento a todas as assinaturas ativas, sem bloquear.
+//
+// Parâmetros:
+//   - e: evento a ser publicado.
+func (b *Broker) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		select {
+		case s.C <- e:
+		default:
+			s.dropped.Add(1)
+		}
+	}
+}
+
+// Subscribers retorna o número de assinaturas ativas.
+func (b *Broker) Subscribers() int {
+	if b == nil {
+		return 0
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
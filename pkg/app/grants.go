@@ -0,0 +1,279 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/events"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// ErrGrantExists indica que a categoria já foi compartilhada com o usuário
+// (ou com todos os usuários).
+var ErrGrantExists = errors.New("concessão já existente")
+
+// CreateCategoryGrant concede a um usuário o acesso a uma categoria de outro
+// usuário.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - categId: identificador da categoria compartilhada.
+//   - userId: identificador do usuário com acesso à categoria. uuid.Nil
+//     concede acesso a todos os usuários.
+//
+// Retorno:
+//   - uuid.UUID: identificador da concessão criada ou, com ErrGrantExists,
+//     da concessão já existente.
+//   - error: ErrGrantExists, caso a categoria já tenha sido compartilhada com
+//     o usuário, ou erro caso não seja possível criar a concessão.
+func CreateCategoryGrant(ctx *context.Context, categId, userId uuid.UUID) (uuid.UUID, error) {
+	// Concessão já existente
+	grants, err := QueryCategoryGrants(ctx, categId)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("não foi possível criar concessão")
+	}
+	for _, g := range grants {
+		if (userId == uuid.Nil && g.UserId == "") || g.UserId == userId.String() {
+			return uuid.MustParse(g.GrantId), ErrGrantExists
+		}
+	}
+
+	// Geração do UUID e Timestamp
+	ts := time.Now().Unix()
+	grantId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar UUID")
+	}
+
+	// Usuário da concessão (nulo para todos os usuários)
+	grantee := sql.NullString{String: userId.String(), Valid: userId != uuid.Nil}
+
+	// Insert query
+	schema := &ctx.Config.Database.Schema
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s)
+		VALUES (:grant_id, :categ_id, :user_id, :created_at)`,
+		schema.Name,
+		schema.CategGrantTable.Name,
+		schema.CategGrantTable.Columns.GrantId,
+		schema.CategGrantTable.Columns.CategId,
+		schema.CategGrantTable.Columns.UserId,
+		schema.CategGrantTable.Columns.CreatedAt,
+	)
+
+	// Criação
+	_, err = ctx.DB.Exec(
+		insert,
+		sql.Named("grant_id", grantId.String()),
+		sql.Named("categ_id", categId.String()),
+		sql.Named("user_id", grantee),
+		sql.Named("created_at", ts),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar concessão.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar concessão")
+	}
+	publishChange(ctx, events.EntityCategory, events.ActionUpdate, categId)
+	return grantId, nil
+}
+
+// QueryCategoryGrants recupera as concessões de acesso de uma categoria.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - categId: identificador da categoria.
+//
+// Retorno:
+//   - []db.CategGrantModel: as concessões encontradas.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryCategoryGrants(ctx *context.Context, categId uuid.UUID) ([]db.CategGrantModel, error) {
+	var grants []db.CategGrantModel
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	gc := &schema.CategGrantTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s,%s,%s,%s
+		FROM %s.%s
+		WHERE %s = :categ_id
+		ORDER BY %s`,
+		gc.GrantId,
+		gc.CategId,
+		gc.UserId,
+		gc.CreatedAt,
+		schema.Name,
+		schema.CategGrantTable.Name,
+		gc.CategId,
+		gc.CreatedAt,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, sql.Named("categ_id", categId.String()))
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar concessões.", zap.Error(err))
+		return grants, fmt.Errorf("não foi possível obter as concessões")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var g db.CategGrantModel
+		var userId sql.NullString
+		if err = rows.Scan(&g.GrantId, &g.CategId, &userId, &g.CreatedAt); err != nil {
+			ctx.Logger.Error("Erro ao obter concessão.", zap.Error(err))
+			return grants, fmt.Errorf("não foi possível obter todas as concessões")
+		}
+		g.UserId = userId.String
+		grants = append(grants, g)
+	}
+	return grants, nil
+}
+
+// DeleteCategoryGrant revoga uma concessão de acesso de uma categoria.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - categId: identificador da categoria.
+//   - grantId: identificador da concessão.
+//
+// Retorno:
+//   - error: ErrEntityNotFound, caso a concessão não exista na categoria, ou
+//     erro caso não seja possível revogá-la.
+func DeleteCategoryGrant(ctx *context.Context, categId, grantId uuid.UUID) error {
+	// Delete query
+	schema := &ctx.Config.Database.Schema
+	del := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE %s = :grant_id AND %s = :categ_id",
+		schema.Name,
+		schema.CategGrantTable.Name,
+		schema.CategGrantTable.Columns.GrantId,
+		schema.CategGrantTable.Columns.CategId,
+	)
+
+	// Exclusão
+	res, err := ctx.DB.Exec(
+		del,
+		sql.Named("grant_id", grantId.String()),
+		sql.Named("categ_id", categId.String()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao revogar concessão.", zap.Error(err))
+		return fmt.Errorf("não foi possível revogar concessão")
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		return ErrEntityNotFound
+	}
+	publishChange(ctx, events.EntityCategory, events.ActionUpdate, categId)
+	return nil
+}
+
+// HasCategoryGrant verifica se um usuário possui acesso a uma categoria por
+// concessão, individual ou para todos os usuários.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - categId: identificador da categoria.
+//   - userId: identificador do usuário.
+//
+// Retorno:
+//   - bool: true caso o usuário possua acesso por concessão.
+//   - error: erro caso a consulta falhe.
+func HasCategoryGrant(ctx *context.Context, categId, userId uuid.UUID) (bool, error) {
+	// Query
+	schema := &ctx.Config.Database.Schema
+	gc := &schema.CategGrantTable.Columns
+	query := fmt.Sprintf(
+		`SELECT COUNT(*)
+		FROM %s.%s
+		WHERE %s = :categ_id AND (%s = :user_id OR %s IS NULL)`,
+		schema.Name,
+		schema.CategGrantTable.Name,
+		gc.CategId,
+		gc.UserId,
+		gc.UserId,
+	)
+
+	// Contagem das concessões
+	var count int64
+	row := ctx.DB.QueryRow(
+		query,
+		sql.Named("categ_id", categId.String()),
+		sql.Named("user_id", userId.String()),
+	)
+	if err := row.Scan(&count); err != nil {
+		ctx.Logger.Error("Erro ao consultar concessão.", zap.Error(err))
+		return false, fmt.Errorf("não foi possível consultar concessão")
+	}
+	return count > 0, nil
+}
+
+// QuerySharedCategories recupera as categorias de outros usuários acessíveis
+// ao usuário informado por concessão.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//
+// Retorno:
+//   - []db.CategModel: as categorias compartilhadas, marcadas como Shared.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QuerySharedCategories(ctx *context.Context, userId uuid.UUID) ([]db.CategModel, error) {
+	var categs []db.CategModel
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	cc := &schema.CategTable.Columns
+	gc := &schema.CategGrantTable.Columns
+	query := fmt.Sprintf(
+		`SELECT c.%s, c.%s, c.%s, c.%s
+		FROM %s.%s c
+		WHERE c.%s <> :user_id
+		AND EXISTS (
+			SELECT 1 FROM %s.%s g
+			WHERE g.%s = c.%s AND (g.%s = :user_id OR g.%s IS NULL)
+		)`,
+		cc.CategId,
+		cc.UserId,
+		cc.Name,
+		cc.UpdatedAt,
+		schema.Name,
+		schema.CategTable.Name,
+		cc.UserId,
+		schema.Name,
+		schema.CategGrantTable.Name,
+		gc.CategId,
+		cc.CategId,
+		gc.UserId,
+		gc.UserId,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, sql.Named("user_id", userId.String()))
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar categorias compartilhadas.", zap.Error(err))
+		return categs, fmt.Errorf("não foi possível obter as categorias compartilhadas")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		c := db.CategModel{Shared: true}
+		err = rows.Scan(&c.CategId, &c.UserId, &c.Name, &c.UpdatedAt)
+		if err != nil {
+			ctx.Logger.Error("Erro ao obter categoria.", zap.Error(err))
+			return categs, fmt.Errorf("não foi possível obter todas as categorias compartilhadas")
+		}
+		categs = append(categs, c)
+	}
+	return categs, nil
+}
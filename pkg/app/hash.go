@@ -0,0 +1,206 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+)
+
+const (
+	// argon2SaltLength é o tamanho, em bytes, do salt do argon2id.
+	argon2SaltLength = 16
+	// argon2KeyLength é o tamanho, em bytes, do hash do argon2id.
+	argon2KeyLength = 32
+	// argon2Prefix é o prefixo dos hashes argon2id no formato PHC.
+	argon2Prefix = "$argon2id$"
+)
+
+// PasswordHasher gera e verifica os hashes das senhas de um algoritmo.
+type PasswordHasher interface {
+	// Hash gera o hash da senha com os parâmetros do hasher.
+	Hash(password string) (string, error)
+	// Verify indica se a senha corresponde ao hash.
+	Verify(hash, password string) bool
+	// NeedsRehash indica se o hash foi gerado por outro algoritmo ou com
+	// parâmetros diferentes dos do hasher.
+	NeedsRehash(hash string) bool
+}
+
+// BcryptHasher gera hashes bcrypt com o custo informado.
+type BcryptHasher struct {
+	Cost int
+}
+
+// Hash implementa PasswordHasher.
+func (h BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify implementa PasswordHasher.
+func (BcryptHasher) Verify(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NeedsRehash implementa PasswordHasher.
+func (h BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.Cost
+}
+
+// Argon2idHasher gera hashes argon2id no formato PHC
+// ($argon2id$v=19$m=<KiB>,t=<passadas>,p=<paralelismo>$<salt>$<hash>).
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// argon2Hash é um hash argon2id decodificado.
+type argon2Hash struct {
+	params Argon2idHasher
+	salt   []byte
+	key    []byte
+}
+
+// parseArgon2 decodifica um hash argon2id no formato PHC.
+func parseArgon2(hash string) (argon2Hash, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return argon2Hash{}, fmt.Errorf("hash argon2id inválido")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return argon2Hash{}, fmt.Errorf("versão do argon2id não suportada")
+	}
+	h := argon2Hash{}
+	_, err := fmt.Sscanf(
+		parts[3], "m=%d,t=%d,p=%d",
+		&h.params.Memory, &h.params.Iterations, &h.params.Parallelism,
+	)
+	if err != nil {
+		return argon2Hash{}, fmt.Errorf("parâmetros do argon2id inválidos")
+	}
+	if h.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return argon2Hash{}, fmt.Errorf("salt do argon2id inválido")
+	}
+	if h.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(h.key) == 0 {
+		return argon2Hash{}, fmt.Errorf("hash do argon2id inválido")
+	}
+	return h, nil
+}
+
+// Hash implementa PasswordHasher.
+func (h Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, argon2KeyLength)
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2Prefix,
+		argon2.Version,
+		h.Memory,
+		h.Iterations,
+		h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify implementa PasswordHasher. Usa os parâmetros gravados no hash.
+func (Argon2idHasher) Verify(hash, password string) bool {
+	h, err := parseArgon2(hash)
+	if err != nil {
+		return false
+	}
+	p := h.params
+	key := argon2.IDKey([]byte(password), h.salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(h.key)))
+	return subtle.ConstantTimeCompare(key, h.key) == 1
+}
+
+// NeedsRehash implementa PasswordHasher.
+func (h Argon2idHasher) NeedsRehash(hash string) bool {
+	parsed, err := parseArgon2(hash)
+	return err != nil || parsed.params != h || len(parsed.key) != argon2KeyLength
+}
+
+// NewPasswordHasher obtém o hasher dos novos hashes, conforme o algoritmo
+// configurado. Algoritmos desconhecidos usam o argon2id.
+//
+// Parâmetros:
+//   - cfg: algoritmo e parâmetros do hash das senhas.
+//
+// Retorno:
+//   - PasswordHasher: o hasher configurado.
+func NewPasswordHasher(cfg config.PasswordHash) PasswordHasher {
+	if cfg.Algorithm == "bcrypt" {
+		return BcryptHasher{Cost: cfg.BcryptCost}
+	}
+	return Argon2idHasher{
+		Memory:      cfg.Argon2Memory,
+		Iterations:  cfg.Argon2Iterations,
+		Parallelism: cfg.Argon2Parallelism,
+	}
+}
+
+// HashPassword gera um hash seguro para a senha fornecida com o algoritmo
+// configurado (Config.PasswordHash).
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo o logger para registrar possíveis
+//     erros.
+//   - password: string contendo a senha a ser hasheada.
+//
+// Retornos:
+//   - string: hash gerado a partir da senha fornecida.
+//   - error: erro, caso ocorra falha ao gerar o hash.
+func HashPassword(ctx *context.Context, password string) (string, error) {
+	hash, err := NewPasswordHasher(ctx.Config.PasswordHash).Hash(password)
+	if err != nil {
+		ctx.Logger.Error("Erro ao gerar hash da senha.", zap.Error(err))
+		return "", fmt.Errorf("error ao criptografar senha")
+	}
+	return hash, nil
+}
+
+// VerifyPassword verifica a senha com o hash armazenado, de qualquer um dos
+// algoritmos suportados (argon2id ou bcrypt), identificado pelo prefixo.
+//
+// Parâmetros:
+//   - hash: hash armazenado da senha.
+//   - password: senha informada.
+//
+// Retorno:
+//   - bool: true se a senha corresponder ao hash.
+func VerifyPassword(hash, password string) bool {
+	if strings.HasPrefix(hash, argon2Prefix) {
+		return Argon2idHasher{}.Verify(hash, password)
+	}
+	return BcryptHasher{}.Verify(hash, password)
+}
+
+// PasswordNeedsRehash indica se o hash armazenado deve ser refeito por ter
+// sido gerado com outro algoritmo ou parâmetros diferentes dos configurados.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração.
+//   - hash: hash armazenado da senha.
+//
+// Retorno:
+//   - bool: true se o hash estiver desatualizado.
+func PasswordNeedsRehash(ctx *context.Context, hash string) bool {
+	return NewPasswordHasher(ctx.Config.PasswordHash).NeedsRehash(hash)
+}
@@ -0,0 +1,263 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/events"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// ErrIdentityNotLinked indica uma identidade externa sem usuário vinculado,
+// quando o vínculo pelo e-mail e a criação automática não são possíveis.
+var ErrIdentityNotLinked = errors.New("identidade externa não vinculada a um usuário")
+
+// queryIdentityUser obtém o usuário vinculado a uma identidade externa.
+func queryIdentityUser(ctx *context.Context, issuer, subject string) (uuid.UUID, error) {
+	schema := &ctx.Config.Database.Schema
+	ic := &schema.UserIdentityTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s FROM %s.%s WHERE %s = :issuer AND %s = :subject`,
+		ic.UserId,
+		schema.Name,
+		schema.UserIdentityTable.Name,
+		ic.Issuer,
+		ic.Subject,
+	)
+
+	var userId string
+	row := ctx.DB.QueryRow(query, sql.Named("issuer", issuer), sql.Named("subject", subject))
+	if err := row.Scan(&userId); errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, ErrIdentityNotLinked
+	} else if err != nil {
+		ctx.Logger.Error("Erro ao consultar identidade externa.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível consultar identidade externa")
+	}
+	return uuid.Parse(userId)
+}
+
+// queryUserIdByUsername obtém o usuário de um nome de usuário, sem
+// diferenciar maiúsculas de minúsculas.
+func queryUserIdByUsername(ctx *context.Context, username string) (uuid.UUID, error) {
+	schema := &ctx.Config.Database.Schema
+	uc := &schema.UserTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s FROM %s.%s WHERE LOWER(%s) = LOWER(:username)`,
+		uc.UserId,
+		schema.Name,
+		schema.UserTable.Name,
+		uc.Username,
+	)
+
+	var userId string
+	row := ctx.DB.QueryRow(query, sql.Named("username", username))
+	if err := row.Scan(&userId); errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, ErrIdentityNotLinked
+	} else if err != nil {
+		ctx.Logger.Error("Erro ao buscar usuário", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível procurar usuário")
+	}
+	return uuid.Parse(userId)
+}
+
+// insertIdentity vincula uma identidade externa a um usuário, usando exec
+// (ctx.DB.Exec ou tx.Exec) para que o vínculo possa fazer parte de outra
+// transação.
+func insertIdentity(
+	ctx *context.Context,
+	exec func(string, ...any) (sql.Result, error),
+	userId uuid.UUID,
+	p IdentityData,
+) error {
+	schema := &ctx.Config.Database.Schema
+	ic := &schema.UserIdentityTable.Columns
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s)
+		VALUES (:identity_id, :user_id, :issuer, :subject, :email, :created_at)`,
+		schema.Name,
+		schema.UserIdentityTable.Name,
+		ic.IdentityId,
+		ic.UserId,
+		ic.Issuer,
+		ic.Subject,
+		ic.Email,
+		ic.CreatedAt,
+	)
+	_, err := exec(
+		insert,
+		sql.Named("identity_id", uuid.NewString()),
+		sql.Named("user_id", userId.String()),
+		sql.Named("issuer", p.Issuer),
+		sql.Named("subject", p.Subject),
+		sql.Named("email", p.Email),
+		sql.Named("created_at", time.Now().Unix()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao vincular identidade externa.", zap.Error(err))
+		return fmt.Errorf("não foi possível vincular identidade externa")
+	}
+	return nil
+}
+
+// provisionUser cria um usuário autenticado externamente (OIDC ou LDAP) e,
+// quando informada, vincula a identidade externa. O usuário recebe uma senha
+// aleatória, desconhecida, e acessa apenas pelo login externo até que um
+// administrador gere uma redefinição de senha.
+func provisionUser(ctx *context.Context, username, name string, p *IdentityData) (uuid.UUID, error) {
+	// Checar nome de usuário: um usuário existente não é vinculado
+	// automaticamente
+	if _, err := queryUserIdByUsername(ctx, username); err == nil {
+		return uuid.Nil, ErrIdentityNotLinked
+	} else if !errors.Is(err, ErrIdentityNotLinked) {
+		return uuid.Nil, err
+	}
+
+	// Geração do UUID, da senha e do nome
+	userId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar UUID")
+	}
+	password, err := newToken()
+	if err != nil {
+		ctx.Logger.Error("Erro ao gerar senha.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível gerar senha")
+	}
+	hash, err := HashPassword(ctx, password)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if name == "" {
+		name = username
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Criação
+	schema := &ctx.Config.Database.Schema
+	uc := &schema.UserTable.Columns
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+		VALUES (:user_id, :username, :name, :password, :updated_at, :must_change, :active,
+		        :valid_from, :valid_until, :last_login_at, :email_notifications)`,
+		schema.Name,
+		schema.UserTable.Name,
+		uc.UserId,
+		uc.Username,
+		uc.Name,
+		uc.Password,
+		uc.UpdatedAt,
+		uc.MustChangePassword,
+		uc.Active,
+		uc.ValidFrom,
+		uc.ValidUntil,
+		uc.LastLoginAt,
+		uc.EmailNotifications,
+	)
+	_, err = tx.Exec(
+		insert,
+		sql.Named("user_id", userId.String()),
+		sql.Named("username", username),
+		sql.Named("name", name),
+		sql.Named("password", hash),
+		sql.Named("updated_at", time.Now().Unix()),
+		sql.Named("must_change", 0),
+		sql.Named("active", 1),
+		sql.Named("valid_from", 0),
+		sql.Named("valid_until", 0),
+		sql.Named("last_login_at", 0),
+		sql.Named("email_notifications", 1),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar usuário.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar usuário")
+	}
+	if p != nil {
+		if err = insertIdentity(ctx, tx.Exec, userId, *p); err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível confirmar transação")
+	}
+	ctx.Logger.Info(
+		"Usuário criado pelo login externo.",
+		zap.String("user_id", userId.String()),
+		zap.String("username", username),
+	)
+	publishChange(ctx, events.EntityUser, events.ActionCreate, userId)
+	return userId, nil
+}
+
+// ResolveIdentity obtém o usuário de uma identidade externa autenticada. A
+// identidade já vinculada identifica o usuário; senão, ela é vinculada ao
+// usuário cujo nome de usuário é o e-mail verificado pelo provedor ou, com a
+// criação automática habilitada, a um novo usuário.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: dados da identidade autenticada.
+//   - autoProvision: habilita a criação automática do usuário.
+//
+// Retorno:
+//   - uuid.UUID: o identificador do usuário.
+//   - bool: verdadeiro caso o usuário tenha sido criado.
+//   - error: ErrIdentityNotLinked ou erro caso não seja possível consultar ou
+//     vincular a identidade.
+func ResolveIdentity(ctx *context.Context, p IdentityData, autoProvision bool) (uuid.UUID, bool, error) {
+	// Identidade vinculada
+	userId, err := queryIdentityUser(ctx, p.Issuer, p.Subject)
+	if !errors.Is(err, ErrIdentityNotLinked) {
+		return userId, false, err
+	}
+
+	// Vínculo pelo e-mail verificado
+	if p.Email != "" && p.EmailVerified {
+		userId, err = queryUserIdByUsername(ctx, p.Email)
+		if err == nil {
+			if err = insertIdentity(ctx, ctx.DB.Exec, userId, p); err != nil {
+				return uuid.Nil, false, err
+			}
+			ctx.Logger.Info(
+				"Identidade externa vinculada pelo e-mail.",
+				zap.String("user_id", userId.String()),
+				zap.String("issuer", p.Issuer),
+				zap.String("subject", p.Subject),
+			)
+			return userId, false, nil
+		} else if !errors.Is(err, ErrIdentityNotLinked) {
+			return uuid.Nil, false, err
+		}
+	}
+
+	// Criação automática, com o e-mail verificado ou o nome sugerido
+	if !autoProvision {
+		return uuid.Nil, false, ErrIdentityNotLinked
+	}
+	username := p.PreferredUsername
+	if p.Email != "" && p.EmailVerified {
+		username = p.Email
+	}
+	if username == "" {
+		return uuid.Nil, false, ErrIdentityNotLinked
+	}
+	userId, err = provisionUser(ctx, username, p.Name, &p)
+	return userId, err == nil, err
+}
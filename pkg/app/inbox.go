@@ -0,0 +1,271 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/events"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrFileTooLarge indica um envio maior que o limite da caixa de entrada.
+	ErrFileTooLarge = errors.New("arquivo maior que o permitido")
+	// ErrFileTypeNotAllowed indica um envio de tipo não aceito pela caixa de
+	// entrada.
+	ErrFileTypeNotAllowed = errors.New("tipo de arquivo não permitido")
+)
+
+// normalizeTypes remove espaços, pontos iniciais e repetições dos tipos
+// aceitos por uma caixa de entrada, convertendo-os para minúsculas.
+func normalizeTypes(types []string) []string {
+	res := make([]string, 0, len(types))
+	seen := make(map[string]bool, len(types))
+	for _, t := range types {
+		t = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(t), "."))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		res = append(res, t)
+	}
+	return res
+}
+
+// CheckInboxUpload verifica se um arquivo atende às regras de uma caixa de
+// entrada. Os tipos aceitos podem ser extensões (ex.: "pdf"), tipos MIME
+// (ex.: "application/pdf") ou famílias de tipos MIME (ex.: "image/*").
+//
+// Parâmetros:
+//   - inbox: regras da caixa de entrada.
+//   - size: tamanho do arquivo, em bytes.
+//   - extension: extensão do arquivo.
+//   - mimetype: tipo MIME do arquivo.
+//
+// Retorno:
+//   - error: ErrFileTooLarge ou ErrFileTypeNotAllowed caso o arquivo não
+//     atenda às regras, ou nil caso contrário.
+func CheckInboxUpload(inbox db.InboxModel, size int64, extension, mimetype string) error {
+	if inbox.MaxFileSize > 0 && size > inbox.MaxFileSize {
+		return ErrFileTooLarge
+	}
+	if len(inbox.AllowedTypes) == 0 {
+		return nil
+	}
+
+	ext := strings.ToLower(strings.TrimPrefix(extension, "."))
+	mime := strings.ToLower(mimetype)
+	for _, t := range inbox.AllowedTypes {
+		family, ok := strings.CutSuffix(t, "/*")
+		if t == ext || t == mime || (ok && strings.HasPrefix(mime, family+"/")) {
+			return nil
+		}
+	}
+	return ErrFileTypeNotAllowed
+}
+
+// SetInbox marca uma categoria como caixa de entrada, ou altera suas regras
+// caso já seja uma.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - categId: identificador da categoria.
+//   - p: regras da caixa de entrada.
+//
+// Retorno:
+//   - error: erro caso não seja possível salvar a caixa de entrada.
+func SetInbox(ctx *context.Context, categId uuid.UUID, p InboxData) error {
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return fmt.Errorf("erro ao iniciar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Remoção das regras atuais
+	schema := &ctx.Config.Database.Schema
+	ic := &schema.InboxTable.Columns
+	del := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE %s = :categ_id",
+		schema.Name,
+		schema.InboxTable.Name,
+		ic.CategId,
+	)
+	if _, err = tx.Exec(del, sql.Named("categ_id", categId.String())); err != nil {
+		ctx.Logger.Error("Erro ao remover caixa de entrada.", zap.Error(err))
+		return fmt.Errorf("não foi possível salvar caixa de entrada")
+	}
+
+	// Insert query
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s)
+		VALUES (:categ_id, :max_file_size, :allowed_types, :updated_at)`,
+		schema.Name,
+		schema.InboxTable.Name,
+		ic.CategId,
+		ic.MaxFileSize,
+		ic.AllowedTypes,
+		ic.UpdatedAt,
+	)
+
+	// Criação
+	_, err = tx.Exec(
+		insert,
+		sql.Named("categ_id", categId.String()),
+		sql.Named("max_file_size", p.MaxFileSize),
+		sql.Named("allowed_types", strings.Join(normalizeTypes(p.AllowedTypes), ",")),
+		sql.Named("updated_at", time.Now().Unix()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar caixa de entrada.", zap.Error(err))
+		return fmt.Errorf("não foi possível salvar caixa de entrada")
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return fmt.Errorf("erro ao confirmar transação")
+	}
+	publishChange(ctx, events.EntityCategory, events.ActionUpdate, categId)
+	return nil
+}
+
+// RemoveInbox desmarca uma categoria como caixa de entrada, tornando-a
+// somente leitura para o usuário proprietário.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - categId: identificador da categoria.
+//
+// Retorno:
+//   - error: ErrEntityNotFound, caso a categoria não seja uma caixa de
+//     entrada, ou erro caso não seja possível removê-la.
+func RemoveInbox(ctx *context.Context, categId uuid.UUID) error {
+	// Delete query
+	schema := &ctx.Config.Database.Schema
+	del := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE %s = :categ_id",
+		schema.Name,
+		schema.InboxTable.Name,
+		schema.InboxTable.Columns.CategId,
+	)
+
+	// Exclusão
+	res, err := ctx.DB.Exec(del, sql.Named("categ_id", categId.String()))
+	if err != nil {
+		ctx.Logger.Error("Erro ao remover caixa de entrada.", zap.Error(err))
+		return fmt.Errorf("não foi possível remover caixa de entrada")
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		return ErrEntityNotFound
+	}
+	publishChange(ctx, events.EntityCategory, events.ActionUpdate, categId)
+	return nil
+}
+
+// QueryInbox recupera as regras de uma caixa de entrada.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - categId: identificador da categoria.
+//
+// Retorno:
+//   - db.InboxModel: as regras da caixa de entrada.
+//   - error: ErrEntityNotFound, caso a categoria não seja uma caixa de
+//     entrada, ou erro caso a consulta falhe.
+func QueryInbox(ctx *context.Context, categId uuid.UUID) (db.InboxModel, error) {
+	var inbox db.InboxModel
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	ic := &schema.InboxTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s,%s,%s,%s
+		FROM %s.%s
+		WHERE %s = :categ_id`,
+		ic.CategId,
+		ic.MaxFileSize,
+		ic.AllowedTypes,
+		ic.UpdatedAt,
+		schema.Name,
+		schema.InboxTable.Name,
+		ic.CategId,
+	)
+
+	// Obtenção da linha
+	var types sql.NullString
+	row := ctx.DB.QueryRow(query, sql.Named("categ_id", categId.String()))
+	err := row.Scan(&inbox.CategId, &inbox.MaxFileSize, &types, &inbox.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return inbox, ErrEntityNotFound
+	} else if err != nil {
+		ctx.Logger.Error("Erro ao consultar caixa de entrada.", zap.Error(err))
+		return inbox, fmt.Errorf("não foi possível consultar caixa de entrada")
+	}
+	inbox.AllowedTypes = normalizeTypes(strings.Split(types.String, ","))
+	return inbox, nil
+}
+
+// QueryUserInboxes recupera os IDs das categorias de um usuário que são
+// caixas de entrada.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário proprietário das categorias.
+//
+// Retorno:
+//   - map[string]bool: os IDs das caixas de entrada do usuário.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryUserInboxes(ctx *context.Context, userId uuid.UUID) (map[string]bool, error) {
+	inboxes := make(map[string]bool)
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	ic := &schema.InboxTable.Columns
+	cc := &schema.CategTable.Columns
+	query := fmt.Sprintf(
+		`SELECT i.%s
+		FROM %s.%s i
+		JOIN %s.%s c ON c.%s = i.%s
+		WHERE c.%s = :user_id`,
+		ic.CategId,
+		schema.Name,
+		schema.InboxTable.Name,
+		schema.Name,
+		schema.CategTable.Name,
+		cc.CategId,
+		ic.CategId,
+		cc.UserId,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, sql.Named("user_id", userId.String()))
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar caixas de entrada.", zap.Error(err))
+		return inboxes, fmt.Errorf("não foi possível obter as caixas de entrada")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var categId string
+		if err = rows.Scan(&categId); err != nil {
+			ctx.Logger.Error("Erro ao obter caixa de entrada.", zap.Error(err))
+			return inboxes, fmt.Errorf("não foi possível obter todas as caixas de entrada")
+		}
+		inboxes[categId] = true
+	}
+	return inboxes, nil
+}
@@ -0,0 +1,225 @@
+// Package keyring mantém as chaves assimétricas de assinatura e verificação
+// dos tokens JWT (RS256 e EdDSA), identificadas pelo cabeçalho kid. Várias
+// chaves de verificação podem estar ativas ao mesmo tempo, o que permite
+// trocar a chave de assinatura sem invalidar os tokens já emitidos.
+//
+// Os métodos de um *Keyring nulo indicam a assinatura HS256 com o segredo
+// JWT, o que permite usar um contexto sem chaves (ex.: testes).
+package keyring
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"os"
+)
+
+// minRsaBits é o tamanho mínimo das chaves RSA.
+const minRsaBits = 2048
+
+// Key é uma chave de assinatura ou verificação.
+type Key struct {
+	// Id é o identificador da chave (kid).
+	Id string
+	// Method é o algoritmo de assinatura da chave.
+	Method jwt.SigningMethod
+	// Private é a chave privada. Nula em chaves apenas de verificação.
+	Private crypto.Signer
+	// Public é a chave pública.
+	Public crypto.PublicKey
+}
+
+// Keyring reúne a chave de assinatura e as chaves de verificação ativas.
+type Keyring struct {
+	signing     *Key
+	keys        map[string]*Key
+	order       []string
+	acceptHs256 bool
+}
+
+// JWK é uma chave pública no formato JSON Web Key (RFC 7517).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS é um conjunto de chaves públicas (JSON Web Key Set).
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// readPem lê o primeiro bloco PEM de um arquivo.
+func readPem(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("arquivo %s sem bloco PEM", path)
+	}
+	return block, nil
+}
+
+// loadKey carrega uma chave da configuração, verificando se o tipo da chave
+// corresponde ao algoritmo.
+func loadKey(cfg config.JwtKey) (*Key, error) {
+	key := &Key{Id: cfg.Id}
+	switch cfg.Algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		key.Method = jwt.SigningMethodRS256
+	case jwt.SigningMethodEdDSA.Alg():
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("algoritmo %q não suportado", cfg.Algorithm)
+	}
+
+	// Chave privada ou pública
+	switch {
+	case cfg.PrivateKeyFile != "":
+		block, err := readPem(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			if parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+				return nil, fmt.Errorf("chave privada inválida: %w", err)
+			}
+		}
+		signer, ok := parsed.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("chave privada não suportada")
+		}
+		key.Private, key.Public = signer, signer.Public()
+	case cfg.PublicKeyFile != "":
+		block, err := readPem(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key.Public, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("chave pública inválida: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("arquivo da chave não informado")
+	}
+
+	// Tipo da chave
+	switch pub := key.Public.(type) {
+	case *rsa.PublicKey:
+		if key.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("chave RSA incompatível com %s", cfg.Algorithm)
+		} else if pub.N.BitLen() < minRsaBits {
+			return nil, fmt.Errorf("chave RSA menor que %d bits", minRsaBits)
+		}
+	case ed25519.PublicKey:
+		if key.Method != jwt.SigningMethodEdDSA {
+			return nil, fmt.Errorf("chave Ed25519 incompatível com %s", cfg.Algorithm)
+		}
+	default:
+		return nil, fmt.Errorf("tipo de chave não suportado")
+	}
+	return key, nil
+}
+
+// Load carrega as chaves configuradas. Sem chave de assinatura, retorna
+// nil, mantendo a assinatura HS256.
+//
+// Parâmetros:
+//   - cfg: configuração das chaves.
+//
+// Retorno:
+//   - *Keyring: as chaves carregadas ou nil.
+//   - error: erro caso alguma chave seja inválida ou a chave de assinatura
+//     não exista ou não possua chave privada.
+func Load(cfg config.JwtKeys) (*Keyring, error) {
+	if cfg.SigningKey == "" {
+		return nil, nil
+	}
+
+	k := &Keyring{keys: make(map[string]*Key), acceptHs256: cfg.AcceptHs256}
+	for _, kc := range cfg.Keys {
+		if kc.Id == "" {
+			return nil, fmt.Errorf("chave JWT sem kid")
+		} else if _, ok := k.keys[kc.Id]; ok {
+			return nil, fmt.Errorf("chave JWT %q duplicada", kc.Id)
+		}
+		key, err := loadKey(kc)
+		if err != nil {
+			return nil, fmt.Errorf("chave JWT %q: %w", kc.Id, err)
+		}
+		k.keys[kc.Id] = key
+		k.order = append(k.order, kc.Id)
+	}
+
+	signing, ok := k.keys[cfg.SigningKey]
+	if !ok {
+		return nil, fmt.Errorf("chave de assinatura %q não encontrada", cfg.SigningKey)
+	} else if signing.Private == nil {
+		return nil, fmt.Errorf("chave de assinatura %q sem chave privada", cfg.SigningKey)
+	}
+	k.signing = signing
+	return k, nil
+}
+
+// Signing retorna a chave de assinatura dos novos tokens. Nil indica a
+// assinatura HS256 com o segredo JWT.
+func (k *Keyring) Signing() *Key {
+	if k == nil {
+		return nil
+	}
+	return k.signing
+}
+
+// Lookup retorna a chave de verificação do identificador informado.
+func (k *Keyring) Lookup(kid string) (*Key, bool) {
+	if k == nil {
+		return nil, false
+	}
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// AcceptsHs256 indica se os tokens HS256, sem kid, são aceitos: sempre sem
+// chave de assinatura assimétrica ou, com ela, quando configurado.
+func (k *Keyring) AcceptsHs256() bool {
+	return k == nil || k.acceptHs256
+}
+
+// JWKS retorna as chaves públicas de verificação, na ordem configurada.
+func (k *Keyring) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	if k == nil {
+		return set
+	}
+	enc := base64.RawURLEncoding
+	for _, kid := range k.order {
+		key := k.keys[kid]
+		jwk := JWK{Kid: kid, Use: "sig", Alg: key.Method.Alg()}
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = enc.EncodeToString(pub.N.Bytes())
+			jwk.E = enc.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = enc.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
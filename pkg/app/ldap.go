@@ -0,0 +1,226 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"crypto/tls"
+	"fmt"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// LdapAuthenticator verifica a senha por bind simples no servidor LDAP
+// configurado. O usuário autenticado corresponde ao usuário local de mesmo
+// nome, criado automaticamente quando habilitado, e seus papéis são
+// sincronizados com os grupos LDAP mapeados em GroupRoles.
+type LdapAuthenticator struct{}
+
+// LdapUserDN monta o DN do usuário a partir do modelo configurado,
+// escapando o nome de usuário.
+//
+// Parâmetros:
+//   - template: modelo do DN, com o marcador {username}.
+//   - username: nome de usuário informado no login.
+//
+// Retorno:
+//   - string: o DN do usuário.
+func LdapUserDN(template, username string) string {
+	return strings.ReplaceAll(template, "{username}", ldap.EscapeDN(username))
+}
+
+// dialLdap conecta ao servidor LDAP configurado, com StartTLS quando
+// habilitado.
+func dialLdap(cfg config.Ldap) (*ldap.Conn, error) {
+	u, err := url.Parse(cfg.Url)
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	tlsConfig := &tls.Config{
+		ServerName:         u.Hostname(),
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	conn, err := ldap.DialURL(
+		cfg.Url,
+		ldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		ldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(timeout)
+	if cfg.StartTls && u.Scheme == "ldap" {
+		if err = conn.StartTLS(tlsConfig); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+// ldapBind autentica o usuário no servidor LDAP e obtém o nome de
+// apresentação e os grupos do usuário.
+func ldapBind(cfg config.Ldap, p LoginParams) (string, []string, error) {
+	conn, err := dialLdap(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Bind simples com o DN do usuário
+	dn := LdapUserDN(cfg.UserDnTemplate, p.Username)
+	if err = conn.Bind(dn, p.Password); err != nil {
+		return "", nil, err
+	}
+
+	// Nome de apresentação, na própria entrada do usuário
+	var name string
+	res, err := conn.Search(ldap.NewSearchRequest(
+		dn, ldap.ScopeBaseObject, ldap.NeverDerefAliases, 1, 0, false,
+		"(objectClass=*)", []string{"displayName", "cn"}, nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return "", nil, err
+	}
+	if err == nil && len(res.Entries) > 0 {
+		name = res.Entries[0].GetAttributeValue("displayName")
+		if name == "" {
+			name = res.Entries[0].GetAttributeValue("cn")
+		}
+	}
+
+	// Grupos do usuário
+	if cfg.GroupBaseDn == "" {
+		return name, nil, nil
+	}
+	filter := strings.NewReplacer(
+		"{dn}", ldap.EscapeFilter(dn),
+		"{username}", ldap.EscapeFilter(p.Username),
+	).Replace(cfg.GroupFilter)
+	res, err = conn.Search(ldap.NewSearchRequest(
+		cfg.GroupBaseDn, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		filter, []string{"cn"}, nil,
+	))
+	if err != nil {
+		return "", nil, err
+	}
+	groups := make([]string, 0, len(res.Entries))
+	for _, entry := range res.Entries {
+		groups = append(groups, entry.GetAttributeValue("cn"))
+	}
+	return name, groups, nil
+}
+
+// syncLdapRoles substitui os papéis do usuário pelos papéis mapeados dos
+// seus grupos LDAP. Grupos sem mapeamento e papéis inexistentes são
+// ignorados; os papéis são alterados apenas quando diferentes dos atuais.
+func syncLdapRoles(ctx *context.Context, userId uuid.UUID, groups []string) error {
+	// Nomes dos papéis dos grupos, sem diferenciar maiúsculas de minúsculas
+	mapping := make(map[string]string, len(ctx.Config.Ldap.GroupRoles))
+	for group, role := range ctx.Config.Ldap.GroupRoles {
+		mapping[strings.ToLower(group)] = role
+	}
+	names := make(map[string]bool)
+	for _, group := range groups {
+		if role, ok := mapping[strings.ToLower(group)]; ok {
+			names[role] = true
+		}
+	}
+
+	// Identificadores dos papéis
+	roles, err := QueryAllRoles(ctx)
+	if err != nil {
+		return err
+	}
+	roleIds := make([]uuid.UUID, 0, len(names))
+	wanted := make([]string, 0, len(names))
+	for _, role := range roles {
+		if !names[role.Name] {
+			continue
+		}
+		roleId, err := uuid.Parse(role.RoleId)
+		if err != nil {
+			continue
+		}
+		roleIds = append(roleIds, roleId)
+		wanted = append(wanted, role.RoleId)
+	}
+
+	// Papéis atuais
+	current, err := QueryUserRoles(ctx, userId)
+	if err != nil {
+		return err
+	}
+	currentIds := make([]string, 0, len(current))
+	for _, role := range current {
+		currentIds = append(currentIds, role.RoleId)
+	}
+	slices.Sort(wanted)
+	slices.Sort(currentIds)
+	if slices.Equal(wanted, currentIds) {
+		return nil
+	}
+
+	if err = SetUserRoles(ctx, userId, roleIds); err != nil {
+		return err
+	}
+	ctx.Logger.Info(
+		"Papéis do usuário sincronizados com os grupos LDAP.",
+		zap.String("user_id", userId.String()),
+		zap.Strings("groups", groups),
+	)
+	return nil
+}
+
+// Authenticate implementa Authenticator. Senhas vazias são sempre
+// recusadas, pois o bind sem senha é anônimo e não autentica o usuário.
+// Com LocalFallback, a falha do bind recorre à senha local.
+func (LdapAuthenticator) Authenticate(ctx *context.Context, p LoginParams) (LoginData, error) {
+	cfg := ctx.Config.Ldap
+	if p.Username == "" || p.Password == "" {
+		return LoginData{}, ErrNotAuthenticated
+	}
+
+	// Bind no servidor
+	name, groups, err := ldapBind(cfg, p)
+	if err != nil {
+		if !ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			ctx.Logger.Error("Erro na autenticação LDAP.", zap.Error(err))
+		}
+		if cfg.LocalFallback {
+			return LocalAuthenticator{}.Authenticate(ctx, p)
+		}
+		return LoginData{}, ErrNotAuthenticated
+	}
+
+	// Usuário local, criado automaticamente quando habilitado
+	data, err := queryLoginUser(ctx, p.Username)
+	if errors.Is(err, ErrNotAuthenticated) && cfg.AutoProvision {
+		data.Name = name
+		if data.Name == "" {
+			data.Name = p.Username
+		}
+		data.UserId, err = provisionUser(ctx, p.Username, name, nil)
+	}
+	if errors.Is(err, ErrIdentityNotLinked) {
+		return LoginData{}, ErrNotAuthenticated
+	} else if err != nil {
+		return LoginData{}, err
+	}
+
+	// Papéis dos grupos, exceto para o administrador
+	if cfg.GroupBaseDn != "" && len(cfg.GroupRoles) > 0 && data.UserId != ctx.AdminId {
+		if err = syncLdapRoles(ctx, data.UserId, groups); err != nil {
+			return LoginData{}, fmt.Errorf("não foi possível sincronizar papéis do usuário")
+		}
+	}
+	return data, nil
+}
@@ -0,0 +1,184 @@
+// Package lockout implementa a proteção contra força bruta no login,
+// contabilizando as falhas de autenticação por nome de usuário e por IP e
+// aplicando bloqueios temporários com back-off exponencial.
+//
+// O estado é mantido em memória, como o limitador de requisições da
+// aplicação. Os métodos de um *Tracker nulo não bloqueiam nada, o que
+// permite usar um contexto sem rastreador (ex.: testes).
+package lockout
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sweepInterval é o intervalo mínimo entre as limpezas das entradas
+// expiradas.
+const sweepInterval = time.Minute
+
+// entry armazena as falhas de uma chave (usuário ou IP).
+type entry struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+// Tracker contabiliza as falhas de login e os bloqueios ativos.
+type Tracker struct {
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+// Lock descreve o bloqueio aplicado a uma falha de login.
+type Lock struct {
+	// Key é a chave bloqueada ("user:<nome>" ou "ip:<endereço>").
+	Key string
+	// Failures é o número de falhas consecutivas da chave.
+	Failures int
+	// Until é o fim do bloqueio.
+	Until time.Time
+}
+
+// New cria um rastreador de falhas vazio.
+func New() *Tracker {
+	return &Tracker{entries: make(map[string]*entry)}
+}
+
+// UserKey retorna a chave de um nome de usuário.
+func UserKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+// IpKey retorna a chave de um endereço IP.
+func IpKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check verifica se o usuário ou o IP estão bloqueados.
+//
+// Parâmetros:
+//   - username: nome de usuário da tentativa.
+//   - ip: endereço IP da tentativa.
+//
+// Retorno:
+//   - time.Duration: tempo restante do bloqueio mais longo, ou zero caso não
+//     haja bloqueio.
+func (t *Tracker) Check(username, ip string) time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range []string{UserKey(username), IpKey(ip)} {
+		if e, ok := t.entries[key]; ok && e.lockedUntil.After(now) {
+			wait = max(wait, e.lockedUntil.Sub(now))
+		}
+	}
+	return wait
+}
+
+// Fail registra uma falha de login do usuário e do IP. Ao atingir o limite
+// de falhas, a chave é bloqueada por policy.BaseDelay segundos, tempo que
+// dobra a cada nova falha, até policy.MaxDelay. As falhas são esquecidas após
+// policy.MaxDelay segundos sem novas tentativas.
+//
+// Parâmetros:
+//   - policy: política de bloqueio da configuração.
+//   - username: nome de usuário da tentativa.
+//   - ip: endereço IP da tentativa.
+//
+// Retorno:
+//   - []Lock: os bloqueios aplicados por esta falha, caso existam.
+func (t *Tracker) Fail(policy config.Lockout, username, ip string) []Lock {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	window := time.Duration(policy.MaxDelay) * time.Second
+	t.sweep(now, window)
+
+	var locks []Lock
+	limits := map[string]int{
+		UserKey(username): policy.MaxAttempts,
+		IpKey(ip):         policy.IpMaxAttempts,
+	}
+	for key, limit := range limits {
+		e, ok := t.entries[key]
+		if !ok || now.Sub(e.lastFailure) > window {
+			e = &entry{}
+			t.entries[key] = e
+		}
+		e.failures++
+		e.lastFailure = now
+		if limit <= 0 || e.failures < limit {
+			continue
+		}
+
+		// Back-off exponencial a partir do limite
+		delay := time.Duration(policy.BaseDelay) * time.Second
+		for i := limit; i < e.failures && delay < window; i++ {
+			delay *= 2
+		}
+		delay = min(delay, window)
+		e.lockedUntil = now.Add(delay)
+		locks = append(locks, Lock{Key: key, Failures: e.failures, Until: e.lockedUntil})
+	}
+	return locks
+}
+
+// Success zera as falhas do usuário após um login bem-sucedido. As falhas
+// do IP são mantidas, para que um login válido não libere tentativas contra
+// outras contas.
+//
+// Parâmetros:
+//   - username: nome de usuário autenticado.
+func (t *Tracker) Success(username string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, UserKey(username))
+}
+
+// Unlock remove as falhas e o bloqueio de um usuário.
+//
+// Parâmetros:
+//   - username: nome de usuário a ser desbloqueado.
+//
+// Retorno:
+//   - bool: verdadeiro caso o usuário possuísse falhas registradas.
+func (t *Tracker) Unlock(username string) bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := UserKey(username)
+	_, ok := t.entries[key]
+	delete(t.entries, key)
+	return ok
+}
+
+// sweep remove as entradas sem bloqueio ativo e sem falhas recentes. Deve
+// ser chamado com o mutex bloqueado.
+func (t *Tracker) sweep(now time.Time, window time.Duration) {
+	if now.Sub(t.lastSweep) < sweepInterval {
+		return
+	}
+	t.lastSweep = now
+	for key, e := range t.entries {
+		if !e.lockedUntil.After(now) && now.Sub(e.lastFailure) > window {
+			delete(t.entries, key)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/mailer"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+// QueueFileNotification agenda a notificação por e-mail do proprietário de
+// uma categoria sobre um arquivo novo ou alterado. As notificações de cada
+// usuário são agrupadas (mailer.Batcher) e enviadas por SendFileDigest.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo o agrupador das notificações.
+//   - categ: categoria do arquivo.
+//   - file: arquivo criado ou alterado.
+//   - action: mailer.ActionCreated ou mailer.ActionUpdated.
+func QueueFileNotification(ctx *context.Context, categ db.CategModel, file db.FileModel, action string) {
+	if !ctx.Config.Smtp.Enabled {
+		return
+	}
+	ctx.Mailer.Add(categ.UserId, mailer.Event{
+		FileId:    file.FileId,
+		FileName:  file.Name + "." + file.Extension,
+		CategName: categ.Name,
+		Action:    action,
+		At:        time.Now(),
+	})
+}
+
+// SendFileDigest envia a um usuário o e-mail com os arquivos novos ou
+// alterados em suas categorias. Usuários sem e-mail, que desabilitaram as
+// notificações ou cuja conta não permite o acesso não são notificados.
+// Falhas são apenas logadas, pois os arquivos já foram salvos.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração SMTP, a do banco
+//     de dados e o zap.Logger.
+//   - key: identificador do usuário.
+//   - events: eventos agrupados dos arquivos.
+func SendFileDigest(ctx *context.Context, key string, events []mailer.Event) {
+	cfg := ctx.Config.Smtp
+	if !cfg.Enabled {
+		return
+	}
+	userId, err := uuid.Parse(key)
+	if err != nil {
+		return
+	}
+	user, err := QueryUserById(ctx, userId)
+	if err != nil {
+		ctx.Logger.Error("Usuário da notificação por e-mail não encontrado.", zap.String("user_id", key), zap.Error(err))
+		return
+	}
+	if user.Email == "" || !user.EmailNotifications || CheckAccount(ctx, user) != nil {
+		return
+	}
+
+	// Envio
+	msg, err := mailer.Render(user.Email, user.Name, cfg.BaseUrl, events)
+	if err != nil {
+		ctx.Logger.Error("Erro ao gerar notificação por e-mail.", zap.Error(err))
+		return
+	}
+	if err = (mailer.SmtpSender{Config: cfg}).Send(msg); err != nil {
+		ctx.Logger.Error(
+			"Notificação por e-mail não enviada.",
+			zap.String("user_id", key),
+			zap.Int("files", len(events)),
+			zap.Error(err),
+		)
+		return
+	}
+	ctx.Logger.Info(
+		"Notificação por e-mail enviada.",
+		zap.String("user_id", key),
+		zap.Int("files", len(events)),
+	)
+}
@@ -0,0 +1,150 @@
+// Package mailer envia as notificações por e-mail (SMTP) dos arquivos novos
+// ou alterados nas categorias de um usuário. Os eventos de cada usuário são
+// agrupados durante uma janela de tempo, de modo que uma rajada de envios
+// resulte em um único e-mail.
+//
+// O estado é mantido em memória. Os métodos de um *Batcher nulo descartam os
+// eventos, o que permite usar um contexto sem notificações por e-mail (ex.:
+// SMTP desabilitado ou testes).
+package mailer
+
+import (
+	"sync"
+	"time"
+)
+
+// Ações dos eventos de arquivo.
+const (
+	// ActionCreated indica um arquivo novo.
+	ActionCreated = "created"
+	// ActionUpdated indica um arquivo alterado.
+	ActionUpdated = "updated"
+)
+
+// Event é a criação ou alteração de um arquivo em uma categoria.
+type Event struct {
+	// FileId é o identificador do arquivo.
+	FileId string
+	// FileName é o nome do arquivo, com a extensão.
+	FileName string
+	// CategName é o nome da categoria do arquivo.
+	CategName string
+	// Action é a ação realizada (ActionCreated ou ActionUpdated).
+	Action string
+	// At é o momento da ação.
+	At time.Time
+}
+
+// ActionLabel retorna a descrição da ação, em português.
+func (e Event) ActionLabel() string {
+	if e.Action == ActionCreated {
+		return "novo"
+	}
+	return "atualizado"
+}
+
+// FlushFunc envia os eventos agrupados de uma chave (ex.: o usuário).
+type FlushFunc func(key string, events []Event)
+
+// Batcher agrupa os eventos por chave durante uma janela de tempo, contada
+// a partir do primeiro evento, e os entrega de uma vez a FlushFunc.
+type Batcher struct {
+	mu      sync.Mutex
+	window  time.Duration
+	pending map[string][]Event
+	timers  map[string]*time.Timer
+	flush   FlushFunc
+}
+
+// NewBatcher cria um agrupador de eventos.
+//
+// Parâmetros:
+//   - window: janela de agrupamento dos eventos de cada chave.
+//   - flush: função que recebe os eventos agrupados.
+//
+// Retorno:
+//   - *Batcher: o agrupador criado.
+func NewBatcher(window time.Duration, flush FlushFunc) *Batcher {
+	return &Batcher{
+		window:  window,
+		pending: make(map[string][]Event),
+		timers:  make(map[string]*time.Timer),
+		flush:   flush,
+	}
+}
+
+// Add inclui um evento no grupo da chave. Um novo evento do mesmo arquivo
+// substitui o anterior, mantendo a ação de criação, de modo que cada arquivo
+// apareça uma única vez no e-mail.
+func (b *Batcher) Add(key string, e Event) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	events := b.pending[key]
+	replaced := false
+	for i, old := range events {
+		if old.FileId == e.FileId {
+			if old.Action == ActionCreated {
+				e.Action = ActionCreated
+			}
+			events[i], replaced = e, true
+			break
+		}
+	}
+	if !replaced {
+		events = append(events, e)
+	}
+	b.pending[key] = events
+
+	// Primeiro evento da chave: início da janela
+	if _, ok := b.timers[key]; !ok {
+		b.timers[key] = time.AfterFunc(b.window, func() { b.flushKey(key) })
+	}
+}
+
+// flushKey entrega os eventos pendentes de uma chave.
+func (b *Batcher) flushKey(key string) {
+	b.mu.Lock()
+	events := b.pending[key]
+	delete(b.pending, key)
+	if t, ok := b.timers[key]; ok {
+		t.Stop()
+		delete(b.timers, key)
+	}
+	b.mu.Unlock()
+
+	if len(events) > 0 {
+		b.flush(key, events)
+	}
+}
+
+// Flush entrega imediatamente os eventos pendentes de todas as chaves (ex.:
+// no encerramento da aplicação).
+func (b *Batcher) Flush() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	keys := make([]string, 0, len(b.pending))
+	for key := range b.pending {
+		keys = append(keys, key)
+	}
+	b.mu.Unlock()
+
+	for _, key := range keys {
+		b.flushKey(key)
+	}
+}
+
+// Pending retorna o número de chaves com eventos pendentes.
+func (b *Batcher) Pending() int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
@@ -0,0 +1,123 @@
+package mailer
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// Message é um e-mail de texto simples.
+type Message struct {
+	// To é o endereço do destinatário.
+	To string
+	// Subject é o assunto do e-mail.
+	Subject string
+	// Body é o corpo do e-mail, em texto simples.
+	Body string
+}
+
+// Sender envia um e-mail.
+type Sender interface {
+	Send(msg Message) error
+}
+
+// SmtpSender envia os e-mails por SMTP, com StartTLS e autenticação PLAIN
+// opcionais (ex.: um servidor local como o MailHog, sem autenticação).
+type SmtpSender struct {
+	// Config é a configuração do servidor SMTP.
+	Config config.Smtp
+}
+
+// Send envia um e-mail ao destinatário.
+//
+// Parâmetros:
+//   - msg: e-mail a ser enviado.
+//
+// Retorno:
+//   - error: erro caso a conexão, a autenticação ou o envio falhem.
+func (s SmtpSender) Send(msg Message) error {
+	cfg := s.Config
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	timeout := time.Duration(cfg.Timeout) * time.Second
+
+	// Conexão
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(timeout))
+	}
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	// StartTLS e autenticação
+	if cfg.StartTls {
+		if err = client.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if cfg.Username != "" {
+		auth := smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+		if err = client.Auth(auth); err != nil {
+			return err
+		}
+	}
+
+	// Envio
+	if err = client.Mail(cfg.From); err != nil {
+		return err
+	}
+	if err = client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(Encode(cfg.From, msg)); err != nil {
+		_ = w.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// Encode codifica um e-mail no formato RFC 5322, com o assunto em UTF-8
+// (RFC 2047) e o corpo em quoted-printable.
+//
+// Parâmetros:
+//   - from: endereço do remetente.
+//   - msg: e-mail a ser codificado.
+//
+// Retorno:
+//   - []byte: o e-mail codificado.
+func Encode(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n")
+	buf.WriteString("\r\n")
+
+	qp := quotedprintable.NewWriter(&buf)
+	_, _ = qp.Write(bytes.ReplaceAll([]byte(msg.Body), []byte("\n"), []byte("\r\n")))
+	_ = qp.Close()
+	return buf.Bytes()
+}
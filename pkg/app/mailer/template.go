@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// subjectTemplate é o modelo do assunto do e-mail de arquivos.
+var subjectTemplate = template.Must(template.New("subject").Parse(
+	`{{if eq (len .Events) 1}}Arquivo {{(index .Events 0).ActionLabel}}: {{(index .Events 0).FileName}}` +
+		`{{else}}{{len .Events}} arquivos novos ou atualizados{{end}}`,
+))
+
+// bodyTemplate é o modelo do corpo do e-mail de arquivos.
+var bodyTemplate = template.Must(template.New("body").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.Local().Format("02/01/2006 15:04") },
+}).Parse(`Olá, {{.Name}}.
+
+{{if eq (len .Events) 1}}Há um arquivo novo ou atualizado{{else}}Há {{len .Events}} arquivos novos ou atualizados{{end}} em suas categorias:
+{{range .Events}}
+  - {{.FileName}} ({{.ActionLabel}}), em "{{.CategName}}", {{date .At}}{{end}}
+{{if .BaseUrl}}
+Acesse {{.BaseUrl}} para consultá-los.
+{{end}}
+Para deixar de receber estes e-mails, desabilite as notificações por e-mail em seu perfil.
+
+Agros Arquivos
+`))
+
+// Render gera o e-mail de arquivos novos ou alterados de um usuário, a
+// partir dos modelos em português.
+//
+// Parâmetros:
+//   - to: e-mail do destinatário.
+//   - name: nome de apresentação do destinatário.
+//   - baseUrl: endereço do frontend (opcional).
+//   - events: eventos dos arquivos.
+//
+// Retorno:
+//   - Message: o e-mail gerado.
+//   - error: erro caso a execução dos modelos falhe.
+func Render(to, name, baseUrl string, events []Event) (Message, error) {
+	data := struct {
+		Name    string
+		BaseUrl string
+		Events  []Event
+	}{name, strings.TrimSuffix(baseUrl, "/"), events}
+
+	var subject, body bytes.Buffer
+	if err := subjectTemplate.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+	if err := bodyTemplate.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	return Message{To: to, Subject: subject.String(), Body: body.String()}, nil
+}
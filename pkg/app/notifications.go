@@ -0,0 +1,181 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Tipos de notificação.
+const (
+	// NotificationInboxUpload indica o envio de um arquivo por um usuário a
+	// uma caixa de entrada.
+	NotificationInboxUpload = "inbox_upload"
+)
+
+// CreateNotification registra uma notificação para os administradores.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: dados da notificação.
+//
+// Retorno:
+//   - uuid.UUID: identificador da notificação criada.
+//   - error: erro caso não seja possível criar a notificação.
+func CreateNotification(ctx *context.Context, p NotificationData) (uuid.UUID, error) {
+	// Geração do UUID e Timestamp
+	ts := time.Now().Unix()
+	notificationId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar UUID")
+	}
+
+	// Insert query
+	schema := &ctx.Config.Database.Schema
+	nc := &schema.NotificationTable.Columns
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s)
+		VALUES (:notification_id, :kind, :user_id, :entity_id, :message, :created_at)`,
+		schema.Name,
+		schema.NotificationTable.Name,
+		nc.NotificationId,
+		nc.Kind,
+		nc.UserId,
+		nc.EntityId,
+		nc.Message,
+		nc.CreatedAt,
+	)
+
+	// Criação
+	_, err = ctx.DB.Exec(
+		insert,
+		sql.Named("notification_id", notificationId.String()),
+		sql.Named("kind", p.Kind),
+		sql.Named("user_id", p.UserId.String()),
+		sql.Named("entity_id", p.EntityId.String()),
+		sql.Named("message", p.Message),
+		sql.Named("created_at", ts),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar notificação.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar notificação")
+	}
+	return notificationId, nil
+}
+
+// QueryNotifications recupera as notificações, das mais recentes para as
+// mais antigas.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - unread: true para recuperar apenas as notificações não lidas.
+//
+// Retorno:
+//   - []db.NotificationModel: as notificações encontradas.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryNotifications(ctx *context.Context, unread bool) ([]db.NotificationModel, error) {
+	var notifications []db.NotificationModel
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	nc := &schema.NotificationTable.Columns
+	where := ""
+	if unread {
+		where = fmt.Sprintf("WHERE %s IS NULL", nc.ReadAt)
+	}
+	query := fmt.Sprintf(
+		`SELECT %s,%s,%s,%s,%s,%s,%s
+		FROM %s.%s
+		%s
+		ORDER BY %s DESC`,
+		nc.NotificationId,
+		nc.Kind,
+		nc.UserId,
+		nc.EntityId,
+		nc.Message,
+		nc.CreatedAt,
+		nc.ReadAt,
+		schema.Name,
+		schema.NotificationTable.Name,
+		where,
+		nc.CreatedAt,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar notificações.", zap.Error(err))
+		return notifications, fmt.Errorf("não foi possível obter as notificações")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var n db.NotificationModel
+		var readAt sql.NullInt64
+		err = rows.Scan(
+			&n.NotificationId,
+			&n.Kind,
+			&n.UserId,
+			&n.EntityId,
+			&n.Message,
+			&n.CreatedAt,
+			&readAt,
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao obter notificação.", zap.Error(err))
+			return notifications, fmt.Errorf("não foi possível obter todas as notificações")
+		}
+		n.ReadAt = readAt.Int64
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+// MarkNotificationRead marca uma notificação como lida.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - notificationId: identificador da notificação.
+//
+// Retorno:
+//   - error: ErrEntityNotFound, caso a notificação não exista, ou erro caso
+//     não seja possível alterá-la.
+func MarkNotificationRead(ctx *context.Context, notificationId uuid.UUID) error {
+	// Update query
+	schema := &ctx.Config.Database.Schema
+	nc := &schema.NotificationTable.Columns
+	update := fmt.Sprintf(
+		`UPDATE %s.%s
+		SET %s = COALESCE(%s, :read_at)
+		WHERE %s = :notification_id`,
+		schema.Name,
+		schema.NotificationTable.Name,
+		nc.ReadAt,
+		nc.ReadAt,
+		nc.NotificationId,
+	)
+
+	// Atualização
+	res, err := ctx.DB.Exec(
+		update,
+		sql.Named("read_at", time.Now().Unix()),
+		sql.Named("notification_id", notificationId.String()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao atualizar notificação.", zap.Error(err))
+		return fmt.Errorf("não foi possível atualizar notificação")
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		return ErrEntityNotFound
+	}
+	return nil
+}
@@ -0,0 +1,351 @@
+// Package oidc implementa o cliente OpenID Connect do login único (SSO), com
+// o fluxo de código de autorização e PKCE (RFC 7636): descoberta do provedor,
+// montagem da URL de autorização, troca do código pelos tokens e validação do
+// ID token com as chaves públicas (JWKS) do provedor.
+//
+// Os documentos de descoberta e as chaves são mantidos em memória por
+// emissor. Como em lockout, a configuração é recebida a cada chamada, o que
+// acompanha as recargas do arquivo de configuração.
+package oidc
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// discoveryTTL é a validade do documento de descoberta e das chaves em
+// memória.
+const discoveryTTL = time.Hour
+
+// Discovery contém os campos utilizados do documento de descoberta
+// (/.well-known/openid-configuration).
+type Discovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JwksUri               string `json:"jwks_uri"`
+}
+
+// Identity contém os dados do usuário autenticado, extraídos do ID token.
+type Identity struct {
+	// Issuer é o emissor do ID token.
+	Issuer string
+	// Subject é o identificador do usuário no provedor (claim sub).
+	Subject string
+	// Email é o e-mail do usuário, caso o escopo email seja concedido.
+	Email string
+	// EmailVerified indica que o provedor verificou o e-mail.
+	EmailVerified bool
+	// Name é o nome de apresentação do usuário.
+	Name string
+	// PreferredUsername é o nome de usuário sugerido pelo provedor.
+	PreferredUsername string
+}
+
+// idTokenClaims define os claims lidos do ID token.
+type idTokenClaims struct {
+	Nonce             string `json:"nonce"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	jwt.RegisteredClaims
+}
+
+// provider armazena o estado em memória de um emissor.
+type provider struct {
+	discovery Discovery
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// Client é o cliente OpenID Connect da aplicação.
+type Client struct {
+	http      *http.Client
+	mu        sync.Mutex
+	providers map[string]*provider
+}
+
+// New cria um cliente OpenID Connect. Um httpClient nulo usa um cliente com
+// timeout de 10 segundos.
+func New(httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Client{http: httpClient, providers: make(map[string]*provider)}
+}
+
+// RandomString gera um valor aleatório codificado em base64url, usado como
+// state, nonce e code verifier.
+func RandomString() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// CodeChallenge calcula o code challenge S256 de um code verifier (PKCE).
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// getJSON obtém e decodifica um documento JSON.
+func (c *Client) getJSON(uri string, v any) error {
+	res, err := c.http.Get(uri)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("resposta %d de %s", res.StatusCode, uri)
+	}
+	return json.NewDecoder(res.Body).Decode(v)
+}
+
+// provider obtém o estado de um emissor, realizando a descoberta e a busca
+// das chaves quando ausente, expirado ou quando forçado (ex.: chave
+// desconhecida, após rotação no provedor).
+func (c *Client) provider(issuer string, refresh bool) (*provider, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	p, ok := c.providers[issuer]
+	if ok && !refresh && time.Since(p.fetchedAt) < discoveryTTL {
+		return p, nil
+	}
+
+	// Descoberta
+	p = &provider{fetchedAt: time.Now()}
+	wellKnown := strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+	if err := c.getJSON(wellKnown, &p.discovery); err != nil {
+		return nil, fmt.Errorf("erro na descoberta do provedor: %w", err)
+	}
+	if p.discovery.Issuer != issuer {
+		return nil, fmt.Errorf("emissor da descoberta divergente: %s", p.discovery.Issuer)
+	}
+
+	// Chaves públicas
+	var jwks struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := c.getJSON(p.discovery.JwksUri, &jwks); err != nil {
+		return nil, fmt.Errorf("erro ao obter chaves do provedor: %w", err)
+	}
+	p.keys = make(map[string]crypto.PublicKey)
+	for _, k := range jwks.Keys {
+		if key, err := k.publicKey(); err == nil && (k.Use == "" || k.Use == "sig") {
+			p.keys[k.Kid] = key
+		}
+	}
+	c.providers[issuer] = p
+	return p, nil
+}
+
+// AuthURL monta a URL de autorização do provedor, para onde o navegador do
+// usuário é redirecionado.
+//
+// Parâmetros:
+//   - cfg: configuração OpenID Connect.
+//   - state: valor aleatório que vincula o retorno à requisição.
+//   - nonce: valor aleatório que vincula o ID token à requisição.
+//   - verifier: code verifier do PKCE.
+//
+// Retorno:
+//   - string: a URL de autorização.
+//   - error: erro caso não seja possível realizar a descoberta do provedor.
+func (c *Client) AuthURL(cfg config.Oidc, state, nonce, verifier string) (string, error) {
+	p, err := c.provider(cfg.Issuer, false)
+	if err != nil {
+		return "", err
+	}
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", cfg.ClientId)
+	params.Set("redirect_uri", cfg.RedirectUrl)
+	params.Set("scope", strings.Join(cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", CodeChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+
+	sep := "?"
+	if strings.Contains(p.discovery.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return p.discovery.AuthorizationEndpoint + sep + params.Encode(), nil
+}
+
+// Exchange troca o código de autorização pelos tokens do provedor e valida o
+// ID token recebido.
+//
+// Parâmetros:
+//   - cfg: configuração OpenID Connect.
+//   - code: código de autorização recebido no retorno.
+//   - verifier: code verifier do PKCE da requisição.
+//   - nonce: nonce da requisição, comparado ao claim do ID token.
+//
+// Retorno:
+//   - Identity: os dados do usuário autenticado.
+//   - error: erro caso a troca falhe ou o ID token seja inválido.
+func (c *Client) Exchange(cfg config.Oidc, code, verifier, nonce string) (Identity, error) {
+	p, err := c.provider(cfg.Issuer, false)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	// Requisição ao token endpoint (client_secret_basic, quando houver
+	// segredo)
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", cfg.RedirectUrl)
+	form.Set("client_id", cfg.ClientId)
+	form.Set("code_verifier", verifier)
+	req, err := http.NewRequest(http.MethodPost, p.discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(cfg.ClientId), url.QueryEscape(cfg.ClientSecret))
+	}
+	res, err := c.http.Do(req)
+	if err != nil {
+		return Identity{}, fmt.Errorf("erro na troca do código: %w", err)
+	}
+	defer func() { _ = res.Body.Close() }()
+	var tokens struct {
+		IdToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&tokens); err != nil {
+		return Identity{}, fmt.Errorf("resposta inválida do token endpoint: %w", err)
+	}
+	if res.StatusCode != http.StatusOK || tokens.IdToken == "" {
+		return Identity{}, fmt.Errorf("troca do código recusada (%d): %s", res.StatusCode, tokens.Error)
+	}
+
+	return c.verify(cfg, p, tokens.IdToken, nonce)
+}
+
+// verify valida a assinatura, o emissor, a audiência, a expiração e o nonce
+// de um ID token.
+func (c *Client) verify(cfg config.Oidc, p *provider, raw, nonce string) (Identity, error) {
+	keyFunc := func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		if key, ok := p.keys[kid]; ok {
+			return key, nil
+		}
+		// Chave desconhecida: buscar as chaves novamente (rotação)
+		refreshed, err := c.provider(cfg.Issuer, true)
+		if err != nil {
+			return nil, err
+		}
+		if key, ok := refreshed.keys[kid]; ok {
+			return key, nil
+		}
+		return nil, fmt.Errorf("chave %q desconhecida", kid)
+	}
+	token, err := jwt.ParseWithClaims(
+		raw,
+		new(idTokenClaims),
+		keyFunc,
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithAudience(cfg.ClientId),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return Identity{}, fmt.Errorf("ID token inválido: %w", err)
+	}
+	claims, ok := token.Claims.(*idTokenClaims)
+	if !ok || !token.Valid {
+		return Identity{}, fmt.Errorf("ID token inválido")
+	}
+	if claims.Nonce != nonce {
+		return Identity{}, fmt.Errorf("nonce do ID token divergente")
+	}
+	if claims.Subject == "" {
+		return Identity{}, fmt.Errorf("ID token sem subject")
+	}
+	return Identity{
+		Issuer:            claims.Issuer,
+		Subject:           claims.Subject,
+		Email:             claims.Email,
+		EmailVerified:     claims.EmailVerified,
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+	}, nil
+}
+
+// jwk representa uma chave pública do documento JWKS (RFC 7517).
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey converte a chave para o tipo da biblioteca padrão. São aceitas
+// chaves RSA e EC P-256.
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	decode := base64.RawURLEncoding.DecodeString
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("curva %q não suportada", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(x),
+			Y:     new(big.Int).SetBytes(y),
+		}, nil
+	default:
+		return nil, fmt.Errorf("tipo de chave %q não suportado", k.Kty)
+	}
+}
@@ -0,0 +1,91 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxPasswordBytes é o tamanho máximo, em bytes, de uma senha aceito pelo
+// bcrypt.
+const maxPasswordBytes = 72
+
+// PasswordPolicyError indica uma senha que não atende à política de senhas,
+// com a descrição de cada regra violada.
+type PasswordPolicyError struct {
+	// Violations contém as mensagens das regras violadas.
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "senha não atende à política de senhas: " + strings.Join(e.Violations, "; ")
+}
+
+// ValidatePassword verifica se uma senha atende à política de senhas:
+// tamanho mínimo, classes de caracteres exigidas, lista de senhas comuns
+// proibidas e diferença em relação ao nome de usuário.
+//
+// Parâmetros:
+//   - policy: política de senhas da configuração.
+//   - username: nome do usuário dono da senha.
+//   - password: senha a ser validada.
+//
+// Retorno:
+//   - error: *PasswordPolicyError com as regras violadas, ou nil caso a
+//     senha seja válida.
+func ValidatePassword(policy config.PasswordPolicy, username, password string) error {
+	var violations []string
+
+	// Tamanho
+	if utf8.RuneCountInString(password) < policy.MinLength {
+		violations = append(violations, fmt.Sprintf("deve ter pelo menos %d caracteres", policy.MinLength))
+	}
+	if len(password) > maxPasswordBytes {
+		violations = append(violations, fmt.Sprintf("deve ter no máximo %d bytes", maxPasswordBytes))
+	}
+
+	// Classes de caracteres
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if policy.RequireUpper && !upper {
+		violations = append(violations, "deve conter uma letra maiúscula")
+	}
+	if policy.RequireLower && !lower {
+		violations = append(violations, "deve conter uma letra minúscula")
+	}
+	if policy.RequireDigit && !digit {
+		violations = append(violations, "deve conter um número")
+	}
+	if policy.RequireSymbol && !symbol {
+		violations = append(violations, "deve conter um caractere especial")
+	}
+
+	// Senhas comuns e nome de usuário
+	for _, banned := range policy.Banned {
+		if strings.EqualFold(password, banned) {
+			violations = append(violations, "é uma senha comum")
+			break
+		}
+	}
+	if username != "" && strings.EqualFold(password, username) {
+		violations = append(violations, "não pode ser igual ao nome de usuário")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+// ErrInvalidResetToken indica um token de redefinição de senha inexistente,
+// já utilizado ou expirado.
+var ErrInvalidResetToken = errors.New("token de redefinição de senha inválido")
+
+// CreatePasswordReset gera um token de uso único para que o usuário defina
+// a própria senha. Os tokens anteriores ainda disponíveis do usuário são
+// invalidados.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário do token.
+//   - createdBy: identificador do administrador que gerou o token.
+//
+// Retorno:
+//   - PasswordResetData: o token gerado e sua expiração.
+//   - error: erro caso não seja possível gerar o token.
+func CreatePasswordReset(ctx *context.Context, userId, createdBy uuid.UUID) (PasswordResetData, error) {
+	res := PasswordResetData{UserId: userId}
+
+	// Geração do UUID, do token e dos timestamps
+	now := time.Now()
+	tokenId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar UUID")
+	}
+	if res.Token, err = newToken(); err != nil {
+		ctx.Logger.Error("Erro ao gerar token de redefinição de senha.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível gerar token de redefinição de senha")
+	}
+	res.ExpiresAt = now.Add(time.Duration(ctx.Config.ResetExpires) * time.Minute)
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Invalidação dos tokens anteriores
+	schema := &ctx.Config.Database.Schema
+	pc := &schema.PasswordResetTable.Columns
+	update := fmt.Sprintf(
+		`UPDATE %s.%s SET %s = :used_at WHERE %s = :user_id AND %s IS NULL`,
+		schema.Name,
+		schema.PasswordResetTable.Name,
+		pc.UsedAt,
+		pc.UserId,
+		pc.UsedAt,
+	)
+	_, err = tx.Exec(update, sql.Named("used_at", now.Unix()), sql.Named("user_id", userId.String()))
+	if err != nil {
+		ctx.Logger.Error("Erro ao invalidar tokens de redefinição de senha.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível invalidar tokens de redefinição de senha")
+	}
+
+	// Criação
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s)
+		VALUES (:token_id, :user_id, :token_hash, :created_by, :created_at, :expires_at)`,
+		schema.Name,
+		schema.PasswordResetTable.Name,
+		pc.TokenId,
+		pc.UserId,
+		pc.TokenHash,
+		pc.CreatedBy,
+		pc.CreatedAt,
+		pc.ExpiresAt,
+	)
+	_, err = tx.Exec(
+		insert,
+		sql.Named("token_id", tokenId.String()),
+		sql.Named("user_id", userId.String()),
+		sql.Named("token_hash", hashToken(res.Token)),
+		sql.Named("created_by", createdBy.String()),
+		sql.Named("created_at", now.Unix()),
+		sql.Named("expires_at", res.ExpiresAt.Unix()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar token de redefinição de senha.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar token de redefinição de senha")
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return res, fmt.Errorf("não foi possível confirmar transação")
+	}
+	return res, nil
+}
+
+// RedeemPasswordReset utiliza um token de redefinição para definir a nova
+// senha do usuário. O token é consumido, a troca obrigatória de senha é
+// removida e as sessões do usuário são encerradas.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - token: token de redefinição recebido do administrador.
+//   - password: nova senha do usuário.
+//
+// Retorno:
+//   - uuid.UUID: o identificador do usuário da senha redefinida.
+//   - error: ErrInvalidResetToken, *PasswordPolicyError ou erro caso não
+//     seja possível redefinir a senha.
+func RedeemPasswordReset(ctx *context.Context, token, password string) (uuid.UUID, error) {
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Query, com bloqueio da linha contra usos concorrentes
+	schema := &ctx.Config.Database.Schema
+	pc := &schema.PasswordResetTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s,%s,%s,%s
+		FROM %s.%s
+		WHERE %s = :token_hash
+		FOR UPDATE`,
+		pc.TokenId,
+		pc.UserId,
+		pc.ExpiresAt,
+		pc.UsedAt,
+		schema.Name,
+		schema.PasswordResetTable.Name,
+		pc.TokenHash,
+	)
+
+	// Obtenção do token
+	var tokenId, userId string
+	var expiresAt int64
+	var usedAt sql.NullInt64
+	row := tx.QueryRow(query, sql.Named("token_hash", hashToken(token)))
+	err = row.Scan(&tokenId, &userId, &expiresAt, &usedAt)
+	now := time.Now().Unix()
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && (usedAt.Valid || expiresAt <= now)) {
+		err = ErrInvalidResetToken
+		return uuid.Nil, err
+	} else if err != nil {
+		ctx.Logger.Error("Erro ao consultar token de redefinição de senha.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível consultar token de redefinição de senha")
+	}
+
+	// Validar senha
+	user, err := QueryUserById(ctx, uuid.MustParse(userId))
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err = ValidatePassword(ctx.Config.PasswordPolicy, user.Username, password); err != nil {
+		return uuid.Nil, err
+	}
+	hash, err := HashPassword(ctx, password)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// Atualização da senha
+	uc := &schema.UserTable.Columns
+	update := fmt.Sprintf(
+		`UPDATE %s.%s
+		SET %s = :password, %s = 0, %s = :updated_at
+		WHERE %s = :user_id`,
+		schema.Name,
+		schema.UserTable.Name,
+		uc.Password,
+		uc.MustChangePassword,
+		uc.UpdatedAt,
+		uc.UserId,
+	)
+	_, err = tx.Exec(
+		update,
+		sql.Named("password", hash),
+		sql.Named("updated_at", nextVersion(user.UpdatedAt)),
+		sql.Named("user_id", userId),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao redefinir senha.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível redefinir senha")
+	}
+
+	// Consumo do token
+	update = fmt.Sprintf(
+		`UPDATE %s.%s SET %s = :used_at WHERE %s = :token_id`,
+		schema.Name,
+		schema.PasswordResetTable.Name,
+		pc.UsedAt,
+		pc.TokenId,
+	)
+	_, err = tx.Exec(update, sql.Named("used_at", now), sql.Named("token_id", tokenId))
+	if err != nil {
+		ctx.Logger.Error("Erro ao consumir token de redefinição de senha.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível redefinir senha")
+	}
+
+	// Encerrar as sessões do usuário
+	if err = revokeSessions(ctx, tx.Exec, true, uuid.MustParse(userId)); err != nil {
+		return uuid.Nil, fmt.Errorf("não foi possível redefinir senha")
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível confirmar transação")
+	}
+	return uuid.MustParse(userId), nil
+}
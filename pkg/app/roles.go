@@ -0,0 +1,526 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Permissões de acesso concedidas pelos papéis.
+const (
+	// PermUsersRead permite consultar todos os usuários.
+	PermUsersRead = "users:read"
+	// PermUsersWrite permite criar, alterar e excluir usuários.
+	PermUsersWrite = "users:write"
+	// PermContentRead permite consultar categorias e arquivos de todos os
+	// usuários.
+	PermContentRead = "content:read"
+	// PermContentWrite permite criar, alterar, excluir, copiar e compartilhar
+	// categorias e arquivos.
+	PermContentWrite = "content:write"
+	// PermReportsRead permite consultar os relatórios.
+	PermReportsRead = "reports:read"
+	// PermAuditRead permite consultar a auditoria.
+	PermAuditRead = "audit:read"
+	// PermRolesManage permite gerenciar papéis e atribuí-los aos usuários.
+	PermRolesManage = "roles:manage"
+)
+
+// Papéis padrão, criados na inicialização da aplicação caso não existam.
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// Permissions lista todas as permissões válidas.
+var Permissions = []string{
+	PermUsersRead,
+	PermUsersWrite,
+	PermContentRead,
+	PermContentWrite,
+	PermReportsRead,
+	PermAuditRead,
+	PermRolesManage,
+}
+
+// DefaultRoles relaciona os papéis padrão às suas permissões iniciais.
+var DefaultRoles = map[string][]string{
+	RoleAdmin: Permissions,
+	RoleEditor: {
+		PermUsersRead,
+		PermContentRead,
+		PermContentWrite,
+		PermReportsRead,
+	},
+	RoleViewer: {
+		PermUsersRead,
+		PermContentRead,
+		PermReportsRead,
+	},
+}
+
+var (
+	// ErrProtectedRole indica a tentativa de excluir um papel padrão.
+	ErrProtectedRole = errors.New("papel padrão não pode ser excluído")
+	// ErrInvalidPermission indica uma permissão desconhecida.
+	ErrInvalidPermission = errors.New("permissão inválida")
+)
+
+// validPermissions verifica se todas as permissões informadas são conhecidas.
+func validPermissions(perms []string) bool {
+	for _, p := range perms {
+		if !slices.Contains(Permissions, p) {
+			return false
+		}
+	}
+	return true
+}
+
+// insertPermissions insere, na transação informada, as permissões de um
+// papel.
+func insertPermissions(ctx *context.Context, tx *sql.Tx, roleId uuid.UUID, perms []string) error {
+	schema := &ctx.Config.Database.Schema
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s (%s, %s) VALUES (:role_id, :permission)`,
+		schema.Name,
+		schema.RolePermissionTable.Name,
+		schema.RolePermissionTable.Columns.RoleId,
+		schema.RolePermissionTable.Columns.Permission,
+	)
+	for _, p := range slices.Compact(slices.Sorted(slices.Values(perms))) {
+		_, err := tx.Exec(
+			insert,
+			sql.Named("role_id", roleId.String()),
+			sql.Named("permission", p),
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao inserir permissão.", zap.Error(err))
+			return fmt.Errorf("não foi possível inserir permissão")
+		}
+	}
+	return nil
+}
+
+// EnsureDefaultRoles cria os papéis padrão (admin, editor e viewer) que ainda
+// não existam no banco de dados. Papéis existentes não são alterados.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//
+// Retorno:
+//   - error: erro caso não seja possível consultar ou criar os papéis.
+func EnsureDefaultRoles(ctx *context.Context) error {
+	roles, err := QueryAllRoles(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{RoleAdmin, RoleEditor, RoleViewer} {
+		exists := slices.ContainsFunc(roles, func(r db.RoleModel) bool {
+			return r.Name == name
+		})
+		if exists {
+			continue
+		}
+		if _, err = CreateRole(ctx, RoleData{Name: name, Permissions: DefaultRoles[name]}); err != nil {
+			return err
+		}
+		ctx.Logger.Info("Papel padrão criado.", zap.String("role", name))
+	}
+	return nil
+}
+
+// CreateRole cria um papel com as permissões informadas.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: nome e permissões do papel.
+//
+// Retorno:
+//   - uuid.UUID: identificador do papel criado.
+//   - error: ErrInvalidPermission, caso alguma permissão seja desconhecida,
+//     ou erro caso não seja possível criar o papel.
+func CreateRole(ctx *context.Context, p RoleData) (uuid.UUID, error) {
+	if !validPermissions(p.Permissions) {
+		return uuid.Nil, ErrInvalidPermission
+	}
+
+	// Geração do UUID e Timestamp
+	ts := time.Now().Unix()
+	roleId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar UUID")
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Insert query
+	schema := &ctx.Config.Database.Schema
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s)
+		VALUES (:role_id, :name, :updated_at)`,
+		schema.Name,
+		schema.RoleTable.Name,
+		schema.RoleTable.Columns.RoleId,
+		schema.RoleTable.Columns.Name,
+		schema.RoleTable.Columns.UpdatedAt,
+	)
+
+	// Criação
+	_, err = tx.Exec(
+		insert,
+		sql.Named("role_id", roleId.String()),
+		sql.Named("name", p.Name),
+		sql.Named("updated_at", ts),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar papel.", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível criar papel")
+	}
+	if err = insertPermissions(ctx, tx, roleId, p.Permissions); err != nil {
+		return uuid.Nil, err
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return uuid.Nil, fmt.Errorf("não foi possível confirmar transação")
+	}
+	return roleId, nil
+}
+
+// queryRoles recupera os papéis que atendem à condição informada, com suas
+// permissões.
+func queryRoles(ctx *context.Context, where string, args ...any) ([]db.RoleModel, error) {
+	var roles []db.RoleModel
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	rc := &schema.RoleTable.Columns
+	pc := &schema.RolePermissionTable.Columns
+	query := fmt.Sprintf(
+		`SELECT r.%s, r.%s, r.%s, p.%s
+		FROM %s.%s r
+		LEFT JOIN %s.%s p ON p.%s = r.%s
+		%s
+		ORDER BY r.%s, p.%s`,
+		rc.RoleId,
+		rc.Name,
+		rc.UpdatedAt,
+		pc.Permission,
+		schema.Name,
+		schema.RoleTable.Name,
+		schema.Name,
+		schema.RolePermissionTable.Name,
+		pc.RoleId,
+		rc.RoleId,
+		where,
+		rc.Name,
+		pc.Permission,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, args...)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar papéis.", zap.Error(err))
+		return roles, fmt.Errorf("não foi possível obter os papéis")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas, agrupando as permissões por papel
+	for rows.Next() {
+		var r db.RoleModel
+		var perm sql.NullString
+		if err = rows.Scan(&r.RoleId, &r.Name, &r.UpdatedAt, &perm); err != nil {
+			ctx.Logger.Error("Erro ao obter papel.", zap.Error(err))
+			return roles, fmt.Errorf("não foi possível obter todos os papéis")
+		}
+		if n := len(roles); n == 0 || roles[n-1].RoleId != r.RoleId {
+			r.Permissions = []string{}
+			roles = append(roles, r)
+		}
+		if perm.Valid {
+			last := &roles[len(roles)-1]
+			last.Permissions = append(last.Permissions, perm.String)
+		}
+	}
+	return roles, nil
+}
+
+// QueryAllRoles recupera todos os papéis, com suas permissões.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//
+// Retorno:
+//   - []db.RoleModel: os papéis encontrados.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryAllRoles(ctx *context.Context) ([]db.RoleModel, error) {
+	return queryRoles(ctx, "")
+}
+
+// QueryRoleById recupera um papel, com suas permissões.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - roleId: identificador do papel.
+//
+// Retorno:
+//   - db.RoleModel: o papel encontrado.
+//   - error: erro caso o papel não exista ou a consulta falhe.
+func QueryRoleById(ctx *context.Context, roleId uuid.UUID) (db.RoleModel, error) {
+	schema := &ctx.Config.Database.Schema
+	roles, err := queryRoles(
+		ctx,
+		fmt.Sprintf("WHERE r.%s = :role_id", schema.RoleTable.Columns.RoleId),
+		sql.Named("role_id", roleId.String()),
+	)
+	if err != nil {
+		return db.RoleModel{}, err
+	} else if len(roles) == 0 {
+		return db.RoleModel{}, fmt.Errorf("não foi possível obter papel")
+	}
+	return roles[0], nil
+}
+
+// QueryUserRoles recupera os papéis atribuídos a um usuário, com suas
+// permissões.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//
+// Retorno:
+//   - []db.RoleModel: os papéis do usuário.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryUserRoles(ctx *context.Context, userId uuid.UUID) ([]db.RoleModel, error) {
+	schema := &ctx.Config.Database.Schema
+	where := fmt.Sprintf(
+		"WHERE r.%s IN (SELECT %s FROM %s.%s WHERE %s = :user_id)",
+		schema.RoleTable.Columns.RoleId,
+		schema.UserRoleTable.Columns.RoleId,
+		schema.Name,
+		schema.UserRoleTable.Name,
+		schema.UserRoleTable.Columns.UserId,
+	)
+	return queryRoles(ctx, where, sql.Named("user_id", userId.String()))
+}
+
+// UpdateRole altera o nome e/ou as permissões de um papel.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - roleId: identificador do papel.
+//   - p: novo nome (vazio para manter) e novas permissões (nil para manter).
+//
+// Retorno:
+//   - error: ErrInvalidPermission, caso alguma permissão seja desconhecida,
+//     ou erro caso não seja possível alterar o papel.
+func UpdateRole(ctx *context.Context, roleId uuid.UUID, p RoleData) error {
+	if !validPermissions(p.Permissions) {
+		return ErrInvalidPermission
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return fmt.Errorf("erro ao iniciar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Checagem dos parâmetros a serem atualizados
+	schema := &ctx.Config.Database.Schema
+	args := []any{sql.Named("updated_at", time.Now().Unix())}
+	set := []string{schema.RoleTable.Columns.UpdatedAt + " = :updated_at"}
+	if p.Name != "" {
+		args = append(args, sql.Named("name", p.Name))
+		set = append(set, schema.RoleTable.Columns.Name+" = :name")
+	}
+
+	// Update query
+	update := fmt.Sprintf(`UPDATE %s.%s
+				SET %s
+				WHERE %s = :role_id`,
+		schema.Name,
+		schema.RoleTable.Name,
+		strings.Join(set, ","),
+		schema.RoleTable.Columns.RoleId,
+	)
+	args = append(args, sql.Named("role_id", roleId.String()))
+
+	// Atualização
+	res, err := tx.Exec(update, args...)
+	if err != nil {
+		ctx.Logger.Error("Erro ao atualizar papel.", zap.Error(err))
+		return fmt.Errorf("não foi possível atualizar papel")
+	} else if n, _ := res.RowsAffected(); n != 1 {
+		err = ErrEntityNotFound
+		return err
+	}
+
+	// Substituição das permissões
+	if p.Permissions != nil {
+		del := fmt.Sprintf(
+			"DELETE FROM %s.%s WHERE %s = :role_id",
+			schema.Name,
+			schema.RolePermissionTable.Name,
+			schema.RolePermissionTable.Columns.RoleId,
+		)
+		if _, err = tx.Exec(del, sql.Named("role_id", roleId.String())); err != nil {
+			ctx.Logger.Error("Erro ao remover permissões.", zap.Error(err))
+			return fmt.Errorf("não foi possível atualizar papel")
+		}
+		if err = insertPermissions(ctx, tx, roleId, p.Permissions); err != nil {
+			return err
+		}
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return fmt.Errorf("erro ao confirmar transação")
+	}
+	return nil
+}
+
+// DeleteRole exclui um papel, suas permissões e suas atribuições aos
+// usuários. Os papéis padrão não podem ser excluídos.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - roleId: identificador do papel.
+//
+// Retorno:
+//   - error: ErrProtectedRole, caso seja um papel padrão, ou erro caso não
+//     seja possível excluir o papel.
+func DeleteRole(ctx *context.Context, roleId uuid.UUID) error {
+	role, err := QueryRoleById(ctx, roleId)
+	if err != nil {
+		return ErrEntityNotFound
+	}
+	if _, ok := DefaultRoles[role.Name]; ok {
+		return ErrProtectedRole
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return fmt.Errorf("erro ao iniciar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Delete queries, das dependências para o papel
+	schema := &ctx.Config.Database.Schema
+	tables := []struct{ name, column string }{
+		{schema.UserRoleTable.Name, schema.UserRoleTable.Columns.RoleId},
+		{schema.RolePermissionTable.Name, schema.RolePermissionTable.Columns.RoleId},
+		{schema.RoleTable.Name, schema.RoleTable.Columns.RoleId},
+	}
+	for _, t := range tables {
+		del := fmt.Sprintf("DELETE FROM %s.%s WHERE %s = :role_id", schema.Name, t.name, t.column)
+		if _, err = tx.Exec(del, sql.Named("role_id", roleId.String())); err != nil {
+			ctx.Logger.Error("Erro ao excluir papel.", zap.Error(err))
+			return fmt.Errorf("não foi possível excluir papel")
+		}
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return fmt.Errorf("erro ao confirmar transação")
+	}
+	return nil
+}
+
+// SetUserRoles substitui os papéis atribuídos a um usuário.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//   - roleIds: identificadores dos papéis atribuídos. Vazio remove todos.
+//
+// Retorno:
+//   - error: erro caso não seja possível atribuir os papéis.
+func SetUserRoles(ctx *context.Context, userId uuid.UUID, roleIds []uuid.UUID) error {
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return fmt.Errorf("erro ao iniciar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Remoção dos papéis atuais
+	schema := &ctx.Config.Database.Schema
+	urc := &schema.UserRoleTable.Columns
+	del := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE %s = :user_id",
+		schema.Name,
+		schema.UserRoleTable.Name,
+		urc.UserId,
+	)
+	if _, err = tx.Exec(del, sql.Named("user_id", userId.String())); err != nil {
+		ctx.Logger.Error("Erro ao remover papéis do usuário.", zap.Error(err))
+		return fmt.Errorf("não foi possível atribuir papéis")
+	}
+
+	// Atribuição dos novos papéis
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s (%s, %s) VALUES (:user_id, :role_id)`,
+		schema.Name,
+		schema.UserRoleTable.Name,
+		urc.UserId,
+		urc.RoleId,
+	)
+	for _, roleId := range roleIds {
+		_, err = tx.Exec(
+			insert,
+			sql.Named("user_id", userId.String()),
+			sql.Named("role_id", roleId.String()),
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao atribuir papel.", zap.Error(err))
+			return fmt.Errorf("não foi possível atribuir papéis")
+		}
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return fmt.Errorf("erro ao confirmar transação")
+	}
+	return nil
+}
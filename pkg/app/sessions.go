@@ -0,0 +1,369 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"time"
+)
+
+// lastSeenInterval é o intervalo mínimo entre atualizações do último acesso
+// de uma sessão, evitando uma escrita no banco a cada requisição.
+const lastSeenInterval = time.Minute
+
+// CreateSession registra uma nova sessão para um usuário, no momento do
+// login, inicia sua família de refresh tokens e registra o último login.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: usuário, agente e endereço IP do login.
+//
+// Retorno:
+//   - RefreshTokenData: o primeiro refresh token da sessão, cuja família é o
+//     identificador da sessão.
+//   - error: erro caso não seja possível criar a sessão.
+func CreateSession(ctx *context.Context, p SessionData) (RefreshTokenData, error) {
+	sessionId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return RefreshTokenData{}, fmt.Errorf("não foi possível criar UUID")
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return RefreshTokenData{}, fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Criação do primeiro refresh token
+	res, err := insertRefreshToken(ctx, tx, p.UserId, sessionId)
+	if err != nil {
+		return res, err
+	}
+
+	// Insert query
+	schema := &ctx.Config.Database.Schema
+	sc := &schema.SessionTable.Columns
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s, %s)
+		VALUES (:session_id, :user_id, :user_agent, :client_ip, :created_at, :last_seen, :expires_at)`,
+		schema.Name,
+		schema.SessionTable.Name,
+		sc.SessionId,
+		sc.UserId,
+		sc.UserAgent,
+		sc.ClientIp,
+		sc.CreatedAt,
+		sc.LastSeen,
+		sc.ExpiresAt,
+	)
+
+	// Criação
+	now := time.Now().Unix()
+	_, err = tx.Exec(
+		insert,
+		sql.Named("session_id", sessionId.String()),
+		sql.Named("user_id", p.UserId.String()),
+		sql.Named("user_agent", p.UserAgent),
+		sql.Named("client_ip", p.ClientIp),
+		sql.Named("created_at", now),
+		sql.Named("last_seen", now),
+		sql.Named("expires_at", res.ExpiresAt.Unix()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar sessão.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar sessão")
+	}
+
+	// Último login do usuário
+	if err = recordLogin(ctx, tx.Exec, p.UserId, time.Unix(now, 0)); err != nil {
+		return res, fmt.Errorf("não foi possível criar sessão")
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return res, fmt.Errorf("não foi possível confirmar transação")
+	}
+	return res, nil
+}
+
+// QueryUserSessions recupera as sessões ativas e não expiradas de um
+// usuário, da mais recente para a mais antiga.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//
+// Retorno:
+//   - []db.SessionModel: as sessões encontradas.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryUserSessions(ctx *context.Context, userId uuid.UUID) ([]db.SessionModel, error) {
+	var sessions []db.SessionModel
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	sc := &schema.SessionTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s,%s,%s,%s,%s,%s,%s
+		FROM %s.%s
+		WHERE %s = :user_id AND %s IS NULL AND %s > :now
+		ORDER BY %s DESC`,
+		sc.SessionId,
+		sc.UserId,
+		sc.UserAgent,
+		sc.ClientIp,
+		sc.CreatedAt,
+		sc.LastSeen,
+		sc.ExpiresAt,
+		schema.Name,
+		schema.SessionTable.Name,
+		sc.UserId,
+		sc.RevokedAt,
+		sc.ExpiresAt,
+		sc.LastSeen,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(
+		query,
+		sql.Named("user_id", userId.String()),
+		sql.Named("now", time.Now().Unix()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar sessões.", zap.Error(err))
+		return sessions, fmt.Errorf("não foi possível obter as sessões")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var s db.SessionModel
+		var userAgent, clientIp sql.NullString
+		err = rows.Scan(
+			&s.SessionId,
+			&s.UserId,
+			&userAgent,
+			&clientIp,
+			&s.CreatedAt,
+			&s.LastSeen,
+			&s.ExpiresAt,
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao obter sessão.", zap.Error(err))
+			return sessions, fmt.Errorf("não foi possível obter todas as sessões")
+		}
+		s.UserAgent, s.ClientIp = userAgent.String, clientIp.String
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// IsSessionActive verifica se uma sessão não foi encerrada. Tokens de acesso
+// de sessões encerradas são rejeitados.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - sessionId: identificador da sessão.
+//
+// Retorno:
+//   - bool: true caso a sessão esteja ativa.
+//   - error: erro caso a consulta falhe.
+func IsSessionActive(ctx *context.Context, sessionId uuid.UUID) (bool, error) {
+	// Query
+	schema := &ctx.Config.Database.Schema
+	sc := &schema.SessionTable.Columns
+	query := fmt.Sprintf(
+		`SELECT COUNT(*)
+		FROM %s.%s
+		WHERE %s = :session_id AND %s IS NULL`,
+		schema.Name,
+		schema.SessionTable.Name,
+		sc.SessionId,
+		sc.RevokedAt,
+	)
+
+	// Contagem
+	var count int64
+	row := ctx.DB.QueryRow(query, sql.Named("session_id", sessionId.String()))
+	if err := row.Scan(&count); err != nil {
+		ctx.Logger.Error("Erro ao consultar sessão.", zap.Error(err))
+		return false, fmt.Errorf("não foi possível consultar sessão")
+	}
+	return count > 0, nil
+}
+
+// TouchSession verifica se uma sessão está ativa e atualiza seu último
+// acesso, no máximo uma vez por lastSeenInterval.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - sessionId: identificador da sessão.
+//
+// Retorno:
+//   - bool: true caso a sessão esteja ativa.
+//   - error: erro caso a consulta falhe.
+func TouchSession(ctx *context.Context, sessionId uuid.UUID) (bool, error) {
+	// Update query
+	schema := &ctx.Config.Database.Schema
+	sc := &schema.SessionTable.Columns
+	update := fmt.Sprintf(
+		`UPDATE %s.%s
+		SET %s = :now
+		WHERE %s = :session_id AND %s IS NULL AND %s < :threshold`,
+		schema.Name,
+		schema.SessionTable.Name,
+		sc.LastSeen,
+		sc.SessionId,
+		sc.RevokedAt,
+		sc.LastSeen,
+	)
+
+	// Atualização; nenhuma linha afetada indica sessão encerrada ou acessada
+	// recentemente
+	now := time.Now()
+	res, err := ctx.DB.Exec(
+		update,
+		sql.Named("now", now.Unix()),
+		sql.Named("session_id", sessionId.String()),
+		sql.Named("threshold", now.Add(-lastSeenInterval).Unix()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao atualizar sessão.", zap.Error(err))
+		return false, fmt.Errorf("não foi possível atualizar sessão")
+	} else if n, _ := res.RowsAffected(); n == 1 {
+		return true, nil
+	}
+	return IsSessionActive(ctx, sessionId)
+}
+
+// revokeSessions encerra sessões e revoga seus refresh tokens, usando exec
+// (ctx.DB.Exec ou tx.Exec) para que a revogação possa fazer parte de outra
+// transação.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - exec: função de execução dos comandos.
+//   - byUser: true para encerrar todas as sessões do usuário id, false para
+//     encerrar apenas a sessão id.
+//   - id: identificador do usuário ou da sessão.
+//
+// Retorno:
+//   - error: erro caso não seja possível encerrar as sessões.
+func revokeSessions(
+	ctx *context.Context,
+	exec func(string, ...any) (sql.Result, error),
+	byUser bool,
+	id uuid.UUID,
+) error {
+	schema := &ctx.Config.Database.Schema
+	rc := &schema.RefreshTokenTable.Columns
+	sc := &schema.SessionTable.Columns
+	tables := []struct{ name, column, revokedAt string }{
+		{schema.RefreshTokenTable.Name, rc.FamilyId, rc.RevokedAt},
+		{schema.SessionTable.Name, sc.SessionId, sc.RevokedAt},
+	}
+	if byUser {
+		tables[0].column, tables[1].column = rc.UserId, sc.UserId
+	}
+
+	// Update queries
+	now := time.Now().Unix()
+	for _, t := range tables {
+		update := fmt.Sprintf(
+			`UPDATE %s.%s SET %s = :revoked_at WHERE %s = :id AND %s IS NULL`,
+			schema.Name,
+			t.name,
+			t.revokedAt,
+			t.column,
+			t.revokedAt,
+		)
+		_, err := exec(update, sql.Named("revoked_at", now), sql.Named("id", id.String()))
+		if err != nil {
+			ctx.Logger.Error("Erro ao encerrar sessões.", zap.Error(err))
+			return fmt.Errorf("não foi possível encerrar sessões")
+		}
+	}
+	return nil
+}
+
+// RevokeSession encerra uma sessão e revoga seus refresh tokens,
+// invalidando também os tokens de acesso emitidos para ela.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - sessionId: identificador da sessão.
+//
+// Retorno:
+//   - error: erro caso não seja possível encerrar a sessão.
+func RevokeSession(ctx *context.Context, sessionId uuid.UUID) error {
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return fmt.Errorf("erro ao iniciar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Encerramento
+	if err = revokeSessions(ctx, tx.Exec, false, sessionId); err != nil {
+		return err
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return fmt.Errorf("erro ao confirmar transação")
+	}
+	return nil
+}
+
+// RevokeUserSessions encerra todas as sessões de um usuário, como ao
+// excluí-lo ou ao alterar sua senha.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//
+// Retorno:
+//   - error: erro caso não seja possível encerrar as sessões.
+func RevokeUserSessions(ctx *context.Context, userId uuid.UUID) error {
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return fmt.Errorf("erro ao iniciar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Encerramento
+	if err = revokeSessions(ctx, tx.Exec, true, userId); err != nil {
+		return err
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return fmt.Errorf("erro ao confirmar transação")
+	}
+	return nil
+}
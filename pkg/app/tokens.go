@@ -0,0 +1,217 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"time"
+)
+
+var (
+	// ErrInvalidRefreshToken indica um refresh token inexistente ou expirado.
+	ErrInvalidRefreshToken = errors.New("refresh token inválido")
+	// ErrRefreshTokenReuse indica o reuso de um refresh token já rotacionado.
+	// Nesse caso, toda a família (sessão) do token é revogada.
+	ErrRefreshTokenReuse = errors.New("reuso de refresh token")
+)
+
+// hashToken calcula o hash SHA-256, em hexadecimal, de um token. Apenas o hash
+// é armazenado no banco.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newToken gera um token aleatório de 256 bits, codificado em base64 (URL).
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// insertRefreshToken gera e armazena um novo refresh token na família
+// informada.
+func insertRefreshToken(ctx *context.Context, tx *sql.Tx, userId, familyId uuid.UUID) (RefreshTokenData, error) {
+	res := RefreshTokenData{UserId: userId, FamilyId: familyId}
+
+	// Geração do UUID, do token e dos timestamps
+	now := time.Now()
+	tokenId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar UUID")
+	}
+	if res.Token, err = newToken(); err != nil {
+		ctx.Logger.Error("Erro ao gerar refresh token.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível gerar refresh token")
+	}
+	res.ExpiresAt = now.Add(time.Duration(ctx.Config.RefreshExpires) * time.Minute)
+
+	// Insert query
+	schema := &ctx.Config.Database.Schema
+	rc := &schema.RefreshTokenTable.Columns
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s)
+		VALUES (:token_id, :family_id, :user_id, :token_hash, :created_at, :expires_at)`,
+		schema.Name,
+		schema.RefreshTokenTable.Name,
+		rc.TokenId,
+		rc.FamilyId,
+		rc.UserId,
+		rc.TokenHash,
+		rc.CreatedAt,
+		rc.ExpiresAt,
+	)
+
+	// Criação
+	_, err = tx.Exec(
+		insert,
+		sql.Named("token_id", tokenId.String()),
+		sql.Named("family_id", familyId.String()),
+		sql.Named("user_id", userId.String()),
+		sql.Named("token_hash", hashToken(res.Token)),
+		sql.Named("created_at", now.Unix()),
+		sql.Named("expires_at", res.ExpiresAt.Unix()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar refresh token.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar refresh token")
+	}
+	return res, nil
+}
+
+// RotateRefreshToken troca um refresh token ativo por um novo, na mesma
+// família. O token apresentado é revogado; caso ele já tenha sido revogado,
+// trata-se de reuso (possível roubo) e toda a família é revogada.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - token: refresh token apresentado pelo cliente.
+//
+// Retorno:
+//   - RefreshTokenData: o novo token, seu usuário, sua família e sua
+//     expiração.
+//   - error: ErrInvalidRefreshToken, ErrRefreshTokenReuse ou erro caso não
+//     seja possível rotacionar o token.
+func RotateRefreshToken(ctx *context.Context, token string) (RefreshTokenData, error) {
+	var res RefreshTokenData
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Query, com bloqueio da linha contra rotações concorrentes
+	schema := &ctx.Config.Database.Schema
+	rc := &schema.RefreshTokenTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s,%s,%s,%s,%s
+		FROM %s.%s
+		WHERE %s = :token_hash
+		FOR UPDATE`,
+		rc.TokenId,
+		rc.FamilyId,
+		rc.UserId,
+		rc.ExpiresAt,
+		rc.RevokedAt,
+		schema.Name,
+		schema.RefreshTokenTable.Name,
+		rc.TokenHash,
+	)
+
+	// Obtenção do token
+	var tokenId, familyId, userId string
+	var expiresAt int64
+	var revokedAt sql.NullInt64
+	row := tx.QueryRow(query, sql.Named("token_hash", hashToken(token)))
+	err = row.Scan(&tokenId, &familyId, &userId, &expiresAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = ErrInvalidRefreshToken
+		return res, err
+	} else if err != nil {
+		ctx.Logger.Error("Erro ao consultar refresh token.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível consultar refresh token")
+	}
+
+	// Reuso de um token rotacionado: revogação de toda a família (sessão)
+	now := time.Now().Unix()
+	if revokedAt.Valid {
+		if err = revokeSessions(ctx, tx.Exec, false, uuid.MustParse(familyId)); err != nil {
+			return res, err
+		}
+		if err = tx.Commit(); err != nil {
+			ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+			return res, fmt.Errorf("não foi possível confirmar transação")
+		}
+		ctx.Logger.Warn(
+			"Reuso de refresh token detectado. Sessão revogada.",
+			zap.String("family_id", familyId),
+			zap.String("user_id", userId),
+		)
+		return res, ErrRefreshTokenReuse
+	}
+	if expiresAt <= now {
+		err = ErrInvalidRefreshToken
+		return res, err
+	}
+
+	// Revogação do token apresentado
+	update := fmt.Sprintf(
+		`UPDATE %s.%s SET %s = :revoked_at WHERE %s = :token_id`,
+		schema.Name,
+		schema.RefreshTokenTable.Name,
+		rc.RevokedAt,
+		rc.TokenId,
+	)
+	_, err = tx.Exec(update, sql.Named("revoked_at", now), sql.Named("token_id", tokenId))
+	if err != nil {
+		ctx.Logger.Error("Erro ao revogar refresh token.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível revogar refresh token")
+	}
+
+	// Criação do novo token e renovação da expiração da sessão
+	res, err = insertRefreshToken(ctx, tx, uuid.MustParse(userId), uuid.MustParse(familyId))
+	if err != nil {
+		return res, err
+	}
+	sc := &schema.SessionTable.Columns
+	update = fmt.Sprintf(
+		`UPDATE %s.%s SET %s = :expires_at WHERE %s = :session_id`,
+		schema.Name,
+		schema.SessionTable.Name,
+		sc.ExpiresAt,
+		sc.SessionId,
+	)
+	_, err = tx.Exec(
+		update,
+		sql.Named("expires_at", res.ExpiresAt.Unix()),
+		sql.Named("session_id", familyId),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao renovar sessão.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível renovar sessão")
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return res, fmt.Errorf("não foi possível confirmar transação")
+	}
+	return res, nil
+}
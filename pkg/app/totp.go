@@ -0,0 +1,506 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Parâmetros do TOTP (RFC 6238), compatíveis com os aplicativos
+// autenticadores usuais.
+const (
+	// totpPeriod é a duração, em segundos, de cada intervalo de tempo.
+	totpPeriod = 30
+	// totpDigits é o número de dígitos de um código.
+	totpDigits = 6
+	// totpSkew é o número de intervalos aceitos antes e depois do atual, para
+	// tolerar diferenças de relógio.
+	totpSkew = 1
+	// recoveryCodeCount é o número de códigos de recuperação gerados.
+	recoveryCodeCount = 10
+)
+
+var (
+	// ErrTotpAlreadyEnabled indica que o usuário já possui o segundo fator
+	// ativo.
+	ErrTotpAlreadyEnabled = errors.New("segundo fator já cadastrado")
+	// ErrTotpNotEnabled indica que o usuário não possui o segundo fator ativo
+	// ou um cadastro pendente.
+	ErrTotpNotEnabled = errors.New("segundo fator não cadastrado")
+	// ErrInvalidTotpCode indica um código TOTP ou de recuperação inválido.
+	ErrInvalidTotpCode = errors.New("código de verificação inválido")
+)
+
+// base32NoPadding é a codificação dos segredos e dos códigos de recuperação.
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// totpCode calcula o código HOTP (RFC 4226) de um segredo para um contador,
+// que no TOTP é o intervalo de tempo.
+func totpCode(secret []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+	mac := hmac.New(sha1.New, secret)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// Truncamento dinâmico
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1_000_000)
+}
+
+// matchTotp procura, na janela de tolerância, o intervalo de tempo em que o
+// código é válido, ignorando intervalos já utilizados.
+//
+// Retorno:
+//   - int64: o intervalo correspondente ao código.
+//   - bool: verdadeiro caso o código seja válido.
+func matchTotp(secret string, code string, lastStep int64) (int64, bool) {
+	key, err := base32NoPadding.DecodeString(secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	now := time.Now().Unix() / totpPeriod
+	for step := now - totpSkew; step <= now+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+		if hmac.Equal([]byte(totpCode(key, step)), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// TotpURI monta a URI de provisionamento (otpauth://), exibida como QR code
+// para o cadastro nos aplicativos autenticadores.
+//
+// Parâmetros:
+//   - issuer: emissor exibido no aplicativo.
+//   - username: nome do usuário da conta.
+//   - secret: segredo compartilhado, em base32.
+//
+// Retorno:
+//   - string: a URI de provisionamento.
+func TotpURI(issuer, username, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + username,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+// normalizeRecoveryCode remove separadores e espaços de um código de
+// recuperação.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+// queryTotp obtém o segredo, o estado do cadastro e o último intervalo
+// aceito do segundo fator de um usuário.
+func queryTotp(ctx *context.Context, userId uuid.UUID) (secret string, enabled bool, lastStep int64, err error) {
+	schema := &ctx.Config.Database.Schema
+	tc := &schema.TotpTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s,%s,%s FROM %s.%s WHERE %s = :user_id`,
+		tc.Secret,
+		tc.Enabled,
+		tc.LastStep,
+		schema.Name,
+		schema.TotpTable.Name,
+		tc.UserId,
+	)
+
+	var enabledInt int
+	row := ctx.DB.QueryRow(query, sql.Named("user_id", userId.String()))
+	err = row.Scan(&secret, &enabledInt, &lastStep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", false, 0, ErrTotpNotEnabled
+	} else if err != nil {
+		ctx.Logger.Error("Erro ao consultar segundo fator.", zap.Error(err))
+		return "", false, 0, fmt.Errorf("não foi possível consultar segundo fator")
+	}
+	return secret, enabledInt != 0, lastStep, nil
+}
+
+// IsTotpEnabled verifica se o usuário possui o segundo fator ativo.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//
+// Retorno:
+//   - bool: verdadeiro caso o cadastro do segundo fator esteja confirmado.
+//   - error: erro caso não seja possível consultar o segundo fator.
+func IsTotpEnabled(ctx *context.Context, userId uuid.UUID) (bool, error) {
+	_, enabled, _, err := queryTotp(ctx, userId)
+	if errors.Is(err, ErrTotpNotEnabled) {
+		return false, nil
+	}
+	return enabled, err
+}
+
+// IsTotpRequired verifica se a configuração obriga o usuário a usar o
+// segundo fator, por possuir algum dos papéis de Totp.EnforceRoles.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração e o Id do
+//     administrador.
+//   - userId: identificador do usuário.
+//   - roles: nomes dos papéis do usuário.
+//
+// Retorno:
+//   - bool: verdadeiro caso o segundo fator seja obrigatório.
+func IsTotpRequired(ctx *context.Context, userId uuid.UUID, roles []string) bool {
+	enforce := ctx.Config.Totp.EnforceRoles
+	if userId == ctx.AdminId && slices.Contains(enforce, RoleAdmin) {
+		return true
+	}
+	for _, r := range roles {
+		if slices.Contains(enforce, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// BeginTotpEnrollment gera um novo segredo TOTP pendente para o usuário,
+// substituindo um cadastro pendente anterior. O segundo fator só é ativado
+// após a confirmação com um código válido (ConfirmTotpEnrollment).
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//   - username: nome do usuário, exibido no aplicativo autenticador.
+//
+// Retorno:
+//   - TotpEnrollmentData: o segredo e a URI de provisionamento.
+//   - error: ErrTotpAlreadyEnabled ou erro caso não seja possível gerar o
+//     segredo.
+func BeginTotpEnrollment(ctx *context.Context, userId uuid.UUID, username string) (TotpEnrollmentData, error) {
+	var res TotpEnrollmentData
+
+	// Verificar cadastro existente
+	if enabled, err := IsTotpEnabled(ctx, userId); err != nil {
+		return res, err
+	} else if enabled {
+		return res, ErrTotpAlreadyEnabled
+	}
+
+	// Geração do segredo (160 bits, como recomendado pela RFC 4226)
+	key := make([]byte, 20)
+	if _, err := rand.Read(key); err != nil {
+		ctx.Logger.Error("Erro ao gerar segredo TOTP.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível gerar segredo")
+	}
+	res.Secret = base32NoPadding.EncodeToString(key)
+	res.URI = TotpURI(ctx.Config.Totp.Issuer, username, res.Secret)
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Substituição do cadastro pendente
+	schema := &ctx.Config.Database.Schema
+	tc := &schema.TotpTable.Columns
+	del := fmt.Sprintf(
+		`DELETE FROM %s.%s WHERE %s = :user_id`,
+		schema.Name,
+		schema.TotpTable.Name,
+		tc.UserId,
+	)
+	if _, err = tx.Exec(del, sql.Named("user_id", userId.String())); err != nil {
+		ctx.Logger.Error("Erro ao remover segundo fator pendente.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível cadastrar segundo fator")
+	}
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s)
+		VALUES (:user_id, :secret, 0, 0, :updated_at)`,
+		schema.Name,
+		schema.TotpTable.Name,
+		tc.UserId,
+		tc.Secret,
+		tc.Enabled,
+		tc.LastStep,
+		tc.UpdatedAt,
+	)
+	_, err = tx.Exec(
+		insert,
+		sql.Named("user_id", userId.String()),
+		sql.Named("secret", res.Secret),
+		sql.Named("updated_at", time.Now().Unix()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao cadastrar segundo fator.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível cadastrar segundo fator")
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return res, fmt.Errorf("não foi possível confirmar transação")
+	}
+	return res, nil
+}
+
+// ConfirmTotpEnrollment ativa o segundo fator pendente do usuário mediante
+// um código válido e gera os códigos de recuperação, substituindo os
+// anteriores.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//   - code: código gerado pelo aplicativo autenticador.
+//
+// Retorno:
+//   - []string: os códigos de recuperação, exibidos uma única vez.
+//   - error: ErrTotpNotEnabled, ErrTotpAlreadyEnabled, ErrInvalidTotpCode ou
+//     erro caso não seja possível ativar o segundo fator.
+func ConfirmTotpEnrollment(ctx *context.Context, userId uuid.UUID, code string) ([]string, error) {
+	// Verificar código do cadastro pendente
+	secret, enabled, lastStep, err := queryTotp(ctx, userId)
+	if err != nil {
+		return nil, err
+	} else if enabled {
+		return nil, ErrTotpAlreadyEnabled
+	}
+	step, ok := matchTotp(secret, code, lastStep)
+	if !ok {
+		return nil, ErrInvalidTotpCode
+	}
+
+	// Geração dos códigos de recuperação
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err = rand.Read(b); err != nil {
+			ctx.Logger.Error("Erro ao gerar código de recuperação.", zap.Error(err))
+			return nil, fmt.Errorf("não foi possível gerar códigos de recuperação")
+		}
+		c := base32NoPadding.EncodeToString(b)[:10]
+		codes[i] = c[:5] + "-" + c[5:]
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return nil, fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Ativação
+	schema := &ctx.Config.Database.Schema
+	tc := &schema.TotpTable.Columns
+	update := fmt.Sprintf(
+		`UPDATE %s.%s
+		SET %s = 1, %s = :last_step, %s = :updated_at
+		WHERE %s = :user_id`,
+		schema.Name,
+		schema.TotpTable.Name,
+		tc.Enabled,
+		tc.LastStep,
+		tc.UpdatedAt,
+		tc.UserId,
+	)
+	_, err = tx.Exec(
+		update,
+		sql.Named("last_step", step),
+		sql.Named("updated_at", time.Now().Unix()),
+		sql.Named("user_id", userId.String()),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao ativar segundo fator.", zap.Error(err))
+		return nil, fmt.Errorf("não foi possível ativar segundo fator")
+	}
+
+	// Substituição dos códigos de recuperação
+	rc := &schema.RecoveryCodeTable.Columns
+	del := fmt.Sprintf(
+		`DELETE FROM %s.%s WHERE %s = :user_id`,
+		schema.Name,
+		schema.RecoveryCodeTable.Name,
+		rc.UserId,
+	)
+	if _, err = tx.Exec(del, sql.Named("user_id", userId.String())); err != nil {
+		ctx.Logger.Error("Erro ao remover códigos de recuperação.", zap.Error(err))
+		return nil, fmt.Errorf("não foi possível gerar códigos de recuperação")
+	}
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s (%s, %s, %s) VALUES (:code_id, :user_id, :code_hash)`,
+		schema.Name,
+		schema.RecoveryCodeTable.Name,
+		rc.CodeId,
+		rc.UserId,
+		rc.CodeHash,
+	)
+	for _, c := range codes {
+		_, err = tx.Exec(
+			insert,
+			sql.Named("code_id", uuid.NewString()),
+			sql.Named("user_id", userId.String()),
+			sql.Named("code_hash", hashToken(normalizeRecoveryCode(c))),
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao inserir código de recuperação.", zap.Error(err))
+			return nil, fmt.Errorf("não foi possível gerar códigos de recuperação")
+		}
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return nil, fmt.Errorf("não foi possível confirmar transação")
+	}
+	return codes, nil
+}
+
+// VerifyTotp verifica o segundo fator de um usuário, aceitando um código do
+// aplicativo autenticador ou um código de recuperação. Ambos são de uso
+// único.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//   - code: código TOTP ou de recuperação.
+//
+// Retorno:
+//   - error: ErrTotpNotEnabled, ErrInvalidTotpCode ou erro caso não seja
+//     possível verificar o código.
+func VerifyTotp(ctx *context.Context, userId uuid.UUID, code string) error {
+	secret, enabled, lastStep, err := queryTotp(ctx, userId)
+	if err != nil {
+		return err
+	} else if !enabled {
+		return ErrTotpNotEnabled
+	}
+	schema := &ctx.Config.Database.Schema
+
+	// Código do aplicativo autenticador, registrando o intervalo utilizado
+	code = strings.TrimSpace(code)
+	if step, ok := matchTotp(secret, code, lastStep); ok {
+		tc := &schema.TotpTable.Columns
+		update := fmt.Sprintf(
+			`UPDATE %s.%s SET %s = :last_step WHERE %s = :user_id AND %s < :last_step`,
+			schema.Name,
+			schema.TotpTable.Name,
+			tc.LastStep,
+			tc.UserId,
+			tc.LastStep,
+		)
+		res, err := ctx.DB.Exec(update, sql.Named("last_step", step), sql.Named("user_id", userId.String()))
+		if err != nil {
+			ctx.Logger.Error("Erro ao registrar código TOTP.", zap.Error(err))
+			return fmt.Errorf("não foi possível verificar código")
+		} else if n, _ := res.RowsAffected(); n == 0 {
+			// Código utilizado por uma requisição concorrente
+			return ErrInvalidTotpCode
+		}
+		return nil
+	}
+
+	// Código de recuperação
+	rc := &schema.RecoveryCodeTable.Columns
+	update := fmt.Sprintf(
+		`UPDATE %s.%s
+		SET %s = :used_at
+		WHERE %s = :user_id AND %s = :code_hash AND %s IS NULL`,
+		schema.Name,
+		schema.RecoveryCodeTable.Name,
+		rc.UsedAt,
+		rc.UserId,
+		rc.CodeHash,
+		rc.UsedAt,
+	)
+	res, err := ctx.DB.Exec(
+		update,
+		sql.Named("used_at", time.Now().Unix()),
+		sql.Named("user_id", userId.String()),
+		sql.Named("code_hash", hashToken(normalizeRecoveryCode(code))),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consumir código de recuperação.", zap.Error(err))
+		return fmt.Errorf("não foi possível verificar código")
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		return ErrInvalidTotpCode
+	}
+	ctx.Logger.Warn("Código de recuperação utilizado.", zap.String("user_id", userId.String()))
+	return nil
+}
+
+// DisableTotp remove o segundo fator e os códigos de recuperação de um
+// usuário.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - userId: identificador do usuário.
+//
+// Retorno:
+//   - error: erro caso não seja possível remover o segundo fator.
+func DisableTotp(ctx *context.Context, userId uuid.UUID) error {
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Remoção
+	schema := &ctx.Config.Database.Schema
+	tables := map[string]string{
+		schema.TotpTable.Name:         schema.TotpTable.Columns.UserId,
+		schema.RecoveryCodeTable.Name: schema.RecoveryCodeTable.Columns.UserId,
+	}
+	for table, column := range tables {
+		del := fmt.Sprintf(`DELETE FROM %s.%s WHERE %s = :user_id`, schema.Name, table, column)
+		if _, err = tx.Exec(del, sql.Named("user_id", userId.String())); err != nil {
+			ctx.Logger.Error("Erro ao remover segundo fator.", zap.Error(err))
+			return fmt.Errorf("não foi possível remover segundo fator")
+		}
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return fmt.Errorf("não foi possível confirmar transação")
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package app
 
 import (
 	"github.com/google/uuid"
+	"time"
 )
 
 // LoginParams define os parâmetros para o login de um usuário.
@@ -18,6 +19,8 @@ type LoginData struct {
 	UserId uuid.UUID
 	// Name especificar o nome de apresentação do usuário
 	Name string
+	// MustChangePassword indica que o usuário deve trocar a senha.
+	MustChangePassword bool
 }
 
 // UserData define os parâmetros para a criação de um usuário.
@@ -28,6 +31,27 @@ type UserData struct {
 	Name string
 	// Password especifica a senha do usuário.
 	Password string
+	// MustChangePassword define se o usuário deve trocar a senha no próximo
+	// acesso. Nulo mantém o valor atual (ou falso, na criação).
+	MustChangePassword *bool
+	// Email especifica o e-mail de contato do usuário. Nulo mantém o valor
+	// atual e vazio o remove.
+	Email *string
+	// Phone especifica o telefone de contato do usuário. Nulo mantém o valor
+	// atual e vazio o remove.
+	Phone *string
+	// Active define se a conta está ativa. Nulo mantém o valor atual (ou
+	// verdadeiro, na criação).
+	Active *bool
+	// ValidFrom especifica o início da validade da conta, em tempo Unix. Nulo
+	// mantém o valor atual e zero remove o início.
+	ValidFrom *int64
+	// ValidUntil especifica o fim da validade da conta, em tempo Unix. Nulo
+	// mantém o valor atual e zero remove o fim.
+	ValidUntil *int64
+	// EmailNotifications define se o usuário recebe as notificações por
+	// e-mail. Nulo mantém o valor atual (ou verdadeiro, na criação).
+	EmailNotifications *bool
 }
 
 // CategData define os parâmetros para a criação de uma categoria.
@@ -51,3 +75,240 @@ type FileData struct {
 	// Content contém o conteúdo do arquivo.
 	Content *[]byte
 }
+
+// DownloadData define os parâmetros para o registro de um download.
+type DownloadData struct {
+	// FileId especifica o identificador do arquivo baixado.
+	FileId uuid.UUID
+	// UserId especifica o identificador do usuário que realizou o download.
+	UserId uuid.UUID
+	// ClientIp especifica o endereço IP de origem do download.
+	ClientIp string
+	// Bytes especifica a quantidade de bytes transferidos.
+	Bytes int64
+}
+
+// ReportPeriod define o intervalo de tempo considerado em um relatório.
+type ReportPeriod struct {
+	// From especifica o início do intervalo, em tempo Unix (segundos). Zero
+	// indica sem limite inferior.
+	From int64
+	// To especifica o fim do intervalo, em tempo Unix (segundos). Zero indica
+	// sem limite superior.
+	To int64
+}
+
+// AuditData define os parâmetros para o registro de uma ação na auditoria.
+type AuditData struct {
+	// ActorId especifica o identificador do usuário autor da ação.
+	ActorId uuid.UUID
+	// Action especifica a ação realizada.
+	Action string
+	// EntityType especifica o tipo da entidade afetada.
+	EntityType string
+	// EntityId especifica o identificador da entidade afetada.
+	EntityId uuid.UUID
+	// Diff especifica as diferenças (JSON) dos metadados da entidade.
+	Diff string
+	// ClientIp especifica o endereço IP de origem da ação.
+	ClientIp string
+}
+
+// AuditFilter define os filtros para a consulta da auditoria. Campos vazios
+// não são considerados.
+type AuditFilter struct {
+	// ActorId filtra pelo autor da ação.
+	ActorId uuid.UUID
+	// Action filtra pela ação realizada.
+	Action string
+	// EntityType filtra pelo tipo da entidade afetada.
+	EntityType string
+	// EntityId filtra pela entidade afetada.
+	EntityId uuid.UUID
+	// Period filtra pelo momento da ação.
+	Period ReportPeriod
+	// Limit especifica a quantidade máxima de registros retornados.
+	Limit int
+	// Offset especifica a quantidade de registros ignorados (paginação).
+	Offset int
+}
+
+// BatchOperation define uma operação de um lote executado em uma única
+// transação.
+type BatchOperation struct {
+	// Op especifica a operação (BatchMove, BatchRename ou BatchDelete).
+	Op string
+	// Entity especifica o tipo da entidade (BatchFile ou BatchCategory).
+	Entity string
+	// Id especifica o identificador da entidade.
+	Id uuid.UUID
+	// TargetId especifica o destino de uma movimentação: a categoria, para
+	// arquivos, ou o usuário, para categorias.
+	TargetId uuid.UUID
+	// Name especifica o novo nome de uma renomeação.
+	Name string
+	// Version especifica a versão (UpdatedAt) esperada da entidade. Zero
+	// indica operação incondicional.
+	Version int64
+}
+
+// BatchResult define o resultado de uma operação de um lote.
+type BatchResult struct {
+	// Status especifica a situação da operação.
+	Status string
+	// Err especifica o erro da operação que impediu a efetivação do lote.
+	Err error
+}
+
+// RoleData define os parâmetros para a criação ou alteração de um papel.
+type RoleData struct {
+	// Name especifica o nome do papel.
+	Name string
+	// Permissions especifica as permissões concedidas pelo papel. Em
+	// alterações, nil mantém as permissões atuais.
+	Permissions []string
+}
+
+// InboxData define as regras de uma caixa de entrada.
+type InboxData struct {
+	// MaxFileSize especifica o tamanho máximo, em bytes, de cada arquivo
+	// enviado. Zero indica sem limite.
+	MaxFileSize int64
+	// AllowedTypes especifica as extensões e tipos MIME aceitos. Vazio
+	// aceita qualquer tipo.
+	AllowedTypes []string
+}
+
+// NotificationData define os parâmetros para a criação de uma notificação.
+type NotificationData struct {
+	// Kind especifica o tipo da notificação.
+	Kind string
+	// UserId especifica o identificador do usuário que originou a
+	// notificação.
+	UserId uuid.UUID
+	// EntityId especifica o identificador da entidade relacionada.
+	EntityId uuid.UUID
+	// Message especifica a mensagem da notificação.
+	Message string
+}
+
+// RefreshTokenData define os dados de um refresh token gerado.
+type RefreshTokenData struct {
+	// Token especifica o refresh token, entregue apenas ao cliente.
+	Token string
+	// UserId especifica o identificador do usuário do token.
+	UserId uuid.UUID
+	// FamilyId especifica o identificador da família (sessão) do token.
+	FamilyId uuid.UUID
+	// ExpiresAt especifica o momento de expiração do token.
+	ExpiresAt time.Time
+}
+
+// SessionData define os parâmetros para a criação de uma sessão.
+type SessionData struct {
+	// UserId especifica o identificador do usuário.
+	UserId uuid.UUID
+	// UserAgent especifica o agente (navegador/dispositivo) do login.
+	UserAgent string
+	// ClientIp especifica o endereço IP de origem do login.
+	ClientIp string
+}
+
+// PasswordResetData define os dados de um token de redefinição de senha
+// gerado.
+type PasswordResetData struct {
+	// Token especifica o token, entregue apenas ao administrador.
+	Token string
+	// UserId especifica o identificador do usuário do token.
+	UserId uuid.UUID
+	// ExpiresAt especifica o momento de expiração do token.
+	ExpiresAt time.Time
+}
+
+// TotpEnrollmentData define os dados de um cadastro de segundo fator (TOTP)
+// pendente de confirmação.
+type TotpEnrollmentData struct {
+	// Secret especifica o segredo compartilhado, em base32.
+	Secret string
+	// URI especifica a URI de provisionamento (otpauth://), exibida como QR
+	// code.
+	URI string
+}
+
+// IdentityData define os dados de uma identidade externa (OIDC) autenticada.
+type IdentityData struct {
+	// Issuer especifica o emissor do provedor de identidade.
+	Issuer string
+	// Subject especifica o identificador do usuário no provedor.
+	Subject string
+	// Email especifica o e-mail informado pelo provedor.
+	Email string
+	// EmailVerified indica que o provedor verificou o e-mail.
+	EmailVerified bool
+	// Name especifica o nome de apresentação informado pelo provedor.
+	Name string
+	// PreferredUsername especifica o nome de usuário sugerido pelo provedor.
+	PreferredUsername string
+}
+
+// ApiKeyData define os parâmetros para a criação de uma chave de API.
+type ApiKeyData struct {
+	// Name especifica o nome descritivo da chave.
+	Name string
+	// Permissions especifica as permissões concedidas pela chave.
+	Permissions []string
+	// Users especifica os usuários acessíveis pela chave. Vazio permite
+	// todos os usuários.
+	Users []uuid.UUID
+	// Categories especifica as categorias acessíveis pela chave. Vazio
+	// permite todas as categorias.
+	Categories []uuid.UUID
+	// ExpiresAt especifica o momento de expiração da chave. Zero indica
+	// chave sem expiração.
+	ExpiresAt time.Time
+}
+
+// ApiKeyCreatedData define os dados de uma chave de API criada.
+type ApiKeyCreatedData struct {
+	// KeyId especifica o identificador da chave.
+	KeyId uuid.UUID
+	// Key especifica a chave, entregue apenas na criação.
+	Key string
+	// Prefix especifica o prefixo da chave, para identificá-la.
+	Prefix string
+}
+
+// WebhookData define os parâmetros para a criação ou alteração de um
+// webhook.
+type WebhookData struct {
+	// Url especifica o endereço (http ou https) que recebe os eventos. Vazio
+	// mantém o valor atual, na alteração.
+	Url string
+	// Events especifica os eventos assinados ("*" assina todos). Nulo mantém
+	// o valor atual, na alteração.
+	Events []string
+	// Active define se o webhook recebe os eventos. Nulo mantém o valor
+	// atual (ou verdadeiro, na criação).
+	Active *bool
+}
+
+// WebhookCreatedData define os dados de um webhook criado.
+type WebhookCreatedData struct {
+	// WebhookId especifica o identificador do webhook.
+	WebhookId uuid.UUID
+	// Secret especifica o segredo da assinatura, entregue apenas na criação.
+	Secret string
+}
+
+// WebhookEvent define um evento publicado aos webhooks.
+type WebhookEvent struct {
+	// Name especifica o nome do evento (ex.: EventFileUploaded).
+	Name string
+	// ActorId especifica o usuário que realizou a ação (uuid.Nil indica o
+	// sistema).
+	ActorId uuid.UUID
+	// EntityId especifica a entidade afetada.
+	EntityId uuid.UUID
+	// Data especifica os metadados da entidade, sem senhas e conteúdos.
+	Data map[string]any
+}
@@ -1,27 +1,10 @@
 package app
 
-import (
-	"agros_arquivos_patrocinadoras/pkg/app/context"
-	"fmt"
-	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
-)
-
-// HashPassword gera um hash seguro para a senha fornecida utilizando o bcrypt.
-//
-// Parâmetros:
-//   - ctx: contexto da aplicação, contendo o logger para registrar possíveis
-//     erros.
-//   - password: string contendo a senha a ser hasheada.
-//
-// Retornos:
-//   - string: hash gerado a partir da senha fornecida.
-//   - error: erro, caso ocorra falha ao gerar o hash.
-func HashPassword(ctx *context.Context, password string) (string, error) {
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		ctx.Logger.Error("Erro ao gerar hash da senha.", zap.Error(err))
-		return "", fmt.Errorf("error ao criptografar senha")
+// boolToInt converte um booleano para o valor (0 ou 1) armazenado nas
+// colunas indicadoras do banco.
+func boolToInt(b bool) int {
+	if b {
+		return 1
 	}
-	return string(hash), nil
+	return 0
 }
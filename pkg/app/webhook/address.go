@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenAddress indica um destino na rede interna (ex.: loopback,
+// rede privada ou o serviço de metadados 169.254.169.254), não permitido
+// aos webhooks fora da lista de hosts permitidos.
+var ErrForbiddenAddress = errors.New("endereço do webhook na rede interna")
+
+// sharedAddressSpace é a faixa 100.64.0.0/10 (RFC 6598), usada pelas redes
+// das operadoras e de alguns provedores de nuvem.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// Internal indica se um endereço IP é de uso interno: loopback, redes
+// privadas, link-local, não especificado ou multicast.
+//
+// Parâmetros:
+//   - ip: endereço a ser verificado.
+//
+// Retorno:
+//   - bool: true caso o endereço seja de uso interno.
+func Internal(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified() ||
+		sharedAddressSpace.Contains(ip)
+}
+
+// allowedHost indica se um host consta na lista de hosts permitidos, sem
+// diferenciar maiúsculas de minúsculas.
+func allowedHost(host string, allowed []string) bool {
+	return slices.ContainsFunc(allowed, func(h string) bool {
+		return strings.EqualFold(h, host)
+	})
+}
+
+// CheckHost resolve o host de um webhook e rejeita os destinos na rede
+// interna, exceto os hosts permitidos.
+//
+// Parâmetros:
+//   - host: nome ou endereço IP do destino, sem a porta.
+//   - allowed: hosts permitidos mesmo na rede interna.
+//
+// Retorno:
+//   - error: ErrForbiddenAddress, erro da resolução do nome ou nil caso o
+//     destino seja permitido.
+func CheckHost(host string, allowed []string) error {
+	if allowedHost(host, allowed) {
+		return nil
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		if Internal(ip) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// NewClient cria o cliente HTTP das entregas. O endereço efetivamente
+// conectado é verificado a cada conexão, de modo que um nome cuja resolução
+// mude após o cadastro (DNS rebinding) não alcance a rede interna. Os
+// proxies do ambiente não são usados, pois esconderiam o destino.
+//
+// Parâmetros:
+//   - timeout: tempo limite de cada tentativa.
+//   - allowed: hosts permitidos mesmo na rede interna.
+//
+// Retorno:
+//   - *http.Client: o cliente a ser usado em Deliver.
+func NewClient(timeout time.Duration, allowed []string) *http.Client {
+	direct := &net.Dialer{Timeout: timeout}
+	guarded := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || Internal(ip) {
+				return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+			}
+			return nil
+		},
+	}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return nil, err
+			}
+			if allowedHost(host, allowed) {
+				return direct.DialContext(ctx, network, address)
+			}
+			return guarded.DialContext(ctx, network, address)
+		},
+		TLSHandshakeTimeout: timeout,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// Os redirecionamentos não são seguidos, pois levariam a entrega a
+		// outro destino
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,69 @@
+package webhook
+
+import (
+	"sync/atomic"
+)
+
+// Queue executa as entregas em segundo plano com um número fixo de
+// workers, de modo que uma rajada de eventos ou destinos lentos não criem
+// goroutines sem limite. As tarefas excedentes à capacidade da fila são
+// descartadas.
+//
+// Os métodos de uma *Queue nula descartam as tarefas, o que permite usar um
+// contexto sem entregas aos webhooks (ex.: testes).
+type Queue struct {
+	jobs    chan func()
+	dropped atomic.Int64
+}
+
+// NewQueue cria uma fila de entregas e inicia os seus workers.
+//
+// Parâmetros:
+//   - workers: número de entregas simultâneas.
+//   - size: capacidade da fila.
+//
+// Retorno:
+//   - *Queue: a fila criada.
+func NewQueue(workers, size int) *Queue {
+	q := &Queue{jobs: make(chan func(), max(size, 0))}
+	for range max(workers, 1) {
+		go q.work()
+	}
+	return q
+}
+
+// work executa as tarefas da fila.
+func (q *Queue) work() {
+	for job := range q.jobs {
+		job()
+	}
+}
+
+// Enqueue adiciona uma tarefa à fila, sem bloquear.
+//
+// Parâmetros:
+//   - job: tarefa a ser executada por um worker.
+//
+// Retorno:
+//   - bool: false caso a fila esteja cheia ou seja nula e a tarefa tenha
+//     sido descartada.
+func (q *Queue) Enqueue(job func()) bool {
+	if q == nil {
+		return false
+	}
+	select {
+	case q.jobs <- job:
+		return true
+	default:
+		q.dropped.Add(1)
+		return false
+	}
+}
+
+// Dropped retorna o número de tarefas descartadas com a fila cheia.
+func (q *Queue) Dropped() int64 {
+	if q == nil {
+		return 0
+	}
+	return q.dropped.Load()
+}
@@ -0,0 +1,170 @@
+// Package webhook entrega os eventos da aplicação (ex.: "file.uploaded") aos
+// endereços cadastrados pelos administradores. Cada entrega é um POST JSON
+// assinado com HMAC-SHA256 e repetida, com intervalo crescente, enquanto o
+// destino estiver indisponível.
+//
+// O pacote não acessa o banco de dados; o registro das entregas é feito
+// pela camada da aplicação.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Cabeçalhos das entregas.
+const (
+	// HeaderEvent contém o nome do evento.
+	HeaderEvent = "X-Agros-Event"
+	// HeaderDelivery contém o identificador da entrega, repetido nas novas
+	// tentativas, o que permite ao destino descartar duplicatas.
+	HeaderDelivery = "X-Agros-Delivery"
+	// HeaderTimestamp contém o momento da tentativa, em tempo Unix.
+	HeaderTimestamp = "X-Agros-Timestamp"
+	// HeaderSignature contém a assinatura "sha256=<hex>" de
+	// "<timestamp>.<corpo>".
+	HeaderSignature = "X-Agros-Signature"
+)
+
+// signaturePrefix é o prefixo do algoritmo da assinatura.
+const signaturePrefix = "sha256="
+
+// Sign assina o corpo de uma entrega com o segredo do webhook. O momento é
+// incluído na assinatura para que o destino possa rejeitar repetições
+// antigas.
+//
+// Parâmetros:
+//   - secret: segredo do webhook.
+//   - timestamp: momento da tentativa, em tempo Unix.
+//   - body: corpo da entrega.
+//
+// Retorno:
+//   - string: a assinatura, no formato "sha256=<hex>".
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify verifica a assinatura de uma entrega, em tempo constante.
+//
+// Parâmetros:
+//   - secret: segredo do webhook.
+//   - timestamp: momento recebido em HeaderTimestamp.
+//   - body: corpo recebido.
+//   - signature: assinatura recebida em HeaderSignature.
+//
+// Retorno:
+//   - bool: true caso a assinatura seja válida.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Policy define as tentativas de uma entrega.
+type Policy struct {
+	// MaxAttempts é o número máximo de tentativas.
+	MaxAttempts int
+	// BaseDelay é o intervalo antes da segunda tentativa, dobrado a cada
+	// nova tentativa.
+	BaseDelay time.Duration
+	// Timeout é o tempo limite de cada tentativa.
+	Timeout time.Duration
+}
+
+// Request é uma entrega a um webhook.
+type Request struct {
+	// Url é o endereço do webhook.
+	Url string
+	// Secret é o segredo da assinatura.
+	Secret string
+	// Event é o nome do evento.
+	Event string
+	// DeliveryId é o identificador da entrega.
+	DeliveryId string
+	// Body é o corpo JSON da entrega.
+	Body []byte
+}
+
+// Result é o resultado de uma entrega.
+type Result struct {
+	// StatusCode é o último código HTTP recebido. Zero indica falha de
+	// conexão.
+	StatusCode int
+	// Attempts é o número de tentativas realizadas.
+	Attempts int
+	// Err é o último erro, ou nil caso a entrega tenha sido aceita (2xx).
+	Err error
+}
+
+// retryable indica se uma resposta justifica uma nova tentativa: falhas de
+// conexão, limite de requisições (429) e erros do servidor (5xx). Os demais
+// códigos indicam uma recusa definitiva do destino.
+func retryable(status int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+// Deliver entrega um evento a um webhook, repetindo as tentativas conforme
+// a política. A chamada bloqueia até o fim das tentativas.
+//
+// Parâmetros:
+//   - client: cliente HTTP (nil usa um cliente com o tempo limite da
+//     política).
+//   - p: política das tentativas.
+//   - r: entrega a ser realizada.
+//
+// Retorno:
+//   - Result: o resultado da última tentativa.
+func Deliver(client *http.Client, p Policy, r Request) Result {
+	if client == nil {
+		client = &http.Client{Timeout: p.Timeout}
+	}
+	var res Result
+	delay := p.BaseDelay
+	for res.Attempts < max(p.MaxAttempts, 1) {
+		if res.Attempts > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		res.Attempts++
+		res.StatusCode, res.Err = attempt(client, r)
+		if res.Err == nil || !retryable(res.StatusCode) || errors.Is(res.Err, ErrForbiddenAddress) {
+			break
+		}
+	}
+	return res
+}
+
+// attempt realiza uma tentativa de entrega.
+func attempt(client *http.Client, r Request) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, r.Url, bytes.NewReader(r.Body))
+	if err != nil {
+		return 0, err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Agros-Arquivos-Webhook")
+	req.Header.Set(HeaderEvent, r.Event)
+	req.Header.Set(HeaderDelivery, r.DeliveryId)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(r.Secret, ts, r.Body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("resposta HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
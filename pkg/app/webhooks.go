@@ -0,0 +1,685 @@
+package app
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/webhook"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Eventos publicados aos webhooks.
+const (
+	EventUserCreated     = "user.created"
+	EventUserUpdated     = "user.updated"
+	EventUserDeleted     = "user.deleted"
+	EventCategoryCreated = "category.created"
+	EventCategoryUpdated = "category.updated"
+	EventCategoryDeleted = "category.deleted"
+	EventFileUploaded    = "file.uploaded"
+	EventFileUpdated     = "file.updated"
+	EventFileDeleted     = "file.deleted"
+	EventFileDownloaded  = "file.downloaded"
+	EventGrantCreated    = "grant.created"
+	EventGrantDeleted    = "grant.deleted"
+	EventRoleCreated     = "role.created"
+	EventRoleUpdated     = "role.updated"
+	EventRoleDeleted     = "role.deleted"
+	EventApiKeyCreated   = "api_key.created"
+	EventApiKeyDeleted   = "api_key.deleted"
+	// EventWebhookTest é o evento de teste, enviado apenas pela rota de
+	// teste ao webhook informado.
+	EventWebhookTest = "webhook.test"
+)
+
+// WebhookAllEvents assina todos os eventos.
+const WebhookAllEvents = "*"
+
+// WebhookEvents lista os eventos que podem ser assinados.
+var WebhookEvents = []string{
+	EventUserCreated,
+	EventUserUpdated,
+	EventUserDeleted,
+	EventCategoryCreated,
+	EventCategoryUpdated,
+	EventCategoryDeleted,
+	EventFileUploaded,
+	EventFileUpdated,
+	EventFileDeleted,
+	EventFileDownloaded,
+	EventGrantCreated,
+	EventGrantDeleted,
+	EventRoleCreated,
+	EventRoleUpdated,
+	EventRoleDeleted,
+	EventApiKeyCreated,
+	EventApiKeyDeleted,
+}
+
+var (
+	// ErrInvalidWebhookUrl indica um endereço de webhook que não é uma URL
+	// http ou https absoluta, cujo host não é resolvido ou que aponta para
+	// a rede interna (webhook.ErrForbiddenAddress).
+	ErrInvalidWebhookUrl = errors.New("endereço do webhook inválido")
+	// ErrInvalidWebhookEvent indica um evento desconhecido ou uma lista de
+	// eventos vazia.
+	ErrInvalidWebhookEvent = errors.New("evento de webhook inválido")
+)
+
+// validateWebhookUrl valida o endereço de um webhook, recusando os destinos
+// na rede interna fora de Webhooks.AllowedHosts.
+func validateWebhookUrl(ctx *context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidWebhookUrl
+	}
+	if err = webhook.CheckHost(u.Hostname(), ctx.Config.Webhooks.AllowedHosts); err != nil {
+		ctx.Logger.Warn("Endereço de webhook recusado.", zap.String("host", u.Hostname()), zap.Error(err))
+		return ErrInvalidWebhookUrl
+	}
+	return nil
+}
+
+// validateWebhookEvents valida os eventos assinados por um webhook.
+func validateWebhookEvents(events []string) error {
+	if len(events) == 0 {
+		return ErrInvalidWebhookEvent
+	}
+	for _, e := range events {
+		if e != WebhookAllEvents && !slices.Contains(WebhookEvents, e) {
+			return ErrInvalidWebhookEvent
+		}
+	}
+	return nil
+}
+
+// subscribed indica se um webhook assina um evento.
+func subscribed(hook db.WebhookModel, event string) bool {
+	return slices.Contains(hook.Events, WebhookAllEvents) || slices.Contains(hook.Events, event)
+}
+
+// CreateWebhook cadastra um webhook, gerando o segredo da assinatura das
+// entregas. O segredo é retornado somente aqui.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - p: endereço, eventos e estado do webhook.
+//   - createdBy: identificador do administrador que criou o webhook.
+//
+// Retorno:
+//   - WebhookCreatedData: o identificador e o segredo do webhook.
+//   - error: ErrInvalidWebhookUrl, ErrInvalidWebhookEvent ou erro caso não
+//     seja possível criar o webhook.
+func CreateWebhook(ctx *context.Context, p WebhookData, createdBy uuid.UUID) (WebhookCreatedData, error) {
+	var res WebhookCreatedData
+	if err := validateWebhookUrl(ctx, p.Url); err != nil {
+		return res, err
+	}
+	if err := validateWebhookEvents(p.Events); err != nil {
+		return res, err
+	}
+
+	// Geração do UUID, do segredo e Timestamp
+	ts := time.Now().Unix()
+	webhookId, err := uuid.NewUUID()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar UUID.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar UUID")
+	}
+	secret, err := newToken()
+	if err != nil {
+		ctx.Logger.Error("Erro ao gerar segredo do webhook.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível gerar segredo do webhook")
+	}
+
+	// Criação
+	schema := &ctx.Config.Database.Schema
+	wc := &schema.WebhookTable.Columns
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s, %s, %s)
+		VALUES (:webhook_id, :url, :secret, :events, :active, :created_by, :created_at, :updated_at)`,
+		schema.Name,
+		schema.WebhookTable.Name,
+		wc.WebhookId,
+		wc.Url,
+		wc.Secret,
+		wc.Events,
+		wc.Active,
+		wc.CreatedBy,
+		wc.CreatedAt,
+		wc.UpdatedAt,
+	)
+	_, err = ctx.DB.Exec(
+		insert,
+		sql.Named("webhook_id", webhookId.String()),
+		sql.Named("url", p.Url),
+		sql.Named("secret", secret),
+		sql.Named("events", strings.Join(p.Events, ",")),
+		sql.Named("active", boolToInt(p.Active == nil || *p.Active)),
+		sql.Named("created_by", createdBy.String()),
+		sql.Named("created_at", ts),
+		sql.Named("updated_at", ts),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar webhook.", zap.Error(err))
+		return res, fmt.Errorf("não foi possível criar webhook")
+	}
+	return WebhookCreatedData{WebhookId: webhookId, Secret: secret}, nil
+}
+
+// queryWebhooks recupera os webhooks que atendem à condição informada.
+func queryWebhooks(ctx *context.Context, where string, args ...any) ([]db.WebhookModel, error) {
+	var hooks []db.WebhookModel
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	wc := &schema.WebhookTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s, %s, %s, %s, %s, %s, %s, %s
+		FROM %s.%s
+		%s
+		ORDER BY %s, %s`,
+		wc.WebhookId,
+		wc.Url,
+		wc.Secret,
+		wc.Events,
+		wc.Active,
+		wc.CreatedBy,
+		wc.CreatedAt,
+		wc.UpdatedAt,
+		schema.Name,
+		schema.WebhookTable.Name,
+		where,
+		wc.CreatedAt,
+		wc.WebhookId,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, args...)
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar webhooks.", zap.Error(err))
+		return hooks, fmt.Errorf("não foi possível obter os webhooks")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var h db.WebhookModel
+		var events string
+		var active int
+		err = rows.Scan(
+			&h.WebhookId,
+			&h.Url,
+			&h.Secret,
+			&events,
+			&active,
+			&h.CreatedBy,
+			&h.CreatedAt,
+			&h.UpdatedAt,
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao obter webhook.", zap.Error(err))
+			return hooks, fmt.Errorf("não foi possível obter todos os webhooks")
+		}
+		h.Events = strings.Split(events, ",")
+		h.Active = active != 0
+		hooks = append(hooks, h)
+	}
+	return hooks, nil
+}
+
+// QueryAllWebhooks recupera todos os webhooks.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//
+// Retorno:
+//   - []db.WebhookModel: os webhooks encontrados.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryAllWebhooks(ctx *context.Context) ([]db.WebhookModel, error) {
+	return queryWebhooks(ctx, "")
+}
+
+// QueryWebhookById recupera um webhook.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - webhookId: identificador do webhook.
+//
+// Retorno:
+//   - db.WebhookModel: o webhook encontrado.
+//   - error: ErrEntityNotFound ou erro caso a consulta falhe.
+func QueryWebhookById(ctx *context.Context, webhookId uuid.UUID) (db.WebhookModel, error) {
+	schema := &ctx.Config.Database.Schema
+	hooks, err := queryWebhooks(
+		ctx,
+		fmt.Sprintf("WHERE %s = :webhook_id", schema.WebhookTable.Columns.WebhookId),
+		sql.Named("webhook_id", webhookId.String()),
+	)
+	if err != nil {
+		return db.WebhookModel{}, err
+	} else if len(hooks) == 0 {
+		return db.WebhookModel{}, ErrEntityNotFound
+	}
+	return hooks[0], nil
+}
+
+// UpdateWebhook altera o endereço, os eventos ou o estado de um webhook. O
+// segredo não é alterado.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - webhookId: identificador do webhook.
+//   - version: versão esperada (UpdatedAt), ou zero para alteração
+//     incondicional.
+//   - p: dados a serem alterados.
+//
+// Retorno:
+//   - error: ErrInvalidWebhookUrl, ErrInvalidWebhookEvent,
+//     ErrEntityNotFound, ErrVersionConflict ou erro caso não seja possível
+//     alterar o webhook.
+func UpdateWebhook(ctx *context.Context, webhookId uuid.UUID, version int64, p WebhookData) error {
+	schema := &ctx.Config.Database.Schema
+	wc := &schema.WebhookTable.Columns
+
+	// Checagem dos parâmetros a serem atualizados
+	var args []any
+	var set []string
+	if p.Url != "" {
+		if err := validateWebhookUrl(ctx, p.Url); err != nil {
+			return err
+		}
+		args = append(args, sql.Named("url", p.Url))
+		set = append(set, wc.Url+" = :url")
+	}
+	if p.Events != nil {
+		if err := validateWebhookEvents(p.Events); err != nil {
+			return err
+		}
+		args = append(args, sql.Named("events", strings.Join(p.Events, ",")))
+		set = append(set, wc.Events+" = :events")
+	}
+	if p.Active != nil {
+		args = append(args, sql.Named("active", boolToInt(*p.Active)))
+		set = append(set, wc.Active+" = :active")
+	}
+	args = append(args, sql.Named("updated_at", nextVersion(version)))
+	set = append(set, wc.UpdatedAt+" = :updated_at")
+
+	// Update query
+	versionCond, versionArgs := versionClause(wc.UpdatedAt, version)
+	update := fmt.Sprintf(
+		`UPDATE %s.%s SET %s WHERE %s = :webhook_id%s`,
+		schema.Name,
+		schema.WebhookTable.Name,
+		strings.Join(set, ","),
+		wc.WebhookId,
+		versionCond,
+	)
+	args = append(args, sql.Named("webhook_id", webhookId.String()))
+	args = append(args, versionArgs...)
+
+	// Atualização
+	res, err := ctx.DB.Exec(update, args...)
+	if err != nil {
+		ctx.Logger.Error("Erro ao atualizar webhook.", zap.Error(err))
+		return fmt.Errorf("não foi possível atualizar webhook")
+	} else if n, _ := res.RowsAffected(); n == 0 {
+		if _, err = QueryWebhookById(ctx, webhookId); err != nil {
+			return err
+		}
+		return ErrVersionConflict
+	}
+	return nil
+}
+
+// DeleteWebhook exclui um webhook com o registro de suas entregas.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - webhookId: identificador do webhook.
+//
+// Retorno:
+//   - error: ErrEntityNotFound ou erro caso não seja possível excluir o
+//     webhook.
+func DeleteWebhook(ctx *context.Context, webhookId uuid.UUID) error {
+	if _, err := QueryWebhookById(ctx, webhookId); err != nil {
+		return err
+	}
+
+	// Iniciar uma transação
+	tx, err := ctx.DB.Begin()
+	if err != nil {
+		ctx.Logger.Error("Erro ao criar transação de banco.", zap.Error(err))
+		return fmt.Errorf("não foi possível criar transação")
+	}
+
+	// Agendar rollback em caso de erro
+	defer rollback(ctx, tx, &err)
+
+	// Delete queries, das entregas para o webhook
+	schema := &ctx.Config.Database.Schema
+	tables := []struct{ name, column string }{
+		{schema.WebhookDeliveryTable.Name, schema.WebhookDeliveryTable.Columns.WebhookId},
+		{schema.WebhookTable.Name, schema.WebhookTable.Columns.WebhookId},
+	}
+	for _, t := range tables {
+		del := fmt.Sprintf("DELETE FROM %s.%s WHERE %s = :webhook_id", schema.Name, t.name, t.column)
+		if _, err = tx.Exec(del, sql.Named("webhook_id", webhookId.String())); err != nil {
+			ctx.Logger.Error("Erro ao excluir webhook.", zap.Error(err))
+			return fmt.Errorf("não foi possível excluir webhook")
+		}
+	}
+
+	// Confirmar a transação
+	if err = tx.Commit(); err != nil {
+		ctx.Logger.Error("Erro ao efetivar transação (COMMIT).", zap.Error(err))
+		return fmt.Errorf("não foi possível confirmar transação")
+	}
+	return nil
+}
+
+// createWebhookDelivery registra a entrega de um evento a um webhook.
+func createWebhookDelivery(ctx *context.Context, d db.WebhookDeliveryModel) error {
+	schema := &ctx.Config.Database.Schema
+	dc := &schema.WebhookDeliveryTable.Columns
+	insert := fmt.Sprintf(
+		`INSERT INTO %s.%s
+  		(%s, %s, %s, %s, %s, %s, %s, %s, %s)
+		VALUES (:delivery_id, :webhook_id, :event, :payload, :status_code, :attempts, :success, :error,
+		        :created_at)`,
+		schema.Name,
+		schema.WebhookDeliveryTable.Name,
+		dc.DeliveryId,
+		dc.WebhookId,
+		dc.Event,
+		dc.Payload,
+		dc.StatusCode,
+		dc.Attempts,
+		dc.Success,
+		dc.Error,
+		dc.CreatedAt,
+	)
+	_, err := ctx.DB.Exec(
+		insert,
+		sql.Named("delivery_id", d.DeliveryId),
+		sql.Named("webhook_id", d.WebhookId),
+		sql.Named("event", d.Event),
+		sql.Named("payload", d.Payload),
+		sql.Named("status_code", d.StatusCode),
+		sql.Named("attempts", d.Attempts),
+		sql.Named("success", boolToInt(d.Success)),
+		sql.Named("error", d.Error),
+		sql.Named("created_at", d.CreatedAt),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao registrar entrega do webhook.", zap.Error(err))
+		return fmt.Errorf("não foi possível registrar entrega do webhook")
+	}
+	return nil
+}
+
+// updateWebhookDelivery registra o resultado das tentativas de uma entrega.
+func updateWebhookDelivery(ctx *context.Context, d db.WebhookDeliveryModel) error {
+	schema := &ctx.Config.Database.Schema
+	dc := &schema.WebhookDeliveryTable.Columns
+	update := fmt.Sprintf(
+		`UPDATE %s.%s SET %s = :status_code, %s = :attempts, %s = :success, %s = :error
+		WHERE %s = :delivery_id`,
+		schema.Name,
+		schema.WebhookDeliveryTable.Name,
+		dc.StatusCode,
+		dc.Attempts,
+		dc.Success,
+		dc.Error,
+		dc.DeliveryId,
+	)
+	_, err := ctx.DB.Exec(
+		update,
+		sql.Named("status_code", d.StatusCode),
+		sql.Named("attempts", d.Attempts),
+		sql.Named("success", boolToInt(d.Success)),
+		sql.Named("error", d.Error),
+		sql.Named("delivery_id", d.DeliveryId),
+	)
+	if err != nil {
+		ctx.Logger.Error("Erro ao atualizar entrega do webhook.", zap.Error(err))
+		return fmt.Errorf("não foi possível atualizar entrega do webhook")
+	}
+	return nil
+}
+
+// QueryWebhookDeliveries recupera as entregas mais recentes de um webhook.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados
+//     e o zap.Logger.
+//   - webhookId: identificador do webhook.
+//   - limit: número máximo de entregas.
+//
+// Retorno:
+//   - []db.WebhookDeliveryModel: as entregas, das mais recentes para as
+//     mais antigas.
+//   - error: erro caso a consulta ou o processamento dos resultados falhe.
+func QueryWebhookDeliveries(ctx *context.Context, webhookId uuid.UUID, limit int) ([]db.WebhookDeliveryModel, error) {
+	var deliveries []db.WebhookDeliveryModel
+
+	// Query
+	schema := &ctx.Config.Database.Schema
+	dc := &schema.WebhookDeliveryTable.Columns
+	query := fmt.Sprintf(
+		`SELECT %s, %s, %s, %s, %s, %s, %s, %s, %s
+		FROM %s.%s
+		WHERE %s = :webhook_id
+		ORDER BY %s DESC, %s
+		FETCH NEXT :limit ROWS ONLY`,
+		dc.DeliveryId,
+		dc.WebhookId,
+		dc.Event,
+		dc.Payload,
+		dc.StatusCode,
+		dc.Attempts,
+		dc.Success,
+		dc.Error,
+		dc.CreatedAt,
+		schema.Name,
+		schema.WebhookDeliveryTable.Name,
+		dc.WebhookId,
+		dc.CreatedAt,
+		dc.DeliveryId,
+	)
+
+	// Obtenção das linhas
+	rows, err := ctx.DB.Query(query, sql.Named("webhook_id", webhookId.String()), sql.Named("limit", limit))
+	if err != nil {
+		ctx.Logger.Error("Erro ao consultar entregas do webhook.", zap.Error(err))
+		return deliveries, fmt.Errorf("não foi possível obter as entregas do webhook")
+	}
+	defer closeRows(ctx, rows)
+
+	// Iterar por cada uma das linhas
+	for rows.Next() {
+		var d db.WebhookDeliveryModel
+		var success int
+		var errMsg sql.NullString
+		err = rows.Scan(
+			&d.DeliveryId,
+			&d.WebhookId,
+			&d.Event,
+			&d.Payload,
+			&d.StatusCode,
+			&d.Attempts,
+			&success,
+			&errMsg,
+			&d.CreatedAt,
+		)
+		if err != nil {
+			ctx.Logger.Error("Erro ao obter entrega do webhook.", zap.Error(err))
+			return deliveries, fmt.Errorf("não foi possível obter todas as entregas do webhook")
+		}
+		d.Success, d.Error = success != 0, errMsg.String
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, nil
+}
+
+// webhookPolicy obtém a política das tentativas da configuração Webhooks.
+func webhookPolicy(ctx *context.Context) webhook.Policy {
+	cfg := ctx.Config.Webhooks
+	return webhook.Policy{
+		MaxAttempts: cfg.MaxAttempts,
+		BaseDelay:   time.Duration(cfg.BaseDelay) * time.Second,
+		Timeout:     time.Duration(cfg.Timeout) * time.Second,
+	}
+}
+
+// DeliverWebhook entrega um evento a um webhook, com as tentativas da
+// configuração Webhooks, e registra o resultado. A chamada bloqueia até o
+// fim das tentativas.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração dos webhooks, a
+//     do banco de dados e o zap.Logger.
+//   - hook: webhook de destino.
+//   - e: evento a ser entregue.
+//
+// Retorno:
+//   - db.WebhookDeliveryModel: o registro da entrega.
+func DeliverWebhook(ctx *context.Context, hook db.WebhookModel, e WebhookEvent) db.WebhookDeliveryModel {
+	return deliverWebhook(ctx, hook, e, webhookPolicy(ctx))
+}
+
+// TestWebhook entrega o evento EventWebhookTest a um webhook, mesmo inativo
+// ou sem assiná-lo, em uma única tentativa, e registra o resultado.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração dos webhooks, a
+//     do banco de dados e o zap.Logger.
+//   - hook: webhook de destino.
+//   - actorId: identificador do administrador que solicitou o teste.
+//
+// Retorno:
+//   - db.WebhookDeliveryModel: o registro da entrega.
+func TestWebhook(ctx *context.Context, hook db.WebhookModel, actorId uuid.UUID) db.WebhookDeliveryModel {
+	policy := webhookPolicy(ctx)
+	policy.MaxAttempts = 1
+	e := WebhookEvent{
+		Name:     EventWebhookTest,
+		ActorId:  actorId,
+		EntityId: uuid.MustParse(hook.WebhookId),
+	}
+	return deliverWebhook(ctx, hook, e, policy)
+}
+
+// deliverWebhook entrega um evento a um webhook com a política informada e
+// registra o resultado.
+func deliverWebhook(ctx *context.Context, hook db.WebhookModel, e WebhookEvent, policy webhook.Policy) db.WebhookDeliveryModel {
+	now := time.Now()
+	d := db.WebhookDeliveryModel{
+		DeliveryId: uuid.NewString(),
+		WebhookId:  hook.WebhookId,
+		Event:      e.Name,
+		CreatedAt:  now.Unix(),
+	}
+
+	// Corpo da entrega
+	data := e.Data
+	if data == nil {
+		data = map[string]any{}
+	}
+	body, err := json.Marshal(map[string]any{
+		"id":          d.DeliveryId,
+		"event":       e.Name,
+		"occurred_at": now.Unix(),
+		"actor_id":    e.ActorId.String(),
+		"entity_id":   e.EntityId.String(),
+		"data":        data,
+	})
+	if err != nil {
+		ctx.Logger.Error("Erro ao gerar corpo da entrega do webhook.", zap.Error(err))
+		return d
+	}
+	d.Payload = string(body)
+
+	// Registro da entrega pendente, antes das tentativas, para que ela conste
+	// do histórico mesmo que a aplicação seja encerrada durante as
+	// tentativas
+	if err = createWebhookDelivery(ctx, d); err != nil {
+		return d
+	}
+
+	// Entrega
+	client := webhook.NewClient(policy.Timeout, ctx.Config.Webhooks.AllowedHosts)
+	res := webhook.Deliver(client, policy, webhook.Request{
+		Url:        hook.Url,
+		Secret:     hook.Secret,
+		Event:      e.Name,
+		DeliveryId: d.DeliveryId,
+		Body:       body,
+	})
+	d.StatusCode, d.Attempts, d.Success = res.StatusCode, res.Attempts, res.Err == nil
+	if res.Err != nil {
+		d.Error = res.Err.Error()
+		ctx.Logger.Warn(
+			"Entrega do webhook não aceita.",
+			zap.String("webhook_id", hook.WebhookId),
+			zap.String("event", e.Name),
+			zap.Int("attempts", res.Attempts),
+			zap.Error(res.Err),
+		)
+	}
+	_ = updateWebhookDelivery(ctx, d)
+	return d
+}
+
+// PublishEvent publica um evento aos webhooks ativos que o assinam. As
+// entregas são feitas em segundo plano pela fila ctx.Webhooks, de modo que a
+// requisição que gerou o evento não aguarda os destinos. Eventos fora de
+// WebhookEvents são ignorados, assim como os excedentes à capacidade da
+// fila.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração dos webhooks, a
+//     do banco de dados, a fila de entregas e o zap.Logger.
+//   - e: evento a ser publicado.
+func PublishEvent(ctx *context.Context, e WebhookEvent) {
+	if !slices.Contains(WebhookEvents, e.Name) || ctx.Webhooks == nil {
+		return
+	}
+	queued := ctx.Webhooks.Enqueue(func() {
+		schema := &ctx.Config.Database.Schema
+		hooks, err := queryWebhooks(ctx, fmt.Sprintf("WHERE %s = 1", schema.WebhookTable.Columns.Active))
+		if err != nil {
+			return
+		}
+		for _, hook := range hooks {
+			if !subscribed(hook, e.Name) {
+				continue
+			}
+			if !ctx.Webhooks.Enqueue(func() { DeliverWebhook(ctx, hook, e) }) {
+				ctx.Logger.Warn(
+					"Fila de entregas dos webhooks cheia, entrega descartada.",
+					zap.String("webhook_id", hook.WebhookId),
+					zap.String("event", e.Name),
+				)
+			}
+		}
+	})
+	if !queued {
+		ctx.Logger.Warn("Fila de entregas dos webhooks cheia, evento descartado.", zap.String("event", e.Name))
+	}
+}
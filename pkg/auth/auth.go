@@ -1,15 +1,20 @@
 // Package auth fornece funcionalidades relacionadas à autenticação e geração de
 // tokens JWT, incluindo a definição de claims personalizadas e métodos para
-// criar tokens seguros usando o algoritmo de assinatura HS256.
+// criar tokens seguros, assinados com as chaves assimétricas configuradas
+// (RS256 ou EdDSA) ou, sem elas, com o algoritmo HS256.
 package auth
 
 import (
+	"agros_arquivos_patrocinadoras/pkg/app"
 	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/events"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
 	"fmt"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
+	"slices"
 	"time"
 )
 
@@ -21,6 +26,45 @@ type ClaimsData struct {
 	Id uuid.UUID `json:"id"`
 	// Name representa o nome de apresentação do usuário.
 	Name string `json:"name"`
+	// Roles representa os nomes dos papéis atribuídos ao usuário.
+	Roles []string `json:"roles,omitempty"`
+	// Permissions representa as permissões concedidas pelos papéis do
+	// usuário.
+	Permissions []string `json:"permissions,omitempty"`
+	// SessionId representa o identificador da sessão (família de refresh
+	// tokens) que emitiu o token. O token é rejeitado após a revogação da
+	// sessão.
+	SessionId uuid.UUID `json:"sid"`
+	// MustChangePassword indica que o usuário deve trocar a senha antes de
+	// acessar as demais rotas.
+	MustChangePassword bool `json:"mcp,omitempty"`
+	// TotpEnrollRequired indica que o usuário deve cadastrar o segundo fator
+	// (TOTP) antes de acessar as demais rotas.
+	TotpEnrollRequired bool `json:"mfa_enroll,omitempty"`
+	// ApiKey representa o escopo da chave de API que autenticou a
+	// requisição. Nulo em requisições autenticadas por JWT.
+	ApiKey *ApiKeyScope `json:"-"`
+}
+
+// NewClaimsData monta os dados dos claims de um usuário, incluindo os nomes
+// de seus papéis e a união de suas permissões.
+//
+// Parâmetros:
+//   - userId: identificador do usuário.
+//   - name: nome de apresentação do usuário.
+//   - roles: papéis atribuídos ao usuário.
+//
+// Retorno:
+//   - ClaimsData: os dados dos claims do usuário.
+func NewClaimsData(userId uuid.UUID, name string, roles []db.RoleModel) ClaimsData {
+	data := ClaimsData{Id: userId, Name: name}
+	for _, r := range roles {
+		data.Roles = append(data.Roles, r.Name)
+		data.Permissions = append(data.Permissions, r.Permissions...)
+	}
+	slices.Sort(data.Permissions)
+	data.Permissions = slices.Compact(data.Permissions)
+	return data
 }
 
 // CustomClaims define uma estrutura personalizada para os claims de um token
@@ -30,39 +74,127 @@ type CustomClaims struct {
 	jwt.RegisteredClaims
 }
 
-// GenerateToken cria um token JWT com claims personalizados, utilizando o
-// algoritmo HS256.
+// GenerateToken cria um token JWT com claims personalizados, assinado pela
+// chave de assinatura configurada (signClaims).
 //
 // Parâmetros:
 //   - c: contexto das requisições HTTP que contém informações
 //     do request atual.
-//   - userId: identificador único do usuário.
-//   - userName: nome do usuário.
+//   - data: dados dos claims do usuário.
+//   - expiresAt: momento de expiração do token.
 //
 // Retornos:
 //   - string: token JWT gerado.
 //   - error: erro caso ocorra algum problema durante a geração do token.
 func GenerateToken(c echo.Context, data ClaimsData, expiresAt time.Time) (string, error) {
-	ctx := context.GetContext(c)
-
-	// JWT Claims
 	claims := CustomClaims{
 		ClaimsData: data,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 		},
 	}
+	return signClaims(context.GetContext(c), claims)
+}
+
+// validMethods lista os algoritmos aceitos na validação dos tokens. O
+// algoritmo de cada token é conferido com o da sua chave em keyFunc.
+var validMethods = []string{
+	jwt.SigningMethodHS256.Alg(),
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// keyFunc obtém a chave de verificação de um token: a chave pública do kid
+// informado, com o mesmo algoritmo, ou o segredo JWT para os tokens HS256
+// sem kid, quando aceitos (keyring.Keyring.AcceptsHs256).
+func keyFunc(ctx *context.Context) jwt.Keyfunc {
+	return func(t *jwt.Token) (any, error) {
+		if kid, _ := t.Header["kid"].(string); kid != "" {
+			key, ok := ctx.Keyring.Lookup(kid)
+			if !ok || key.Method.Alg() != t.Method.Alg() {
+				return nil, fmt.Errorf("chave %q desconhecida", kid)
+			}
+			return key.Public, nil
+		}
+		if t.Method.Alg() != jwt.SigningMethodHS256.Alg() || !ctx.Keyring.AcceptsHs256() {
+			return nil, fmt.Errorf("token sem kid não aceito")
+		}
+		return []byte(ctx.Config.JwtSecret), nil
+	}
+}
 
-	// Declaração do token com algoritmo HS256 e geração do token criptografado
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	t, err := token.SignedString([]byte(ctx.Config.JwtSecret))
+// ParseToken valida um token JWT de acesso e verifica se a sessão que o
+// emitiu continua ativa, rejeitando tokens de sessões revogadas (logout,
+// reuso de refresh token, troca de senha ou exclusão do usuário) e de contas
+// desativadas ou fora do período de validade. É usada como ParseTokenFunc do
+// middleware echojwt.
+//
+// Parâmetros:
+//   - c: contexto das requisições HTTP.
+//   - raw: token JWT recebido na requisição.
+//
+// Retornos:
+//   - any: o *jwt.Token validado.
+//   - error: erro caso o token seja inválido, sua sessão esteja revogada ou
+//     a conta esteja inativa.
+func ParseToken(c echo.Context, raw string) (any, error) {
+	ctx := context.GetContext(c)
+
+	// Validação da assinatura e da expiração
+	token, err := jwt.ParseWithClaims(
+		raw,
+		new(CustomClaims),
+		keyFunc(ctx),
+		jwt.WithValidMethods(validMethods),
+	)
 	if err != nil {
-		ctx.Logger.Error("Erro ao gerar JWT.", zap.Error(err))
-		return "", err
+		return nil, err
 	}
-	return t, nil
+	claims, ok := token.Claims.(*CustomClaims)
+	if !ok || !token.Valid || len(claims.Audience) > 0 {
+		// Tokens com audiência (ex.: MfaAudience) não são tokens de acesso
+		return nil, fmt.Errorf("token inválido")
+	}
+
+	// Verificação de encerramento da sessão e registro do último acesso
+	active, err := app.TouchSession(ctx, claims.SessionId)
+	if err != nil {
+		return nil, err
+	} else if !active {
+		return nil, fmt.Errorf("sessão revogada")
+	}
+
+	// Verificação da conta (ativa e dentro do período de validade)
+	if active, err = app.IsUserActive(ctx, claims.Id); err != nil {
+		return nil, err
+	} else if !active {
+		return nil, fmt.Errorf("conta inativa")
+	}
+	return token, nil
+}
+
+// SessionActive verifica se a sessão dos claims continua ativa e se a conta
+// continua ativa e dentro do período de validade. É usada nas conexões de
+// longa duração (ex.: o fluxo de eventos), validadas por ParseToken apenas na
+// abertura.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo a configuração do banco de dados.
+//   - data: dados dos claims do usuário conectado.
+//
+// Retorno:
+//   - bool: false caso a sessão tenha sido encerrada, a conta esteja inativa
+//     ou a consulta falhe.
+func SessionActive(ctx *context.Context, data ClaimsData) bool {
+	active, err := app.IsSessionActive(ctx, data.SessionId)
+	if err != nil || !active {
+		return false
+	}
+	active, err = app.IsUserActive(ctx, data.Id)
+	return err == nil && active
 }
 
+// GetClaims obtém os claims do token JWT validado da requisição.
 func GetClaims(c echo.Context) (*CustomClaims, error) {
 	user, ok := c.Get("user").(*jwt.Token)
 	if !ok {
@@ -76,25 +208,482 @@ func GetClaims(c echo.Context) (*CustomClaims, error) {
 	return claims, nil
 }
 
-func AuthenticateAdmin(c echo.Context) bool {
-	ctx := context.GetContext(c)
+// Allows verifica se os dados dos claims concedem a permissão informada. O
+// administrador configurado (AdminId) possui todas as permissões.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo o Id do administrador.
+//   - data: dados dos claims do usuário.
+//   - perm: permissão requerida (ex.: app.PermUsersWrite).
+//
+// Retorno:
+//   - bool: true caso a permissão seja concedida.
+func Allows(ctx *context.Context, data ClaimsData, perm string) bool {
+	return data.Id == ctx.AdminId || slices.Contains(data.Permissions, perm)
+}
+
+// EffectivePermissions retorna as permissões efetivas dos dados dos claims,
+// considerando que o administrador configurado (AdminId) possui todas.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo o Id do administrador.
+//   - data: dados dos claims do usuário.
+//
+// Retorno:
+//   - []string: as permissões efetivas do usuário.
+func EffectivePermissions(ctx *context.Context, data ClaimsData) []string {
+	if data.Id == ctx.AdminId {
+		return app.Permissions
+	}
+	if data.Permissions == nil {
+		return []string{}
+	}
+	return data.Permissions
+}
+
+// HasPermission verifica se o usuário da requisição possui a permissão
+// informada.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - perm: permissão requerida (ex.: app.PermUsersWrite).
+//
+// Retorno:
+//   - bool: true caso a permissão seja concedida.
+func HasPermission(c echo.Context, perm string) bool {
 	claims, err := GetClaims(c)
-	if err != nil || claims.Id != ctx.AdminId {
+	if err != nil {
 		return false
 	}
-	return true
+	return Allows(context.GetContext(c), claims.ClaimsData, perm)
 }
 
+// AuthenticateUser verifica se o usuário da requisição pode acessar os dados
+// do usuário informado, seja o próprio usuário ou um usuário com permissão de
+// leitura de conteúdo.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - userId: identificador do usuário informado na URL.
+//
+// Retorno:
+//   - bool: true caso o acesso seja permitido.
 func AuthenticateUser(c echo.Context, userId uuid.UUID) bool {
+	claims, err := GetClaims(c)
+	if err != nil {
+		return false
+	}
+	return claims.Id == userId || Allows(context.GetContext(c), claims.ClaimsData, app.PermContentRead)
+}
+
+// CanManageUser verifica se o usuário da requisição pode alterar a conta do
+// usuário informado (senha, segundo fator, sessões, exclusão). As permissões
+// efetivas do usuário informado devem estar contidas nas do usuário da
+// requisição, de modo que um papel restrito não assuma contas com mais
+// permissões. A conta do administrador configurado (AdminId) só pode ser
+// alterada por ele mesmo; as chaves de API nunca a alteram.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - userId: identificador do usuário informado na URL.
+//
+// Retorno:
+//   - bool: true caso a alteração seja permitida.
+func CanManageUser(c echo.Context, userId uuid.UUID) bool {
 	ctx := context.GetContext(c)
 	claims, err := GetClaims(c)
 	if err != nil {
 		return false
 	}
+	if claims.Id == ctx.AdminId {
+		return true
+	} else if userId == ctx.AdminId {
+		return false
+	}
+
+	// Permissões efetivas do usuário informado
+	roles, err := app.QueryUserRoles(ctx, userId)
+	if err != nil {
+		return false
+	}
+	granted := EffectivePermissions(ctx, claims.ClaimsData)
+	for _, perm := range EffectivePermissions(ctx, NewClaimsData(userId, "", roles)) {
+		if !slices.Contains(granted, perm) {
+			return false
+		}
+	}
+	return true
+}
+
+// AuthenticateCategory verifica se o usuário da requisição pode acessar a
+// categoria informada no contexto do usuário da URL, seja como proprietário ou
+// por concessão (individual ou para todos os usuários).
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - userId: identificador do usuário informado na URL.
+//   - categ: categoria a ser acessada.
+//
+// Retorno:
+//   - bool: true caso o acesso seja permitido.
+func AuthenticateCategory(c echo.Context, userId uuid.UUID, categ db.CategModel) bool {
+	if !AuthenticateUser(c, userId) {
+		return false
+	}
+	if categ.UserId == userId.String() {
+		return true
+	}
+
+	// Verificar concessão
+	ctx := context.GetContext(c)
+	categId, err := uuid.Parse(categ.CategId)
+	if err != nil {
+		return false
+	}
+	granted, err := app.HasCategoryGrant(ctx, categId, userId)
+	return err == nil && granted
+}
+
+// CanSeeEvent verifica se um usuário pode receber um evento do fluxo de
+// alterações, com as mesmas regras da leitura das entidades: usuários exigem
+// a permissão de leitura de usuários, salvo o próprio usuário; categorias e
+// arquivos exigem a permissão de leitura de conteúdo, salvo o proprietário e
+// os usuários com concessão da categoria.
+//
+// Parâmetros:
+//   - ctx: contexto da aplicação, contendo o Id do administrador e a
+//     configuração do banco de dados.
+//   - data: dados dos claims do usuário conectado ao fluxo.
+//   - e: evento a ser entregue.
+//
+// Retorno:
+//   - bool: true caso o usuário possa receber o evento.
+func CanSeeEvent(ctx *context.Context, data ClaimsData, e events.Event) bool {
+	if e.Entity == events.EntityUser {
+		return e.Id == data.Id.String() || Allows(ctx, data, app.PermUsersRead)
+	}
+	if e.UserId == data.Id.String() || Allows(ctx, data, app.PermContentRead) {
+		return true
+	}
 
-	if claims.Id != userId && claims.Id != ctx.AdminId {
+	// Verificar concessão
+	categId, err := uuid.Parse(e.CategId)
+	if err != nil {
 		return false
 	}
+	granted, err := app.HasCategoryGrant(ctx, categId, data.Id)
+	return err == nil && granted
+}
+
+// MfaAudience é a audiência dos tokens intermediários de login, emitidos
+// após a verificação da senha e antes da verificação do segundo fator.
+const MfaAudience = "mfa"
+
+// mfaTokenExpires é a validade do token intermediário de login.
+const mfaTokenExpires = 5 * time.Minute
+
+// GenerateMfaToken cria o token intermediário de login de um usuário com
+// segundo fator, que não é aceito como token de acesso (ParseToken).
+//
+// Parâmetros:
+//   - c: contexto das requisições HTTP.
+//   - userId: identificador do usuário cuja senha foi verificada.
+//
+// Retornos:
+//   - string: token JWT gerado.
+//   - error: erro caso ocorra algum problema durante a geração do token.
+func GenerateMfaToken(c echo.Context, userId uuid.UUID) (string, error) {
+	ctx := context.GetContext(c)
+	claims := jwt.RegisteredClaims{
+		Subject:   userId.String(),
+		Audience:  jwt.ClaimStrings{MfaAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaTokenExpires)),
+	}
+	return signClaims(ctx, claims)
+}
+
+// signClaims assina claims com a chave de assinatura configurada, informando
+// seu kid, ou, sem ela, com o segredo JWT, usando o algoritmo HS256.
+func signClaims(ctx *context.Context, claims jwt.Claims) (string, error) {
+	var t string
+	var err error
+	if key := ctx.Keyring.Signing(); key != nil {
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.Id
+		t, err = token.SignedString(key.Private)
+	} else {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		t, err = token.SignedString([]byte(ctx.Config.JwtSecret))
+	}
+	if err != nil {
+		ctx.Logger.Error("Erro ao gerar JWT.", zap.Error(err))
+		return "", err
+	}
+	return t, nil
+}
+
+// parseClaims valida um token auxiliar assinado por signClaims com a
+// audiência informada.
+func parseClaims(ctx *context.Context, raw string, claims jwt.Claims, audience string) error {
+	token, err := jwt.ParseWithClaims(
+		raw,
+		claims,
+		keyFunc(ctx),
+		jwt.WithValidMethods(validMethods),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return err
+	} else if !token.Valid {
+		return fmt.Errorf("token inválido")
+	}
+	return nil
+}
+
+// ParseMfaToken valida um token intermediário de login.
+//
+// Parâmetros:
+//   - c: contexto das requisições HTTP.
+//   - raw: token recebido na requisição.
+//
+// Retornos:
+//   - uuid.UUID: o identificador do usuário do token.
+//   - error: erro caso o token seja inválido ou esteja expirado.
+func ParseMfaToken(c echo.Context, raw string) (uuid.UUID, error) {
+	claims := new(jwt.RegisteredClaims)
+	if err := parseClaims(context.GetContext(c), raw, claims, MfaAudience); err != nil {
+		return uuid.Nil, err
+	}
+	return uuid.Parse(claims.Subject)
+}
+
+// EventsAudience é a audiência dos tickets do fluxo de eventos. O
+// EventSource do navegador não envia o cabeçalho Authorization, de modo que,
+// fora do modo de autenticação por cookies, a conexão é autenticada por um
+// ticket no parâmetro QueryEventsTicket.
+const EventsAudience = "events"
+
+// QueryEventsTicket é o parâmetro da URL do fluxo de eventos que contém o
+// ticket.
+const QueryEventsTicket = "ticket"
+
+// EventsTicketExpires é a validade de um ticket do fluxo de eventos, que
+// deve ser usado logo após a emissão.
+const EventsTicketExpires = 30 * time.Second
+
+// EventsTicket armazena os claims de um ticket do fluxo de eventos.
+type EventsTicket struct {
+	ClaimsData
+	// StreamExpiresAt é a expiração do token de acesso que emitiu o ticket,
+	// em tempo Unix, que também encerra o fluxo.
+	StreamExpiresAt int64 `json:"sexp,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// GenerateEventsTicket cria o ticket do fluxo de eventos do usuário da
+// requisição, com os seus claims. O ticket não é aceito como token de acesso
+// (ParseToken).
+//
+// Parâmetros:
+//   - c: contexto das requisições HTTP.
+//   - claims: claims do token de acesso do usuário.
+//
+// Retornos:
+//   - string: token JWT gerado.
+//   - error: erro caso ocorra algum problema durante a geração do token.
+func GenerateEventsTicket(c echo.Context, claims *CustomClaims) (string, error) {
+	ticket := EventsTicket{
+		ClaimsData: claims.ClaimsData,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{EventsAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(EventsTicketExpires)),
+		},
+	}
+	if claims.ExpiresAt != nil {
+		ticket.StreamExpiresAt = claims.ExpiresAt.Unix()
+	}
+	return signClaims(context.GetContext(c), ticket)
+}
+
+// ParseEventsTicket valida um ticket do fluxo de eventos e verifica, como
+// ParseToken, se a sessão e a conta continuam ativas.
+//
+// Parâmetros:
+//   - c: contexto das requisições HTTP.
+//   - raw: ticket recebido na requisição.
+//
+// Retornos:
+//   - *jwt.Token: token com os claims do usuário, a ser usado como o token
+//     de acesso validado da requisição, expirando com o token que emitiu o
+//     ticket.
+//   - error: erro caso o ticket seja inválido ou esteja expirado, ou a
+//     sessão ou a conta estejam inativas.
+func ParseEventsTicket(c echo.Context, raw string) (*jwt.Token, error) {
+	ctx := context.GetContext(c)
+	ticket := new(EventsTicket)
+	if err := parseClaims(ctx, raw, ticket, EventsAudience); err != nil {
+		return nil, err
+	}
+	if !SessionActive(ctx, ticket.ClaimsData) {
+		return nil, fmt.Errorf("sessão revogada ou conta inativa")
+	}
+	claims := &CustomClaims{ClaimsData: ticket.ClaimsData}
+	if ticket.StreamExpiresAt > 0 {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Unix(ticket.StreamExpiresAt, 0))
+	}
+	return &jwt.Token{Claims: claims, Valid: true}, nil
+}
+
+// OidcAudience é a audiência dos tokens de estado do login OpenID Connect,
+// guardados em cookie entre o redirecionamento ao provedor e o retorno.
+const OidcAudience = "oidc"
+
+// oidcStateExpires é a validade do estado do login OpenID Connect.
+const oidcStateExpires = 10 * time.Minute
+
+// OidcState armazena os valores de uma requisição de login OpenID Connect.
+type OidcState struct {
+	// State vincula o retorno do provedor à requisição.
+	State string `json:"state"`
+	// Nonce vincula o ID token à requisição.
+	Nonce string `json:"nonce"`
+	// Verifier é o code verifier do PKCE.
+	Verifier string `json:"verifier"`
+	jwt.RegisteredClaims
+}
+
+// GenerateOidcState assina o estado de uma requisição de login OpenID
+// Connect, que não é aceito como token de acesso (ParseToken).
+//
+// Parâmetros:
+//   - c: contexto das requisições HTTP.
+//   - state: valores da requisição.
+//
+// Retornos:
+//   - string: token JWT gerado.
+//   - error: erro caso ocorra algum problema durante a geração do token.
+func GenerateOidcState(c echo.Context, state OidcState) (string, error) {
+	state.RegisteredClaims = jwt.RegisteredClaims{
+		Audience:  jwt.ClaimStrings{OidcAudience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(oidcStateExpires)),
+	}
+	return signClaims(context.GetContext(c), state)
+}
+
+// ParseOidcState valida o estado de uma requisição de login OpenID Connect.
+//
+// Parâmetros:
+//   - c: contexto das requisições HTTP.
+//   - raw: token recebido no cookie.
+//
+// Retornos:
+//   - OidcState: os valores da requisição.
+//   - error: erro caso o token seja inválido ou esteja expirado.
+func ParseOidcState(c echo.Context, raw string) (OidcState, error) {
+	var state OidcState
+	err := parseClaims(context.GetContext(c), raw, &state, OidcAudience)
+	return state, err
+}
+
+// HeaderApiKey é o cabeçalho das requisições autenticadas por chave de API,
+// alternativo ao JWT nas rotas do grupo /auth.
+const HeaderApiKey = "X-API-Key"
+
+// ApiKeyScope define os usuários e as categorias acessíveis por uma chave de
+// API. Listas vazias não restringem o acesso.
+type ApiKeyScope struct {
+	// Users especifica os identificadores dos usuários acessíveis.
+	Users []string
+	// Categories especifica os identificadores das categorias acessíveis.
+	Categories []string
+}
+
+// ParseApiKey valida uma chave de API e monta o token equivalente ao JWT de
+// acesso, com as permissões da chave e seu escopo. O identificador dos
+// claims é o da chave, registrado como autor na auditoria.
+//
+// Parâmetros:
+//   - c: contexto das requisições HTTP.
+//   - raw: chave recebida no cabeçalho HeaderApiKey.
+//
+// Retornos:
+//   - *jwt.Token: o token com os claims da chave.
+//   - error: erro caso a chave seja inválida ou expirada.
+func ParseApiKey(c echo.Context, raw string) (*jwt.Token, error) {
+	ctx := context.GetContext(c)
+	key, err := app.AuthenticateApiKey(ctx, raw)
+	if err != nil {
+		return nil, err
+	}
+	keyId, err := uuid.Parse(key.KeyId)
+	if err != nil {
+		return nil, fmt.Errorf("chave de API inválida")
+	}
 
+	claims := &CustomClaims{
+		ClaimsData: ClaimsData{
+			Id:          keyId,
+			Name:        key.Name,
+			Permissions: key.Permissions,
+			ApiKey:      &ApiKeyScope{Users: key.Users, Categories: key.Categories},
+		},
+	}
+	return &jwt.Token{Claims: claims, Valid: true}, nil
+}
+
+// ApiKeyInScope verifica se a rota da requisição está no escopo da chave de
+// API que a autenticou. Chaves restritas a usuários ou categorias acessam
+// apenas as rotas com o usuário (:userId) ou a categoria (:categId) no
+// escopo. Requisições autenticadas por JWT não são restringidas.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo), já roteada.
+//
+// Retorno:
+//   - bool: true caso o acesso seja permitido.
+func ApiKeyInScope(c echo.Context) bool {
+	claims, err := GetClaims(c)
+	if err != nil {
+		return false
+	}
+	scope := claims.ApiKey
+	if scope == nil {
+		return true
+	}
+	inScope := func(ids []string, param string) bool {
+		id, err := uuid.Parse(c.Param(param))
+		return err == nil && slices.Contains(ids, id.String())
+	}
+	if len(scope.Users) > 0 && !inScope(scope.Users, "userId") {
+		return false
+	}
+	if len(scope.Categories) > 0 && !inScope(scope.Categories, "categId") {
+		return false
+	}
 	return true
 }
+
+// ApiKeyAllowsUser verifica se um usuário informado no corpo da requisição
+// (ex.: o destino de uma cópia ou de uma concessão), fora dos parâmetros
+// verificados por ApiKeyInScope, está no escopo de usuários da chave de API
+// que a autenticou. Requisições autenticadas por JWT e chaves sem escopo de
+// usuários não são restringidas.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - userId: identificador do usuário, ou uuid.Nil para todos os usuários.
+//
+// Retorno:
+//   - bool: true caso o acesso seja permitido.
+func ApiKeyAllowsUser(c echo.Context, userId uuid.UUID) bool {
+	claims, err := GetClaims(c)
+	if err != nil {
+		return false
+	}
+	scope := claims.ApiKey
+	if scope == nil || len(scope.Users) == 0 {
+		return true
+	}
+	return userId != uuid.Nil && slices.Contains(scope.Users, userId.String())
+}
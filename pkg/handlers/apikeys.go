@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+// CreateApiKeyHandler cria uma chave de API para clientes de máquina (ex.:
+// scripts de automação), aceita no cabeçalho auth.HeaderApiKey no lugar do
+// JWT. O administrador concede apenas permissões que ele próprio possui.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func CreateApiKeyHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[CreateApiKeyReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+	for _, perm := range body.Permissions {
+		if !auth.Allows(ctx, claims.ClaimsData, perm) {
+			return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+		}
+	}
+	data := app.ApiKeyData{
+		Name:        body.Name,
+		Permissions: body.Permissions,
+		Users:       body.Users,
+		Categories:  body.Categories,
+	}
+	if body.ExpiresAt != 0 {
+		data.ExpiresAt = time.Unix(body.ExpiresAt, 0)
+		if !data.ExpiresAt.After(time.Now()) {
+			return c.JSON(http.StatusBadRequest, ApiKeyExpiredMessage)
+		}
+	}
+
+	// Criação
+	key, err := app.CreateApiKey(ctx, data, claims.Id)
+	if errors.Is(err, app.ErrInvalidPermission) {
+		return c.JSON(http.StatusBadRequest, InvalidPermissionMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Auditoria
+	after, _ := app.QueryApiKeyById(ctx, key.KeyId)
+	RecordAudit(c, app.AuditCreate, ApiKey, key.KeyId, nil, after)
+
+	return c.JSON(http.StatusCreated, ApiKeyRes{
+		Id:      key.KeyId,
+		Key:     key.Key,
+		Prefix:  key.Prefix,
+		Message: CreatedApiKeyMessage,
+	})
+}
+
+// GetAllApiKeys obtém todas as chaves de API, com seus escopos e último uso.
+// As chaves em si não são retornadas.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetAllApiKeys(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersRead) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção das chaves
+	ctx := context.GetContext(c)
+	keys, err := app.QueryAllApiKeys(ctx)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, ApiKeysNotFoundMessage)
+	}
+	return c.JSON(http.StatusOK, keys)
+}
+
+// DeleteApiKeyHandler revoga uma chave de API.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func DeleteApiKeyHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Parâmetro da URL e chave antes da exclusão
+	ctx := context.GetContext(c)
+	keyId, err := ParseEntityUUID(c, ApiKey)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidApiKeyIdMessage)
+	}
+	before, _ := app.QueryApiKeyById(ctx, keyId)
+
+	// Exclusão
+	err = app.DeleteApiKey(ctx, keyId)
+	if errors.Is(err, app.ErrEntityNotFound) {
+		return c.JSON(http.StatusNotFound, ApiKeyNotFoundMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	RecordAudit(c, app.AuditDelete, ApiKey, keyId, before, nil)
+
+	return c.JSON(http.StatusOK, DeletedApiKeyMessage)
+}
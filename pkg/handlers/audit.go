@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// Limites de paginação da consulta da auditoria.
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// auditChange representa a alteração de um campo registrada na auditoria.
+type auditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// metadataMap converte uma entidade em um mapa de campos, removendo senhas e
+// conteúdos binários, que não são registrados na auditoria.
+func metadataMap(entity any) map[string]any {
+	m := make(map[string]any)
+	if entity == nil || reflect.ValueOf(entity).IsZero() {
+		return m
+	}
+	if f, ok := entity.(db.FileModel); ok {
+		f.Blob = nil
+		entity = f
+	}
+	payload, err := json.Marshal(entity)
+	if err != nil {
+		return m
+	}
+	_ = json.Unmarshal(payload, &m)
+	delete(m, "password")
+	delete(m, "blob")
+	delete(m, "content")
+	return m
+}
+
+// MetadataDiff calcula as diferenças entre os metadados de uma entidade antes
+// e depois de uma alteração. Apenas os campos alterados são retornados.
+//
+// Parâmetros:
+//   - before: entidade antes da alteração (nil em criações).
+//   - after: entidade depois da alteração (nil em exclusões).
+//
+// Retorno:
+//   - string: JSON no formato {"campo": {"before": ..., "after": ...}}.
+func MetadataDiff(before, after any) string {
+	b, a := metadataMap(before), metadataMap(after)
+	diff := make(map[string]auditChange)
+	for k, v := range b {
+		if !reflect.DeepEqual(v, a[k]) {
+			diff[k] = auditChange{Before: v, After: a[k]}
+		}
+	}
+	for k, v := range a {
+		if _, ok := b[k]; !ok {
+			diff[k] = auditChange{Before: nil, After: v}
+		}
+	}
+
+	payload, err := json.Marshal(diff)
+	if err != nil {
+		return "{}"
+	}
+	return string(payload)
+}
+
+// RecordAudit registra na auditoria uma alteração realizada pelo usuário da
+// requisição e a publica aos webhooks (ex.: "category.deleted"). Falhas no
+// registro são apenas logadas, pois a alteração já foi efetivada.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - action: ação realizada (app.AuditCreate, app.AuditUpdate ou
+//     app.AuditDelete).
+//   - entity: tipo da entidade afetada.
+//   - entityId: identificador da entidade afetada.
+//   - before: entidade antes da alteração (nil em criações).
+//   - after: entidade depois da alteração (nil em exclusões).
+func RecordAudit(
+	c echo.Context,
+	action string,
+	entity EntityType,
+	entityId uuid.UUID,
+	before, after any,
+) {
+	ctx := context.GetContext(c)
+
+	// Autor da ação
+	actorId := uuid.Nil
+	if claims, err := auth.GetClaims(c); err == nil {
+		actorId = claims.Id
+	}
+
+	entry := app.AuditData{
+		ActorId:    actorId,
+		Action:     action,
+		EntityType: entity.String(),
+		EntityId:   entityId,
+		Diff:       MetadataDiff(before, after),
+		ClientIp:   c.RealIP(),
+	}
+	if err := app.CreateAuditEntry(ctx, entry); err != nil {
+		ctx.Logger.Error(
+			"Ação não registrada na auditoria.",
+			zap.String("action", action),
+			zap.String("entity_type", entry.EntityType),
+			zap.String("entity_id", entityId.String()),
+			zap.Error(err),
+		)
+	}
+
+	// Publicação aos webhooks, com a entidade atual (ou a excluída)
+	metadata := after
+	if action == app.AuditDelete {
+		metadata = before
+	}
+	PublishEvent(c, webhookEventName(action, entity), entityId, metadata)
+}
+
+// parseAuditFilter extrai os filtros da consulta da auditoria a partir dos
+// parâmetros de consulta da requisição HTTP.
+func parseAuditFilter(c echo.Context) (app.AuditFilter, bool) {
+	var err error
+	f := app.AuditFilter{Limit: defaultAuditLimit}
+
+	if f.Period, err = ParseReportPeriod(c); err != nil {
+		return f, false
+	}
+	if v := c.QueryParam("actor_id"); v != "" {
+		if f.ActorId, err = uuid.Parse(v); err != nil {
+			return f, false
+		}
+	}
+	if v := c.QueryParam("entity_id"); v != "" {
+		if f.EntityId, err = uuid.Parse(v); err != nil {
+			return f, false
+		}
+	}
+	switch v := c.QueryParam("action"); v {
+	case "", app.AuditCreate, app.AuditUpdate, app.AuditDelete:
+		f.Action = v
+	default:
+		return f, false
+	}
+	switch v := c.QueryParam("entity_type"); v {
+	case "", User.String(), Category.String(), File.String(), Grant.String(), Role.String(),
+		Notification.String(), Session.String():
+		f.EntityType = v
+	default:
+		return f, false
+	}
+	if v := c.QueryParam("limit"); v != "" {
+		if f.Limit, err = strconv.Atoi(v); err != nil || f.Limit <= 0 {
+			return f, false
+		}
+		f.Limit = min(f.Limit, maxAuditLimit)
+	}
+	if v := c.QueryParam("offset"); v != "" {
+		if f.Offset, err = strconv.Atoi(v); err != nil || f.Offset < 0 {
+			return f, false
+		}
+	}
+	return f, true
+}
+
+// GetAuditLog obtém os registros da auditoria, com filtros opcionais por
+// autor (actor_id), ação (action), tipo de entidade (entity_type), entidade
+// (entity_id), período (from, to) e paginação (limit, offset).
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetAuditLog(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermAuditRead) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e dos filtros
+	ctx := context.GetContext(c)
+	filter, ok := parseAuditFilter(c)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, InvalidAuditFilterMessage)
+	}
+
+	// Consulta
+	entries, err := app.QueryAuditEntries(ctx, filter)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, AuditNotFoundMessage)
+	}
+	return c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/mailer"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// parseBatchOperations converte as operações da requisição em operações da
+// aplicação, validando os identificadores informados.
+func parseBatchOperations(body *BatchReq) ([]app.BatchOperation, bool) {
+	ops := make([]app.BatchOperation, 0, len(body.Operations))
+	for _, o := range body.Operations {
+		id, err := uuid.Parse(o.Id)
+		if err != nil {
+			return nil, false
+		}
+		targetId := uuid.Nil
+		if o.Op == app.BatchMove {
+			if targetId, err = uuid.Parse(o.TargetId); err != nil {
+				return nil, false
+			}
+		}
+		if o.Version < 0 {
+			return nil, false
+		}
+		ops = append(ops, app.BatchOperation{
+			Op:       o.Op,
+			Entity:   o.Entity,
+			Id:       id,
+			TargetId: targetId,
+			Name:     o.Name,
+			Version:  o.Version,
+		})
+	}
+	return ops, true
+}
+
+// batchEntityType converte a entidade de uma operação em lote no tipo de
+// entidade dos handlers.
+func batchEntityType(entity string) EntityType {
+	if entity == app.BatchCategory {
+		return Category
+	}
+	return File
+}
+
+// queryBatchEntity obtém os metadados de uma entidade de uma operação em lote,
+// para registro na auditoria.
+func queryBatchEntity(ctx *context.Context, op app.BatchOperation) any {
+	if op.Entity == app.BatchCategory {
+		if categ, err := app.QueryCategoryById(ctx, op.Id); err == nil {
+			return categ
+		}
+		return nil
+	}
+	if file, err := app.QueryFileInfoById(ctx, op.Id); err == nil {
+		return file
+	}
+	return nil
+}
+
+// notifyBatchOperation notifica por e-mail os proprietários das categorias
+// dos arquivos alterados por uma operação do lote. A movimentação de uma
+// categoria notifica o novo proprietário de cada um dos seus arquivos.
+func notifyBatchOperation(c echo.Context, op app.BatchOperation, after any) {
+	switch {
+	case op.Op == app.BatchDelete:
+		return
+	case op.Entity == app.BatchFile:
+		if file, ok := after.(db.FileModel); ok {
+			NotifyFileChange(c, file, mailer.ActionUpdated)
+		}
+	case op.Op == app.BatchMove:
+		files, err := app.QueryAllFiles(context.GetContext(c), op.Id)
+		if err != nil {
+			return
+		}
+		for _, file := range files {
+			NotifyFileChange(c, file, mailer.ActionUpdated)
+		}
+	}
+}
+
+// batchErrorMessage converte o erro de uma operação em lote em uma mensagem
+// HTTP.
+func batchErrorMessage(err error) HTTPMessage {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, app.ErrVersionConflict):
+		return VersionConflictMessage
+	case errors.Is(err, app.ErrEntityNotFound):
+		return EntityNotFoundMessage
+	case errors.Is(err, app.ErrInvalidOperation):
+		return InvalidBatchMessage
+	default:
+		return InternalServerErrorMessage
+	}
+}
+
+// BatchHandler executa um lote de operações (movimentação, renomeação e
+// exclusão) de arquivos e categorias em uma única transação. O lote é
+// efetivado apenas se todas as operações forem bem-sucedidas, e a resposta
+// contém o resultado de cada operação.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func BatchHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[BatchReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidBatchMessage)
+	}
+	ops, ok := parseBatchOperations(body)
+	if !ok {
+		return c.JSON(http.StatusBadRequest, InvalidBatchMessage)
+	}
+
+	// Metadados anteriores das entidades, para a auditoria
+	before := make([]any, len(ops))
+	for i, op := range ops {
+		before[i] = queryBatchEntity(ctx, op)
+	}
+
+	// Execução do lote
+	results, err := app.ExecuteBatch(ctx, ops)
+	res := BatchRes{Message: BatchSuccessMessage}
+	for i, r := range results {
+		res.Results = append(res.Results, BatchItemRes{
+			Index:   i,
+			Op:      ops[i].Op,
+			Entity:  ops[i].Entity,
+			Id:      ops[i].Id.String(),
+			Status:  r.Status,
+			Message: batchErrorMessage(r.Err),
+		})
+	}
+	if errors.Is(err, app.ErrBatchFailed) {
+		res.Message = BatchFailedMessage
+		return c.JSON(http.StatusConflict, res)
+	} else if err != nil {
+		res.Message = InternalServerErrorMessage
+		return c.JSON(http.StatusInternalServerError, res)
+	}
+
+	// Auditoria
+	for i, op := range ops {
+		action, after := app.AuditUpdate, any(nil)
+		if op.Op == app.BatchDelete {
+			action = app.AuditDelete
+		} else {
+			after = queryBatchEntity(ctx, op)
+		}
+		RecordAudit(c, action, batchEntityType(op.Entity), op.Id, before[i], after)
+
+		// Notificação por e-mail dos proprietários das categorias
+		notifyBatchOperation(c, op, after)
+	}
+	return c.JSON(http.StatusOK, res)
+}
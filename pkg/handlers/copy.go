@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/mailer"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// CopyCategoryHandler copia uma categoria, com todos os seus arquivos, para
+// um ou mais usuários, retornando os IDs das categorias e arquivos criados.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func CopyCategoryHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[CopyCategoryReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Parâmetros da URL e verificar se usuário e categoria existem
+	userId, err := ParseEntityUUID(c, User)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+	}
+	if _, err = app.QueryUserById(ctx, userId); err != nil {
+		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+	}
+
+	categId, err := ParseEntityUUID(c, Category)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidCategoryIdMessage)
+	}
+	categ, err := app.QueryCategoryById(ctx, categId)
+	if err != nil || categ.UserId != userId.String() {
+		return c.JSON(http.StatusNotFound, CategoryNotFoundMessage)
+	}
+
+	// Usuários destino, sem repetições
+	seen := make(map[uuid.UUID]bool, len(body.UserIds))
+	targets := make([]uuid.UUID, 0, len(body.UserIds))
+	for _, id := range body.UserIds {
+		targetId, err := uuid.Parse(id)
+		if err != nil || targetId == ctx.AdminId {
+			return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+		}
+		if !auth.ApiKeyAllowsUser(c, targetId) {
+			return c.JSON(http.StatusForbidden, ApiKeyScopeMessage)
+		}
+		if seen[targetId] {
+			continue
+		}
+		if _, err = app.QueryUserById(ctx, targetId); err != nil {
+			return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+		}
+		seen[targetId] = true
+		targets = append(targets, targetId)
+	}
+
+	// Cópia
+	copies, err := app.CopyCategory(ctx, categId, targets)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Auditoria e notificação por e-mail dos proprietários das categorias
+	for _, cp := range copies {
+		newCategId := uuid.MustParse(cp.CategId)
+		after, _ := app.QueryCategoryById(ctx, newCategId)
+		RecordAudit(c, app.AuditCreate, Category, newCategId, nil, after)
+		for _, id := range cp.Files {
+			newFileId := uuid.MustParse(id)
+			file, err := app.QueryFileInfoById(ctx, newFileId)
+			RecordAudit(c, app.AuditCreate, File, newFileId, nil, file)
+			if err == nil {
+				NotifyFileChange(c, file, mailer.ActionCreated)
+			}
+		}
+	}
+	return c.JSON(http.StatusCreated, CopyCategoryRes{
+		Message: CopiedCategoryMessage,
+		Copies:  copies,
+	})
+}
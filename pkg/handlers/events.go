@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"encoding/json"
+	"fmt"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"time"
+)
+
+// Parâmetros do fluxo de eventos (Server-Sent Events).
+const (
+	// eventsKeepalive é o intervalo dos comentários enviados para manter a
+	// conexão aberta em proxies com tempo limite de inatividade.
+	eventsKeepalive = 25 * time.Second
+	// eventsRetry é o intervalo, em milissegundos, sugerido ao navegador
+	// para a reconexão.
+	eventsRetry = 5000
+	// eventsName é o nome dos eventos do fluxo (campo "event").
+	eventsName = "change"
+)
+
+// EventsHandler mantém aberto um fluxo Server-Sent Events com as criações,
+// alterações e exclusões de usuários, categorias e arquivos, para que as
+// telas sejam atualizadas sem recarregar. Cada evento é um JSON
+// (events.Event) entregue apenas aos usuários que podem ler a entidade
+// (auth.CanSeeEvent). A conexão é autenticada pelo token de acesso ou, no
+// navegador fora do modo de autenticação por cookies, pelo ticket de
+// EventsTicketHandler. O fluxo é encerrado na expiração do token de acesso,
+// cabendo ao cliente reconectar com um token renovado, e, a cada
+// keepalive, caso a sessão tenha sido encerrada (logout ou revogação) ou a
+// conta desativada (auth.SessionActive).
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil ao fim do
+//     fluxo.
+func EventsHandler(c echo.Context) error {
+	// Obtenção dos claims
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Assinatura dos eventos
+	ctx := context.GetContext(c)
+	sub := ctx.Events.Subscribe()
+	if sub == nil {
+		return c.JSON(http.StatusServiceUnavailable, EventsUnavailableMessage)
+	}
+	defer ctx.Events.Unsubscribe(sub)
+
+	// Fim do fluxo na expiração do token
+	var expired <-chan time.Time
+	if claims.ExpiresAt != nil {
+		timer := time.NewTimer(time.Until(claims.ExpiresAt.Time))
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	// Cabeçalhos
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	if _, err = fmt.Fprintf(res, "retry: %d\n\n", eventsRetry); err != nil {
+		return nil
+	}
+	res.Flush()
+
+	keepalive := time.NewTicker(eventsKeepalive)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-expired:
+			return nil
+		case <-keepalive.C:
+			if !auth.SessionActive(ctx, claims.ClaimsData) {
+				return nil
+			}
+			if _, err = fmt.Fprint(res, ": keepalive\n\n"); err != nil {
+				return nil
+			}
+		case e, ok := <-sub.C:
+			if !ok {
+				return nil
+			}
+			if !auth.CanSeeEvent(ctx, claims.ClaimsData, e) {
+				continue
+			}
+			payload, err := json.Marshal(e)
+			if err != nil {
+				continue
+			}
+			if _, err = fmt.Fprintf(res, "event: %s\ndata: %s\n\n", eventsName, payload); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+// EventsTicketHandler emite o ticket do fluxo de eventos do usuário da
+// requisição. O EventSource do navegador não envia o cabeçalho
+// Authorization; o ticket, de curta validade, é informado no parâmetro
+// auth.QueryEventsTicket da conexão ao fluxo.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func EventsTicketHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção dos claims
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Emissão
+	ticket, err := auth.GenerateEventsTicket(c, claims)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	return c.JSON(http.StatusOK, EventsTicketRes{
+		Ticket:    ticket,
+		ExpiresIn: int(auth.EventsTicketExpires.Seconds()),
+		Message:   EventsTicketMessage,
+	})
+}
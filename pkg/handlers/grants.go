@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// ownedCategory obtém a categoria da URL, verificando se pertence ao usuário
+// da URL. Em caso de falha, retorna o status e a mensagem HTTP apropriados.
+func ownedCategory(c echo.Context) (db.CategModel, int, HTTPMessage) {
+	ctx := context.GetContext(c)
+
+	userId, err := ParseEntityUUID(c, User)
+	if err != nil {
+		return db.CategModel{}, http.StatusBadRequest, InvalidUserIdMessage
+	}
+	if _, err = app.QueryUserById(ctx, userId); err != nil {
+		return db.CategModel{}, http.StatusNotFound, UserNotFoundMessage
+	}
+
+	categId, err := ParseEntityUUID(c, Category)
+	if err != nil {
+		return db.CategModel{}, http.StatusBadRequest, InvalidCategoryIdMessage
+	}
+	categ, err := app.QueryCategoryById(ctx, categId)
+	if err != nil || categ.UserId != userId.String() {
+		return db.CategModel{}, http.StatusNotFound, CategoryNotFoundMessage
+	}
+	return categ, http.StatusOK, ""
+}
+
+// CreateGrantHandler compartilha uma categoria com um usuário ou com todos os
+// usuários.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func CreateGrantHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[CreateGrantReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Categoria compartilhada
+	categ, status, msg := ownedCategory(c)
+	if status != http.StatusOK {
+		return c.JSON(status, msg)
+	}
+	categId := uuid.MustParse(categ.CategId)
+
+	// Usuário da concessão (uuid.Nil para todos)
+	granteeId := uuid.Nil
+	if !body.All {
+		granteeId, err = uuid.Parse(body.UserId)
+		if err != nil || granteeId == ctx.AdminId || granteeId.String() == categ.UserId {
+			return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+		}
+	}
+	if !auth.ApiKeyAllowsUser(c, granteeId) {
+		return c.JSON(http.StatusForbidden, ApiKeyScopeMessage)
+	}
+	if granteeId != uuid.Nil {
+		if _, err = app.QueryUserById(ctx, granteeId); err != nil {
+			return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+		}
+	}
+
+	// Criação
+	grantId, err := app.CreateCategoryGrant(ctx, categId, granteeId)
+	if errors.Is(err, app.ErrGrantExists) {
+		return c.JSON(http.StatusConflict, CreateResponse{
+			Id:      grantId,
+			Message: GrantExistsMessage,
+		})
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Auditoria
+	grant := db.CategGrantModel{
+		GrantId: grantId.String(),
+		CategId: categ.CategId,
+	}
+	if granteeId != uuid.Nil {
+		grant.UserId = granteeId.String()
+	}
+	RecordAudit(c, app.AuditCreate, Grant, grantId, nil, grant)
+
+	return c.JSON(http.StatusCreated, CreateResponse{
+		Id:      grantId,
+		Message: CreatedGrantMessage,
+	})
+}
+
+// GetCategoryGrants obtém as concessões de acesso de uma categoria.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetCategoryGrants(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentRead) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Categoria compartilhada
+	ctx := context.GetContext(c)
+	categ, status, msg := ownedCategory(c)
+	if status != http.StatusOK {
+		return c.JSON(status, msg)
+	}
+
+	// Obtenção das concessões
+	grants, err := app.QueryCategoryGrants(ctx, uuid.MustParse(categ.CategId))
+	if err != nil {
+		return c.JSON(http.StatusNotFound, GrantsNotFoundMessage)
+	}
+	return c.JSON(http.StatusOK, grants)
+}
+
+// DeleteGrantHandler revoga uma concessão de acesso de uma categoria.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func DeleteGrantHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Categoria compartilhada e concessão
+	ctx := context.GetContext(c)
+	categ, status, msg := ownedCategory(c)
+	if status != http.StatusOK {
+		return c.JSON(status, msg)
+	}
+	categId := uuid.MustParse(categ.CategId)
+
+	grantId, err := ParseEntityUUID(c, Grant)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidGrantIdMessage)
+	}
+
+	// Concessão antes da revogação, para a auditoria
+	var before any
+	if grants, err := app.QueryCategoryGrants(ctx, categId); err == nil {
+		for _, g := range grants {
+			if g.GrantId == grantId.String() {
+				before = g
+			}
+		}
+	}
+
+	// Revogação
+	if err = app.DeleteCategoryGrant(ctx, categId, grantId); errors.Is(err, app.ErrEntityNotFound) {
+		return c.JSON(http.StatusNotFound, GrantNotFoundMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	RecordAudit(c, app.AuditDelete, Grant, grantId, before, nil)
+	return c.JSON(http.StatusOK, DeletedGrantMessage)
+}
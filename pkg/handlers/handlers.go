@@ -9,12 +9,13 @@ package handlers
 import (
 	"agros_arquivos_patrocinadoras/pkg/app"
 	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/mailer"
 	"agros_arquivos_patrocinadoras/pkg/auth"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 	"net/http"
-	"time"
 )
 
 // SessionHandler retorna os dados do JWT token para a sessão de um usuário.
@@ -38,13 +39,17 @@ func SessionHandler(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
-	// Checar se é administrador
-	admin := auth.AuthenticateAdmin(c)
+	// Checar acesso administrativo (leitura de conteúdo de todos os usuários)
+	admin := auth.Allows(ctx, claims.ClaimsData, app.PermContentRead)
 
 	return c.JSON(http.StatusOK, echo.Map{
-		"id":    claims.Id,
-		"name":  claims.Name,
-		"admin": admin,
+		"id":                   claims.Id,
+		"name":                 claims.Name,
+		"admin":                admin,
+		"roles":                claims.Roles,
+		"permissions":          auth.EffectivePermissions(ctx, claims.ClaimsData),
+		"must_change_password": claims.MustChangePassword,
+		"totp_enroll_required": claims.TotpEnrollRequired,
 	})
 }
 
@@ -71,8 +76,13 @@ func LoginHandler(c echo.Context) error {
 	if body.Username == "" {
 		return c.JSON(http.StatusBadRequest, EmptyUsernameMessage)
 	}
-	if len(body.Password) < 4 {
-		return c.JSON(http.StatusBadRequest, InvalidPasswordMessage)
+	if body.Password == "" {
+		return c.JSON(http.StatusBadRequest, EmptyPasswordMessage)
+	}
+
+	// Verificar bloqueio por falhas anteriores
+	if loginLocked(c, body.Username) {
+		return c.JSON(http.StatusTooManyRequests, LoginLockedMessage)
 	}
 
 	// Verificar credenciais
@@ -81,40 +91,70 @@ func LoginHandler(c echo.Context) error {
 		Password: body.Password,
 	}
 	loginData, err := app.QueryLogin(ctx, loginParams)
-	if err != nil || loginData.UserId == uuid.Nil {
+	if msg, ok := accountErrorMessage(err); ok {
+		// Credenciais corretas, mas conta sem acesso
+		ctx.Lockout.Success(body.Username)
+		return c.JSON(http.StatusForbidden, msg)
+	} else if err != nil || loginData.UserId == uuid.Nil {
+		recordLoginFailure(c, body.Username)
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
-	// Verificar se é administrador
-	admin := auth.AuthenticateAdmin(c)
-
-	// Gerar token
-	duration := time.Duration(ctx.Config.JwtExpires) * time.Minute
-	expiresAt := time.Now().Add(duration)
-	claimsData := auth.ClaimsData{
-		Id:   loginData.UserId,
-		Name: loginData.Name,
+	// Segundo fator: a sessão é iniciada apenas após a verificação do código
+	// (LoginTotpHandler), que também zera as falhas registradas; zerá-las
+	// aqui liberaria tentativas ilimitadas do código a quem tem a senha
+	enabled, err := app.IsTotpEnabled(ctx, loginData.UserId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	if enabled {
+		mfaToken, err := auth.GenerateMfaToken(c, loginData.UserId)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+		}
+		return c.JSON(http.StatusOK, MfaRequiredRes{
+			MfaRequired: true,
+			MfaToken:    mfaToken,
+			Message:     TotpRequiredMessage,
+		})
 	}
-	token, err := auth.GenerateToken(c, claimsData, expiresAt)
+	ctx.Lockout.Success(body.Username)
+
+	// Iniciar sessão, gerar tokens, cookies e resposta
+	res, err := startSession(c, loginData.UserId, loginData.Name, loginData.MustChangePassword)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
 	}
+	res.Message = LoginSuccessMessage
+	return c.JSON(http.StatusOK, res)
+}
 
-	// Adicionar cookie e resposta
-	jwtCookie := &http.Cookie{
-		Name:    "jwt",
-		Value:   token,
-		Expires: expiresAt,
+// startSession inicia uma sessão (família de refresh tokens) para o usuário
+// autenticado e gera seus tokens.
+func startSession(c echo.Context, userId uuid.UUID, name string, mustChange bool) (LoginRes, error) {
+	ctx := context.GetContext(c)
+	session := app.SessionData{
+		UserId:    userId,
+		UserAgent: truncate(c.Request().UserAgent(), maxUserAgentLen),
+		ClientIp:  c.RealIP(),
 	}
-	c.SetCookie(jwtCookie)
-	res := LoginRes{
-		Token:   token,
-		Message: LoginSuccessMessage,
-		Id:      loginData.UserId.String(),
-		Name:    loginData.Name,
-		Admin:   admin,
+	refresh, err := app.CreateSession(ctx, session)
+	if err != nil {
+		return LoginRes{}, err
 	}
-	return c.JSON(http.StatusOK, res)
+	return issueTokens(c, name, mustChange, refresh)
+}
+
+// accountErrorMessage obtém a mensagem HTTP de um erro de situação da conta
+// (app.CheckAccount).
+func accountErrorMessage(err error) (HTTPMessage, bool) {
+	switch {
+	case errors.Is(err, app.ErrAccountInactive):
+		return AccountInactiveMessage, true
+	case errors.Is(err, app.ErrAccountExpired):
+		return AccountExpiredMessage, true
+	}
+	return "", false
 }
 
 // CreateUserHandler gerencia a criação de um novo usuário no sistema.
@@ -129,8 +169,8 @@ func CreateUserHandler(c echo.Context) error {
 	// Cabeçalho
 	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
-	// Checar se é admin
-	if admin := auth.AuthenticateAdmin(c); !admin {
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
@@ -148,24 +188,41 @@ func CreateUserHandler(c echo.Context) error {
 	if body.Name == "" {
 		return c.JSON(http.StatusBadRequest, EmptyNameMessage)
 	}
-	if len(body.Password) < 4 {
-		return c.JSON(http.StatusBadRequest, InvalidPasswordMessage)
-	}
 
 	// Criar usuário
 	user := app.UserData{
-		Username: body.Username,
-		Name:     body.Name,
-		Password: body.Password,
+		Username:           body.Username,
+		Name:               body.Name,
+		Password:           body.Password,
+		MustChangePassword: &body.MustChangePassword,
+		Email:              &body.Email,
+		Phone:              &body.Phone,
+		Active:             body.Active,
+		ValidFrom:          &body.ValidFrom,
+		ValidUntil:         &body.ValidUntil,
 	}
 	id, err := app.CreateUser(ctx, user)
 	if err != nil {
+		var policyErr *app.PasswordPolicyError
+		if errors.As(err, &policyErr) {
+			return c.JSON(http.StatusBadRequest, NewPasswordErrorRes(policyErr))
+		}
+		if msg, ok := contactErrorMessage(err); ok {
+			return c.JSON(http.StatusBadRequest, msg)
+		}
+		if errors.Is(err, app.ErrInvalidValidity) {
+			return c.JSON(http.StatusBadRequest, InvalidValidityMessage)
+		}
 		if err.Error() == "nome de usuário já existente" {
 			return c.JSON(http.StatusConflict, DuplicateUserMessage)
 		}
 		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
 	}
 
+	// Auditoria
+	created, _ := app.QueryUserById(ctx, id)
+	RecordAudit(c, app.AuditCreate, User, id, nil, created)
+
 	// Resposta
 	res := CreateResponse{
 		Id:      id,
@@ -187,8 +244,8 @@ func CreateCategoryHandler(c echo.Context) error {
 	// Cabeçalho
 	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
-	// Checar se é admin
-	if admin := auth.AuthenticateAdmin(c); !admin {
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
@@ -218,6 +275,10 @@ func CreateCategoryHandler(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
 	}
 
+	// Auditoria
+	created, _ := app.QueryCategoryById(ctx, id)
+	RecordAudit(c, app.AuditCreate, Category, id, nil, created)
+
 	// Resposta
 	res := CreateResponse{
 		Id:      id,
@@ -227,7 +288,8 @@ func CreateCategoryHandler(c echo.Context) error {
 }
 
 // CreateFileHandler gerencia a criação de um novo arquivo em uma categoria
-// existente.
+// existente. Usuários sem permissão de escrita podem enviar arquivos apenas
+// às suas caixas de entrada.
 //
 // Parâmetros:
 //   - c: contexto Echo contendo as informações da requisição HTTP.
@@ -239,9 +301,17 @@ func CreateFileHandler(c echo.Context) error {
 	// Cabeçalho
 	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
-	// Checar se é admin
-	if admin := auth.AuthenticateAdmin(c); !admin {
-		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	// Checar permissão: sem permissão de escrita, o usuário pode apenas
+	// enviar arquivos às suas próprias caixas de entrada
+	upload := !auth.HasPermission(c, app.PermContentWrite)
+	if upload {
+		claims, err := auth.GetClaims(c)
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+		}
+		if userId, err := ParseEntityUUID(c, User); err != nil || userId != claims.Id {
+			return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+		}
 	}
 
 	// Obtenção do contexto da aplicação e do corpo da requisição
@@ -265,10 +335,26 @@ func CreateFileHandler(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, InvalidCategoryIdMessage)
 	}
 	categ, err := app.QueryCategoryById(ctx, categId)
-	if err != nil && categ.UserId != userId.String() {
+	if err != nil || categ.UserId != userId.String() {
 		return c.JSON(http.StatusNotFound, CategoryNotFoundMessage)
 	}
 
+	// Caixa de entrada: o envio deve respeitar as regras da categoria
+	if upload {
+		inbox, err := app.QueryInbox(ctx, categId)
+		if errors.Is(err, app.ErrEntityNotFound) {
+			return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+		} else if err != nil {
+			return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+		}
+		err = app.CheckInboxUpload(inbox, int64(len(body.Content)), body.Extension, body.Mimetype)
+		if errors.Is(err, app.ErrFileTooLarge) {
+			return c.JSON(http.StatusRequestEntityTooLarge, FileTooLargeMessage)
+		} else if errors.Is(err, app.ErrFileTypeNotAllowed) {
+			return c.JSON(http.StatusUnsupportedMediaType, FileTypeNotAllowedMessage)
+		}
+	}
+
 	// Criar arquivo
 	file := app.FileData{
 		CategId:   categId,
@@ -282,6 +368,18 @@ func CreateFileHandler(c echo.Context) error {
 		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
 	}
 
+	// Auditoria
+	created, _ := app.QueryFileInfoById(ctx, id)
+	RecordAudit(c, app.AuditCreate, File, id, nil, created)
+
+	// Notificação dos administradores sobre envios às caixas de entrada
+	if upload {
+		NotifyInboxUpload(c, userId, categ, created)
+	}
+
+	// Notificação por e-mail do proprietário da categoria
+	NotifyFileChange(c, created, mailer.ActionCreated)
+
 	// Resposta
 	res := CreateResponse{
 		Id:      id,
@@ -302,8 +400,8 @@ func GetAllUsers(c echo.Context) error {
 	// Cabeçalho
 	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
-	// Checar se é admin
-	if admin := auth.AuthenticateAdmin(c); !admin {
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersRead) {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
@@ -345,6 +443,7 @@ func GetUserById(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
 	}
+	SetETag(c, user.UpdatedAt)
 	return c.JSON(http.StatusOK, user)
 }
 
@@ -375,12 +474,23 @@ func GetAllCategories(c echo.Context) error {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
-	// Obtenção de todas as categorias
+	// Obtenção de todas as categorias, próprias e compartilhadas
 	categs, err := app.QueryAllCategories(ctx, userId)
 	if err != nil {
 		return c.JSON(http.StatusNotFound, CategoriesNotFoundMessage)
 	}
-	return c.JSON(http.StatusOK, categs)
+	shared, err := app.QuerySharedCategories(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, CategoriesNotFoundMessage)
+	}
+	inboxes, err := app.QueryUserInboxes(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, CategoriesNotFoundMessage)
+	}
+	for i := range categs {
+		categs[i].Inbox = inboxes[categs[i].CategId]
+	}
+	return c.JSON(http.StatusOK, append(categs, shared...))
 }
 
 // GetCategoryById obtém uma categoria específica com base em seu identificador
@@ -418,9 +528,15 @@ func GetCategoryById(c echo.Context) error {
 
 	// Obtenção da categoria
 	categ, err := app.QueryCategoryById(ctx, categId)
-	if err != nil {
+	if err != nil || !auth.AuthenticateCategory(c, userId, categ) {
 		return c.JSON(http.StatusNotFound, CategoryNotFoundMessage)
 	}
+	categ.Shared = categ.UserId != userId.String()
+	if !categ.Shared {
+		_, err = app.QueryInbox(ctx, categId)
+		categ.Inbox = err == nil
+	}
+	SetETag(c, categ.UpdatedAt)
 	return c.JSON(http.StatusOK, categ)
 }
 
@@ -451,12 +567,12 @@ func GetAllFiles(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, InvalidCategoryIdMessage)
 	}
 	categ, err := app.QueryCategoryById(ctx, categId)
-	if err != nil && categ.UserId != userId.String() {
+	if err != nil {
 		return c.JSON(http.StatusNotFound, CategoryNotFoundMessage)
 	}
 
-	// Autorizar usuário
-	if check := auth.AuthenticateUser(c, userId); !check {
+	// Autorizar usuário, como proprietário ou por concessão
+	if check := auth.AuthenticateCategory(c, userId, categ); !check {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
@@ -495,7 +611,7 @@ func GetFileById(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, InvalidCategoryIdMessage)
 	}
 	categ, err := app.QueryCategoryById(ctx, categId)
-	if err != nil && categ.UserId != userId.String() {
+	if err != nil {
 		return c.JSON(http.StatusNotFound, CategoryNotFoundMessage)
 	}
 
@@ -504,16 +620,33 @@ func GetFileById(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, InvalidFileIdMessage)
 	}
 
-	// Autorizar usuário
-	if check := auth.AuthenticateUser(c, userId); !check {
+	// Autorizar usuário, como proprietário ou por concessão
+	if check := auth.AuthenticateCategory(c, userId, categ); !check {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
 	// Obtenção dos dados do arquivo
 	file, err := app.QueryFileById(ctx, fileId)
-	if err != nil {
+	if err != nil || file.CategId != categId.String() {
 		return c.JSON(http.StatusNotFound, FileNotFoundMessage)
 	}
+
+	// Registrar download (acesso administrativo não é contabilizado)
+	if !auth.HasPermission(c, app.PermContentRead) {
+		if claims, err := auth.GetClaims(c); err == nil {
+			download := app.DownloadData{
+				FileId:   fileId,
+				UserId:   claims.Id,
+				ClientIp: c.RealIP(),
+				Bytes:    int64(len(file.Blob)),
+			}
+			if err = app.CreateDownload(ctx, download); err != nil {
+				ctx.Logger.Warn("Download não registrado.", zap.Error(err))
+			}
+			PublishEvent(c, app.EventFileDownloaded, fileId, file)
+		}
+	}
+	SetETag(c, file.UpdatedAt)
 	return c.JSON(http.StatusOK, file)
 }
 
@@ -529,8 +662,8 @@ func UpdateUserHandler(c echo.Context) error {
 	// Cabeçalho
 	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
-	// Checar se é admin
-	if admin := auth.AuthenticateAdmin(c); !admin {
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
@@ -542,7 +675,9 @@ func UpdateUserHandler(c echo.Context) error {
 	}
 
 	// Caso nada seja requisitado para alterar
-	if body.Username == "" && body.Name == "" && body.Password == "" {
+	if body.Username == "" && body.Name == "" && body.Password == "" && body.MustChangePassword == nil &&
+		body.Email == nil && body.Phone == nil &&
+		body.Active == nil && body.ValidFrom == nil && body.ValidUntil == nil {
 		return c.JSON(http.StatusBadRequest, BadRequestMessage)
 	}
 
@@ -551,22 +686,51 @@ func UpdateUserHandler(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
 	}
-	if _, err = app.QueryUserById(ctx, userId); err != nil {
+	if !auth.CanManageUser(c, userId) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	before, err := app.QueryUserById(ctx, userId)
+	if err != nil {
 		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
 	}
 
-	// Validar senha e alteração
-	if body.Password != "" && len(body.Password) < 4 {
-		return c.JSON(http.StatusBadRequest, InvalidPasswordMessage)
+	// Verificar versão esperada (If-Match)
+	version, err := ParseIfMatch(c)
+	if errors.Is(err, ErrMissingIfMatch) {
+		return c.JSON(http.StatusPreconditionRequired, PreconditionRequiredMessage)
+	} else if err != nil || (version != 0 && version != before.UpdatedAt) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
 	}
+
+	// Validar senha e alteração
 	userParams := app.UserData{
-		Username: body.Username,
-		Name:     body.Name,
-		Password: body.Password,
-	}
-	if err = app.UpdateUser(ctx, userId, userParams); err != nil {
+		Username:           body.Username,
+		Name:               body.Name,
+		Password:           body.Password,
+		MustChangePassword: body.MustChangePassword,
+		Email:              body.Email,
+		Phone:              body.Phone,
+		Active:             body.Active,
+		ValidFrom:          body.ValidFrom,
+		ValidUntil:         body.ValidUntil,
+	}
+	var policyErr *app.PasswordPolicyError
+	if err = app.UpdateUser(ctx, userId, version, userParams); errors.As(err, &policyErr) {
+		return c.JSON(http.StatusBadRequest, NewPasswordErrorRes(policyErr))
+	} else if msg, ok := contactErrorMessage(err); ok {
+		return c.JSON(http.StatusBadRequest, msg)
+	} else if errors.Is(err, app.ErrInvalidValidity) {
+		return c.JSON(http.StatusBadRequest, InvalidValidityMessage)
+	} else if errors.Is(err, app.ErrVersionConflict) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	} else if err != nil {
 		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
 	}
+
+	// Auditoria
+	after, _ := app.QueryUserById(ctx, userId)
+	RecordAudit(c, app.AuditUpdate, User, userId, before, after)
+	SetETag(c, after.UpdatedAt)
 	return c.JSON(http.StatusOK, UpdatedUserMessage)
 }
 
@@ -583,8 +747,8 @@ func UpdateCategoryHandler(c echo.Context) error {
 	// Cabeçalho
 	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
-	// Checar se é admin
-	if admin := auth.AuthenticateAdmin(c); !admin {
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
@@ -630,11 +794,26 @@ func UpdateCategoryHandler(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, CategoryNotFoundMessage)
 	}
 
+	// Verificar versão esperada (If-Match)
+	version, err := ParseIfMatch(c)
+	if errors.Is(err, ErrMissingIfMatch) {
+		return c.JSON(http.StatusPreconditionRequired, PreconditionRequiredMessage)
+	} else if err != nil || (version != 0 && version != categ.UpdatedAt) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	}
+
 	// Alteração
 	categParams := app.CategData{UserId: parsedUserId, Name: body.Name}
-	if err = app.UpdateCategory(ctx, categId, categParams); err != nil {
+	if err = app.UpdateCategory(ctx, categId, version, categParams); errors.Is(err, app.ErrVersionConflict) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	} else if err != nil {
 		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
 	}
+
+	// Auditoria
+	after, _ := app.QueryCategoryById(ctx, categId)
+	RecordAudit(c, app.AuditUpdate, Category, categId, categ, after)
+	SetETag(c, after.UpdatedAt)
 	return c.JSON(http.StatusOK, UpdatedCategoryMessage)
 }
 
@@ -650,8 +829,8 @@ func UpdateFileHandler(c echo.Context) error {
 	// Cabeçalho
 	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
-	// Checar se é admin
-	if admin := auth.AuthenticateAdmin(c); !admin {
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
@@ -707,6 +886,14 @@ func UpdateFileHandler(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, FileNotFoundMessage)
 	}
 
+	// Verificar versão esperada (If-Match)
+	version, err := ParseIfMatch(c)
+	if errors.Is(err, ErrMissingIfMatch) {
+		return c.JSON(http.StatusPreconditionRequired, PreconditionRequiredMessage)
+	} else if err != nil || (version != 0 && version != file.UpdatedAt) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	}
+
 	// Alteração
 	fileParams := app.FileData{
 		CategId:   parsedCategId,
@@ -715,9 +902,20 @@ func UpdateFileHandler(c echo.Context) error {
 		Mimetype:  body.Mimetype,
 		Content:   &body.Content,
 	}
-	if err = app.UpdateFile(ctx, fileId, fileParams); err != nil {
+	if err = app.UpdateFile(ctx, fileId, version, fileParams); errors.Is(err, app.ErrVersionConflict) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	} else if err != nil {
 		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
 	}
+
+	// Auditoria
+	after, _ := app.QueryFileInfoById(ctx, fileId)
+	RecordAudit(c, app.AuditUpdate, File, fileId, file, after)
+
+	// Notificação por e-mail do proprietário da categoria
+	NotifyFileChange(c, after, mailer.ActionUpdated)
+
+	SetETag(c, after.UpdatedAt)
 	return c.JSON(http.StatusOK, UpdatedFileMessage)
 }
 
@@ -734,8 +932,8 @@ func DeleteUser(c echo.Context) error {
 	ctx := context.GetContext(c)
 	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
-	// Checar se é admin
-	if admin := auth.AuthenticateAdmin(c); !admin {
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
@@ -744,14 +942,29 @@ func DeleteUser(c echo.Context) error {
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
 	}
-	if _, err = app.QueryUserById(ctx, userId); err != nil {
+	if !auth.CanManageUser(c, userId) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	user, err := app.QueryUserById(ctx, userId)
+	if err != nil {
 		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
 	}
 
+	// Verificar versão esperada (If-Match)
+	version, err := ParseIfMatch(c)
+	if errors.Is(err, ErrMissingIfMatch) {
+		return c.JSON(http.StatusPreconditionRequired, PreconditionRequiredMessage)
+	} else if err != nil || (version != 0 && version != user.UpdatedAt) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	}
+
 	// Remoção do usuário
-	if err = app.DeleteUser(ctx, userId); err != nil {
+	if err = app.DeleteUser(ctx, userId, version); errors.Is(err, app.ErrVersionConflict) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	} else if err != nil {
 		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
 	}
+	RecordAudit(c, app.AuditDelete, User, userId, user, nil)
 	return c.JSON(http.StatusOK, DeletedUserMessage)
 }
 
@@ -768,8 +981,8 @@ func DeleteCategory(c echo.Context) error {
 	ctx := context.GetContext(c)
 	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
-	// Checar se é admin
-	if admin := auth.AuthenticateAdmin(c); !admin {
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
@@ -791,10 +1004,21 @@ func DeleteCategory(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, CategoryNotFoundMessage)
 	}
 
+	// Verificar versão esperada (If-Match)
+	version, err := ParseIfMatch(c)
+	if errors.Is(err, ErrMissingIfMatch) {
+		return c.JSON(http.StatusPreconditionRequired, PreconditionRequiredMessage)
+	} else if err != nil || (version != 0 && version != categ.UpdatedAt) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	}
+
 	// Remoção da categoria
-	if err = app.DeleteCategory(ctx, categId); err != nil {
+	if err = app.DeleteCategory(ctx, categId, version); errors.Is(err, app.ErrVersionConflict) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	} else if err != nil {
 		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
 	}
+	RecordAudit(c, app.AuditDelete, Category, categId, categ, nil)
 	return c.JSON(http.StatusOK, DeletedCategoryMessage)
 }
 
@@ -811,8 +1035,8 @@ func DeleteFile(c echo.Context) error {
 	ctx := context.GetContext(c)
 	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
 
-	// Checar se é admin
-	if admin := auth.AuthenticateAdmin(c); !admin {
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
 		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
 	}
 
@@ -843,9 +1067,20 @@ func DeleteFile(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, FileNotFoundMessage)
 	}
 
+	// Verificar versão esperada (If-Match)
+	version, err := ParseIfMatch(c)
+	if errors.Is(err, ErrMissingIfMatch) {
+		return c.JSON(http.StatusPreconditionRequired, PreconditionRequiredMessage)
+	} else if err != nil || (version != 0 && version != file.UpdatedAt) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	}
+
 	// Remoção do arquivo
-	if err = app.DeleteFile(ctx, fileId); err != nil {
+	if err = app.DeleteFile(ctx, fileId, version); errors.Is(err, app.ErrVersionConflict) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	} else if err != nil {
 		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
 	}
+	RecordAudit(c, app.AuditDelete, File, fileId, file, nil)
 	return c.JSON(http.StatusOK, DeletedFileMessage)
 }
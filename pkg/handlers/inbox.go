@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// NotifyInboxUpload notifica os administradores sobre o envio de um arquivo
+// a uma caixa de entrada. Falhas são apenas logadas, pois o arquivo já foi
+// criado.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - userId: identificador do usuário que enviou o arquivo.
+//   - categ: caixa de entrada que recebeu o arquivo.
+//   - file: arquivo enviado.
+func NotifyInboxUpload(c echo.Context, userId uuid.UUID, categ db.CategModel, file db.FileModel) {
+	ctx := context.GetContext(c)
+
+	name := userId.String()
+	if user, err := app.QueryUserById(ctx, userId); err == nil {
+		name = user.Name
+	}
+	fileId, _ := uuid.Parse(file.FileId)
+
+	data := app.NotificationData{
+		Kind:     app.NotificationInboxUpload,
+		UserId:   userId,
+		EntityId: fileId,
+		Message: fmt.Sprintf(
+			"%s enviou o arquivo %s.%s para %s.",
+			name,
+			file.Name,
+			file.Extension,
+			categ.Name,
+		),
+	}
+	if _, err := app.CreateNotification(ctx, data); err != nil {
+		ctx.Logger.Error(
+			"Envio à caixa de entrada não notificado.",
+			zap.String("file_id", file.FileId),
+			zap.Error(err),
+		)
+	}
+}
+
+// GetInbox obtém as regras de uma caixa de entrada.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetInbox(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Categoria do usuário da URL
+	ctx := context.GetContext(c)
+	categ, status, msg := ownedCategory(c)
+	if status != http.StatusOK {
+		return c.JSON(status, msg)
+	}
+
+	// Autorizar usuário
+	if check := auth.AuthenticateUser(c, uuid.MustParse(categ.UserId)); !check {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção das regras
+	inbox, err := app.QueryInbox(ctx, uuid.MustParse(categ.CategId))
+	if errors.Is(err, app.ErrEntityNotFound) {
+		return c.JSON(http.StatusNotFound, InboxNotFoundMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	return c.JSON(http.StatusOK, inbox)
+}
+
+// SetInboxHandler marca uma categoria como caixa de entrada, permitindo que
+// o usuário proprietário envie arquivos a ela, ou altera as regras de uma
+// caixa de entrada existente.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func SetInboxHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[SetInboxReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Categoria do usuário da URL
+	categ, status, msg := ownedCategory(c)
+	if status != http.StatusOK {
+		return c.JSON(status, msg)
+	}
+	categId := uuid.MustParse(categ.CategId)
+
+	// Regras anteriores, para a auditoria
+	var before any
+	if inbox, err := app.QueryInbox(ctx, categId); err == nil {
+		before = inbox
+	}
+
+	// Atualização
+	data := app.InboxData{
+		MaxFileSize:  body.MaxFileSize,
+		AllowedTypes: body.AllowedTypes,
+	}
+	if err = app.SetInbox(ctx, categId, data); err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Auditoria
+	after, _ := app.QueryInbox(ctx, categId)
+	RecordAudit(c, app.AuditUpdate, Category, categId, before, after)
+
+	return c.JSON(http.StatusOK, UpdatedInboxMessage)
+}
+
+// RemoveInboxHandler desmarca uma categoria como caixa de entrada, tornando-a
+// somente leitura para o usuário proprietário.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func RemoveInboxHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Categoria do usuário da URL
+	ctx := context.GetContext(c)
+	categ, status, msg := ownedCategory(c)
+	if status != http.StatusOK {
+		return c.JSON(status, msg)
+	}
+	categId := uuid.MustParse(categ.CategId)
+
+	// Remoção
+	before, _ := app.QueryInbox(ctx, categId)
+	if err := app.RemoveInbox(ctx, categId); errors.Is(err, app.ErrEntityNotFound) {
+		return c.JSON(http.StatusNotFound, InboxNotFoundMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	RecordAudit(c, app.AuditUpdate, Category, categId, before, nil)
+
+	return c.JSON(http.StatusOK, RemovedInboxMessage)
+}
+
+// GetNotifications obtém as notificações dos administradores. O parâmetro de
+// consulta "unread=true" restringe a listagem às notificações não lidas.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetNotifications(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção das notificações
+	ctx := context.GetContext(c)
+	notifications, err := app.QueryNotifications(ctx, c.QueryParam("unread") == "true")
+	if err != nil {
+		return c.JSON(http.StatusNotFound, NotificationsNotFoundMessage)
+	}
+	return c.JSON(http.StatusOK, notifications)
+}
+
+// ReadNotificationHandler marca uma notificação como lida.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func ReadNotificationHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermContentWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Parâmetro da URL
+	ctx := context.GetContext(c)
+	notificationId, err := ParseEntityUUID(c, Notification)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidNotificationIdMessage)
+	}
+
+	// Atualização
+	err = app.MarkNotificationRead(ctx, notificationId)
+	if errors.Is(err, app.ErrEntityNotFound) {
+		return c.JSON(http.StatusNotFound, NotificationNotFoundMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	RecordAudit(c, app.AuditUpdate, Notification, notificationId, nil, nil)
+
+	return c.JSON(http.StatusOK, ReadNotificationMessage)
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// jwksMaxAge é o tempo, em segundos, de cache das chaves públicas pelos
+// serviços que verificam os tokens.
+const jwksMaxAge = "300"
+
+// JwksHandler publica as chaves públicas de verificação dos tokens JWT
+// (JSON Web Key Set), para que outros serviços validem os tokens emitidos.
+// Sem chaves assimétricas configuradas, o conjunto é vazio.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func JwksHandler(c echo.Context) error {
+	ctx := context.GetContext(c)
+	c.Response().Header().Set(echo.HeaderCacheControl, "public, max-age="+jwksMaxAge)
+	return c.JSON(http.StatusOK, ctx.Keyring.JWKS())
+}
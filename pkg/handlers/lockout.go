@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// loginLocked verifica se o usuário ou o IP da requisição estão bloqueados
+// por falhas de login, definindo o cabeçalho Retry-After em caso positivo.
+func loginLocked(c echo.Context, username string) bool {
+	ctx := context.GetContext(c)
+	wait := ctx.Lockout.Check(username, c.RealIP())
+	if wait <= 0 {
+		return false
+	}
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return true
+}
+
+// recordLoginFailure registra uma falha de login do usuário e do IP da
+// requisição, registrando no log os bloqueios aplicados.
+func recordLoginFailure(c echo.Context, username string) {
+	ctx := context.GetContext(c)
+	ip := c.RealIP()
+	for _, lock := range ctx.Lockout.Fail(ctx.Config.Lockout, username, ip) {
+		ctx.Logger.Warn(
+			"Login bloqueado temporariamente por falhas consecutivas.",
+			zap.String("lockout_key", lock.Key),
+			zap.String("username", username),
+			zap.String("ip", ip),
+			zap.Int("failures", lock.Failures),
+			zap.Time("locked_until", lock.Until),
+		)
+	}
+}
+
+// UnlockUserHandler remove o bloqueio de login e as falhas registradas de um
+// usuário.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func UnlockUserHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Parâmetros da URL
+	ctx := context.GetContext(c)
+	userId, err := ParseEntityUUID(c, User)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+	}
+
+	// Obtenção do usuário
+	user, err := app.QueryUserById(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+	}
+
+	// Desbloqueio
+	if !ctx.Lockout.Unlock(user.Username) {
+		return c.JSON(http.StatusNotFound, UserNotLockedMessage)
+	}
+	ctx.Logger.Info(
+		"Login desbloqueado.",
+		zap.String("user_id", user.UserId),
+		zap.String("username", user.Username),
+	)
+	before := map[string]bool{"locked": true}
+	after := map[string]bool{"locked": false}
+	RecordAudit(c, app.AuditUpdate, User, userId, before, after)
+
+	return c.JSON(http.StatusOK, UnlockedUserMessage)
+}
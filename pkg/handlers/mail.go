@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+// NotifyFileChange agenda a notificação por e-mail do proprietário da
+// categoria de um arquivo novo ou alterado. A categoria é obtida do próprio
+// arquivo, de modo que um arquivo movido notifica o novo proprietário. O
+// usuário não é notificado das próprias alterações (ex.: envios às caixas de
+// entrada).
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - file: arquivo criado ou alterado.
+//   - action: mailer.ActionCreated ou mailer.ActionUpdated.
+func NotifyFileChange(c echo.Context, file db.FileModel, action string) {
+	ctx := context.GetContext(c)
+	if !ctx.Config.Smtp.Enabled {
+		return
+	}
+
+	categId, err := uuid.Parse(file.CategId)
+	if err != nil {
+		return
+	}
+	categ, err := app.QueryCategoryById(ctx, categId)
+	if err != nil {
+		ctx.Logger.Error(
+			"Alteração do arquivo não notificada por e-mail.",
+			zap.String("file_id", file.FileId),
+			zap.Error(err),
+		)
+		return
+	}
+	if claims, err := auth.GetClaims(c); err == nil && claims.Id.String() == categ.UserId {
+		return
+	}
+	app.QueueFileNotification(ctx, categ, file, action)
+}
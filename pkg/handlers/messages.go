@@ -4,17 +4,65 @@ type HTTPMessage string
 
 // Mensagens relacionadas ao usuário.
 const (
-	InvalidUserIdMessage   HTTPMessage = "Id de usuário inválido."
-	CreatedUserMessage     HTTPMessage = "Usuário criado com sucesso."
-	UserNotFoundMessage    HTTPMessage = "Usuário não encontrado."
-	UsersNotFoundMessage   HTTPMessage = "Nenhum usuário foi encontrado."
-	UpdatedUserMessage     HTTPMessage = "Usuário atualizado com sucesso."
-	DeletedUserMessage     HTTPMessage = "Usuário excluído com sucesso."
-	LoginSuccessMessage    HTTPMessage = "Login realizado com sucesso."
-	EmptyUsernameMessage   HTTPMessage = "Nome de usuário vazio."
-	EmptyNameMessage       HTTPMessage = "Nome vazio."
-	InvalidPasswordMessage HTTPMessage = "Senha com menos de 4 caracteres."
-	DuplicateUserMessage   HTTPMessage = "Nome de usuário já existe."
+	InvalidUserIdMessage          HTTPMessage = "Id de usuário inválido."
+	CreatedUserMessage            HTTPMessage = "Usuário criado com sucesso."
+	UserNotFoundMessage           HTTPMessage = "Usuário não encontrado."
+	UsersNotFoundMessage          HTTPMessage = "Nenhum usuário foi encontrado."
+	UpdatedUserMessage            HTTPMessage = "Usuário atualizado com sucesso."
+	DeletedUserMessage            HTTPMessage = "Usuário excluído com sucesso."
+	LoginSuccessMessage           HTTPMessage = "Login realizado com sucesso."
+	EmptyUsernameMessage          HTTPMessage = "Nome de usuário vazio."
+	EmptyNameMessage              HTTPMessage = "Nome vazio."
+	InvalidPasswordMessage        HTTPMessage = "Senha não atende à política de senhas."
+	PasswordChangedMessage        HTTPMessage = "Senha alterada com sucesso."
+	PasswordChangeRequiredMessage HTTPMessage = "É necessário trocar a senha antes de continuar."
+	WrongPasswordMessage          HTTPMessage = "Senha atual incorreta."
+	PasswordResetCreatedMessage   HTTPMessage = "Token de redefinição de senha gerado com sucesso."
+	InvalidResetTokenMessage      HTTPMessage = "Token de redefinição de senha inválido ou expirado."
+	EmptyPasswordMessage          HTTPMessage = "Senha vazia."
+	DuplicateUserMessage          HTTPMessage = "Nome de usuário já existe."
+	InvalidEmailMessage           HTTPMessage = "E-mail inválido."
+	InvalidPhoneMessage           HTTPMessage = "Telefone inválido."
+	UpdatedProfileMessage         HTTPMessage = "Perfil atualizado com sucesso."
+	AccountInactiveMessage        HTTPMessage = "Conta desativada."
+	AccountExpiredMessage         HTTPMessage = "Conta fora do período de validade."
+	InvalidValidityMessage        HTTPMessage = "Período de validade inválido."
+)
+
+// Mensagens relacionadas à sessão.
+const (
+	RefreshSuccessMessage      HTTPMessage = "Sessão renovada com sucesso."
+	InvalidRefreshTokenMessage HTTPMessage = "Refresh token inválido ou expirado."
+	RefreshTokenReuseMessage   HTTPMessage = "Refresh token reutilizado. Sessão encerrada."
+	LogoutSuccessMessage       HTTPMessage = "Logout realizado com sucesso."
+	InvalidSessionIdMessage    HTTPMessage = "Id de sessão inválido."
+	SessionNotFoundMessage     HTTPMessage = "Sessão não encontrada."
+	SessionsNotFoundMessage    HTTPMessage = "Nenhuma sessão foi encontrada."
+	RevokedSessionMessage      HTTPMessage = "Sessão encerrada com sucesso."
+	RevokedSessionsMessage     HTTPMessage = "Sessões encerradas com sucesso."
+	LoginLockedMessage         HTTPMessage = "Muitas tentativas de login. Tente novamente mais tarde."
+	UnlockedUserMessage        HTTPMessage = "Usuário desbloqueado com sucesso."
+	UserNotLockedMessage       HTTPMessage = "Usuário não possui tentativas de login bloqueadas."
+)
+
+// Mensagens relacionadas ao segundo fator (TOTP).
+const (
+	TotpRequiredMessage       HTTPMessage = "Informe o código de verificação do segundo fator."
+	InvalidTotpCodeMessage    HTTPMessage = "Código de verificação inválido."
+	InvalidMfaTokenMessage    HTTPMessage = "Token de login inválido ou expirado. Faça login novamente."
+	TotpEnrollStartedMessage  HTTPMessage = "Cadastre o segredo no aplicativo autenticador e confirme com um código."
+	TotpEnabledMessage        HTTPMessage = "Segundo fator ativado com sucesso. Guarde os códigos de recuperação."
+	TotpDisabledMessage       HTTPMessage = "Segundo fator removido com sucesso."
+	TotpAlreadyEnabledMessage HTTPMessage = "Segundo fator já cadastrado."
+	TotpNotEnabledMessage     HTTPMessage = "Segundo fator não cadastrado."
+	TotpEnforcedMessage       HTTPMessage = "Segundo fator obrigatório para o seu papel de acesso."
+	TotpEnrollRequiredMessage HTTPMessage = "É necessário cadastrar o segundo fator antes de continuar."
+)
+
+// Mensagens relacionadas ao login único (OpenID Connect).
+const (
+	OidcDisabledMessage      HTTPMessage = "Login único (SSO) não habilitado."
+	OidcProviderErrorMessage HTTPMessage = "Provedor de identidade indisponível. Tente novamente."
 )
 
 // Mensagens relacionadas à categoria.
@@ -25,6 +73,7 @@ const (
 	CategoriesNotFoundMessage HTTPMessage = "Nenhuma categoria foi encontrada."
 	UpdatedCategoryMessage    HTTPMessage = "Categoria atualizada com sucesso."
 	DeletedCategoryMessage    HTTPMessage = "Categoria excluída com sucesso."
+	CopiedCategoryMessage     HTTPMessage = "Categoria copiada com sucesso."
 )
 
 // Mensagens relacionadas ao arquivo.
@@ -37,9 +86,102 @@ const (
 	DeletedFileMessage   HTTPMessage = "Arquivo excluído com sucesso."
 )
 
+// Mensagens relacionadas às concessões de categorias.
+const (
+	InvalidGrantIdMessage HTTPMessage = "Id de concessão inválido."
+	CreatedGrantMessage   HTTPMessage = "Categoria compartilhada com sucesso."
+	GrantExistsMessage    HTTPMessage = "Categoria já compartilhada com o usuário."
+	GrantNotFoundMessage  HTTPMessage = "Concessão não encontrada."
+	GrantsNotFoundMessage HTTPMessage = "Nenhuma concessão foi encontrada."
+	DeletedGrantMessage   HTTPMessage = "Concessão revogada com sucesso."
+)
+
+// Mensagens relacionadas às caixas de entrada e notificações.
+const (
+	InboxNotFoundMessage         HTTPMessage = "Categoria não é uma caixa de entrada."
+	UpdatedInboxMessage          HTTPMessage = "Caixa de entrada salva com sucesso."
+	RemovedInboxMessage          HTTPMessage = "Caixa de entrada removida com sucesso."
+	FileTooLargeMessage          HTTPMessage = "Arquivo maior que o permitido pela caixa de entrada."
+	FileTypeNotAllowedMessage    HTTPMessage = "Tipo de arquivo não permitido pela caixa de entrada."
+	InvalidNotificationIdMessage HTTPMessage = "Id de notificação inválido."
+	NotificationNotFoundMessage  HTTPMessage = "Notificação não encontrada."
+	NotificationsNotFoundMessage HTTPMessage = "Nenhuma notificação foi encontrada."
+	ReadNotificationMessage      HTTPMessage = "Notificação marcada como lida."
+)
+
+// Mensagens relacionadas aos papéis de acesso.
+const (
+	InvalidRoleIdMessage     HTTPMessage = "Id de papel inválido."
+	InvalidPermissionMessage HTTPMessage = "Permissão inválida."
+	CreatedRoleMessage       HTTPMessage = "Papel criado com sucesso."
+	RoleNotFoundMessage      HTTPMessage = "Papel não encontrado."
+	RolesNotFoundMessage     HTTPMessage = "Nenhum papel foi encontrado."
+	UpdatedRoleMessage       HTTPMessage = "Papel atualizado com sucesso."
+	DeletedRoleMessage       HTTPMessage = "Papel excluído com sucesso."
+	DuplicateRoleMessage     HTTPMessage = "Nome de papel já existe."
+	ProtectedRoleMessage     HTTPMessage = "Papéis padrão não podem ser excluídos."
+	UpdatedUserRolesMessage  HTTPMessage = "Papéis do usuário atualizados com sucesso."
+)
+
+// Mensagens relacionadas às chaves de API.
+const (
+	CreatedApiKeyMessage    HTTPMessage = "Chave de API criada com sucesso. Guarde-a: ela não será exibida novamente."
+	ApiKeyNotFoundMessage   HTTPMessage = "Chave de API não encontrada."
+	ApiKeysNotFoundMessage  HTTPMessage = "Não foi possível obter as chaves de API."
+	DeletedApiKeyMessage    HTTPMessage = "Chave de API revogada com sucesso."
+	InvalidApiKeyMessage    HTTPMessage = "Chave de API inválida ou expirada."
+	InvalidApiKeyIdMessage  HTTPMessage = "Id de chave de API inválido."
+	ApiKeyExpiredMessage    HTTPMessage = "A expiração da chave de API deve ser futura."
+	ApiKeyScopeMessage      HTTPMessage = "Rota fora do escopo da chave de API."
+	ApiKeyNotAllowedMessage HTTPMessage = "Rota não disponível para chaves de API."
+)
+
+// Mensagens relacionadas aos webhooks.
+const (
+	CreatedWebhookMessage      HTTPMessage = "Webhook cadastrado com sucesso. Guarde o segredo: ele não será exibido novamente."
+	UpdatedWebhookMessage      HTTPMessage = "Webhook atualizado com sucesso."
+	DeletedWebhookMessage      HTTPMessage = "Webhook excluído com sucesso."
+	WebhookNotFoundMessage     HTTPMessage = "Webhook não encontrado."
+	WebhooksNotFoundMessage    HTTPMessage = "Não foi possível obter os webhooks."
+	DeliveriesNotFoundMessage  HTTPMessage = "Não foi possível obter as entregas do webhook."
+	InvalidWebhookIdMessage    HTTPMessage = "Id de webhook inválido."
+	InvalidWebhookUrlMessage   HTTPMessage = "Endereço do webhook inválido. Informe uma URL http ou https fora da rede interna."
+	InvalidWebhookEventMessage HTTPMessage = "Evento de webhook inválido."
+)
+
+// Mensagens relacionadas aos relatórios.
+const (
+	InvalidPeriodMessage   HTTPMessage = "Período inválido."
+	InvalidIntervalMessage HTTPMessage = "Intervalo de agrupamento inválido."
+	ReportNotFoundMessage  HTTPMessage = "Não foi possível gerar o relatório."
+)
+
+// Mensagens relacionadas à auditoria.
+const (
+	InvalidAuditFilterMessage HTTPMessage = "Filtro de auditoria inválido."
+	AuditNotFoundMessage      HTTPMessage = "Não foi possível obter a auditoria."
+)
+
+// Mensagens relacionadas às operações em lote.
+const (
+	InvalidBatchMessage   HTTPMessage = "Lote de operações inválido."
+	BatchSuccessMessage   HTTPMessage = "Lote executado com sucesso."
+	BatchFailedMessage    HTTPMessage = "Lote não executado. Nenhuma alteração foi realizada."
+	EntityNotFoundMessage HTTPMessage = "Entidade não encontrada."
+)
+
+// Mensagens relacionadas ao fluxo de eventos.
+const (
+	EventsUnavailableMessage   HTTPMessage = "Fluxo de eventos indisponível."
+	InvalidEventsTicketMessage HTTPMessage = "Ticket do fluxo de eventos inválido ou expirado."
+	EventsTicketMessage        HTTPMessage = "Ticket do fluxo de eventos emitido."
+)
+
 // Mensagens gerais.
 const (
-	BadRequestMessage          HTTPMessage = "Falha na requisição. Verifique os dados e tente novamente."
-	InternalServerErrorMessage HTTPMessage = "Erro interno no sistema. Tente novamente."
-	UnauthorizedMessage        HTTPMessage = "Acesso negado. Verifique suas credenciais."
+	BadRequestMessage           HTTPMessage = "Falha na requisição. Verifique os dados e tente novamente."
+	InternalServerErrorMessage  HTTPMessage = "Erro interno no sistema. Tente novamente."
+	UnauthorizedMessage         HTTPMessage = "Acesso negado. Verifique suas credenciais."
+	PreconditionRequiredMessage HTTPMessage = "Versão do registro não informada (If-Match)."
+	VersionConflictMessage      HTTPMessage = "Registro alterado por outra requisição. Recarregue e tente novamente."
 )
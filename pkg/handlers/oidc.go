@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/oidc"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"crypto/subtle"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CookieOidcState é o cookie do estado do login OpenID Connect, guardado
+// entre o redirecionamento ao provedor e o retorno.
+const CookieOidcState = "oidc_state"
+
+// Códigos de erro do login OpenID Connect, informados ao frontend no
+// parâmetro oidc_error do redirecionamento.
+const (
+	oidcErrorDenied    = "denied"
+	oidcErrorState     = "state"
+	oidcErrorExchange  = "exchange"
+	oidcErrorNotLinked = "not_linked"
+	oidcErrorInternal  = "internal"
+	oidcErrorInactive  = "inactive"
+)
+
+// setOidcStateCookie define o cookie do estado do login. O cookie é
+// SameSite=Lax, pois o retorno do provedor é uma navegação entre sites.
+func setOidcStateCookie(c echo.Context, value string, expires time.Time) {
+	ctx := context.GetContext(c)
+	c.SetCookie(&http.Cookie{
+		Name:     CookieOidcState,
+		Value:    value,
+		Path:     "/oidc",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   ctx.Config.EnableTLS,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// oidcRedirect redireciona o navegador ao frontend após o login. Em caso de
+// erro ou de segundo fator, o destino é a página de login do frontend, com o
+// erro no parâmetro oidc_error ou o token intermediário no fragmento, que não
+// é enviado aos servidores.
+func oidcRedirect(c echo.Context, oidcError, mfaToken string) error {
+	target := context.GetContext(c).Config.Oidc.PostLoginRedirect
+	if oidcError == "" && mfaToken == "" {
+		return c.Redirect(http.StatusFound, target)
+	}
+	target = strings.TrimSuffix(target, "/") + "/login"
+	if oidcError != "" {
+		target += "?oidc_error=" + url.QueryEscape(oidcError)
+	}
+	if mfaToken != "" {
+		target += "#mfa_token=" + url.QueryEscape(mfaToken)
+	}
+	return c.Redirect(http.StatusFound, target)
+}
+
+// OidcLoginHandler inicia o login único por OpenID Connect, redirecionando
+// o navegador ao provedor de identidade com o código de autorização e PKCE.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func OidcLoginHandler(c echo.Context) error {
+	// Verificar configuração
+	ctx := context.GetContext(c)
+	if !ctx.Config.Oidc.Enabled || ctx.Oidc == nil {
+		return c.JSON(http.StatusNotFound, OidcDisabledMessage)
+	}
+
+	// Geração do state, do nonce e do code verifier
+	var state auth.OidcState
+	var err error
+	for _, v := range []*string{&state.State, &state.Nonce, &state.Verifier} {
+		if *v, err = oidc.RandomString(); err != nil {
+			ctx.Logger.Error("Erro ao gerar estado do login OIDC.", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+		}
+	}
+
+	// URL de autorização do provedor
+	authURL, err := ctx.Oidc.AuthURL(ctx.Config.Oidc, state.State, state.Nonce, state.Verifier)
+	if err != nil {
+		ctx.Logger.Error("Erro ao montar URL de autorização OIDC.", zap.Error(err))
+		return c.JSON(http.StatusBadGateway, OidcProviderErrorMessage)
+	}
+
+	// Cookie do estado e redirecionamento
+	signed, err := auth.GenerateOidcState(c, state)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	setOidcStateCookie(c, signed, time.Time{})
+	return c.Redirect(http.StatusFound, authURL)
+}
+
+// OidcCallbackHandler conclui o login único por OpenID Connect: valida o
+// estado, troca o código de autorização pelo ID token, obtém o usuário
+// vinculado à identidade (app.ResolveIdentity) e inicia a sessão, com os
+// mesmos tokens do login por senha. Usuários com segundo fator recebem o
+// token intermediário, concluindo o login em LoginTotpHandler.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func OidcCallbackHandler(c echo.Context) error {
+	// Verificar configuração
+	ctx := context.GetContext(c)
+	if !ctx.Config.Oidc.Enabled || ctx.Oidc == nil {
+		return c.JSON(http.StatusNotFound, OidcDisabledMessage)
+	}
+
+	// Estado da requisição, de uso único
+	cookie, err := c.Cookie(CookieOidcState)
+	setOidcStateCookie(c, "", time.Unix(0, 0))
+	if errParam := c.QueryParam("error"); errParam != "" {
+		ctx.Logger.Warn("Login OIDC recusado pelo provedor.", zap.String("error", errParam))
+		return oidcRedirect(c, oidcErrorDenied, "")
+	}
+	if err != nil {
+		return oidcRedirect(c, oidcErrorState, "")
+	}
+	state, err := auth.ParseOidcState(c, cookie.Value)
+	if err != nil || subtle.ConstantTimeCompare([]byte(state.State), []byte(c.QueryParam("state"))) != 1 {
+		return oidcRedirect(c, oidcErrorState, "")
+	}
+
+	// Troca do código e validação do ID token
+	identity, err := ctx.Oidc.Exchange(ctx.Config.Oidc, c.QueryParam("code"), state.Verifier, state.Nonce)
+	if err != nil {
+		ctx.Logger.Error("Erro no login OIDC.", zap.Error(err))
+		return oidcRedirect(c, oidcErrorExchange, "")
+	}
+
+	// Usuário vinculado à identidade
+	userId, created, err := app.ResolveIdentity(ctx, app.IdentityData{
+		Issuer:            identity.Issuer,
+		Subject:           identity.Subject,
+		Email:             identity.Email,
+		EmailVerified:     identity.EmailVerified,
+		Name:              identity.Name,
+		PreferredUsername: identity.PreferredUsername,
+	}, ctx.Config.Oidc.AutoProvision)
+	if errors.Is(err, app.ErrIdentityNotLinked) {
+		ctx.Logger.Warn(
+			"Login OIDC sem usuário vinculado.",
+			zap.String("subject", identity.Subject),
+			zap.String("email", identity.Email),
+		)
+		return oidcRedirect(c, oidcErrorNotLinked, "")
+	} else if err != nil {
+		return oidcRedirect(c, oidcErrorInternal, "")
+	}
+	user, err := app.QueryUserById(ctx, userId)
+	if err != nil {
+		return oidcRedirect(c, oidcErrorInternal, "")
+	}
+	if created {
+		RecordAudit(c, app.AuditCreate, User, userId, nil, user)
+	}
+	if err = app.CheckAccount(ctx, user); err != nil {
+		return oidcRedirect(c, oidcErrorInactive, "")
+	}
+
+	// Segundo fator
+	enabled, err := app.IsTotpEnabled(ctx, userId)
+	if err != nil {
+		return oidcRedirect(c, oidcErrorInternal, "")
+	}
+	if enabled {
+		mfaToken, err := auth.GenerateMfaToken(c, userId)
+		if err != nil {
+			return oidcRedirect(c, oidcErrorInternal, "")
+		}
+		return oidcRedirect(c, "", mfaToken)
+	}
+
+	// Iniciar sessão, gerar tokens e cookies
+	if _, err = startSession(c, userId, user.Name, user.MustChangePassword); err != nil {
+		return oidcRedirect(c, oidcErrorInternal, "")
+	}
+	ctx.Logger.Info("Login OIDC realizado.", zap.String("user_id", userId.String()))
+	return oidcRedirect(c, "", "")
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// CreatePasswordResetHandler gera um token de redefinição de senha de uso
+// único para um usuário. O administrador envia o token ao usuário, que
+// define a própria senha em RedeemPasswordResetHandler.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func CreatePasswordResetHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Parâmetros da URL e verificar se usuário existe
+	ctx := context.GetContext(c)
+	userId, err := ParseEntityUUID(c, User)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+	}
+	if !auth.CanManageUser(c, userId) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	if _, err = app.QueryUserById(ctx, userId); err != nil {
+		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+	}
+
+	// Geração do token
+	reset, err := app.CreatePasswordReset(ctx, userId, claims.Id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	after := map[string]int64{"password_reset_expires_at": reset.ExpiresAt.Unix()}
+	RecordAudit(c, app.AuditUpdate, User, userId, nil, after)
+
+	return c.JSON(http.StatusCreated, PasswordResetRes{
+		Token:     reset.Token,
+		ExpiresAt: reset.ExpiresAt.Unix(),
+		Message:   PasswordResetCreatedMessage,
+	})
+}
+
+// RedeemPasswordResetHandler define a nova senha de um usuário a partir de
+// um token de redefinição. A rota é pública, pois o token autentica o
+// usuário.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func RedeemPasswordResetHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[RedeemPasswordResetReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Redefinição
+	var policyErr *app.PasswordPolicyError
+	userId, err := app.RedeemPasswordReset(ctx, body.Token, body.Password)
+	if errors.As(err, &policyErr) {
+		return c.JSON(http.StatusBadRequest, NewPasswordErrorRes(policyErr))
+	} else if errors.Is(err, app.ErrInvalidResetToken) {
+		return c.JSON(http.StatusBadRequest, InvalidResetTokenMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	ctx.Logger.Info("Senha redefinida por token.", zap.String("user_id", userId.String()))
+
+	return c.JSON(http.StatusOK, PasswordChangedMessage)
+}
+
+// ChangePasswordHandler troca a senha do usuário da requisição, mediante a
+// senha atual. As demais sessões do usuário são encerradas e uma nova sessão
+// é iniciada, com novos tokens.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func ChangePasswordHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação, do usuário e do corpo da requisição
+	ctx := context.GetContext(c)
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	body, err := BodyUnmarshall[ChangePasswordReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+	user, err := app.QueryUserById(ctx, claims.Id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+	}
+
+	// Verificar senha atual, sujeita ao bloqueio por falhas como o login
+	if loginLocked(c, user.Username) {
+		return c.JSON(http.StatusTooManyRequests, LoginLockedMessage)
+	}
+	loginParams := app.LoginParams{Username: user.Username, Password: body.CurrentPassword}
+	if _, err = app.QueryLogin(ctx, loginParams); err != nil {
+		recordLoginFailure(c, user.Username)
+		return c.JSON(http.StatusUnauthorized, WrongPasswordMessage)
+	}
+	ctx.Lockout.Success(user.Username)
+
+	// Troca da senha, que encerra as sessões do usuário
+	mustChange := false
+	userParams := app.UserData{Password: body.NewPassword, MustChangePassword: &mustChange}
+	var policyErr *app.PasswordPolicyError
+	if err = app.UpdateUser(ctx, claims.Id, 0, userParams); errors.As(err, &policyErr) {
+		return c.JSON(http.StatusBadRequest, NewPasswordErrorRes(policyErr))
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	after, _ := app.QueryUserById(ctx, claims.Id)
+	RecordAudit(c, app.AuditUpdate, User, claims.Id, user, after)
+
+	// Nova sessão para a requisição
+	res, err := startSession(c, claims.Id, user.Name, false)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	res.Message = PasswordChangedMessage
+	return c.JSON(http.StatusOK, res)
+}
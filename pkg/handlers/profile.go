@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// contactErrorMessage obtém a mensagem HTTP de um erro de validação dos
+// dados de contato (app.ValidateContact).
+func contactErrorMessage(err error) (HTTPMessage, bool) {
+	switch {
+	case errors.Is(err, app.ErrInvalidEmail):
+		return InvalidEmailMessage, true
+	case errors.Is(err, app.ErrInvalidPhone):
+		return InvalidPhoneMessage, true
+	}
+	return "", false
+}
+
+// GetProfile obtém o perfil do usuário da requisição: nome de apresentação e
+// dados de contato. A troca de senha é feita em ChangePasswordHandler.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetProfile(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação e do usuário
+	ctx := context.GetContext(c)
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do usuário
+	user, err := app.QueryUserById(ctx, claims.Id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+	}
+	SetETag(c, user.UpdatedAt)
+	return c.JSON(http.StatusOK, user)
+}
+
+// UpdateProfileHandler altera o nome de apresentação, os dados de contato e
+// o recebimento das notificações por e-mail do usuário da requisição. O nome
+// de usuário e a senha não são alterados por esta rota.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func UpdateProfileHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação, do usuário e do corpo da requisição
+	ctx := context.GetContext(c)
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	body, err := BodyUnmarshall[UpdateProfileReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Caso nada seja requisitado para alterar
+	if body.Name == "" && body.Email == nil && body.Phone == nil && body.EmailNotifications == nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+	before, err := app.QueryUserById(ctx, claims.Id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+	}
+
+	// Verificar versão esperada (If-Match)
+	version, err := ParseIfMatch(c)
+	if errors.Is(err, ErrMissingIfMatch) {
+		return c.JSON(http.StatusPreconditionRequired, PreconditionRequiredMessage)
+	} else if err != nil || (version != 0 && version != before.UpdatedAt) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	}
+
+	// Alteração
+	userParams := app.UserData{
+		Name:               body.Name,
+		Email:              body.Email,
+		Phone:              body.Phone,
+		EmailNotifications: body.EmailNotifications,
+	}
+	err = app.UpdateUser(ctx, claims.Id, version, userParams)
+	if msg, ok := contactErrorMessage(err); ok {
+		return c.JSON(http.StatusBadRequest, msg)
+	} else if errors.Is(err, app.ErrVersionConflict) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Auditoria
+	after, _ := app.QueryUserById(ctx, claims.Id)
+	RecordAudit(c, app.AuditUpdate, User, claims.Id, before, after)
+	SetETag(c, after.UpdatedAt)
+	return c.JSON(http.StatusOK, UpdatedProfileMessage)
+}
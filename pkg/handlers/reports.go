@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// formatUnix formata um timestamp Unix (segundos) no padrão RFC 3339 para
+// exportação, retornando vazio para timestamps zerados.
+func formatUnix(ts int64) string {
+	if ts == 0 {
+		return ""
+	}
+	return time.Unix(ts, 0).Format(time.RFC3339)
+}
+
+// fileStatsCSV converte as estatísticas de download por arquivo em linhas CSV.
+func fileStatsCSV(stats []db.FileDownloadStats) ([]string, [][]string) {
+	header := []string{
+		"file_id",
+		"file_name",
+		"categ_id",
+		"categ_name",
+		"user_id",
+		"downloads",
+		"bytes",
+		"last_download_at",
+	}
+	records := make([][]string, 0, len(stats))
+	for _, s := range stats {
+		records = append(records, []string{
+			s.FileId,
+			s.FileName,
+			s.CategId,
+			s.CategName,
+			s.UserId,
+			strconv.FormatInt(s.Downloads, 10),
+			strconv.FormatInt(s.Bytes, 10),
+			formatUnix(s.LastDownloadAt),
+		})
+	}
+	return header, records
+}
+
+// GetDownloadsByFile gera o relatório de downloads por arquivo.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetDownloadsByFile(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermReportsRead) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do período
+	ctx := context.GetContext(c)
+	period, err := ParseReportPeriod(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidPeriodMessage)
+	}
+
+	// Relatório
+	stats, err := app.QueryDownloadsByFile(ctx, period)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ReportNotFoundMessage)
+	}
+	if WantsCSV(c) {
+		header, records := fileStatsCSV(stats)
+		return WriteCSV(c, "downloads_por_arquivo.csv", header, records)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// GetDownloadsByUser gera o relatório de downloads por usuário
+// (patrocinadora).
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetDownloadsByUser(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermReportsRead) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do período
+	ctx := context.GetContext(c)
+	period, err := ParseReportPeriod(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidPeriodMessage)
+	}
+
+	// Relatório
+	stats, err := app.QueryDownloadsByUser(ctx, period)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ReportNotFoundMessage)
+	}
+	if WantsCSV(c) {
+		header := []string{
+			"user_id",
+			"username",
+			"name",
+			"downloads",
+			"files",
+			"bytes",
+			"last_download_at",
+		}
+		records := make([][]string, 0, len(stats))
+		for _, s := range stats {
+			records = append(records, []string{
+				s.UserId,
+				s.Username,
+				s.Name,
+				strconv.FormatInt(s.Downloads, 10),
+				strconv.FormatInt(s.Files, 10),
+				strconv.FormatInt(s.Bytes, 10),
+				formatUnix(s.LastDownloadAt),
+			})
+		}
+		return WriteCSV(c, "downloads_por_patrocinadora.csv", header, records)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// GetDownloadsByPeriod gera o relatório de downloads agrupados por período,
+// conforme o parâmetro de consulta "interval" (day, month ou year).
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetDownloadsByPeriod(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermReportsRead) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação, do período e do agrupamento
+	ctx := context.GetContext(c)
+	period, err := ParseReportPeriod(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidPeriodMessage)
+	}
+	interval := c.QueryParam("interval")
+	switch interval {
+	case "":
+		interval = app.IntervalDay
+	case app.IntervalDay, app.IntervalMonth, app.IntervalYear:
+	default:
+		return c.JSON(http.StatusBadRequest, InvalidIntervalMessage)
+	}
+
+	// Relatório
+	stats, err := app.QueryDownloadsByPeriod(ctx, period, interval)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ReportNotFoundMessage)
+	}
+	if WantsCSV(c) {
+		header := []string{"period", "start", "downloads", "bytes"}
+		records := make([][]string, 0, len(stats))
+		for _, s := range stats {
+			records = append(records, []string{
+				s.Period,
+				formatUnix(s.Start),
+				strconv.FormatInt(s.Downloads, 10),
+				strconv.FormatInt(s.Bytes, 10),
+			})
+		}
+		return WriteCSV(c, "downloads_por_periodo.csv", header, records)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
+
+// GetUndownloadedFiles gera o relatório de arquivos que nunca foram baixados
+// no período informado.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetUndownloadedFiles(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermReportsRead) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do período
+	ctx := context.GetContext(c)
+	period, err := ParseReportPeriod(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidPeriodMessage)
+	}
+
+	// Relatório
+	stats, err := app.QueryUndownloadedFiles(ctx, period)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, ReportNotFoundMessage)
+	}
+	if WantsCSV(c) {
+		header, records := fileStatsCSV(stats)
+		return WriteCSV(c, "arquivos_nao_baixados.csv", header, records)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
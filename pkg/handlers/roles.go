@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// roleNameTaken verifica se já existe outro papel com o nome informado.
+func roleNameTaken(ctx *context.Context, name string, roleId uuid.UUID) bool {
+	roles, err := app.QueryAllRoles(ctx)
+	if err != nil {
+		return false
+	}
+	for _, r := range roles {
+		if r.Name == name && r.RoleId != roleId.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateRoleHandler cria um novo papel de acesso com as permissões
+// informadas.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func CreateRoleHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermRolesManage) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[CreateRoleReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+	if roleNameTaken(ctx, body.Name, uuid.Nil) {
+		return c.JSON(http.StatusConflict, DuplicateRoleMessage)
+	}
+
+	// Criação
+	data := app.RoleData{Name: body.Name, Permissions: body.Permissions}
+	roleId, err := app.CreateRole(ctx, data)
+	if errors.Is(err, app.ErrInvalidPermission) {
+		return c.JSON(http.StatusBadRequest, InvalidPermissionMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Auditoria
+	after, _ := app.QueryRoleById(ctx, roleId)
+	RecordAudit(c, app.AuditCreate, Role, roleId, nil, after)
+
+	return c.JSON(http.StatusCreated, CreateResponse{
+		Id:      roleId,
+		Message: CreatedRoleMessage,
+	})
+}
+
+// GetAllRoles obtém todos os papéis de acesso com suas permissões.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetAllRoles(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermRolesManage) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção dos papéis
+	ctx := context.GetContext(c)
+	roles, err := app.QueryAllRoles(ctx)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, RolesNotFoundMessage)
+	}
+	return c.JSON(http.StatusOK, roles)
+}
+
+// UpdateRoleHandler altera o nome e/ou as permissões de um papel de acesso.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func UpdateRoleHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermRolesManage) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[UpdateRoleReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Parâmetro da URL e papel antes da alteração
+	roleId, err := ParseEntityUUID(c, Role)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidRoleIdMessage)
+	}
+	before, err := app.QueryRoleById(ctx, roleId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, RoleNotFoundMessage)
+	}
+
+	// Os papéis padrão mantêm o nome, pois são recriados na inicialização
+	if _, ok := app.DefaultRoles[before.Name]; ok && body.Name != "" && body.Name != before.Name {
+		return c.JSON(http.StatusConflict, ProtectedRoleMessage)
+	}
+	if body.Name != "" && roleNameTaken(ctx, body.Name, roleId) {
+		return c.JSON(http.StatusConflict, DuplicateRoleMessage)
+	}
+
+	// Atualização
+	data := app.RoleData{Name: body.Name}
+	if body.Permissions != nil {
+		data.Permissions = *body.Permissions
+		if data.Permissions == nil {
+			data.Permissions = []string{}
+		}
+	}
+	err = app.UpdateRole(ctx, roleId, data)
+	if errors.Is(err, app.ErrInvalidPermission) {
+		return c.JSON(http.StatusBadRequest, InvalidPermissionMessage)
+	} else if errors.Is(err, app.ErrEntityNotFound) {
+		return c.JSON(http.StatusNotFound, RoleNotFoundMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Auditoria
+	after, _ := app.QueryRoleById(ctx, roleId)
+	RecordAudit(c, app.AuditUpdate, Role, roleId, before, after)
+
+	return c.JSON(http.StatusOK, UpdatedRoleMessage)
+}
+
+// DeleteRoleHandler exclui um papel de acesso e suas atribuições. Os papéis
+// padrão não podem ser excluídos.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func DeleteRoleHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermRolesManage) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Parâmetro da URL e papel antes da exclusão
+	ctx := context.GetContext(c)
+	roleId, err := ParseEntityUUID(c, Role)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidRoleIdMessage)
+	}
+	before, _ := app.QueryRoleById(ctx, roleId)
+
+	// Exclusão
+	err = app.DeleteRole(ctx, roleId)
+	if errors.Is(err, app.ErrEntityNotFound) {
+		return c.JSON(http.StatusNotFound, RoleNotFoundMessage)
+	} else if errors.Is(err, app.ErrProtectedRole) {
+		return c.JSON(http.StatusConflict, ProtectedRoleMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	RecordAudit(c, app.AuditDelete, Role, roleId, before, nil)
+
+	return c.JSON(http.StatusOK, DeletedRoleMessage)
+}
+
+// GetUserRoles obtém os papéis de acesso atribuídos a um usuário.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetUserRoles(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermRolesManage) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Parâmetro da URL
+	ctx := context.GetContext(c)
+	userId, err := ParseEntityUUID(c, User)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+	}
+	if _, err = app.QueryUserById(ctx, userId); err != nil {
+		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+	}
+
+	// Obtenção dos papéis
+	roles, err := app.QueryUserRoles(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, RolesNotFoundMessage)
+	}
+	return c.JSON(http.StatusOK, roles)
+}
+
+// SetUserRolesHandler substitui os papéis de acesso atribuídos a um usuário.
+// As novas permissões valem a partir do próximo login do usuário.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func SetUserRolesHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermRolesManage) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[SetUserRolesReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Parâmetro da URL
+	userId, err := ParseEntityUUID(c, User)
+	if err != nil || userId == ctx.AdminId {
+		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+	}
+	if _, err = app.QueryUserById(ctx, userId); err != nil {
+		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+	}
+
+	// Papéis atribuídos, sem repetições
+	seen := make(map[uuid.UUID]bool, len(body.RoleIds))
+	roleIds := make([]uuid.UUID, 0, len(body.RoleIds))
+	for _, id := range body.RoleIds {
+		roleId, err := uuid.Parse(id)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, InvalidRoleIdMessage)
+		}
+		if seen[roleId] {
+			continue
+		}
+		if _, err = app.QueryRoleById(ctx, roleId); err != nil {
+			return c.JSON(http.StatusNotFound, RoleNotFoundMessage)
+		}
+		seen[roleId] = true
+		roleIds = append(roleIds, roleId)
+	}
+
+	// Atribuição
+	before, _ := app.QueryUserRoles(ctx, userId)
+	if err = app.SetUserRoles(ctx, userId, roleIds); err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	after, _ := app.QueryUserRoles(ctx, userId)
+	RecordAudit(c, app.AuditUpdate, User, userId, before, after)
+
+	return c.JSON(http.StatusOK, UpdatedUserRolesMessage)
+}
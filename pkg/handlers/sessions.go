@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+)
+
+// maxUserAgentLen é o tamanho máximo do agente armazenado em uma sessão.
+const maxUserAgentLen = 255
+
+// authenticateSessions verifica se o usuário da requisição pode gerenciar as
+// sessões do usuário da URL: as próprias, ou as de qualquer usuário com a
+// permissão informada.
+func authenticateSessions(c echo.Context, userId uuid.UUID, perm string) bool {
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return false
+	}
+	return claims.Id == userId || auth.HasPermission(c, perm)
+}
+
+// authorizeRevokeSessions verifica se o usuário da requisição pode encerrar
+// as sessões do usuário da URL: as próprias, ou as de um usuário que possa
+// gerenciar (ver auth.CanManageUser) com a permissão de escrita de usuários.
+func authorizeRevokeSessions(c echo.Context, userId uuid.UUID) bool {
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return false
+	}
+	if claims.Id == userId {
+		return true
+	}
+	return auth.HasPermission(c, app.PermUsersWrite) && auth.CanManageUser(c, userId)
+}
+
+// GetUserSessions obtém as sessões ativas de um usuário, indicando a sessão
+// da requisição.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetUserSessions(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Parâmetros da URL
+	ctx := context.GetContext(c)
+	userId, err := ParseEntityUUID(c, User)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+	}
+
+	// Autorizar usuário
+	if !authenticateSessions(c, userId, app.PermUsersRead) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção das sessões
+	sessions, err := app.QueryUserSessions(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, SessionsNotFoundMessage)
+	}
+	if claims, err := auth.GetClaims(c); err == nil {
+		for i := range sessions {
+			sessions[i].Current = sessions[i].SessionId == claims.SessionId.String()
+		}
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// DeleteSessionHandler encerra uma sessão de um usuário, invalidando seus
+// tokens.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func DeleteSessionHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Parâmetros da URL
+	ctx := context.GetContext(c)
+	userId, err := ParseEntityUUID(c, User)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+	}
+	sessionId, err := ParseEntityUUID(c, Session)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidSessionIdMessage)
+	}
+
+	// Autorizar usuário
+	if !authorizeRevokeSessions(c, userId) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Sessão ativa do usuário
+	sessions, err := app.QueryUserSessions(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	var before any
+	for _, s := range sessions {
+		if s.SessionId == sessionId.String() {
+			before = s
+		}
+	}
+	if before == nil {
+		return c.JSON(http.StatusNotFound, SessionNotFoundMessage)
+	}
+
+	// Encerramento
+	if err = app.RevokeSession(ctx, sessionId); err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	RecordAudit(c, app.AuditDelete, Session, sessionId, before, nil)
+
+	return c.JSON(http.StatusOK, RevokedSessionMessage)
+}
+
+// DeleteUserSessionsHandler encerra todas as sessões de um usuário,
+// inclusive a da requisição, caso seja do próprio usuário.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func DeleteUserSessionsHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Parâmetros da URL
+	ctx := context.GetContext(c)
+	userId, err := ParseEntityUUID(c, User)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+	}
+
+	// Autorizar usuário
+	if !authorizeRevokeSessions(c, userId) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Encerramento
+	sessions, _ := app.QueryUserSessions(ctx, userId)
+	if err = app.RevokeUserSessions(ctx, userId); err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	for _, s := range sessions {
+		RecordAudit(c, app.AuditDelete, Session, uuid.MustParse(s.SessionId), s, nil)
+	}
+
+	return c.JSON(http.StatusOK, RevokedSessionsMessage)
+}
@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/random"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// Nomes dos cookies de autenticação.
+const (
+	// CookieJwt é o cookie do token de acesso.
+	CookieJwt = "jwt"
+	// CookieRefresh é o cookie do refresh token, inacessível ao JavaScript.
+	CookieRefresh = "refresh_token"
+	// CookieCSRF é o cookie do token CSRF (double-submit), lido pelo frontend
+	// e reenviado no cabeçalho HeaderCSRFToken.
+	CookieCSRF = "_csrf"
+	// HeaderCSRFToken é o cabeçalho com o token CSRF, exigido em requisições
+	// POST, PUT, PATCH e DELETE no modo de autenticação por cookies.
+	HeaderCSRFToken = "X-CSRF-Token"
+)
+
+// csrfTokenLength é o tamanho do token CSRF emitido no login.
+const csrfTokenLength = 32
+
+// setAuthCookies define os cookies dos tokens de acesso e de renovação. Um
+// valor vazio remove o cookie correspondente. No modo de autenticação por
+// cookies, o cookie do token de acesso é HttpOnly e um novo token CSRF é
+// emitido junto aos tokens. Os cookies são Secure com TLS e sempre no modo
+// de autenticação por cookies, inclusive atrás de um proxy que termina o TLS.
+func setAuthCookies(c echo.Context, token string, tokenExpires time.Time, refresh string, refreshExpires time.Time) {
+	ctx := context.GetContext(c)
+	if token == "" {
+		tokenExpires = time.Unix(0, 0)
+	}
+	if refresh == "" {
+		refreshExpires = time.Unix(0, 0)
+	}
+	cookieAuth := ctx.Config.CookieAuth
+	secure := cookieAuth || ctx.Config.EnableTLS
+	c.SetCookie(&http.Cookie{
+		Name:     CookieJwt,
+		Value:    token,
+		Path:     "/",
+		Expires:  tokenExpires,
+		HttpOnly: cookieAuth,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	c.SetCookie(&http.Cookie{
+		Name:     CookieRefresh,
+		Value:    refresh,
+		Path:     "/",
+		Expires:  refreshExpires,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	if cookieAuth {
+		csrf := ""
+		if refresh != "" {
+			csrf = random.String(csrfTokenLength)
+		}
+		c.SetCookie(&http.Cookie{
+			Name:     CookieCSRF,
+			Value:    csrf,
+			Path:     "/",
+			Expires:  refreshExpires,
+			Secure:   secure,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+}
+
+// issueTokens gera um token de acesso para a sessão do refresh token
+// informado, com os papéis e permissões atuais do usuário, e define os
+// cookies de autenticação.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//   - name: nome de apresentação do usuário.
+//   - mustChange: indica se o usuário deve trocar a senha.
+//   - refresh: refresh token da sessão.
+//
+// Retorno:
+//   - LoginRes: a resposta com os tokens e os dados do usuário, sem mensagem.
+//   - error: erro caso não seja possível gerar o token de acesso.
+func issueTokens(c echo.Context, name string, mustChange bool, refresh app.RefreshTokenData) (LoginRes, error) {
+	ctx := context.GetContext(c)
+
+	// Papéis e permissões do usuário
+	roles, err := app.QueryUserRoles(ctx, refresh.UserId)
+	if err != nil {
+		return LoginRes{}, err
+	}
+	claimsData := auth.NewClaimsData(refresh.UserId, name, roles)
+	claimsData.SessionId = refresh.FamilyId
+	claimsData.MustChangePassword = mustChange
+	if claimsData.TotpEnrollRequired, err = totpEnrollRequired(ctx, claimsData); err != nil {
+		return LoginRes{}, err
+	}
+
+	// Gerar token de acesso
+	duration := time.Duration(ctx.Config.JwtExpires) * time.Minute
+	expiresAt := time.Now().Add(duration)
+	token, err := auth.GenerateToken(c, claimsData, expiresAt)
+	if err != nil {
+		return LoginRes{}, err
+	}
+
+	// Adicionar cookies e resposta
+	setAuthCookies(c, token, expiresAt, refresh.Token, refresh.ExpiresAt)
+	res := LoginRes{
+		Id:                 refresh.UserId.String(),
+		Name:               name,
+		Admin:              auth.Allows(ctx, claimsData, app.PermContentRead),
+		Roles:              claimsData.Roles,
+		Permissions:        auth.EffectivePermissions(ctx, claimsData),
+		MustChangePassword: mustChange,
+		TotpEnrollRequired: claimsData.TotpEnrollRequired,
+	}
+	// No modo de autenticação por cookies, os tokens não são expostos ao
+	// JavaScript
+	if !ctx.Config.CookieAuth {
+		res.Token = token
+		res.RefreshToken = refresh.Token
+	}
+	return res, nil
+}
+
+// RefreshHandler renova o token de acesso de uma sessão. O refresh token
+// apresentado, no corpo ou no cookie, é trocado por um novo (rotação); o
+// reuso de um token já trocado encerra toda a sessão.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func RefreshHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação e do refresh token
+	ctx := context.GetContext(c)
+	var token string
+	if body, err := BodyUnmarshall[RefreshReq](c); err == nil {
+		token = body.RefreshToken
+	}
+	if token == "" {
+		if cookie, err := c.Cookie(CookieRefresh); err == nil {
+			token = cookie.Value
+		}
+	}
+	if token == "" {
+		return c.JSON(http.StatusUnauthorized, InvalidRefreshTokenMessage)
+	}
+
+	// Rotação
+	refresh, err := app.RotateRefreshToken(ctx, token)
+	if errors.Is(err, app.ErrRefreshTokenReuse) {
+		setAuthCookies(c, "", time.Time{}, "", time.Time{})
+		return c.JSON(http.StatusUnauthorized, RefreshTokenReuseMessage)
+	} else if errors.Is(err, app.ErrInvalidRefreshToken) {
+		setAuthCookies(c, "", time.Time{}, "", time.Time{})
+		return c.JSON(http.StatusUnauthorized, InvalidRefreshTokenMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Dados atuais do usuário
+	user, err := app.QueryUserById(ctx, refresh.UserId)
+	if err != nil {
+		if err = app.RevokeSession(ctx, refresh.FamilyId); err != nil {
+			ctx.Logger.Error("Sessão de usuário inexistente não revogada.", zap.Error(err))
+		}
+		return c.JSON(http.StatusUnauthorized, InvalidRefreshTokenMessage)
+	}
+	if msg, ok := accountErrorMessage(app.CheckAccount(ctx, user)); ok {
+		if err = app.RevokeSession(ctx, refresh.FamilyId); err != nil {
+			ctx.Logger.Error("Sessão de conta inativa não revogada.", zap.Error(err))
+		}
+		setAuthCookies(c, "", time.Time{}, "", time.Time{})
+		return c.JSON(http.StatusForbidden, msg)
+	}
+
+	// Gerar tokens, cookies e resposta
+	res, err := issueTokens(c, user.Name, user.MustChangePassword, refresh)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	res.Message = RefreshSuccessMessage
+	return c.JSON(http.StatusOK, res)
+}
+
+// LogoutHandler encerra a sessão do token de acesso da requisição, revogando
+// seus refresh tokens e, consequentemente, seus tokens de acesso.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func LogoutHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação e da sessão
+	ctx := context.GetContext(c)
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Revogação
+	if err = app.RevokeSession(ctx, claims.SessionId); err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	setAuthCookies(c, "", time.Time{}, "", time.Time{})
+	return c.JSON(http.StatusOK, LogoutSuccessMessage)
+}
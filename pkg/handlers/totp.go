@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"errors"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// totpEnrollRequired verifica se o usuário dos claims é obrigado a usar o
+// segundo fator (Totp.EnforceRoles) e ainda não o cadastrou.
+func totpEnrollRequired(ctx *context.Context, data auth.ClaimsData) (bool, error) {
+	if !app.IsTotpRequired(ctx, data.Id, data.Roles) {
+		return false, nil
+	}
+	enabled, err := app.IsTotpEnabled(ctx, data.Id)
+	return !enabled, err
+}
+
+// LoginTotpHandler conclui o login de um usuário com segundo fator,
+// verificando o código TOTP ou de recuperação junto ao token intermediário
+// emitido por LoginHandler. As falhas contam para o bloqueio de login.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func LoginTotpHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[LoginTotpReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Validar token intermediário e obter usuário
+	userId, err := auth.ParseMfaToken(c, body.MfaToken)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, InvalidMfaTokenMessage)
+	}
+	user, err := app.QueryUserById(ctx, userId)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, InvalidMfaTokenMessage)
+	}
+	if msg, ok := accountErrorMessage(app.CheckAccount(ctx, user)); ok {
+		return c.JSON(http.StatusForbidden, msg)
+	}
+
+	// Verificar bloqueio por falhas anteriores
+	if loginLocked(c, user.Username) {
+		return c.JSON(http.StatusTooManyRequests, LoginLockedMessage)
+	}
+
+	// Verificar código
+	err = app.VerifyTotp(ctx, userId, body.Code)
+	if errors.Is(err, app.ErrInvalidTotpCode) || errors.Is(err, app.ErrTotpNotEnabled) {
+		recordLoginFailure(c, user.Username)
+		return c.JSON(http.StatusUnauthorized, InvalidTotpCodeMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	ctx.Lockout.Success(user.Username)
+
+	// Iniciar sessão, gerar tokens, cookies e resposta
+	res, err := startSession(c, userId, user.Name, user.MustChangePassword)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	res.Message = LoginSuccessMessage
+	return c.JSON(http.StatusOK, res)
+}
+
+// GetTotpStatus obtém a situação do segundo fator do usuário da requisição.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetTotpStatus(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação e do usuário
+	ctx := context.GetContext(c)
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Query
+	enabled, err := app.IsTotpEnabled(ctx, claims.Id)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	return c.JSON(http.StatusOK, TotpStatusRes{
+		Enabled:  enabled,
+		Required: app.IsTotpRequired(ctx, claims.Id, claims.Roles),
+	})
+}
+
+// BeginTotpHandler inicia o cadastro do segundo fator do usuário da
+// requisição, retornando o segredo e a URI de provisionamento. O cadastro é
+// ativado em ConfirmTotpHandler.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func BeginTotpHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação e do usuário
+	ctx := context.GetContext(c)
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	user, err := app.QueryUserById(ctx, claims.Id)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+	}
+
+	// Geração do segredo
+	enrollment, err := app.BeginTotpEnrollment(ctx, claims.Id, user.Username)
+	if errors.Is(err, app.ErrTotpAlreadyEnabled) {
+		return c.JSON(http.StatusConflict, TotpAlreadyEnabledMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	return c.JSON(http.StatusCreated, TotpEnrollmentRes{
+		Secret:  enrollment.Secret,
+		URI:     enrollment.URI,
+		Message: TotpEnrollStartedMessage,
+	})
+}
+
+// ConfirmTotpHandler ativa o segundo fator pendente do usuário da requisição
+// mediante um código do aplicativo autenticador, retornando os códigos de
+// recuperação. Os claims do token de acesso são atualizados na próxima
+// renovação (RefreshHandler).
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func ConfirmTotpHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação, do usuário e do corpo da requisição
+	ctx := context.GetContext(c)
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	body, err := BodyUnmarshall[TotpCodeReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Ativação
+	codes, err := app.ConfirmTotpEnrollment(ctx, claims.Id, body.Code)
+	if errors.Is(err, app.ErrInvalidTotpCode) {
+		return c.JSON(http.StatusBadRequest, InvalidTotpCodeMessage)
+	} else if errors.Is(err, app.ErrTotpNotEnabled) {
+		return c.JSON(http.StatusNotFound, TotpNotEnabledMessage)
+	} else if errors.Is(err, app.ErrTotpAlreadyEnabled) {
+		return c.JSON(http.StatusConflict, TotpAlreadyEnabledMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	RecordAudit(c, app.AuditUpdate, User, claims.Id, nil, map[string]bool{"totp_enabled": true})
+
+	return c.JSON(http.StatusOK, RecoveryCodesRes{
+		RecoveryCodes: codes,
+		Message:       TotpEnabledMessage,
+	})
+}
+
+// DisableTotpHandler remove o segundo fator do usuário da requisição,
+// mediante um código TOTP ou de recuperação. Usuários obrigados ao segundo
+// fator (Totp.EnforceRoles) não podem removê-lo.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func DisableTotpHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Obtenção do contexto da aplicação, do usuário e do corpo da requisição
+	ctx := context.GetContext(c)
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	body, err := BodyUnmarshall[TotpCodeReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+	if app.IsTotpRequired(ctx, claims.Id, claims.Roles) {
+		return c.JSON(http.StatusForbidden, TotpEnforcedMessage)
+	}
+
+	// Verificar código
+	err = app.VerifyTotp(ctx, claims.Id, body.Code)
+	if errors.Is(err, app.ErrTotpNotEnabled) {
+		return c.JSON(http.StatusNotFound, TotpNotEnabledMessage)
+	} else if errors.Is(err, app.ErrInvalidTotpCode) {
+		return c.JSON(http.StatusBadRequest, InvalidTotpCodeMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Remoção
+	if err = app.DisableTotp(ctx, claims.Id); err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	RecordAudit(c, app.AuditUpdate, User, claims.Id, map[string]bool{"totp_enabled": true}, nil)
+
+	return c.JSON(http.StatusOK, TotpDisabledMessage)
+}
+
+// ResetUserTotpHandler remove o segundo fator de um usuário, para os casos
+// de perda do dispositivo e dos códigos de recuperação. Usuários obrigados
+// ao segundo fator devem cadastrá-lo novamente no próximo acesso.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func ResetUserTotpHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Parâmetros da URL e verificar se usuário existe
+	ctx := context.GetContext(c)
+	userId, err := ParseEntityUUID(c, User)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidUserIdMessage)
+	}
+	if !auth.CanManageUser(c, userId) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	if _, err = app.QueryUserById(ctx, userId); err != nil {
+		return c.JSON(http.StatusNotFound, UserNotFoundMessage)
+	}
+
+	// Remoção
+	if err = app.DisableTotp(ctx, userId); err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	ctx.Logger.Warn("Segundo fator removido pelo administrador.", zap.String("user_id", userId.String()))
+	RecordAudit(c, app.AuditUpdate, User, userId, map[string]bool{"totp_enabled": true}, nil)
+
+	return c.JSON(http.StatusOK, TotpDisabledMessage)
+}
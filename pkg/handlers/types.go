@@ -1,6 +1,9 @@
 package handlers
 
-import "github.com/google/uuid"
+import (
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"github.com/google/uuid"
+)
 
 // EntityType define os tipos de entidades possíveis no sistema.
 type EntityType int
@@ -12,8 +15,46 @@ const (
 	Category
 	// File representa um tipo de entidade para arquivos.
 	File
+	// Grant representa um tipo de entidade para concessões de categorias.
+	Grant
+	// Role representa um tipo de entidade para papéis de acesso.
+	Role
+	// Notification representa um tipo de entidade para notificações.
+	Notification
+	// Session representa um tipo de entidade para sessões de usuários.
+	Session
+	// ApiKey representa um tipo de entidade para chaves de API.
+	ApiKey
+	// Webhook representa um tipo de entidade para webhooks.
+	Webhook
 )
 
+// String retorna o nome da entidade, como registrado na auditoria.
+func (e EntityType) String() string {
+	switch e {
+	case User:
+		return "user"
+	case Category:
+		return "category"
+	case File:
+		return "file"
+	case Grant:
+		return "grant"
+	case Role:
+		return "role"
+	case Notification:
+		return "notification"
+	case Session:
+		return "session"
+	case ApiKey:
+		return "api_key"
+	case Webhook:
+		return "webhook"
+	default:
+		return "unknown"
+	}
+}
+
 // LoginReq representa os dados necessários para autenticação de um usuário.
 type LoginReq struct {
 	// Username especifica o nome de usuário para autenticação.
@@ -23,11 +64,42 @@ type LoginReq struct {
 }
 
 type LoginRes struct {
-	Token   string      `json:"token"`
+	Token              string      `json:"token,omitempty"`
+	RefreshToken       string      `json:"refresh_token,omitempty"`
+	Message            HTTPMessage `json:"message"`
+	Id                 string      `json:"id"`
+	Name               string      `json:"name"`
+	Admin              bool        `json:"admin"`
+	Roles              []string    `json:"roles"`
+	Permissions        []string    `json:"permissions"`
+	MustChangePassword bool        `json:"must_change_password"`
+	TotpEnrollRequired bool        `json:"totp_enroll_required"`
+}
+
+// MfaRequiredRes representa a resposta do login de um usuário com segundo
+// fator, que deve ser concluído em LoginTotpHandler.
+type MfaRequiredRes struct {
+	// MfaRequired indica que o código do segundo fator é necessário.
+	MfaRequired bool `json:"mfa_required"`
+	// MfaToken é o token intermediário, válido por poucos minutos.
+	MfaToken string `json:"mfa_token"`
+	// Message é a descrição de retorno da operação.
 	Message HTTPMessage `json:"message"`
-	Id      string      `json:"id"`
-	Name    string      `json:"name"`
-	Admin   bool        `json:"admin"`
+}
+
+// LoginTotpReq representa os dados necessários para concluir o login com o
+// segundo fator.
+type LoginTotpReq struct {
+	// MfaToken especifica o token intermediário recebido no login.
+	MfaToken string `json:"mfa_token" validate:"required"`
+	// Code especifica o código TOTP ou de recuperação.
+	Code string `json:"code" validate:"required"`
+}
+
+// RefreshReq representa os dados necessários para renovar o token de acesso.
+type RefreshReq struct {
+	// RefreshToken especifica o refresh token. Ausente, é lido do cookie.
+	RefreshToken string `json:"refresh_token"`
 }
 
 // CreateUserReq representa os dados necessários para criar um novo usuário.
@@ -38,6 +110,21 @@ type CreateUserReq struct {
 	Name string `json:"name" validate:"required"`
 	// Password especifica a senha do novo usuário.
 	Password string `json:"password" validate:"required"`
+	// MustChangePassword obriga o usuário a trocar a senha no primeiro
+	// acesso.
+	MustChangePassword bool `json:"must_change_password"`
+	// Email especifica o e-mail de contato do novo usuário (opcional).
+	Email string `json:"email"`
+	// Phone especifica o telefone de contato do novo usuário (opcional).
+	Phone string `json:"phone"`
+	// Active define se a conta está ativa. Ausente, a conta é criada ativa.
+	Active *bool `json:"active"`
+	// ValidFrom especifica o início da validade da conta, em tempo Unix
+	// (opcional).
+	ValidFrom int64 `json:"valid_from"`
+	// ValidUntil especifica o fim da validade da conta (ex.: fim do
+	// patrocínio), em tempo Unix (opcional).
+	ValidUntil int64 `json:"valid_until"`
 }
 
 // CreateCategoryReq representa os dados necessários para criar uma nova categoria.
@@ -66,6 +153,179 @@ type UpdateUserReq struct {
 	Name string `json:"name"`
 	// Password especifica a nova senha do usuário.
 	Password string `json:"password"`
+	// MustChangePassword define se o usuário deve trocar a senha no próximo
+	// acesso. Ausente, mantém o valor atual.
+	MustChangePassword *bool `json:"must_change_password"`
+	// Email especifica o novo e-mail de contato. Ausente, mantém o valor
+	// atual; vazio, remove o e-mail.
+	Email *string `json:"email"`
+	// Phone especifica o novo telefone de contato. Ausente, mantém o valor
+	// atual; vazio, remove o telefone.
+	Phone *string `json:"phone"`
+	// Active define se a conta está ativa. A desativação encerra as sessões
+	// do usuário. Ausente, mantém o valor atual.
+	Active *bool `json:"active"`
+	// ValidFrom especifica o início da validade da conta, em tempo Unix.
+	// Ausente, mantém o valor atual; zero, remove o início.
+	ValidFrom *int64 `json:"valid_from"`
+	// ValidUntil especifica o fim da validade da conta, em tempo Unix.
+	// Ausente, mantém o valor atual; zero, remove o fim.
+	ValidUntil *int64 `json:"valid_until"`
+}
+
+// UpdateProfileReq representa os dados do próprio perfil que o usuário pode
+// alterar.
+type UpdateProfileReq struct {
+	// Name especifica o novo nome de apresentação.
+	Name string `json:"name"`
+	// Email especifica o novo e-mail de contato. Ausente, mantém o valor
+	// atual; vazio, remove o e-mail.
+	Email *string `json:"email"`
+	// Phone especifica o novo telefone de contato. Ausente, mantém o valor
+	// atual; vazio, remove o telefone.
+	Phone *string `json:"phone"`
+	// EmailNotifications habilita ou desabilita as notificações por e-mail
+	// dos arquivos novos ou alterados. Ausente, mantém o valor atual.
+	EmailNotifications *bool `json:"email_notifications"`
+}
+
+// EventsTicketRes representa a resposta da emissão de um ticket do fluxo de
+// eventos.
+type EventsTicketRes struct {
+	// Ticket é o ticket, a ser informado no parâmetro "ticket" do fluxo.
+	Ticket string `json:"ticket"`
+	// ExpiresIn é a validade do ticket, em segundos.
+	ExpiresIn int `json:"expires_in"`
+	// Message é a descrição de retorno da operação.
+	Message HTTPMessage `json:"message"`
+}
+
+// PasswordResetRes representa a resposta da geração de um token de
+// redefinição de senha.
+type PasswordResetRes struct {
+	// Token é o token de uso único, a ser enviado ao usuário.
+	Token string `json:"token"`
+	// ExpiresAt é o momento de expiração do token, em segundos Unix.
+	ExpiresAt int64 `json:"expires_at"`
+	// Message é a descrição de retorno da operação.
+	Message HTTPMessage `json:"message"`
+}
+
+// CreateApiKeyReq representa os dados necessários para criar uma chave de
+// API.
+type CreateApiKeyReq struct {
+	// Name especifica o nome descritivo da chave (ex.: o cliente).
+	Name string `json:"name" validate:"required"`
+	// Permissions especifica as permissões concedidas pela chave.
+	Permissions []string `json:"permissions" validate:"required"`
+	// Users especifica os usuários acessíveis pela chave. Vazio permite
+	// todos os usuários.
+	Users []uuid.UUID `json:"users"`
+	// Categories especifica as categorias acessíveis pela chave. Vazio
+	// permite todas as categorias.
+	Categories []uuid.UUID `json:"categories"`
+	// ExpiresAt especifica o momento de expiração da chave, em segundos
+	// Unix. Zero indica chave sem expiração.
+	ExpiresAt int64 `json:"expires_at"`
+}
+
+// ApiKeyRes representa a resposta da criação de uma chave de API.
+type ApiKeyRes struct {
+	// Id é o identificador da chave.
+	Id uuid.UUID `json:"id"`
+	// Key é a chave, exibida apenas na criação.
+	Key string `json:"key"`
+	// Prefix é o prefixo da chave, para identificá-la.
+	Prefix string `json:"prefix"`
+	// Message é a descrição de retorno da operação.
+	Message HTTPMessage `json:"message"`
+}
+
+// CreateWebhookReq representa os dados necessários para cadastrar um
+// webhook.
+type CreateWebhookReq struct {
+	// Url especifica o endereço (http ou https) que recebe os eventos.
+	Url string `json:"url" validate:"required"`
+	// Events especifica os eventos assinados ("*" assina todos).
+	Events []string `json:"events" validate:"required"`
+	// Active define se o webhook recebe os eventos. Ausente, o webhook é
+	// criado ativo.
+	Active *bool `json:"active"`
+}
+
+// UpdateWebhookReq representa os dados de um webhook que podem ser
+// alterados. Campos ausentes mantêm o valor atual.
+type UpdateWebhookReq struct {
+	// Url especifica o novo endereço.
+	Url string `json:"url"`
+	// Events especifica os novos eventos assinados.
+	Events []string `json:"events"`
+	// Active habilita ou desabilita o webhook.
+	Active *bool `json:"active"`
+}
+
+// WebhookRes representa a resposta do cadastro de um webhook.
+type WebhookRes struct {
+	// Id é o identificador do webhook.
+	Id uuid.UUID `json:"id"`
+	// Secret é o segredo da assinatura das entregas, exibido apenas no
+	// cadastro.
+	Secret string `json:"secret"`
+	// Message é a descrição de retorno da operação.
+	Message HTTPMessage `json:"message"`
+}
+
+// RedeemPasswordResetReq representa os dados necessários para redefinir a
+// senha com um token.
+type RedeemPasswordResetReq struct {
+	// Token especifica o token de redefinição recebido do administrador.
+	Token string `json:"token" validate:"required"`
+	// Password especifica a nova senha.
+	Password string `json:"password" validate:"required"`
+}
+
+// ChangePasswordReq representa os dados necessários para o usuário trocar a
+// própria senha.
+type ChangePasswordReq struct {
+	// CurrentPassword especifica a senha atual.
+	CurrentPassword string `json:"current_password" validate:"required"`
+	// NewPassword especifica a nova senha.
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// TotpCodeReq representa os dados necessários para confirmar ou remover o
+// segundo fator.
+type TotpCodeReq struct {
+	// Code especifica o código TOTP ou de recuperação.
+	Code string `json:"code" validate:"required"`
+}
+
+// TotpStatusRes representa a situação do segundo fator de um usuário.
+type TotpStatusRes struct {
+	// Enabled indica se o segundo fator está ativo.
+	Enabled bool `json:"enabled"`
+	// Required indica se o segundo fator é obrigatório para o usuário.
+	Required bool `json:"required"`
+}
+
+// TotpEnrollmentRes representa a resposta do início do cadastro do segundo
+// fator.
+type TotpEnrollmentRes struct {
+	// Secret é o segredo compartilhado, em base32, para cadastro manual.
+	Secret string `json:"secret"`
+	// URI é a URI de provisionamento (otpauth://), exibida como QR code.
+	URI string `json:"uri"`
+	// Message é a descrição de retorno da operação.
+	Message HTTPMessage `json:"message"`
+}
+
+// RecoveryCodesRes representa a resposta da ativação do segundo fator, com
+// os códigos de recuperação exibidos uma única vez.
+type RecoveryCodesRes struct {
+	// RecoveryCodes são os códigos de recuperação, de uso único.
+	RecoveryCodes []string `json:"recovery_codes"`
+	// Message é a descrição de retorno da operação.
+	Message HTTPMessage `json:"message"`
 }
 
 // UpdateCategoryReq representa os dados necessários para atualizar uma categoria.
@@ -98,3 +358,120 @@ type CreateResponse struct {
 	// Message é a descrição de retorno da operação.
 	Message HTTPMessage `json:"message"`
 }
+
+// FieldErrorsRes representa a resposta de uma requisição com campos
+// inválidos, com as mensagens de erro de cada campo.
+type FieldErrorsRes struct {
+	// Message é a descrição de retorno da operação.
+	Message HTTPMessage `json:"message"`
+	// Fields associa cada campo inválido às suas mensagens de erro.
+	Fields map[string][]string `json:"fields"`
+}
+
+// BatchOperationReq representa uma operação de um lote.
+type BatchOperationReq struct {
+	// Op especifica a operação: move, rename ou delete.
+	Op string `json:"op" validate:"required,oneof=move rename delete"`
+	// Entity especifica o tipo da entidade: file ou category.
+	Entity string `json:"entity" validate:"required,oneof=file category"`
+	// Id especifica o ID da entidade.
+	Id string `json:"id" validate:"required"`
+	// TargetId especifica o destino de uma movimentação: o ID da categoria,
+	// para arquivos, ou o ID do usuário, para categorias.
+	TargetId string `json:"target_id" validate:"required_if=Op move"`
+	// Name especifica o novo nome de uma renomeação.
+	Name string `json:"name" validate:"required_if=Op rename"`
+	// Version especifica a versão (updated_at) esperada da entidade. Zero ou
+	// ausente indica operação incondicional.
+	Version int64 `json:"version"`
+}
+
+// BatchReq representa os dados necessários para executar um lote de
+// operações.
+type BatchReq struct {
+	// Operations especifica as operações do lote, executadas em ordem.
+	Operations []BatchOperationReq `json:"operations" validate:"required,min=1,max=500,dive"`
+}
+
+// BatchItemRes representa o resultado de uma operação de um lote.
+type BatchItemRes struct {
+	// Index é a posição da operação no lote.
+	Index int `json:"index"`
+	// Op é a operação solicitada.
+	Op string `json:"op"`
+	// Entity é o tipo da entidade.
+	Entity string `json:"entity"`
+	// Id é o identificador da entidade.
+	Id string `json:"id"`
+	// Status é a situação da operação: ok, failed, rolled_back ou skipped.
+	Status string `json:"status"`
+	// Message é a descrição da falha da operação, quando houver.
+	Message HTTPMessage `json:"message,omitempty"`
+}
+
+// BatchRes representa a resposta da execução de um lote de operações.
+type BatchRes struct {
+	// Message é a descrição de retorno do lote.
+	Message HTTPMessage `json:"message"`
+	// Results são os resultados de cada operação, na ordem do lote.
+	Results []BatchItemRes `json:"results"`
+}
+
+// CopyCategoryReq representa os dados necessários para copiar uma categoria
+// para outros usuários.
+type CopyCategoryReq struct {
+	// UserIds especifica os IDs dos usuários destino das cópias.
+	UserIds []string `json:"user_ids" validate:"required,min=1,max=500,dive,required"`
+}
+
+// CopyCategoryRes representa a resposta retornada após a cópia de uma
+// categoria.
+type CopyCategoryRes struct {
+	// Message é a descrição de retorno da operação.
+	Message HTTPMessage `json:"message"`
+	// Copies são as categorias e arquivos criados para cada usuário.
+	Copies []db.CategoryCopy `json:"copies"`
+}
+
+// CreateGrantReq representa os dados necessários para compartilhar uma
+// categoria. Deve ser informado um usuário ou o acesso para todos.
+type CreateGrantReq struct {
+	// UserId especifica o ID do usuário com acesso à categoria.
+	UserId string `json:"user_id" validate:"required_without=All,excluded_with=All"`
+	// All especifica o acesso para todos os usuários.
+	All bool `json:"all"`
+}
+
+// CreateRoleReq representa os dados necessários para criar um papel.
+type CreateRoleReq struct {
+	// Name especifica o nome do novo papel.
+	Name string `json:"name" validate:"required"`
+	// Permissions especifica as permissões concedidas pelo novo papel.
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+// UpdateRoleReq representa os dados necessários para atualizar um papel.
+type UpdateRoleReq struct {
+	// Name especifica o novo nome do papel.
+	Name string `json:"name"`
+	// Permissions especifica as novas permissões do papel. Ausente mantém as
+	// permissões atuais.
+	Permissions *[]string `json:"permissions"`
+}
+
+// SetUserRolesReq representa os dados necessários para atribuir papéis a um
+// usuário.
+type SetUserRolesReq struct {
+	// RoleIds especifica os IDs dos papéis atribuídos. Vazio remove todos.
+	RoleIds []string `json:"role_ids" validate:"required"`
+}
+
+// SetInboxReq representa as regras de uma caixa de entrada.
+type SetInboxReq struct {
+	// MaxFileSize especifica o tamanho máximo, em bytes, de cada arquivo
+	// enviado. Zero ou ausente indica sem limite.
+	MaxFileSize int64 `json:"max_file_size" validate:"gte=0"`
+	// AllowedTypes especifica as extensões (ex.: "pdf") e tipos MIME (ex.:
+	// "image/*") aceitos. Vazio ou ausente aceita qualquer tipo.
+	AllowedTypes []string `json:"allowed_types" validate:"max=100,dive,required"`
+}
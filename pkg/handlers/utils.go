@@ -1,16 +1,34 @@
 package handlers
 
 import (
+	"agros_arquivos_patrocinadoras/pkg/app"
 	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"net/http"
+	"strconv"
+	"strings"
 )
 
+// Cabeçalhos HTTP de controle de concorrência otimista.
+const (
+	// HeaderETag é o cabeçalho com a versão atual de uma entidade.
+	HeaderETag = "ETag"
+	// HeaderIfMatch é o cabeçalho com a versão esperada de uma entidade em
+	// alterações e exclusões.
+	HeaderIfMatch = "If-Match"
+)
+
+// ErrMissingIfMatch indica que a requisição não informou o cabeçalho If-Match.
+var ErrMissingIfMatch = errors.New("cabeçalho If-Match ausente")
+
 // BodyUnmarshall realiza o desagrupamento (unmarshal) do corpo da requisição
 // para uma estrutura genérica.
 //
@@ -100,6 +118,18 @@ func ParseEntityUUID(c echo.Context, entityType EntityType) (uuid.UUID, error) {
 		param = c.Param("categId")
 	case File:
 		param = c.Param("fileId")
+	case Grant:
+		param = c.Param("grantId")
+	case Role:
+		param = c.Param("roleId")
+	case Notification:
+		param = c.Param("notificationId")
+	case Session:
+		param = c.Param("sessionId")
+	case ApiKey:
+		param = c.Param("keyId")
+	case Webhook:
+		param = c.Param("webhookId")
 	default:
 		return uuid.Nil, fmt.Errorf("entidade %d não suportada", entityType)
 	}
@@ -110,3 +140,137 @@ func ParseEntityUUID(c echo.Context, entityType EntityType) (uuid.UUID, error) {
 	}
 	return id, nil
 }
+
+// ParseReportPeriod realiza o parse do intervalo de tempo de um relatório a
+// partir dos parâmetros de consulta "from" e "to" da requisição HTTP, ambos
+// em tempo Unix (segundos) e opcionais.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//
+// Retornos:
+//   - app.ReportPeriod: o intervalo extraído da requisição.
+//   - error: erro, caso algum dos parâmetros seja inválido.
+func ParseReportPeriod(c echo.Context) (app.ReportPeriod, error) {
+	var period app.ReportPeriod
+	var err error
+
+	if from := c.QueryParam("from"); from != "" {
+		if period.From, err = strconv.ParseInt(from, 10, 64); err != nil || period.From < 0 {
+			return period, fmt.Errorf("início do período inválido")
+		}
+	}
+	if to := c.QueryParam("to"); to != "" {
+		if period.To, err = strconv.ParseInt(to, 10, 64); err != nil || period.To < 0 {
+			return period, fmt.Errorf("fim do período inválido")
+		}
+	}
+	if period.From > 0 && period.To > 0 && period.From > period.To {
+		return period, fmt.Errorf("início do período posterior ao fim")
+	}
+	return period, nil
+}
+
+// WantsCSV indica se a requisição HTTP solicitou a resposta no formato CSV,
+// através do parâmetro de consulta "format".
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//
+// Retorno:
+//   - bool: true caso o formato CSV tenha sido solicitado.
+func WantsCSV(c echo.Context) bool {
+	return c.QueryParam("format") == "csv"
+}
+
+// WriteCSV escreve uma resposta HTTP no formato CSV, como anexo para
+// download.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - filename: nome do arquivo sugerido ao cliente.
+//   - header: cabeçalho das colunas do CSV.
+//   - records: linhas do CSV.
+//
+// Retorno:
+//   - error: erro, caso ocorra falha na escrita da resposta.
+func WriteCSV(c echo.Context, filename string, header []string, records [][]string) error {
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+	res.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(res)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	if err := w.WriteAll(records); err != nil {
+		return err
+	}
+	return w.Error()
+}
+
+// SetETag define o cabeçalho ETag da resposta a partir da versão (UpdatedAt)
+// de uma entidade.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - version: versão (UpdatedAt) da entidade.
+func SetETag(c echo.Context, version int64) {
+	c.Response().Header().Set(HeaderETag, `"`+strconv.FormatInt(version, 10)+`"`)
+}
+
+// ParseIfMatch realiza o parse da versão esperada de uma entidade a partir do
+// cabeçalho If-Match da requisição HTTP. O valor "*" indica uma alteração
+// incondicional e resulta na versão zero.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//
+// Retornos:
+//   - int64: a versão (UpdatedAt) esperada da entidade.
+//   - error: ErrMissingIfMatch, caso o cabeçalho não tenha sido enviado, ou
+//     erro de formato, caso o valor não corresponda a um ETag válido.
+func ParseIfMatch(c echo.Context) (int64, error) {
+	value := strings.TrimSpace(c.Request().Header.Get(HeaderIfMatch))
+	if value == "" {
+		return 0, ErrMissingIfMatch
+	}
+	if value == "*" {
+		return 0, nil
+	}
+
+	unquoted, ok := strings.CutPrefix(value, `"`)
+	if !ok || !strings.HasSuffix(unquoted, `"`) {
+		return 0, fmt.Errorf("ETag inválido: %s", value)
+	}
+	version, err := strconv.ParseInt(strings.TrimSuffix(unquoted, `"`), 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("ETag inválido: %s", value)
+	}
+	return version, nil
+}
+
+// truncate limita um texto a n runas, para armazenamento em colunas de
+// tamanho limitado.
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// NewPasswordErrorRes cria a resposta de uma senha que não atende à política
+// de senhas, com as regras violadas no campo "password".
+//
+// Parâmetros:
+//   - err: erro de validação retornado por app.ValidatePassword.
+//
+// Retorno:
+//   - FieldErrorsRes: a resposta com a mensagem e as regras violadas.
+func NewPasswordErrorRes(err *app.PasswordPolicyError) FieldErrorsRes {
+	return FieldErrorsRes{
+		Message: InvalidPasswordMessage,
+		Fields:  map[string][]string{"password": err.Violations},
+	}
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"net/http"
+	"strconv"
+)
+
+// Limites de paginação da consulta das entregas dos webhooks.
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 500
+)
+
+// webhookEventName obtém o nome do evento publicado aos webhooks para uma
+// ação registrada na auditoria (ex.: "category.deleted"). A criação de um
+// arquivo é publicada como EventFileUploaded.
+func webhookEventName(action string, entity EntityType) string {
+	if entity == File && action == app.AuditCreate {
+		return app.EventFileUploaded
+	}
+	switch action {
+	case app.AuditCreate:
+		return entity.String() + ".created"
+	case app.AuditUpdate:
+		return entity.String() + ".updated"
+	case app.AuditDelete:
+		return entity.String() + ".deleted"
+	}
+	return ""
+}
+
+// PublishEvent publica aos webhooks um evento gerado pelo usuário da
+// requisição. Os metadados da entidade são enviados sem senhas e conteúdos,
+// como na auditoria.
+//
+// Parâmetros:
+//   - c: contexto da requisição HTTP (do pacote echo).
+//   - event: nome do evento (ex.: app.EventFileDownloaded).
+//   - entityId: identificador da entidade afetada.
+//   - entity: metadados da entidade (nil para nenhum).
+func PublishEvent(c echo.Context, event string, entityId uuid.UUID, entity any) {
+	ctx := context.GetContext(c)
+	actorId := uuid.Nil
+	if claims, err := auth.GetClaims(c); err == nil {
+		actorId = claims.Id
+	}
+	app.PublishEvent(ctx, app.WebhookEvent{
+		Name:     event,
+		ActorId:  actorId,
+		EntityId: entityId,
+		Data:     metadataMap(entity),
+	})
+}
+
+// webhookErrorMessage obtém a mensagem HTTP de um erro de validação de um
+// webhook.
+func webhookErrorMessage(err error) (HTTPMessage, bool) {
+	switch {
+	case errors.Is(err, app.ErrInvalidWebhookUrl):
+		return InvalidWebhookUrlMessage, true
+	case errors.Is(err, app.ErrInvalidWebhookEvent):
+		return InvalidWebhookEventMessage, true
+	}
+	return "", false
+}
+
+// CreateWebhookHandler cadastra um webhook, que passa a receber os eventos
+// assinados (ex.: "file.uploaded") em um POST JSON assinado com o segredo
+// retornado.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func CreateWebhookHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+	claims, err := auth.GetClaims(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[CreateWebhookReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Criação
+	data := app.WebhookData{Url: body.Url, Events: body.Events, Active: body.Active}
+	hook, err := app.CreateWebhook(ctx, data, claims.Id)
+	if msg, ok := webhookErrorMessage(err); ok {
+		return c.JSON(http.StatusBadRequest, msg)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Auditoria
+	after, _ := app.QueryWebhookById(ctx, hook.WebhookId)
+	RecordAudit(c, app.AuditCreate, Webhook, hook.WebhookId, nil, after)
+
+	return c.JSON(http.StatusCreated, WebhookRes{
+		Id:      hook.WebhookId,
+		Secret:  hook.Secret,
+		Message: CreatedWebhookMessage,
+	})
+}
+
+// GetAllWebhooks obtém todos os webhooks. Os segredos não são retornados.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetAllWebhooks(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersRead) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção dos webhooks
+	ctx := context.GetContext(c)
+	hooks, err := app.QueryAllWebhooks(ctx)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, WebhooksNotFoundMessage)
+	}
+	return c.JSON(http.StatusOK, hooks)
+}
+
+// UpdateWebhookHandler altera o endereço, os eventos ou o estado de um
+// webhook.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func UpdateWebhookHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Obtenção do contexto da aplicação e do corpo da requisição
+	ctx := context.GetContext(c)
+	body, err := BodyUnmarshall[UpdateWebhookReq](c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Caso nada seja requisitado para alterar
+	if body.Url == "" && body.Events == nil && body.Active == nil {
+		return c.JSON(http.StatusBadRequest, BadRequestMessage)
+	}
+
+	// Parâmetro da URL e webhook antes da alteração
+	webhookId, err := ParseEntityUUID(c, Webhook)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidWebhookIdMessage)
+	}
+	before, err := app.QueryWebhookById(ctx, webhookId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, WebhookNotFoundMessage)
+	}
+
+	// Verificar versão esperada (If-Match)
+	version, err := ParseIfMatch(c)
+	if errors.Is(err, ErrMissingIfMatch) {
+		return c.JSON(http.StatusPreconditionRequired, PreconditionRequiredMessage)
+	} else if err != nil || (version != 0 && version != before.UpdatedAt) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	}
+
+	// Alteração
+	data := app.WebhookData{Url: body.Url, Events: body.Events, Active: body.Active}
+	err = app.UpdateWebhook(ctx, webhookId, version, data)
+	if msg, ok := webhookErrorMessage(err); ok {
+		return c.JSON(http.StatusBadRequest, msg)
+	} else if errors.Is(err, app.ErrEntityNotFound) {
+		return c.JSON(http.StatusNotFound, WebhookNotFoundMessage)
+	} else if errors.Is(err, app.ErrVersionConflict) {
+		return c.JSON(http.StatusPreconditionFailed, VersionConflictMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+
+	// Auditoria
+	after, _ := app.QueryWebhookById(ctx, webhookId)
+	RecordAudit(c, app.AuditUpdate, Webhook, webhookId, before, after)
+	SetETag(c, after.UpdatedAt)
+	return c.JSON(http.StatusOK, UpdatedWebhookMessage)
+}
+
+// DeleteWebhookHandler exclui um webhook e o registro de suas entregas.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func DeleteWebhookHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Parâmetro da URL e webhook antes da exclusão
+	ctx := context.GetContext(c)
+	webhookId, err := ParseEntityUUID(c, Webhook)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidWebhookIdMessage)
+	}
+	before, _ := app.QueryWebhookById(ctx, webhookId)
+
+	// Exclusão
+	err = app.DeleteWebhook(ctx, webhookId)
+	if errors.Is(err, app.ErrEntityNotFound) {
+		return c.JSON(http.StatusNotFound, WebhookNotFoundMessage)
+	} else if err != nil {
+		return c.JSON(http.StatusInternalServerError, InternalServerErrorMessage)
+	}
+	RecordAudit(c, app.AuditDelete, Webhook, webhookId, before, nil)
+
+	return c.JSON(http.StatusOK, DeletedWebhookMessage)
+}
+
+// GetWebhookDeliveries obtém as entregas mais recentes de um webhook. O
+// parâmetro de consulta "limit" define o número de entregas (padrão: 50).
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func GetWebhookDeliveries(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersRead) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Parâmetros da URL e da consulta
+	ctx := context.GetContext(c)
+	webhookId, err := ParseEntityUUID(c, Webhook)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidWebhookIdMessage)
+	}
+	limit := defaultDeliveryLimit
+	if v := c.QueryParam("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 || limit > maxDeliveryLimit {
+			return c.JSON(http.StatusBadRequest, BadRequestMessage)
+		}
+	}
+	if _, err = app.QueryWebhookById(ctx, webhookId); err != nil {
+		return c.JSON(http.StatusNotFound, WebhookNotFoundMessage)
+	}
+
+	// Obtenção das entregas
+	deliveries, err := app.QueryWebhookDeliveries(ctx, webhookId, limit)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, DeliveriesNotFoundMessage)
+	}
+	return c.JSON(http.StatusOK, deliveries)
+}
+
+// TestWebhookHandler envia o evento app.EventWebhookTest a um webhook,
+// mesmo inativo ou sem assiná-lo, em uma única tentativa, e retorna o
+// registro da entrega.
+//
+// Parâmetros:
+//   - c: contexto Echo contendo as informações da requisição HTTP.
+//
+// Retorno:
+//   - error: um erro HTTP apropriado em caso de falha ou nil caso o processo
+//     seja bem-sucedido.
+func TestWebhookHandler(c echo.Context) error {
+	// Cabeçalho
+	c.Response().Header().Add(echo.HeaderContentType, echo.MIMEApplicationJSON)
+
+	// Checar permissão
+	if !auth.HasPermission(c, app.PermUsersWrite) {
+		return c.JSON(http.StatusUnauthorized, UnauthorizedMessage)
+	}
+
+	// Parâmetro da URL
+	ctx := context.GetContext(c)
+	webhookId, err := ParseEntityUUID(c, Webhook)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, InvalidWebhookIdMessage)
+	}
+	hook, err := app.QueryWebhookById(ctx, webhookId)
+	if err != nil {
+		return c.JSON(http.StatusNotFound, WebhookNotFoundMessage)
+	}
+
+	// Entrega
+	actorId := uuid.Nil
+	if claims, err := auth.GetClaims(c); err == nil {
+		actorId = claims.Id
+	}
+	return c.JSON(http.StatusOK, app.TestWebhook(ctx, hook, actorId))
+}
@@ -20,8 +20,41 @@ type Config struct {
 	Database Database `json:"database" validate:"required"`
 	// JwtSecret define a chave secreta usada para geração e validação de tokens JWT.
 	JwtSecret string `json:"jwt_secret" validate:"required"`
-	// JwtExpires define, em minutos, o tempo de expiração para o token JWT.
+	// JwtKeys define as chaves assimétricas de assinatura dos tokens JWT.
+	JwtKeys JwtKeys `json:"jwt_keys"`
+	// JwtExpires define, em minutos, o tempo de expiração para o token JWT de
+	// acesso. Deve ser curto, pois o token é renovado pelo refresh token.
 	JwtExpires int `json:"jwt_expires" validate:"required"`
+	// RefreshExpires define, em minutos, o tempo de expiração para o refresh
+	// token (padrão: 7 dias).
+	RefreshExpires int `json:"refresh_expires"`
+	// ResetExpires define, em minutos, o tempo de expiração dos tokens de
+	// redefinição de senha (padrão: 24 horas).
+	ResetExpires int `json:"reset_expires"`
+	// AccountExpiryInterval define, em minutos, o intervalo da desativação
+	// das contas com validade encerrada (padrão: 60).
+	AccountExpiryInterval int `json:"account_expiry_interval"`
+	// CookieAuth define o modo de autenticação apenas por cookies: os tokens
+	// não são retornados no corpo das respostas, são lidos somente de cookies
+	// HttpOnly e as requisições POST/PUT/PATCH/DELETE exigem o token CSRF.
+	// Os cookies são sempre Secure, exigindo HTTPS (ou um proxy com TLS).
+	CookieAuth bool `json:"cookie_auth"`
+	// Lockout define a política de bloqueio contra força bruta no login.
+	Lockout Lockout `json:"lockout"`
+	// PasswordPolicy define a política de senhas dos usuários.
+	PasswordPolicy PasswordPolicy `json:"password_policy"`
+	// PasswordHash define o algoritmo e os parâmetros do hash das senhas.
+	PasswordHash PasswordHash `json:"password_hash"`
+	// Totp define a autenticação em dois fatores por TOTP.
+	Totp Totp `json:"totp"`
+	// Oidc define o login único (SSO) por OpenID Connect.
+	Oidc Oidc `json:"oidc"`
+	// Ldap define a autenticação do login por LDAP.
+	Ldap Ldap `json:"ldap"`
+	// Smtp define as notificações por e-mail dos arquivos novos ou alterados.
+	Smtp Smtp `json:"smtp"`
+	// Webhooks define a entrega dos eventos aos webhooks.
+	Webhooks Webhooks `json:"webhooks"`
 	// EnableTLS define se o servidor usará TLS ou não.
 	EnableTLS bool `json:"enable_tls"`
 	// CertFile contém o caminho para o arquivo de certificado SSL (opcional).
@@ -30,6 +63,197 @@ type Config struct {
 	KeyFile string `json:"key_file"`
 }
 
+// JwtKeys representa as chaves assimétricas de assinatura e verificação dos
+// tokens JWT. Sem chave de assinatura, os tokens são assinados com HS256 e
+// JwtSecret.
+type JwtKeys struct {
+	// SigningKey define o identificador (kid) da chave de assinatura dos
+	// novos tokens.
+	SigningKey string `json:"signing_key"`
+	// AcceptHs256 aceita os tokens HS256 (JwtSecret), sem kid, enquanto a
+	// chave de assinatura é assimétrica.
+	AcceptHs256 bool `json:"accept_hs256"`
+	// Keys define as chaves de verificação ativas.
+	Keys []JwtKey `json:"keys"`
+}
+
+// JwtKey representa uma chave de assinatura ou verificação dos tokens JWT.
+type JwtKey struct {
+	// Id define o identificador da chave (kid).
+	Id string `json:"kid"`
+	// Algorithm define o algoritmo da chave: RS256 ou EdDSA.
+	Algorithm string `json:"algorithm"`
+	// PrivateKeyFile define o arquivo PEM da chave privada.
+	PrivateKeyFile string `json:"private_key_file"`
+	// PublicKeyFile define o arquivo PEM da chave pública, para chaves apenas
+	// de verificação.
+	PublicKeyFile string `json:"public_key_file"`
+}
+
+// Lockout representa a política de bloqueio temporário de login após falhas
+// consecutivas.
+type Lockout struct {
+	// MaxAttempts define o número de falhas de um usuário antes do bloqueio
+	// (padrão: 5).
+	MaxAttempts int `json:"max_attempts"`
+	// IpMaxAttempts define o número de falhas de um IP, em qualquer usuário,
+	// antes do bloqueio (padrão: 20).
+	IpMaxAttempts int `json:"ip_max_attempts"`
+	// BaseDelay define, em segundos, a duração do primeiro bloqueio, que
+	// dobra a cada nova falha (padrão: 30).
+	BaseDelay int `json:"base_delay"`
+	// MaxDelay define, em segundos, a duração máxima de um bloqueio e o tempo
+	// após o qual as falhas são esquecidas (padrão: 900).
+	MaxDelay int `json:"max_delay"`
+}
+
+// PasswordPolicy representa os requisitos das senhas definidas para os
+// usuários, inclusive a do administrador.
+type PasswordPolicy struct {
+	// MinLength define o número mínimo de caracteres (padrão: 4).
+	MinLength int `json:"min_length"`
+	// RequireUpper exige ao menos uma letra maiúscula.
+	RequireUpper bool `json:"require_upper"`
+	// RequireLower exige ao menos uma letra minúscula.
+	RequireLower bool `json:"require_lower"`
+	// RequireDigit exige ao menos um número.
+	RequireDigit bool `json:"require_digit"`
+	// RequireSymbol exige ao menos um caractere especial.
+	RequireSymbol bool `json:"require_symbol"`
+	// Banned contém as senhas comuns proibidas, comparadas sem diferenciar
+	// maiúsculas e minúsculas.
+	Banned []string `json:"banned"`
+}
+
+// PasswordHash representa o algoritmo e os parâmetros do hash das senhas.
+type PasswordHash struct {
+	// Algorithm define o algoritmo dos novos hashes: "argon2id" ou "bcrypt".
+	Algorithm string `json:"algorithm"`
+	// Argon2Memory define a memória do argon2id, em KiB.
+	Argon2Memory uint32 `json:"argon2_memory"`
+	// Argon2Iterations define o número de passadas do argon2id.
+	Argon2Iterations uint32 `json:"argon2_iterations"`
+	// Argon2Parallelism define o paralelismo do argon2id.
+	Argon2Parallelism uint8 `json:"argon2_parallelism"`
+	// BcryptCost define o custo do bcrypt.
+	BcryptCost int `json:"bcrypt_cost"`
+}
+
+// Totp representa a configuração da autenticação em dois fatores por TOTP
+// (RFC 6238).
+type Totp struct {
+	// Issuer define o emissor exibido nos aplicativos autenticadores
+	// (padrão: "Agros Arquivos").
+	Issuer string `json:"issuer"`
+	// EnforceRoles define os papéis cujos usuários são obrigados a usar o
+	// segundo fator (ex.: ["admin"]). O papel "admin" inclui o administrador
+	// configurado.
+	EnforceRoles []string `json:"enforce_roles"`
+}
+
+// Oidc representa a configuração do login único (SSO) por OpenID Connect,
+// com código de autorização e PKCE.
+type Oidc struct {
+	// Enabled habilita o login por OpenID Connect.
+	Enabled bool `json:"enabled"`
+	// Issuer define a URL do emissor do provedor de identidade, usada na
+	// descoberta (/.well-known/openid-configuration).
+	Issuer string `json:"issuer"`
+	// ClientId define o identificador do cliente registrado no provedor.
+	ClientId string `json:"client_id"`
+	// ClientSecret define o segredo do cliente. Vazio indica cliente público.
+	ClientSecret string `json:"client_secret"`
+	// RedirectUrl define a URL de retorno (/oidc/callback) registrada no
+	// provedor.
+	RedirectUrl string `json:"redirect_url"`
+	// Scopes define os escopos solicitados (padrão: openid, email e
+	// profile).
+	Scopes []string `json:"scopes"`
+	// AutoProvision cria automaticamente os usuários sem vínculo.
+	AutoProvision bool `json:"auto_provision"`
+	// PostLoginRedirect define o endereço do frontend após o login (padrão:
+	// "/").
+	PostLoginRedirect string `json:"post_login_redirect"`
+}
+
+// Ldap representa a configuração da autenticação do login por bind simples
+// em um servidor LDAP.
+type Ldap struct {
+	// Enabled habilita a autenticação por LDAP.
+	Enabled bool `json:"enabled"`
+	// Url define a URL do servidor (ldap:// ou ldaps://).
+	Url string `json:"url"`
+	// StartTls usa StartTLS em conexões ldap://.
+	StartTls bool `json:"start_tls"`
+	// InsecureSkipVerify não verifica o certificado do servidor.
+	InsecureSkipVerify bool `json:"insecure_skip_verify"`
+	// Timeout define o tempo limite das operações, em segundos.
+	Timeout int `json:"timeout"`
+	// UserDnTemplate define o modelo do DN do usuário, com o marcador
+	// {username}.
+	UserDnTemplate string `json:"user_dn_template"`
+	// GroupBaseDn define o DN base da busca dos grupos. Vazio desabilita o
+	// mapeamento de grupos.
+	GroupBaseDn string `json:"group_base_dn"`
+	// GroupFilter define o filtro da busca dos grupos, com os marcadores
+	// {dn} e {username}.
+	GroupFilter string `json:"group_filter"`
+	// GroupRoles mapeia o nome (cn) de cada grupo para o nome de um papel.
+	GroupRoles map[string]string `json:"group_roles"`
+	// AutoProvision cria os usuários autenticados sem cadastro local.
+	AutoProvision bool `json:"auto_provision"`
+	// LocalFallback verifica a senha local quando o bind falha.
+	LocalFallback bool `json:"local_fallback"`
+}
+
+// Smtp representa a configuração do envio, por SMTP, das notificações por
+// e-mail dos arquivos novos ou alterados nas categorias de um usuário.
+type Smtp struct {
+	// Enabled habilita as notificações por e-mail.
+	Enabled bool `json:"enabled"`
+	// Host define o endereço do servidor SMTP.
+	Host string `json:"host"`
+	// Port define a porta do servidor SMTP.
+	Port int `json:"port"`
+	// Username define o usuário da autenticação. Vazio desabilita a
+	// autenticação.
+	Username string `json:"username"`
+	// Password define a senha da autenticação.
+	Password string `json:"password"`
+	// From define o remetente dos e-mails.
+	From string `json:"from"`
+	// StartTls usa StartTLS na conexão com o servidor.
+	StartTls bool `json:"start_tls"`
+	// Timeout define o tempo limite da conexão, em segundos.
+	Timeout int `json:"timeout"`
+	// BatchWindow define, em segundos, a janela de agrupamento dos arquivos
+	// de um usuário em um único e-mail.
+	BatchWindow int `json:"batch_window"`
+	// BaseUrl define o endereço do frontend incluído nos e-mails.
+	BaseUrl string `json:"base_url"`
+}
+
+// Webhooks representa a configuração da entrega dos eventos (ex.:
+// "file.uploaded") aos webhooks cadastrados pelos administradores.
+type Webhooks struct {
+	// MaxAttempts define o número máximo de tentativas de cada entrega.
+	MaxAttempts int `json:"max_attempts"`
+	// BaseDelay define, em segundos, o intervalo antes da segunda tentativa,
+	// dobrado a cada nova tentativa.
+	BaseDelay int `json:"base_delay"`
+	// Timeout define o tempo limite de cada tentativa, em segundos.
+	Timeout int `json:"timeout"`
+	// Workers define o número de entregas simultâneas.
+	Workers int `json:"workers"`
+	// QueueSize define a capacidade da fila de entregas. Os eventos
+	// excedentes são descartados.
+	QueueSize int `json:"queue_size"`
+	// AllowedHosts define os hosts (nomes ou endereços IP) permitidos como
+	// destino mesmo na rede interna (ex.: loopback ou redes privadas), que
+	// é recusada aos demais webhooks.
+	AllowedHosts []string `json:"allowed_hosts"`
+}
+
 // Database representa as configurações de conexão e credenciais do banco de
 // dados.
 type Database struct {
@@ -57,6 +281,57 @@ type Schema struct {
 	CategTable Table[CategTable] `json:"categ_table" validate:"required"`
 	// FileTable representa a configuração da tabela de arquivos no esquema.
 	FileTable Table[FileTable] `json:"file_table" validate:"required"`
+	// DownloadTable representa a configuração da tabela de downloads no
+	// esquema.
+	DownloadTable Table[DownloadTable] `json:"download_table" validate:"required"`
+	// AuditTable representa a configuração da tabela de auditoria no esquema.
+	AuditTable Table[AuditTable] `json:"audit_table" validate:"required"`
+	// CategGrantTable representa a configuração da tabela de concessões de
+	// categorias no esquema.
+	CategGrantTable Table[CategGrantTable] `json:"categ_grant_table" validate:"required"`
+	// RoleTable representa a configuração da tabela de papéis no esquema.
+	RoleTable Table[RoleTable] `json:"role_table" validate:"required"`
+	// RolePermissionTable representa a configuração da tabela de permissões
+	// dos papéis no esquema.
+	RolePermissionTable Table[RolePermissionTable] `json:"role_permission_table" validate:"required"`
+	// UserRoleTable representa a configuração da tabela de papéis dos
+	// usuários no esquema.
+	UserRoleTable Table[UserRoleTable] `json:"user_role_table" validate:"required"`
+	// InboxTable representa a configuração da tabela de caixas de entrada
+	// (categorias que aceitam envios dos usuários) no esquema.
+	InboxTable Table[InboxTable] `json:"inbox_table" validate:"required"`
+	// NotificationTable representa a configuração da tabela de notificações
+	// no esquema.
+	NotificationTable Table[NotificationTable] `json:"notification_table" validate:"required"`
+	// RefreshTokenTable representa a configuração da tabela de refresh
+	// tokens no esquema.
+	RefreshTokenTable Table[RefreshTokenTable] `json:"refresh_token_table" validate:"required"`
+	// SessionTable representa a configuração da tabela de sessões no esquema.
+	SessionTable Table[SessionTable] `json:"session_table" validate:"required"`
+	// PasswordResetTable representa a configuração da tabela de tokens de
+	// redefinição de senha no esquema.
+	PasswordResetTable Table[PasswordResetTable] `json:"password_reset_table" validate:"required"`
+	// TotpTable representa a configuração da tabela de segredos TOTP no
+	// esquema.
+	TotpTable Table[TotpTable] `json:"totp_table" validate:"required"`
+	// RecoveryCodeTable representa a configuração da tabela de códigos de
+	// recuperação do segundo fator no esquema.
+	RecoveryCodeTable Table[RecoveryCodeTable] `json:"recovery_code_table" validate:"required"`
+	// UserIdentityTable representa a configuração da tabela de identidades
+	// externas (OIDC) dos usuários no esquema.
+	UserIdentityTable Table[UserIdentityTable] `json:"user_identity_table" validate:"required"`
+	// ApiKeyTable representa a configuração da tabela de chaves de API no
+	// esquema.
+	ApiKeyTable Table[ApiKeyTable] `json:"api_key_table" validate:"required"`
+	// ApiKeyScopeTable representa a configuração da tabela do escopo das
+	// chaves de API no esquema.
+	ApiKeyScopeTable Table[ApiKeyScopeTable] `json:"api_key_scope_table" validate:"required"`
+	// WebhookTable representa a configuração da tabela de webhooks no
+	// esquema.
+	WebhookTable Table[WebhookTable] `json:"webhook_table" validate:"required"`
+	// WebhookDeliveryTable representa a configuração da tabela de entregas
+	// dos webhooks no esquema.
+	WebhookDeliveryTable Table[WebhookDeliveryTable] `json:"webhook_delivery_table" validate:"required"`
 }
 
 // Table representa uma tabela genérica usada no esquema do banco de dados.
@@ -79,6 +354,24 @@ type UserTable struct {
 	Password string `json:"password" validate:"required"`
 	// UpdatedAt define a coluna da última atualização do usuário.
 	UpdatedAt string `json:"updated_at" validate:"required"`
+	// MustChangePassword define a coluna (0 ou 1) que obriga o usuário a
+	// trocar a senha antes de usar as demais rotas.
+	MustChangePassword string `json:"must_change_password" validate:"required"`
+	// Email define a coluna do e-mail de contato de um usuário.
+	Email string `json:"email" validate:"required"`
+	// Phone define a coluna do telefone de contato de um usuário.
+	Phone string `json:"phone" validate:"required"`
+	// Active define a coluna (0 ou 1) que indica se a conta está ativa.
+	Active string `json:"active" validate:"required"`
+	// ValidFrom define a coluna do início da validade da conta.
+	ValidFrom string `json:"valid_from" validate:"required"`
+	// ValidUntil define a coluna do fim da validade da conta.
+	ValidUntil string `json:"valid_until" validate:"required"`
+	// LastLoginAt define a coluna do último login do usuário.
+	LastLoginAt string `json:"last_login_at" validate:"required"`
+	// EmailNotifications define a coluna (0 ou 1) que indica se o usuário
+	// recebe as notificações por e-mail.
+	EmailNotifications string `json:"email_notifications" validate:"required"`
 }
 
 // CategTable representa a estrutura das colunas na tabela de categorias do banco.
@@ -110,3 +403,322 @@ type FileTable struct {
 	// UpdatedAt define a coluna da última atualização do arquivo.
 	UpdatedAt string `json:"updated_at" validate:"required"`
 }
+
+// DownloadTable representa a estrutura das colunas na tabela de downloads do
+// banco.
+type DownloadTable struct {
+	// DownloadId define a coluna do identificador único de um download.
+	DownloadId string `json:"download_id" validate:"required"`
+	// FileId define a coluna que referencia o identificador de um arquivo.
+	FileId string `json:"file_id" validate:"required"`
+	// UserId define a coluna que referencia o identificador do usuário que
+	// realizou o download.
+	UserId string `json:"user_id" validate:"required"`
+	// ClientIp define a coluna do endereço IP de origem do download.
+	ClientIp string `json:"client_ip" validate:"required"`
+	// Bytes define a coluna da quantidade de bytes transferidos.
+	Bytes string `json:"bytes" validate:"required"`
+	// DownloadedAt define a coluna do momento em que o download ocorreu.
+	DownloadedAt string `json:"downloaded_at" validate:"required"`
+}
+
+// AuditTable representa a estrutura das colunas na tabela de auditoria do
+// banco. A tabela é somente de inserção (append-only).
+type AuditTable struct {
+	// AuditId define a coluna do identificador único de um registro.
+	AuditId string `json:"audit_id" validate:"required"`
+	// ActorId define a coluna que referencia o usuário autor da ação.
+	ActorId string `json:"actor_id" validate:"required"`
+	// Action define a coluna da ação realizada (ex.: "create").
+	Action string `json:"action" validate:"required"`
+	// EntityType define a coluna do tipo da entidade afetada (ex.: "file").
+	EntityType string `json:"entity_type" validate:"required"`
+	// EntityId define a coluna do identificador da entidade afetada.
+	EntityId string `json:"entity_id" validate:"required"`
+	// Diff define a coluna com as diferenças (JSON) dos metadados da entidade.
+	Diff string `json:"diff" validate:"required"`
+	// ClientIp define a coluna do endereço IP de origem da ação.
+	ClientIp string `json:"client_ip" validate:"required"`
+	// CreatedAt define a coluna do momento em que a ação ocorreu.
+	CreatedAt string `json:"created_at" validate:"required"`
+}
+
+// CategGrantTable representa a estrutura das colunas na tabela de concessões
+// de categorias do banco, que compartilha uma categoria com outros usuários.
+type CategGrantTable struct {
+	// GrantId define a coluna do identificador único de uma concessão.
+	GrantId string `json:"grant_id" validate:"required"`
+	// CategId define a coluna que referencia a categoria compartilhada.
+	CategId string `json:"categ_id" validate:"required"`
+	// UserId define a coluna que referencia o usuário com acesso à categoria.
+	// Valores nulos concedem acesso a todos os usuários.
+	UserId string `json:"user_id" validate:"required"`
+	// CreatedAt define a coluna do momento em que a concessão foi criada.
+	CreatedAt string `json:"created_at" validate:"required"`
+}
+
+// RoleTable representa a estrutura das colunas na tabela de papéis (roles)
+// do banco.
+type RoleTable struct {
+	// RoleId define a coluna do identificador único de um papel.
+	RoleId string `json:"role_id" validate:"required"`
+	// Name define a coluna do nome do papel (ex.: "admin").
+	Name string `json:"name" validate:"required"`
+	// UpdatedAt define a coluna da última atualização do papel.
+	UpdatedAt string `json:"updated_at" validate:"required"`
+}
+
+// RolePermissionTable representa a estrutura das colunas na tabela de
+// permissões dos papéis do banco.
+type RolePermissionTable struct {
+	// RoleId define a coluna que referencia o papel.
+	RoleId string `json:"role_id" validate:"required"`
+	// Permission define a coluna da permissão concedida (ex.: "users:write").
+	Permission string `json:"permission" validate:"required"`
+}
+
+// UserRoleTable representa a estrutura das colunas na tabela de papéis dos
+// usuários do banco.
+type UserRoleTable struct {
+	// UserId define a coluna que referencia o usuário.
+	UserId string `json:"user_id" validate:"required"`
+	// RoleId define a coluna que referencia o papel atribuído ao usuário.
+	RoleId string `json:"role_id" validate:"required"`
+}
+
+// InboxTable representa a estrutura das colunas na tabela de caixas de
+// entrada do banco. Uma categoria é uma caixa de entrada quando possui uma
+// linha nesta tabela.
+type InboxTable struct {
+	// CategId define a coluna que referencia a categoria.
+	CategId string `json:"categ_id" validate:"required"`
+	// MaxFileSize define a coluna do tamanho máximo, em bytes, de cada
+	// arquivo enviado. Zero indica sem limite.
+	MaxFileSize string `json:"max_file_size" validate:"required"`
+	// AllowedTypes define a coluna das extensões e tipos MIME aceitos,
+	// separados por vírgula. Vazio aceita qualquer tipo.
+	AllowedTypes string `json:"allowed_types" validate:"required"`
+	// UpdatedAt define a coluna da última atualização da caixa de entrada.
+	UpdatedAt string `json:"updated_at" validate:"required"`
+}
+
+// NotificationTable representa a estrutura das colunas na tabela de
+// notificações do banco.
+type NotificationTable struct {
+	// NotificationId define a coluna do identificador único de uma
+	// notificação.
+	NotificationId string `json:"notification_id" validate:"required"`
+	// Kind define a coluna do tipo da notificação (ex.: "inbox_upload").
+	Kind string `json:"kind" validate:"required"`
+	// UserId define a coluna que referencia o usuário que originou a
+	// notificação.
+	UserId string `json:"user_id" validate:"required"`
+	// EntityId define a coluna do identificador da entidade relacionada.
+	EntityId string `json:"entity_id" validate:"required"`
+	// Message define a coluna da mensagem da notificação.
+	Message string `json:"message" validate:"required"`
+	// CreatedAt define a coluna do momento em que a notificação foi criada.
+	CreatedAt string `json:"created_at" validate:"required"`
+	// ReadAt define a coluna do momento em que a notificação foi lida. Nulo
+	// indica não lida.
+	ReadAt string `json:"read_at" validate:"required"`
+}
+
+// RefreshTokenTable representa a estrutura das colunas na tabela de refresh
+// tokens do banco. Os tokens de um mesmo login formam uma família (sessão),
+// renovada a cada rotação.
+type RefreshTokenTable struct {
+	// TokenId define a coluna do identificador único de um refresh token.
+	TokenId string `json:"token_id" validate:"required"`
+	// FamilyId define a coluna do identificador da família (sessão) do token.
+	FamilyId string `json:"family_id" validate:"required"`
+	// UserId define a coluna que referencia o usuário do token.
+	UserId string `json:"user_id" validate:"required"`
+	// TokenHash define a coluna do hash SHA-256 do token.
+	TokenHash string `json:"token_hash" validate:"required"`
+	// CreatedAt define a coluna do momento em que o token foi criado.
+	CreatedAt string `json:"created_at" validate:"required"`
+	// ExpiresAt define a coluna do momento em que o token expira.
+	ExpiresAt string `json:"expires_at" validate:"required"`
+	// RevokedAt define a coluna do momento em que o token foi revogado, por
+	// rotação ou logout. Nulo indica token ativo.
+	RevokedAt string `json:"revoked_at" validate:"required"`
+}
+
+// SessionTable representa a estrutura das colunas na tabela de sessões do
+// banco. Cada sessão corresponde a uma família de refresh tokens.
+type SessionTable struct {
+	// SessionId define a coluna do identificador único de uma sessão, igual
+	// ao identificador da família de refresh tokens.
+	SessionId string `json:"session_id" validate:"required"`
+	// UserId define a coluna que referencia o usuário da sessão.
+	UserId string `json:"user_id" validate:"required"`
+	// UserAgent define a coluna do agente (navegador/dispositivo) do login.
+	UserAgent string `json:"user_agent" validate:"required"`
+	// ClientIp define a coluna do endereço IP de origem do login.
+	ClientIp string `json:"client_ip" validate:"required"`
+	// CreatedAt define a coluna do momento do login.
+	CreatedAt string `json:"created_at" validate:"required"`
+	// LastSeen define a coluna do momento da última requisição da sessão.
+	LastSeen string `json:"last_seen" validate:"required"`
+	// ExpiresAt define a coluna do momento de expiração do refresh token
+	// atual da sessão.
+	ExpiresAt string `json:"expires_at" validate:"required"`
+	// RevokedAt define a coluna do momento em que a sessão foi encerrada.
+	// Nulo indica sessão ativa.
+	RevokedAt string `json:"revoked_at" validate:"required"`
+}
+
+// PasswordResetTable representa a estrutura das colunas na tabela de tokens
+// de redefinição de senha do banco. Os tokens são de uso único.
+type PasswordResetTable struct {
+	// TokenId define a coluna do identificador único de um token.
+	TokenId string `json:"token_id" validate:"required"`
+	// UserId define a coluna que referencia o usuário do token.
+	UserId string `json:"user_id" validate:"required"`
+	// TokenHash define a coluna do hash SHA-256 do token.
+	TokenHash string `json:"token_hash" validate:"required"`
+	// CreatedBy define a coluna do usuário (administrador) que gerou o token.
+	CreatedBy string `json:"created_by" validate:"required"`
+	// CreatedAt define a coluna do momento de criação do token.
+	CreatedAt string `json:"created_at" validate:"required"`
+	// ExpiresAt define a coluna do momento de expiração do token.
+	ExpiresAt string `json:"expires_at" validate:"required"`
+	// UsedAt define a coluna do momento de uso ou invalidação do token. Nulo
+	// indica token disponível.
+	UsedAt string `json:"used_at" validate:"required"`
+}
+
+// TotpTable representa a estrutura das colunas na tabela de segredos TOTP do
+// banco. Cada usuário possui no máximo um segredo.
+type TotpTable struct {
+	// UserId define a coluna que referencia o usuário do segredo.
+	UserId string `json:"user_id" validate:"required"`
+	// Secret define a coluna do segredo compartilhado, em base32.
+	Secret string `json:"secret" validate:"required"`
+	// Enabled define a coluna (0 ou 1) que indica se o cadastro foi
+	// confirmado com um código válido.
+	Enabled string `json:"enabled" validate:"required"`
+	// LastStep define a coluna do último intervalo de tempo aceito, que
+	// impede a reutilização de um código.
+	LastStep string `json:"last_step" validate:"required"`
+	// UpdatedAt define a coluna da última atualização do segredo.
+	UpdatedAt string `json:"updated_at" validate:"required"`
+}
+
+// RecoveryCodeTable representa a estrutura das colunas na tabela de códigos
+// de recuperação do banco. Os códigos são de uso único.
+type RecoveryCodeTable struct {
+	// CodeId define a coluna do identificador único de um código.
+	CodeId string `json:"code_id" validate:"required"`
+	// UserId define a coluna que referencia o usuário do código.
+	UserId string `json:"user_id" validate:"required"`
+	// CodeHash define a coluna do hash SHA-256 do código.
+	CodeHash string `json:"code_hash" validate:"required"`
+	// UsedAt define a coluna do momento de uso do código. Nulo indica
+	// código disponível.
+	UsedAt string `json:"used_at" validate:"required"`
+}
+
+// UserIdentityTable representa a estrutura das colunas na tabela de
+// identidades externas do banco. Cada identidade (emissor e subject) está
+// vinculada a um único usuário.
+type UserIdentityTable struct {
+	// IdentityId define a coluna do identificador único de uma identidade.
+	IdentityId string `json:"identity_id" validate:"required"`
+	// UserId define a coluna que referencia o usuário da identidade.
+	UserId string `json:"user_id" validate:"required"`
+	// Issuer define a coluna do emissor do provedor de identidade.
+	Issuer string `json:"issuer" validate:"required"`
+	// Subject define a coluna do identificador do usuário no provedor
+	// (claim sub).
+	Subject string `json:"subject" validate:"required"`
+	// Email define a coluna do e-mail informado pelo provedor no vínculo.
+	Email string `json:"email" validate:"required"`
+	// CreatedAt define a coluna do momento do vínculo.
+	CreatedAt string `json:"created_at" validate:"required"`
+}
+
+// ApiKeyTable representa a estrutura das colunas na tabela de chaves de API
+// do banco. Apenas o hash da chave é armazenado.
+type ApiKeyTable struct {
+	// KeyId define a coluna do identificador único de uma chave.
+	KeyId string `json:"key_id" validate:"required"`
+	// Name define a coluna do nome descritivo da chave.
+	Name string `json:"name" validate:"required"`
+	// KeyPrefix define a coluna do prefixo da chave, exibido para
+	// identificá-la.
+	KeyPrefix string `json:"key_prefix" validate:"required"`
+	// KeyHash define a coluna do hash SHA-256 da chave.
+	KeyHash string `json:"key_hash" validate:"required"`
+	// CreatedBy define a coluna do usuário (administrador) que criou a chave.
+	CreatedBy string `json:"created_by" validate:"required"`
+	// CreatedAt define a coluna do momento de criação da chave.
+	CreatedAt string `json:"created_at" validate:"required"`
+	// ExpiresAt define a coluna do momento de expiração da chave. Zero
+	// indica chave sem expiração.
+	ExpiresAt string `json:"expires_at" validate:"required"`
+	// LastUsedAt define a coluna do momento do último uso da chave. Zero
+	// indica chave nunca usada.
+	LastUsedAt string `json:"last_used_at" validate:"required"`
+}
+
+// ApiKeyScopeTable representa a estrutura das colunas na tabela do escopo
+// das chaves de API do banco: as permissões concedidas e os usuários e
+// categorias acessíveis.
+type ApiKeyScopeTable struct {
+	// KeyId define a coluna que referencia a chave do escopo.
+	KeyId string `json:"key_id" validate:"required"`
+	// Kind define a coluna do tipo do item (permission, user ou category).
+	Kind string `json:"kind" validate:"required"`
+	// Value define a coluna do valor do item: a permissão ou o identificador
+	// do usuário ou da categoria.
+	Value string `json:"value" validate:"required"`
+}
+
+// WebhookTable representa a estrutura das colunas na tabela de webhooks do
+// banco. O segredo é armazenado em texto, pois assina cada entrega.
+type WebhookTable struct {
+	// WebhookId define a coluna do identificador único de um webhook.
+	WebhookId string `json:"webhook_id" validate:"required"`
+	// Url define a coluna do endereço que recebe os eventos.
+	Url string `json:"url" validate:"required"`
+	// Secret define a coluna do segredo da assinatura HMAC das entregas.
+	Secret string `json:"secret" validate:"required"`
+	// Events define a coluna dos eventos assinados, separados por vírgula
+	// ("*" assina todos).
+	Events string `json:"events" validate:"required"`
+	// Active define a coluna (0 ou 1) que indica se o webhook está ativo.
+	Active string `json:"active" validate:"required"`
+	// CreatedBy define a coluna do usuário (administrador) que criou o
+	// webhook.
+	CreatedBy string `json:"created_by" validate:"required"`
+	// CreatedAt define a coluna do momento de criação do webhook.
+	CreatedAt string `json:"created_at" validate:"required"`
+	// UpdatedAt define a coluna da última atualização do webhook.
+	UpdatedAt string `json:"updated_at" validate:"required"`
+}
+
+// WebhookDeliveryTable representa a estrutura das colunas na tabela de
+// entregas dos webhooks do banco: o registro de cada evento enviado.
+type WebhookDeliveryTable struct {
+	// DeliveryId define a coluna do identificador único de uma entrega.
+	DeliveryId string `json:"delivery_id" validate:"required"`
+	// WebhookId define a coluna que referencia o webhook da entrega.
+	WebhookId string `json:"webhook_id" validate:"required"`
+	// Event define a coluna do nome do evento.
+	Event string `json:"event" validate:"required"`
+	// Payload define a coluna do corpo JSON enviado.
+	Payload string `json:"payload" validate:"required"`
+	// StatusCode define a coluna do último código HTTP recebido. Zero indica
+	// falha de conexão.
+	StatusCode string `json:"status_code" validate:"required"`
+	// Attempts define a coluna do número de tentativas realizadas.
+	Attempts string `json:"attempts" validate:"required"`
+	// Success define a coluna (0 ou 1) que indica se a entrega foi aceita.
+	Success string `json:"success" validate:"required"`
+	// Error define a coluna da mensagem do último erro.
+	Error string `json:"error" validate:"required"`
+	// CreatedAt define a coluna do momento do evento.
+	CreatedAt string `json:"created_at" validate:"required"`
+}
@@ -3,6 +3,8 @@
 // para identificação e rastreamento de atualizações de dados.
 package db
 
+import "encoding/json"
+
 // UserModel representa o modelo do usuário armazenado no banco de dados.
 type UserModel struct {
 	// UserId é um identificador único para o usuário.
@@ -16,6 +18,28 @@ type UserModel struct {
 	// UpdatedAt representa o timestamp da última atualização dos dados
 	// do usuário, armazenado como um tempo Unix em segundos.
 	UpdatedAt int64 `json:"updated_at"`
+	// MustChangePassword indica que o usuário deve trocar a senha antes de
+	// usar as demais rotas.
+	MustChangePassword bool `json:"must_change_password"`
+	// Email representa o e-mail de contato do usuário.
+	Email string `json:"email"`
+	// Phone representa o telefone de contato do usuário.
+	Phone string `json:"phone"`
+	// Active indica se a conta do usuário está ativa.
+	Active bool `json:"active"`
+	// ValidFrom representa o início da validade da conta, como um tempo Unix
+	// em segundos. Zero indica conta sem início definido.
+	ValidFrom int64 `json:"valid_from"`
+	// ValidUntil representa o fim da validade da conta (ex.: fim do
+	// patrocínio), como um tempo Unix em segundos. Zero indica conta sem fim
+	// definido.
+	ValidUntil int64 `json:"valid_until"`
+	// LastLoginAt representa o último login do usuário, como um tempo Unix
+	// em segundos. Zero indica que o usuário nunca fez login.
+	LastLoginAt int64 `json:"last_login_at"`
+	// EmailNotifications indica se o usuário recebe as notificações por
+	// e-mail dos arquivos novos ou alterados em suas categorias.
+	EmailNotifications bool `json:"email_notifications"`
 }
 
 // CategModel representa o modelo da categoria armazenada no banco de dados.
@@ -29,6 +53,12 @@ type CategModel struct {
 	// UpdatedAt representa o timestamp da última atualização dos dados
 	// da categoria, armazenado como um tempo Unix em segundos.
 	UpdatedAt int64 `json:"updated_at"`
+	// Shared indica que a categoria pertence a outro usuário e é acessível
+	// por concessão.
+	Shared bool `json:"shared"`
+	// Inbox indica que a categoria é uma caixa de entrada, que aceita envios
+	// do usuário proprietário.
+	Inbox bool `json:"inbox"`
 }
 
 // FileModel representa o modelo do arquivo armazenado no banco de dados.
@@ -51,3 +81,263 @@ type FileModel struct {
 	// do arquivo, armazenado como um tempo Unix em segundos.
 	UpdatedAt int64 `json:"updated_at"`
 }
+
+// FileDownloadStats representa as estatísticas de download de um arquivo.
+type FileDownloadStats struct {
+	// FileId representa o identificador único do arquivo.
+	FileId string `json:"file_id"`
+	// FileName representa o nome do arquivo.
+	FileName string `json:"file_name"`
+	// CategId representa o identificador único da categoria do arquivo.
+	CategId string `json:"categ_id"`
+	// CategName representa o nome da categoria do arquivo.
+	CategName string `json:"categ_name"`
+	// UserId representa o identificador único do usuário dono da categoria.
+	UserId string `json:"user_id"`
+	// Downloads representa a quantidade de downloads realizados.
+	Downloads int64 `json:"downloads"`
+	// Bytes representa o total de bytes transferidos.
+	Bytes int64 `json:"bytes"`
+	// LastDownloadAt representa o timestamp do último download, armazenado
+	// como um tempo Unix em segundos.
+	LastDownloadAt int64 `json:"last_download_at"`
+}
+
+// UserDownloadStats representa as estatísticas de download de um usuário
+// (patrocinadora).
+type UserDownloadStats struct {
+	// UserId representa o identificador único do usuário.
+	UserId string `json:"user_id"`
+	// Username representa o nome de usuário.
+	Username string `json:"username"`
+	// Name representa o nome de apresentação do usuário.
+	Name string `json:"name"`
+	// Downloads representa a quantidade de downloads realizados.
+	Downloads int64 `json:"downloads"`
+	// Files representa a quantidade de arquivos distintos baixados.
+	Files int64 `json:"files"`
+	// Bytes representa o total de bytes transferidos.
+	Bytes int64 `json:"bytes"`
+	// LastDownloadAt representa o timestamp do último download, armazenado
+	// como um tempo Unix em segundos.
+	LastDownloadAt int64 `json:"last_download_at"`
+}
+
+// PeriodDownloadStats representa as estatísticas de download agrupadas por
+// período.
+type PeriodDownloadStats struct {
+	// Period representa o rótulo do período (ex.: "2025-01-31", "2025-01").
+	Period string `json:"period"`
+	// Start representa o início do período, armazenado como um tempo Unix em
+	// segundos.
+	Start int64 `json:"start"`
+	// Downloads representa a quantidade de downloads realizados no período.
+	Downloads int64 `json:"downloads"`
+	// Bytes representa o total de bytes transferidos no período.
+	Bytes int64 `json:"bytes"`
+}
+
+// AuditModel representa um registro da auditoria de alterações armazenado no
+// banco de dados.
+type AuditModel struct {
+	// AuditId representa o identificador único do registro.
+	AuditId string `json:"audit_id"`
+	// ActorId representa o identificador do usuário autor da ação.
+	ActorId string `json:"actor_id"`
+	// Action representa a ação realizada (ex.: "create", "update", "delete").
+	Action string `json:"action"`
+	// EntityType representa o tipo da entidade afetada (ex.: "user").
+	EntityType string `json:"entity_type"`
+	// EntityId representa o identificador da entidade afetada.
+	EntityId string `json:"entity_id"`
+	// Diff representa as diferenças dos metadados da entidade, no formato
+	// {"campo": {"before": ..., "after": ...}}.
+	Diff json.RawMessage `json:"diff"`
+	// ClientIp representa o endereço IP de origem da ação.
+	ClientIp string `json:"client_ip"`
+	// CreatedAt representa o timestamp da ação, armazenado como um tempo
+	// Unix em segundos.
+	CreatedAt int64 `json:"created_at"`
+}
+
+// CategoryCopy representa a cópia de uma categoria, com seus arquivos, para
+// um usuário.
+type CategoryCopy struct {
+	// UserId representa o identificador do usuário destino da cópia.
+	UserId string `json:"user_id"`
+	// CategId representa o identificador da nova categoria.
+	CategId string `json:"categ_id"`
+	// Files relaciona o identificador de cada arquivo original ao
+	// identificador de sua cópia.
+	Files map[string]string `json:"files"`
+}
+
+// CategGrantModel representa uma concessão de acesso a uma categoria
+// armazenada no banco de dados.
+type CategGrantModel struct {
+	// GrantId representa o identificador único da concessão.
+	GrantId string `json:"grant_id"`
+	// CategId representa o identificador da categoria compartilhada.
+	CategId string `json:"categ_id"`
+	// UserId representa o identificador do usuário com acesso à categoria.
+	// Vazio indica acesso para todos os usuários.
+	UserId string `json:"user_id"`
+	// CreatedAt representa o timestamp da criação da concessão, armazenado
+	// como um tempo Unix em segundos.
+	CreatedAt int64 `json:"created_at"`
+}
+
+// RoleModel representa um papel (role) de acesso armazenado no banco de
+// dados, com suas permissões.
+type RoleModel struct {
+	// RoleId representa o identificador único do papel.
+	RoleId string `json:"role_id"`
+	// Name representa o nome do papel.
+	Name string `json:"name"`
+	// Permissions representa as permissões concedidas pelo papel.
+	Permissions []string `json:"permissions"`
+	// UpdatedAt representa o timestamp da última atualização do papel,
+	// armazenado como um tempo Unix em segundos.
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// InboxModel representa as regras de uma caixa de entrada, categoria que
+// aceita envios de arquivos do usuário proprietário.
+type InboxModel struct {
+	// CategId representa o identificador único da categoria.
+	CategId string `json:"categ_id"`
+	// MaxFileSize representa o tamanho máximo, em bytes, de cada arquivo
+	// enviado. Zero indica sem limite.
+	MaxFileSize int64 `json:"max_file_size"`
+	// AllowedTypes representa as extensões e tipos MIME aceitos. Vazio
+	// aceita qualquer tipo.
+	AllowedTypes []string `json:"allowed_types"`
+	// UpdatedAt representa o timestamp da última atualização da caixa de
+	// entrada, armazenado como um tempo Unix em segundos.
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// NotificationModel representa uma notificação destinada aos
+// administradores.
+type NotificationModel struct {
+	// NotificationId representa o identificador único da notificação.
+	NotificationId string `json:"notification_id"`
+	// Kind representa o tipo da notificação.
+	Kind string `json:"kind"`
+	// UserId representa o identificador do usuário que originou a
+	// notificação.
+	UserId string `json:"user_id"`
+	// EntityId representa o identificador da entidade relacionada.
+	EntityId string `json:"entity_id"`
+	// Message representa a mensagem da notificação.
+	Message string `json:"message"`
+	// CreatedAt representa o momento de criação da notificação, armazenado
+	// como um tempo Unix em segundos.
+	CreatedAt int64 `json:"created_at"`
+	// ReadAt representa o momento de leitura da notificação, armazenado
+	// como um tempo Unix em segundos. Zero indica não lida.
+	ReadAt int64 `json:"read_at"`
+}
+
+// SessionModel representa uma sessão ativa de um usuário.
+type SessionModel struct {
+	// SessionId representa o identificador único da sessão.
+	SessionId string `json:"session_id"`
+	// UserId representa o identificador do usuário da sessão.
+	UserId string `json:"user_id"`
+	// UserAgent representa o agente (navegador/dispositivo) do login.
+	UserAgent string `json:"user_agent"`
+	// ClientIp representa o endereço IP de origem do login.
+	ClientIp string `json:"client_ip"`
+	// CreatedAt representa o momento do login, armazenado como um tempo Unix
+	// em segundos.
+	CreatedAt int64 `json:"created_at"`
+	// LastSeen representa o momento da última requisição da sessão,
+	// armazenado como um tempo Unix em segundos.
+	LastSeen int64 `json:"last_seen"`
+	// ExpiresAt representa o momento de expiração da sessão, caso não seja
+	// renovada, armazenado como um tempo Unix em segundos.
+	ExpiresAt int64 `json:"expires_at"`
+	// Current indica que é a sessão da requisição.
+	Current bool `json:"current"`
+}
+
+// ApiKeyModel representa uma chave de API de um cliente de máquina, com seu
+// escopo. A chave em si não é armazenada.
+type ApiKeyModel struct {
+	// KeyId representa o identificador único da chave.
+	KeyId string `json:"key_id"`
+	// Name representa o nome descritivo da chave.
+	Name string `json:"name"`
+	// Prefix representa o prefixo da chave, para identificá-la.
+	Prefix string `json:"prefix"`
+	// Permissions representa as permissões concedidas pela chave.
+	Permissions []string `json:"permissions"`
+	// Users representa os usuários acessíveis pela chave. Vazio indica todos
+	// os usuários.
+	Users []string `json:"users"`
+	// Categories representa as categorias acessíveis pela chave. Vazio
+	// indica todas as categorias.
+	Categories []string `json:"categories"`
+	// CreatedBy representa o identificador do administrador que criou a
+	// chave.
+	CreatedBy string `json:"created_by"`
+	// CreatedAt representa o momento de criação da chave, armazenado como um
+	// tempo Unix em segundos.
+	CreatedAt int64 `json:"created_at"`
+	// ExpiresAt representa o momento de expiração da chave, armazenado como
+	// um tempo Unix em segundos. Zero indica chave sem expiração.
+	ExpiresAt int64 `json:"expires_at"`
+	// LastUsedAt representa o momento do último uso da chave, armazenado
+	// como um tempo Unix em segundos. Zero indica chave nunca usada.
+	LastUsedAt int64 `json:"last_used_at"`
+}
+
+// WebhookModel representa o modelo do webhook armazenado no banco de dados.
+type WebhookModel struct {
+	// WebhookId representa o identificador único do webhook.
+	WebhookId string `json:"webhook_id"`
+	// Url representa o endereço que recebe os eventos.
+	Url string `json:"url"`
+	// Secret representa o segredo da assinatura das entregas. Não é
+	// retornado nas consultas, apenas na criação.
+	Secret string `json:"-"`
+	// Events representa os eventos assinados. "*" assina todos.
+	Events []string `json:"events"`
+	// Active indica se o webhook recebe os eventos.
+	Active bool `json:"active"`
+	// CreatedBy representa o identificador do administrador que criou o
+	// webhook.
+	CreatedBy string `json:"created_by"`
+	// CreatedAt representa o momento de criação do webhook, armazenado como
+	// um tempo Unix em segundos.
+	CreatedAt int64 `json:"created_at"`
+	// UpdatedAt representa o timestamp da última atualização do webhook,
+	// armazenado como um tempo Unix em segundos.
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// WebhookDeliveryModel representa o registro da entrega de um evento a um
+// webhook.
+type WebhookDeliveryModel struct {
+	// DeliveryId representa o identificador único da entrega.
+	DeliveryId string `json:"delivery_id"`
+	// WebhookId representa o identificador do webhook.
+	WebhookId string `json:"webhook_id"`
+	// Event representa o nome do evento.
+	Event string `json:"event"`
+	// Payload representa o corpo JSON enviado.
+	Payload string `json:"payload"`
+	// StatusCode representa o último código HTTP recebido. Zero indica
+	// falha de conexão.
+	StatusCode int `json:"status_code"`
+	// Attempts representa o número de tentativas realizadas.
+	Attempts int `json:"attempts"`
+	// Success indica se a entrega foi aceita pelo destino.
+	Success bool `json:"success"`
+	// Error representa a mensagem do último erro.
+	Error string `json:"error"`
+	// CreatedAt representa o momento do evento, armazenado como um tempo
+	// Unix em segundos.
+	CreatedAt int64 `json:"created_at"`
+}
@@ -1,10 +1,10 @@
 package main
 
 import (
+	"agros_arquivos_patrocinadoras/pkg/app"
 	"agros_arquivos_patrocinadoras/pkg/app/context"
 	"agros_arquivos_patrocinadoras/pkg/auth"
 	"agros_arquivos_patrocinadoras/pkg/handlers"
-	"github.com/golang-jwt/jwt/v5"
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 	"net/http"
@@ -18,41 +18,163 @@ import (
 func ConfigRoutes(e *echo.Echo, ctx *context.Context) {
 	ctx.Logger.Info("Configurando rotas")
 
-	// Grupo para autenticação
-	authGroup := e.Group("/auth")
-	authGroup.Use(echojwt.WithConfig(echojwt.Config{
-		SigningKey: []byte(ctx.Config.JwtSecret),
-		NewClaimsFunc: func(c echo.Context) jwt.Claims {
-			return new(auth.CustomClaims)
+	// Grupo para autenticação. No modo de autenticação por cookies, o token
+	// é lido apenas do cookie HttpOnly. Requisições com chave de API são
+	// autenticadas por ApiKeyMiddleware, e o fluxo de eventos com ticket por
+	// EventsTicketMiddleware, sem o JWT
+	jwtConfig := echojwt.Config{
+		ParseTokenFunc: auth.ParseToken,
+		Skipper: func(c echo.Context) bool {
+			return c.Request().Header.Get(auth.HeaderApiKey) != "" ||
+				(c.Path() == "/auth/events" && c.QueryParam(auth.QueryEventsTicket) != "")
 		},
-	}))
+	}
+	if ctx.Config.CookieAuth {
+		jwtConfig.TokenLookup = "cookie:" + handlers.CookieJwt
+	}
+	authGroup := e.Group("/auth")
+	authGroup.Use(
+		ApiKeyMiddleware(
+			"/auth/logout",
+			"/auth/session",
+			"/auth/me",
+			"/auth/me/password",
+			"/auth/me/totp",
+			"/auth/me/totp/confirm",
+			"/auth/apikey",
+			"/auth/apikey/:keyId",
+			"/auth/webhook",
+			"/auth/webhook/:webhookId",
+			"/auth/webhook/:webhookId/delivery",
+			"/auth/webhook/:webhookId/test",
+			"/auth/events",
+			"/auth/events/ticket",
+		),
+		EventsTicketMiddleware("/auth/events"),
+		echojwt.WithConfig(jwtConfig),
+		PasswordChangeMiddleware("/auth/me/password", "/auth/logout", "/auth/session"),
+		TotpEnrollMiddleware(
+			"/auth/me/totp",
+			"/auth/me/totp/confirm",
+			"/auth/me/password",
+			"/auth/logout",
+			"/auth/session",
+		),
+	)
+
+	// Middlewares de permissão
+	usersRead := PermissionMiddleware(app.PermUsersRead)
+	usersWrite := PermissionMiddleware(app.PermUsersWrite)
+	contentRead := PermissionMiddleware(app.PermContentRead)
+	contentWrite := PermissionMiddleware(app.PermContentWrite)
+	reportsRead := PermissionMiddleware(app.PermReportsRead)
+	auditRead := PermissionMiddleware(app.PermAuditRead)
+	roles := PermissionMiddleware(app.PermRolesManage)
 
 	// Login
 	e.POST("/login", handlers.LoginHandler)
+	e.POST("/login/totp", handlers.LoginTotpHandler)
+	e.POST("/refresh", handlers.RefreshHandler)
+	e.GET("/oidc/login", handlers.OidcLoginHandler)
+	e.GET("/oidc/callback", handlers.OidcCallbackHandler)
+	e.GET("/.well-known/jwks.json", handlers.JwksHandler)
+	authGroup.POST("/logout", handlers.LogoutHandler)
+
+	// Perfil do próprio usuário
+	authGroup.GET("/me", handlers.GetProfile)
+	authGroup.PATCH("/me", handlers.UpdateProfileHandler)
+
+	// Senha
+	e.POST("/password-reset", handlers.RedeemPasswordResetHandler)
+	authGroup.PUT("/me/password", handlers.ChangePasswordHandler)
+	authGroup.POST("/user/:userId/password-reset", handlers.CreatePasswordResetHandler, usersWrite)
+
+	// Segundo fator (TOTP)
+	authGroup.GET("/me/totp", handlers.GetTotpStatus)
+	authGroup.POST("/me/totp", handlers.BeginTotpHandler)
+	authGroup.POST("/me/totp/confirm", handlers.ConfirmTotpHandler)
+	authGroup.DELETE("/me/totp", handlers.DisableTotpHandler)
+	authGroup.DELETE("/user/:userId/totp", handlers.ResetUserTotpHandler, usersWrite)
 
 	// Sessão
 	authGroup.GET("/session", handlers.SessionHandler)
+	authGroup.GET("/user/:userId/session", handlers.GetUserSessions)
+	authGroup.DELETE("/user/:userId/session", handlers.DeleteUserSessionsHandler)
+	authGroup.DELETE("/user/:userId/session/:sessionId", handlers.DeleteSessionHandler)
 
 	// Usuário
-	authGroup.POST("/user", handlers.CreateUserHandler)
-	authGroup.GET("/user", handlers.GetAllUsers)
+	authGroup.POST("/user", handlers.CreateUserHandler, usersWrite)
+	authGroup.GET("/user", handlers.GetAllUsers, usersRead)
 	authGroup.GET("/user/:userId", handlers.GetUserById)
-	authGroup.PATCH("/user/:userId", handlers.UpdateUserHandler)
-	authGroup.DELETE("/user/:userId", handlers.DeleteUser)
+	authGroup.PATCH("/user/:userId", handlers.UpdateUserHandler, usersWrite)
+	authGroup.DELETE("/user/:userId", handlers.DeleteUser, usersWrite)
+	authGroup.DELETE("/user/:userId/lockout", handlers.UnlockUserHandler, usersWrite)
 
 	// Categorias
-	authGroup.POST("/user/:userId/category", handlers.CreateCategoryHandler)
+	authGroup.POST("/user/:userId/category", handlers.CreateCategoryHandler, contentWrite)
 	authGroup.GET("/user/:userId/category", handlers.GetAllCategories)
 	authGroup.GET("/user/:userId/category/:categId", handlers.GetCategoryById)
-	authGroup.PATCH("/user/:userId/category/:categId", handlers.UpdateCategoryHandler)
-	authGroup.DELETE("/user/:userId/category/:categId", handlers.DeleteCategory)
+	authGroup.PATCH("/user/:userId/category/:categId", handlers.UpdateCategoryHandler, contentWrite)
+	authGroup.DELETE("/user/:userId/category/:categId", handlers.DeleteCategory, contentWrite)
+	authGroup.POST("/user/:userId/category/:categId/copy", handlers.CopyCategoryHandler, contentWrite)
+
+	// Compartilhamento de categorias
+	authGroup.POST("/user/:userId/category/:categId/grant", handlers.CreateGrantHandler, contentWrite)
+	authGroup.GET("/user/:userId/category/:categId/grant", handlers.GetCategoryGrants, contentRead)
+	authGroup.DELETE("/user/:userId/category/:categId/grant/:grantId", handlers.DeleteGrantHandler, contentWrite)
+
+	// Caixas de entrada
+	authGroup.GET("/user/:userId/category/:categId/inbox", handlers.GetInbox)
+	authGroup.PUT("/user/:userId/category/:categId/inbox", handlers.SetInboxHandler, contentWrite)
+	authGroup.DELETE("/user/:userId/category/:categId/inbox", handlers.RemoveInboxHandler, contentWrite)
 
 	// Arquivos
 	authGroup.POST("/user/:userId/category/:categId/file", handlers.CreateFileHandler)
 	authGroup.GET("/user/:userId/category/:categId/file", handlers.GetAllFiles)
 	authGroup.GET("/user/:userId/category/:categId/file/:fileId", handlers.GetFileById)
-	authGroup.PATCH("/user/:userId/category/:categId/file/:fileId", handlers.UpdateFileHandler)
-	authGroup.DELETE("/user/:userId/category/:categId/file/:fileId", handlers.DeleteFile)
+	authGroup.PATCH("/user/:userId/category/:categId/file/:fileId", handlers.UpdateFileHandler, contentWrite)
+	authGroup.DELETE("/user/:userId/category/:categId/file/:fileId", handlers.DeleteFile, contentWrite)
+
+	// Notificações
+	authGroup.GET("/notification", handlers.GetNotifications, contentWrite)
+	authGroup.PATCH("/notification/:notificationId", handlers.ReadNotificationHandler, contentWrite)
+
+	// Operações em lote
+	authGroup.POST("/batch", handlers.BatchHandler, contentWrite)
+
+	// Relatórios
+	authGroup.GET("/report/download/file", handlers.GetDownloadsByFile, reportsRead)
+	authGroup.GET("/report/download/user", handlers.GetDownloadsByUser, reportsRead)
+	authGroup.GET("/report/download/period", handlers.GetDownloadsByPeriod, reportsRead)
+	authGroup.GET("/report/download/unused", handlers.GetUndownloadedFiles, reportsRead)
+
+	// Auditoria
+	authGroup.GET("/audit", handlers.GetAuditLog, auditRead)
+
+	// Chaves de API
+	authGroup.POST("/apikey", handlers.CreateApiKeyHandler, usersWrite)
+	authGroup.GET("/apikey", handlers.GetAllApiKeys, usersRead)
+	authGroup.DELETE("/apikey/:keyId", handlers.DeleteApiKeyHandler, usersWrite)
+
+	// Webhooks
+	authGroup.POST("/webhook", handlers.CreateWebhookHandler, usersWrite)
+	authGroup.GET("/webhook", handlers.GetAllWebhooks, usersRead)
+	authGroup.PATCH("/webhook/:webhookId", handlers.UpdateWebhookHandler, usersWrite)
+	authGroup.DELETE("/webhook/:webhookId", handlers.DeleteWebhookHandler, usersWrite)
+	authGroup.GET("/webhook/:webhookId/delivery", handlers.GetWebhookDeliveries, usersRead)
+	authGroup.POST("/webhook/:webhookId/test", handlers.TestWebhookHandler, usersWrite)
+
+	// Fluxo de eventos (Server-Sent Events)
+	authGroup.GET("/events", handlers.EventsHandler)
+	authGroup.POST("/events/ticket", handlers.EventsTicketHandler)
+
+	// Papéis de acesso
+	authGroup.POST("/role", handlers.CreateRoleHandler, roles)
+	authGroup.GET("/role", handlers.GetAllRoles, roles)
+	authGroup.PATCH("/role/:roleId", handlers.UpdateRoleHandler, roles)
+	authGroup.DELETE("/role/:roleId", handlers.DeleteRoleHandler, roles)
+	authGroup.GET("/user/:userId/role", handlers.GetUserRoles, roles)
+	authGroup.PUT("/user/:userId/role", handlers.SetUserRolesHandler, roles)
 
 	// Preflight: rota coringa
 	e.OPTIONS("/*", func(c echo.Context) error {
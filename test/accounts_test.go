@@ -0,0 +1,141 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApp_ValidateValidity(t *testing.T) {
+	now := time.Now().Unix()
+
+	t.Run(
+		"Deve_Aceitar_Periodo_Quando_Fim_Posterior_Ou_Indefinido",
+		func(t *testing.T) {
+			assert.NoError(t, app.ValidateValidity(now, now+60))
+			assert.NoError(t, app.ValidateValidity(0, now))
+			assert.NoError(t, app.ValidateValidity(now, 0))
+			assert.NoError(t, app.ValidateValidity(0, 0))
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Erro_Quando_Fim_Anterior_Ao_Inicio",
+		func(t *testing.T) {
+			assert.ErrorIs(t, app.ValidateValidity(now, now), app.ErrInvalidValidity)
+			assert.ErrorIs(t, app.ValidateValidity(now, now-60), app.ErrInvalidValidity)
+			assert.ErrorIs(t, app.ValidateValidity(-1, 0), app.ErrInvalidValidity)
+		},
+	)
+}
+
+func TestApp_AccountLifecycle(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	now := time.Now().Unix()
+	past, future := now-3600, now+3600
+	login := func(username string) error {
+		_, err := app.QueryLogin(ctx, app.LoginParams{Username: username, Password: "123456789"})
+		return err
+	}
+	activeId, err := app.CreateUser(ctx, app.UserData{
+		Username:   "LifecycleActive",
+		Name:       "LifecycleActive",
+		Password:   "123456789",
+		ValidUntil: &future,
+	})
+	assert.NoError(t, err)
+	expiredId, err := app.CreateUser(ctx, app.UserData{
+		Username:   "LifecycleExpired",
+		Name:       "LifecycleExpired",
+		Password:   "123456789",
+		ValidUntil: &past,
+	})
+	assert.NoError(t, err)
+
+	t.Run(
+		"Deve_Permitir_Login_Quando_Conta_Ativa_E_Valida",
+		func(t *testing.T) {
+			assert.NoError(t, login("LifecycleActive"))
+			user, err := app.QueryUserById(ctx, activeId)
+			assert.NoError(t, err)
+			assert.True(t, user.Active)
+			assert.Equal(t, future, user.ValidUntil)
+		},
+	)
+
+	t.Run(
+		"Deve_Registrar_Ultimo_Login_Quando_Sessao_Criada",
+		func(t *testing.T) {
+			_, err := app.CreateSession(ctx, app.SessionData{UserId: activeId})
+			assert.NoError(t, err)
+			user, err := app.QueryUserById(ctx, activeId)
+			assert.NoError(t, err)
+			assert.GreaterOrEqual(t, user.LastLoginAt, now)
+		},
+	)
+
+	t.Run(
+		"Deve_Negar_Login_Quando_Conta_Expirada",
+		func(t *testing.T) {
+			assert.ErrorIs(t, login("LifecycleExpired"), app.ErrAccountExpired)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Forbidden_Quando_Login_De_Conta_Expirada",
+		func(t *testing.T) {
+			body := `{"username":"LifecycleExpired","password":"123456789"}`
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			if assert.NoError(t, h.LoginHandler(echoNewContext(req, rec))) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+				assert.Contains(t, rec.Body.String(), string(h.AccountExpiredMessage))
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Desativar_Apenas_Contas_Expiradas_Quando_Job_Executado",
+		func(t *testing.T) {
+			deactivated, err := app.DeactivateExpiredUsers(ctx)
+			assert.NoError(t, err)
+			assert.True(t, slices.Contains(deactivated, expiredId))
+			assert.False(t, slices.Contains(deactivated, activeId))
+
+			user, err := app.QueryUserById(ctx, expiredId)
+			assert.NoError(t, err)
+			assert.False(t, user.Active)
+			assert.ErrorIs(t, login("LifecycleExpired"), app.ErrAccountInactive)
+		},
+	)
+
+	t.Run(
+		"Deve_Negar_Acesso_Quando_Conta_Desativada",
+		func(t *testing.T) {
+			inactive := false
+			assert.NoError(t, app.UpdateUser(ctx, activeId, 0, app.UserData{Active: &inactive}))
+			assert.ErrorIs(t, login("LifecycleActive"), app.ErrAccountInactive)
+
+			active, err := app.IsUserActive(ctx, activeId)
+			assert.NoError(t, err)
+			assert.False(t, active)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Erro_Quando_Validade_Invalida",
+		func(t *testing.T) {
+			err := app.UpdateUser(ctx, activeId, 0, app.UserData{ValidFrom: &future, ValidUntil: &past})
+			assert.ErrorIs(t, err, app.ErrInvalidValidity)
+		},
+	)
+}
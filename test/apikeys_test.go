@@ -0,0 +1,209 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlers_CreateApiKey(t *testing.T) {
+	createApiKey := func(body string, admin bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/auth/apikey", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		if admin {
+			setClaims(c, context.GetContext(c).AdminId, "admin")
+		} else {
+			setClaims(c, uuid.New(), "Usuário")
+		}
+		assert.NoError(t, h.CreateApiKeyHandler(c))
+		return rec
+	}
+
+	t.Run(
+		"Deve_Criar_Chave_Quando_Permissoes_Validas",
+		func(t *testing.T) {
+			rec := createApiKey(`{"name":"Automação","permissions":["content:write"]}`, true)
+			assert.Equal(t, http.StatusCreated, rec.Code)
+
+			var res h.ApiKeyRes
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.True(t, strings.HasPrefix(res.Key, app.ApiKeyPrefix))
+			assert.True(t, strings.HasPrefix(res.Key, res.Prefix))
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_BadRequest_Quando_Permissao_Invalida",
+		func(t *testing.T) {
+			rec := createApiKey(`{"name":"Automação","permissions":["files:everything"]}`, true)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Sem_Permissao",
+		func(t *testing.T) {
+			rec := createApiKey(`{"name":"Automação","permissions":["content:write"]}`, false)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		},
+	)
+}
+
+func TestAuth_ParseApiKey(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userId, otherId := uuid.New(), uuid.New()
+	key, err := app.CreateApiKey(ctx, app.ApiKeyData{
+		Name:        "Automação",
+		Permissions: []string{app.PermContentWrite},
+		Users:       []uuid.UUID{userId},
+	}, ctx.AdminId)
+	assert.NoError(t, err)
+
+	newApiKeyContext := func(userParam string) echo.Context {
+		req := httptest.NewRequest(http.MethodPost, "/auth/user/"+userParam+"/category", nil)
+		c := echoNewContext(req, httptest.NewRecorder())
+		c.SetPath("/auth/user/:userId/category")
+		c.SetParamNames("userId")
+		c.SetParamValues(userParam)
+		return c
+	}
+
+	t.Run(
+		"Deve_Conceder_Apenas_Permissoes_Da_Chave_Quando_Valida",
+		func(t *testing.T) {
+			c := newApiKeyContext(userId.String())
+			token, err := auth.ParseApiKey(c, key.Key)
+			if assert.NoError(t, err) {
+				c.Set("user", token)
+				assert.True(t, auth.HasPermission(c, app.PermContentWrite))
+				assert.False(t, auth.HasPermission(c, app.PermUsersWrite))
+				assert.True(t, auth.ApiKeyInScope(c))
+			}
+
+			// Último uso registrado
+			stored, err := app.QueryApiKeyById(ctx, key.KeyId)
+			assert.NoError(t, err)
+			assert.NotZero(t, stored.LastUsedAt)
+		},
+	)
+
+	t.Run(
+		"Deve_Negar_Acesso_Quando_Usuario_Fora_Do_Escopo",
+		func(t *testing.T) {
+			c := newApiKeyContext(otherId.String())
+			token, err := auth.ParseApiKey(c, key.Key)
+			if assert.NoError(t, err) {
+				c.Set("user", token)
+				assert.False(t, auth.ApiKeyInScope(c))
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Rejeitar_Chave_Quando_Expirada",
+		func(t *testing.T) {
+			expired, err := app.CreateApiKey(ctx, app.ApiKeyData{
+				Name:        "Expirada",
+				Permissions: []string{app.PermContentRead},
+				ExpiresAt:   time.Now().Add(-time.Minute),
+			}, ctx.AdminId)
+			assert.NoError(t, err)
+
+			_, err = auth.ParseApiKey(newApiKeyContext(userId.String()), expired.Key)
+			assert.ErrorIs(t, err, app.ErrInvalidApiKey)
+		},
+	)
+
+	t.Run(
+		"Deve_Rejeitar_Chave_Quando_Revogada",
+		func(t *testing.T) {
+			assert.NoError(t, app.DeleteApiKey(ctx, key.KeyId))
+			_, err := auth.ParseApiKey(newApiKeyContext(userId.String()), key.Key)
+			assert.ErrorIs(t, err, app.ErrInvalidApiKey)
+		},
+	)
+}
+
+func TestHandlers_ApiKeyBodyScope(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userId, err := app.CreateUser(ctx, app.UserData{Username: "ScopeUser1", Name: "ScopeUser1", Password: "123456789"})
+	assert.NoError(t, err)
+	otherId, err := app.CreateUser(ctx, app.UserData{Username: "ScopeUser2", Name: "ScopeUser2", Password: "123456789"})
+	assert.NoError(t, err)
+	categId, err := app.CreateCategory(ctx, app.CategData{UserId: userId, Name: "ScopeCateg"})
+	assert.NoError(t, err)
+	key, err := app.CreateApiKey(ctx, app.ApiKeyData{
+		Name:        "Automação",
+		Permissions: []string{app.PermContentWrite},
+		Users:       []uuid.UUID{userId},
+	}, ctx.AdminId)
+	assert.NoError(t, err)
+
+	newScopedContext := func(route, body string) (echo.Context, *httptest.ResponseRecorder) {
+		target := "/auth/user/" + userId.String() + "/category/" + categId.String() + "/" + route
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		c.SetPath("/auth/user/:userId/category/:categId/" + route)
+		c.SetParamNames("userId", "categId")
+		c.SetParamValues(userId.String(), categId.String())
+		token, err := auth.ParseApiKey(c, key.Key)
+		assert.NoError(t, err)
+		c.Set("user", token)
+		assert.True(t, auth.ApiKeyInScope(c))
+		return c, rec
+	}
+
+	t.Run(
+		"Deve_Retornar_Forbidden_Quando_Copia_Para_Usuario_Fora_Do_Escopo",
+		func(t *testing.T) {
+			c, rec := newScopedContext("copy", `{"user_ids":["`+otherId.String()+`"]}`)
+			if assert.NoError(t, h.CopyCategoryHandler(c)) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.ApiKeyScopeMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Forbidden_Quando_Concessao_Para_Usuario_Fora_Do_Escopo",
+		func(t *testing.T) {
+			c, rec := newScopedContext("grant", `{"user_id":"`+otherId.String()+`"}`)
+			if assert.NoError(t, h.CreateGrantHandler(c)) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.ApiKeyScopeMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Forbidden_Quando_Concessao_Para_Todos",
+		func(t *testing.T) {
+			c, rec := newScopedContext("grant", `{"all":true}`)
+			if assert.NoError(t, h.CreateGrantHandler(c)) {
+				assert.Equal(t, http.StatusForbidden, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.ApiKeyScopeMessage)
+			}
+		},
+	)
+
+	// Nenhuma concessão criada
+	grants, err := app.QueryCategoryGrants(ctx, categId)
+	assert.NoError(t, err)
+	assert.Empty(t, grants)
+}
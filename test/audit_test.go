@@ -0,0 +1,116 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlers_MetadataDiff(t *testing.T) {
+	before := db.UserModel{UserId: "1", Username: "user", Name: "Antes", Password: "hash"}
+	after := db.UserModel{UserId: "1", Username: "user", Name: "Depois", Password: "outro"}
+
+	t.Run(
+		"Deve_Retornar_Apenas_Campos_Alterados",
+		func(t *testing.T) {
+			diff := h.MetadataDiff(before, after)
+			assert.JSONEq(t, `{"name":{"before":"Antes","after":"Depois"}}`, diff)
+		},
+	)
+
+	t.Run(
+		"Deve_Omitir_Senha_E_Conteudo",
+		func(t *testing.T) {
+			file := db.FileModel{FileId: "1", Name: "Arquivo", Blob: []byte("conteúdo")}
+			diff := h.MetadataDiff(nil, file)
+			assert.NotContains(t, diff, "blob")
+			assert.NotContains(t, h.MetadataDiff(nil, after), "password")
+		},
+	)
+}
+
+func TestHandlers_AuditLog(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userData := app.UserData{
+		Username: "AuditUser1",
+		Name:     "AuditUser1",
+		Password: "123456789",
+	}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+
+	// Criação de categoria pelo administrador
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/user/"+userId.String()+"/category",
+		strings.NewReader(`{"name": "AuditCateg1"}`),
+	)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := echoNewContext(req, rec)
+	c.SetPath("/user/:userId/category")
+	c.SetParamNames("userId")
+	c.SetParamValues(userId.String())
+	setClaims(c, uuid.Nil, "Admin")
+	assert.NoError(t, h.CreateCategoryHandler(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+
+	// Cenário positivo
+	t.Run(
+		"Deve_Retornar_OK_Quando_Auditoria_Filtrada",
+		func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodGet,
+				"/audit?action=create&entity_type=category",
+				nil,
+			)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.GetAuditLog(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"action":"create"`)
+				assert.Contains(t, rec.Body.String(), `"after":"AuditCateg1"`)
+			}
+		},
+	)
+
+	// Cenários negativos
+	t.Run(
+		"Deve_Retornar_Bad_Request_Quando_Filtro_Invalido",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/audit?action=read", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.GetAuditLog(c)) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.InvalidAuditFilterMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Nao_Administrador",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/audit", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, userId, userData.Name)
+
+			if assert.NoError(t, h.GetAuditLog(c)) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			}
+		},
+	)
+}
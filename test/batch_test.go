@@ -0,0 +1,114 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlers_Batch(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userData := app.UserData{
+		Username: "BatchUser1",
+		Name:     "BatchUser1",
+		Password: "123456789",
+	}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+
+	srcId, err := app.CreateCategory(ctx, app.CategData{UserId: userId, Name: "BatchCateg1"})
+	assert.NoError(t, err)
+	dstId, err := app.CreateCategory(ctx, app.CategData{UserId: userId, Name: "BatchCateg2"})
+	assert.NoError(t, err)
+
+	content := []byte("Test")
+	var fileIds []uuid.UUID
+	for _, name := range []string{"BatchFile1", "BatchFile2"} {
+		fileParams := app.FileData{
+			CategId:   srcId,
+			Name:      name,
+			Extension: ".txt",
+			Mimetype:  "text/plain",
+			Content:   &content,
+		}
+		fileId, err := app.CreateFile(ctx, fileParams)
+		assert.NoError(t, err)
+		fileIds = append(fileIds, fileId)
+	}
+
+	batchRequest := func(payload string) (*httptest.ResponseRecorder, echo.Context) {
+		req := httptest.NewRequest(http.MethodPost, "/batch", strings.NewReader(payload))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		setClaims(c, uuid.Nil, "Admin")
+		return rec, c
+	}
+
+	// Cenário negativo
+	t.Run(
+		"Deve_Retornar_Conflict_Quando_Uma_Operacao_Falha",
+		func(t *testing.T) {
+			payload := `{"operations":[` +
+				`{"op":"move","entity":"file","id":"` + fileIds[0].String() + `","target_id":"` + dstId.String() + `"},` +
+				`{"op":"delete","entity":"file","id":"` + uuid.New().String() + `"}]}`
+			rec, c := batchRequest(payload)
+
+			if assert.NoError(t, h.BatchHandler(c)) {
+				assert.Equal(t, http.StatusConflict, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"status":"rolled_back"`)
+				assert.Contains(t, rec.Body.String(), `"status":"failed"`)
+			}
+			file, err := app.QueryFileInfoById(ctx, fileIds[0])
+			if assert.NoError(t, err) {
+				assert.Equal(t, srcId.String(), file.CategId)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Bad_Request_Quando_Operacao_Invalida",
+		func(t *testing.T) {
+			payload := `{"operations":[{"op":"copy","entity":"file","id":"` + fileIds[0].String() + `"}]}`
+			rec, c := batchRequest(payload)
+
+			if assert.NoError(t, h.BatchHandler(c)) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.InvalidBatchMessage)
+			}
+		},
+	)
+
+	// Cenário positivo
+	t.Run(
+		"Deve_Retornar_OK_Quando_Lote_Executado_Com_Sucesso",
+		func(t *testing.T) {
+			payload := `{"operations":[` +
+				`{"op":"move","entity":"file","id":"` + fileIds[0].String() + `","target_id":"` + dstId.String() + `"},` +
+				`{"op":"rename","entity":"file","id":"` + fileIds[1].String() + `","name":"BatchRenamed"},` +
+				`{"op":"rename","entity":"category","id":"` + srcId.String() + `","name":"BatchCateg3"}]}`
+			rec, c := batchRequest(payload)
+
+			if assert.NoError(t, h.BatchHandler(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.BatchSuccessMessage)
+				assert.NotContains(t, rec.Body.String(), `"status":"failed"`)
+			}
+			file, err := app.QueryFileInfoById(ctx, fileIds[0])
+			if assert.NoError(t, err) {
+				assert.Equal(t, dstId.String(), file.CategId)
+			}
+			file, err = app.QueryFileInfoById(ctx, fileIds[1])
+			if assert.NoError(t, err) {
+				assert.Equal(t, "BatchRenamed", file.Name)
+			}
+		},
+	)
+}
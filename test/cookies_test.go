@@ -0,0 +1,57 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlers_CookieAuth(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userData := app.UserData{Username: "CookieUser", Name: "CookieUser", Password: "123456789"}
+	_, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+
+	// Cenário positivo
+	t.Run(
+		"Deve_Retornar_Apenas_Cookies_Quando_Modo_Cookie",
+		func(t *testing.T) {
+			body := `{"username":"CookieUser","password":"123456789"}`
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			context.GetContext(c).Config.CookieAuth = true
+
+			if assert.NoError(t, h.LoginHandler(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.NotContains(t, rec.Body.String(), `"token"`)
+				assert.NotContains(t, rec.Body.String(), `"refresh_token"`)
+			}
+
+			cookies := map[string]*http.Cookie{}
+			for _, cookie := range rec.Result().Cookies() {
+				cookies[cookie.Name] = cookie
+			}
+			if assert.Contains(t, cookies, h.CookieJwt) {
+				assert.True(t, cookies[h.CookieJwt].HttpOnly)
+				assert.True(t, cookies[h.CookieJwt].Secure)
+			}
+			if assert.Contains(t, cookies, h.CookieRefresh) {
+				assert.True(t, cookies[h.CookieRefresh].Secure)
+			}
+			if assert.Contains(t, cookies, h.CookieCSRF) {
+				assert.False(t, cookies[h.CookieCSRF].HttpOnly)
+				assert.NotEmpty(t, cookies[h.CookieCSRF].Value)
+				assert.True(t, cookies[h.CookieCSRF].Secure)
+			}
+		},
+	)
+}
@@ -0,0 +1,98 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlers_CopyCategory(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	var userIds []uuid.UUID
+	for _, name := range []string{"CopyUser1", "CopyUser2", "CopyUser3"} {
+		userData := app.UserData{Username: name, Name: name, Password: "123456789"}
+		userId, err := app.CreateUser(ctx, userData)
+		assert.NoError(t, err)
+		userIds = append(userIds, userId)
+	}
+
+	categId, err := app.CreateCategory(ctx, app.CategData{UserId: userIds[0], Name: "CopyCateg1"})
+	assert.NoError(t, err)
+
+	content := []byte("Test")
+	fileParams := app.FileData{
+		CategId:   categId,
+		Name:      "CopyFile1",
+		Extension: ".txt",
+		Mimetype:  "text/plain",
+		Content:   &content,
+	}
+	fileId, err := app.CreateFile(ctx, fileParams)
+	assert.NoError(t, err)
+
+	copyRequest := func(payload string) (*httptest.ResponseRecorder, echo.Context) {
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/user/"+userIds[0].String()+"/category/"+categId.String()+"/copy",
+			strings.NewReader(payload),
+		)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		c.SetPath("/user/:userId/category/:categId/copy")
+		c.SetParamNames("userId", "categId")
+		c.SetParamValues(userIds[0].String(), categId.String())
+		setClaims(c, uuid.Nil, "Admin")
+		return rec, c
+	}
+
+	// Cenário positivo
+	t.Run(
+		"Deve_Retornar_Created_Quando_Categoria_Copiada_Com_Sucesso",
+		func(t *testing.T) {
+			payload := `{"user_ids":["` + userIds[1].String() + `","` + userIds[2].String() + `"]}`
+			rec, c := copyRequest(payload)
+
+			if assert.NoError(t, h.CopyCategoryHandler(c)) {
+				assert.Equal(t, http.StatusCreated, rec.Code)
+
+				var res h.CopyCategoryRes
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+				assert.Len(t, res.Copies, 2)
+				for i, cp := range res.Copies {
+					assert.Equal(t, userIds[i+1].String(), cp.UserId)
+
+					copiedId, err := uuid.Parse(cp.Files[fileId.String()])
+					assert.NoError(t, err)
+					file, err := app.QueryFileById(ctx, copiedId)
+					if assert.NoError(t, err) {
+						assert.Equal(t, cp.CategId, file.CategId)
+						assert.Equal(t, content, file.Blob)
+					}
+				}
+			}
+		},
+	)
+
+	// Cenário negativo
+	t.Run(
+		"Deve_Retornar_Not_Found_Quando_Usuario_Destino_Nao_Existe",
+		func(t *testing.T) {
+			payload := `{"user_ids":["` + uuid.New().String() + `"]}`
+			rec, c := copyRequest(payload)
+
+			if assert.NoError(t, h.CopyCategoryHandler(c)) {
+				assert.Equal(t, http.StatusNotFound, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UserNotFoundMessage)
+			}
+		},
+	)
+}
@@ -0,0 +1,290 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/events"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	stdctx "context"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEvents_Broker(t *testing.T) {
+	event := events.Event{Entity: events.EntityFile, Action: events.ActionCreate, Id: "1"}
+
+	t.Run(
+		"Deve_Entregar_Evento_Quando_Assinado",
+		func(t *testing.T) {
+			b := events.NewBroker(events.DefaultBuffer)
+			s1, s2 := b.Subscribe(), b.Subscribe()
+			assert.Equal(t, 2, b.Subscribers())
+
+			b.Publish(event)
+			assert.Equal(t, event, <-s1.C)
+			assert.Equal(t, event, <-s2.C)
+
+			b.Unsubscribe(s1)
+			_, ok := <-s1.C
+			assert.False(t, ok)
+			assert.Equal(t, 1, b.Subscribers())
+		},
+	)
+
+	t.Run(
+		"Deve_Descartar_Eventos_Quando_Buffer_Cheio",
+		func(t *testing.T) {
+			b := events.NewBroker(1)
+			s := b.Subscribe()
+			b.Publish(event)
+			b.Publish(event)
+			b.Publish(event)
+			assert.Len(t, s.C, 1)
+			assert.Equal(t, int64(2), s.Dropped())
+		},
+	)
+
+	t.Run(
+		"Deve_Encerrar_Assinaturas_Quando_UnsubscribeAll",
+		func(t *testing.T) {
+			b := events.NewBroker(events.DefaultBuffer)
+			s := b.Subscribe()
+			b.UnsubscribeAll()
+			_, ok := <-s.C
+			assert.False(t, ok)
+			assert.Zero(t, b.Subscribers())
+			b.Unsubscribe(s)
+		},
+	)
+
+	t.Run(
+		"Deve_Ignorar_Eventos_Quando_Broker_Nulo",
+		func(t *testing.T) {
+			var b *events.Broker
+			assert.Nil(t, b.Subscribe())
+			b.Publish(event)
+			b.UnsubscribeAll()
+			assert.Zero(t, b.Subscribers())
+		},
+	)
+}
+
+func TestAuth_CanSeeEvent(t *testing.T) {
+	adminId, userId, otherId := uuid.New(), uuid.New(), uuid.New()
+	ctx := &context.Context{AdminId: adminId}
+	user := auth.ClaimsData{Id: userId}
+
+	t.Run(
+		"Deve_Permitir_Quando_Proprio_Usuario",
+		func(t *testing.T) {
+			e := events.Event{Entity: events.EntityUser, Action: events.ActionUpdate, Id: userId.String()}
+			assert.True(t, auth.CanSeeEvent(ctx, user, e))
+		},
+	)
+
+	t.Run(
+		"Deve_Negar_Quando_Outro_Usuario_Sem_Permissao",
+		func(t *testing.T) {
+			e := events.Event{Entity: events.EntityUser, Action: events.ActionUpdate, Id: otherId.String()}
+			assert.False(t, auth.CanSeeEvent(ctx, user, e))
+			assert.True(t, auth.CanSeeEvent(ctx, auth.ClaimsData{Id: adminId}, e))
+			reader := auth.ClaimsData{Id: otherId, Permissions: []string{app.PermUsersRead}}
+			assert.True(t, auth.CanSeeEvent(ctx, reader, events.Event{Entity: events.EntityUser, Id: uuid.NewString()}))
+		},
+	)
+
+	t.Run(
+		"Deve_Permitir_Quando_Proprietario_Da_Categoria",
+		func(t *testing.T) {
+			e := events.Event{Entity: events.EntityFile, Action: events.ActionDelete, Id: "1", UserId: userId.String()}
+			assert.True(t, auth.CanSeeEvent(ctx, user, e))
+			reader := auth.ClaimsData{Id: otherId, Permissions: []string{app.PermContentRead}}
+			assert.True(t, auth.CanSeeEvent(ctx, reader, e))
+			assert.False(t, auth.CanSeeEvent(ctx, auth.ClaimsData{Id: otherId}, e))
+		},
+	)
+}
+
+func TestAuth_SessionActive(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userId, err := app.CreateUser(ctx, app.UserData{Username: "EventsUser3", Name: "EventsUser3", Password: "123456789"})
+	assert.NoError(t, err)
+	session, err := app.CreateSession(ctx, app.SessionData{UserId: userId})
+	assert.NoError(t, err)
+	data := auth.ClaimsData{Id: userId, SessionId: session.FamilyId}
+
+	t.Run(
+		"Deve_Retornar_True_Quando_Sessao_Ativa",
+		func(t *testing.T) {
+			assert.True(t, auth.SessionActive(ctx, data))
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_False_Quando_Sessao_Revogada",
+		func(t *testing.T) {
+			assert.NoError(t, app.RevokeSession(ctx, session.FamilyId))
+			assert.False(t, auth.SessionActive(ctx, data))
+		},
+	)
+}
+
+func TestAuth_EventsTicket(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userId, err := app.CreateUser(ctx, app.UserData{Username: "EventsUser4", Name: "EventsUser4", Password: "123456789"})
+	assert.NoError(t, err)
+	session, err := app.CreateSession(ctx, app.SessionData{UserId: userId})
+	assert.NoError(t, err)
+	expiresAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	claims := &auth.CustomClaims{
+		ClaimsData:       auth.ClaimsData{Id: userId, Name: "EventsUser4", SessionId: session.FamilyId},
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(expiresAt)},
+	}
+	newEchoContext := func() echo.Context {
+		return echoNewContext(httptest.NewRequest(http.MethodGet, "/auth/events", nil), httptest.NewRecorder())
+	}
+
+	t.Run(
+		"Deve_Retornar_Claims_Do_Token_Quando_Ticket_Valido",
+		func(t *testing.T) {
+			c := newEchoContext()
+			ticket, err := auth.GenerateEventsTicket(c, claims)
+			assert.NoError(t, err)
+
+			token, err := auth.ParseEventsTicket(c, ticket)
+			if assert.NoError(t, err) {
+				parsed := token.Claims.(*auth.CustomClaims)
+				assert.Equal(t, claims.ClaimsData, parsed.ClaimsData)
+				assert.Equal(t, expiresAt, parsed.ExpiresAt.Time)
+			}
+
+			// O ticket não é um token de acesso
+			_, err = auth.ParseToken(c, ticket)
+			assert.Error(t, err)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Erro_Quando_Token_De_Outra_Audiencia",
+		func(t *testing.T) {
+			c := newEchoContext()
+			mfaToken, err := auth.GenerateMfaToken(c, userId)
+			assert.NoError(t, err)
+			_, err = auth.ParseEventsTicket(c, mfaToken)
+			assert.Error(t, err)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Erro_Quando_Sessao_Revogada",
+		func(t *testing.T) {
+			c := newEchoContext()
+			ticket, err := auth.GenerateEventsTicket(c, claims)
+			assert.NoError(t, err)
+			assert.NoError(t, app.RevokeSession(ctx, session.FamilyId))
+			_, err = auth.ParseEventsTicket(c, ticket)
+			assert.Error(t, err)
+		},
+	)
+}
+
+func TestHandlers_EventsTicket(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userId, err := app.CreateUser(ctx, app.UserData{Username: "EventsUser5", Name: "EventsUser5", Password: "123456789"})
+	assert.NoError(t, err)
+
+	t.Run(
+		"Deve_Retornar_Ok_Quando_Ticket_Emitido",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/events/ticket", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, userId, "EventsUser5")
+			assert.NoError(t, h.EventsTicketHandler(c))
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			var res h.EventsTicketRes
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			assert.NotEmpty(t, res.Ticket)
+			assert.Equal(t, int(auth.EventsTicketExpires.Seconds()), res.ExpiresIn)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Sem_Token",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/auth/events/ticket", nil)
+			rec := httptest.NewRecorder()
+			assert.NoError(t, h.EventsTicketHandler(echoNewContext(req, rec)))
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		},
+	)
+}
+
+func TestHandlers_Events(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	ownerId, err := app.CreateUser(ctx, app.UserData{Username: "EventsUser1", Name: "EventsUser1", Password: "123456789"})
+	assert.NoError(t, err)
+	otherId, err := app.CreateUser(ctx, app.UserData{Username: "EventsUser2", Name: "EventsUser2", Password: "123456789"})
+	assert.NoError(t, err)
+
+	t.Run(
+		"Deve_Entregar_Apenas_Eventos_Visiveis_Quando_Conectado",
+		func(t *testing.T) {
+			reqCtx, cancel := stdctx.WithCancel(stdctx.Background())
+			req := httptest.NewRequest(http.MethodGet, "/auth/events", nil).WithContext(reqCtx)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, ownerId, "EventsUser1")
+			appCtx := context.GetContext(c)
+			appCtx.Events = events.NewBroker(events.DefaultBuffer)
+
+			done := make(chan error)
+			go func() { done <- h.EventsHandler(c) }()
+			for appCtx.Events.Subscribers() == 0 {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			// Alterações do usuário conectado e de outro usuário
+			ownCateg, err := app.CreateCategory(appCtx, app.CategData{UserId: ownerId, Name: "EventsCateg1"})
+			assert.NoError(t, err)
+			otherCateg, err := app.CreateCategory(appCtx, app.CategData{UserId: otherId, Name: "EventsCateg2"})
+			assert.NoError(t, err)
+			assert.NoError(t, app.DeleteCategory(appCtx, ownCateg, 0))
+
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+			assert.NoError(t, <-done)
+
+			body := rec.Body.String()
+			assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+			assert.Contains(t, body, `"action":"create","id":"`+ownCateg.String())
+			assert.Contains(t, body, `"action":"delete","id":"`+ownCateg.String())
+			assert.NotContains(t, body, otherCateg.String())
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_ServiceUnavailable_Quando_Fluxo_Desabilitado",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/auth/events", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, ownerId, "EventsUser1")
+			assert.NoError(t, h.EventsHandler(c))
+			assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		},
+	)
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlers_CategoryGrants(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	var userIds []uuid.UUID
+	for _, name := range []string{"GrantUser1", "GrantUser2", "GrantUser3"} {
+		userData := app.UserData{Username: name, Name: name, Password: "123456789"}
+		userId, err := app.CreateUser(ctx, userData)
+		assert.NoError(t, err)
+		userIds = append(userIds, userId)
+	}
+	ownerId, granteeId, otherId := userIds[0], userIds[1], userIds[2]
+
+	categId, err := app.CreateCategory(ctx, app.CategData{UserId: ownerId, Name: "Documentos gerais"})
+	assert.NoError(t, err)
+
+	// Cenários positivos
+	t.Run(
+		"Deve_Retornar_Created_Quando_Categoria_Compartilhada",
+		func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/user/"+ownerId.String()+"/category/"+categId.String()+"/grant",
+				strings.NewReader(`{"user_id":"`+granteeId.String()+`"}`),
+			)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/category/:categId/grant")
+			c.SetParamNames("userId", "categId")
+			c.SetParamValues(ownerId.String(), categId.String())
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.CreateGrantHandler(c)) {
+				assert.Equal(t, http.StatusCreated, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.CreatedGrantMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Listar_Categoria_Compartilhada_Quando_Usuario_Com_Concessao",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user/"+granteeId.String()+"/category", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/category")
+			c.SetParamNames("userId")
+			c.SetParamValues(granteeId.String())
+			setClaims(c, granteeId, "GrantUser2")
+
+			if assert.NoError(t, h.GetAllCategories(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"categ_id":"`+categId.String()+`"`)
+				assert.Contains(t, rec.Body.String(), `"shared":true`)
+			}
+		},
+	)
+
+	// Cenários negativos
+	t.Run(
+		"Deve_Retornar_Conflict_Quando_Concessao_Existente",
+		func(t *testing.T) {
+			grants, err := app.QueryCategoryGrants(ctx, categId)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/user/"+ownerId.String()+"/category/"+categId.String()+"/grant",
+				strings.NewReader(`{"user_id":"`+granteeId.String()+`"}`),
+			)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/category/:categId/grant")
+			c.SetParamNames("userId", "categId")
+			c.SetParamValues(ownerId.String(), categId.String())
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.CreateGrantHandler(c)) {
+				assert.Equal(t, http.StatusConflict, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.GrantExistsMessage)
+				if assert.Len(t, grants, 1) {
+					assert.Contains(t, rec.Body.String(), grants[0].GrantId)
+				}
+			}
+
+			// Nenhuma concessão criada
+			after, err := app.QueryCategoryGrants(ctx, categId)
+			assert.NoError(t, err)
+			assert.Len(t, after, len(grants))
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Usuario_Sem_Concessao",
+		func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodGet,
+				"/user/"+otherId.String()+"/category/"+categId.String()+"/file",
+				nil,
+			)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/category/:categId/file")
+			c.SetParamNames("userId", "categId")
+			c.SetParamValues(otherId.String(), categId.String())
+			setClaims(c, otherId, "GrantUser3")
+
+			if assert.NoError(t, h.GetAllFiles(c)) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UnauthorizedMessage)
+			}
+		},
+	)
+}
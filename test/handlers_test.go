@@ -3,14 +3,17 @@ package test
 import (
 	"agros_arquivos_patrocinadoras/pkg/app"
 	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/auth"
 	h "agros_arquivos_patrocinadoras/pkg/handlers"
 	"encoding/base64"
 	"fmt"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"strings"
 	"testing"
 )
@@ -22,6 +25,14 @@ func echoNewContext(req *http.Request, rec *httptest.ResponseRecorder) echo.Cont
 	return c
 }
 
+// setClaims simula o token JWT validado de um usuário na requisição.
+func setClaims(c echo.Context, userId uuid.UUID, name string) {
+	claims := &auth.CustomClaims{
+		ClaimsData: auth.ClaimsData{Id: userId, Name: name},
+	}
+	c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+}
+
 func TestHandlers_CreateUser(t *testing.T) {
 	// Mock
 	validUserJSON := `{"name": "User1", "password": "123456789"}`
@@ -914,6 +925,7 @@ func updateCategTestHelper(
 		strings.NewReader(payload),
 	)
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(h.HeaderIfMatch, "*")
 	rec := httptest.NewRecorder()
 	c := echoNewContext(req, rec)
 	c.SetPath("/user/:userId/category/:categId")
@@ -944,6 +956,7 @@ func updateFileTestHelper(
 		strings.NewReader(payload),
 	)
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(h.HeaderIfMatch, "*")
 	rec := httptest.NewRecorder()
 	c := echoNewContext(req, rec)
 	c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -992,6 +1005,7 @@ func TestHandlers_UpdateUser(t *testing.T) {
 				strings.NewReader(`{"name":"`+newName+`"}`),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId")
@@ -1017,6 +1031,7 @@ func TestHandlers_UpdateUser(t *testing.T) {
 				strings.NewReader(`{"password":"`+newPwd+`"}`),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec = httptest.NewRecorder()
 			c = echoNewContext(req, rec)
 			c.SetPath("/user/:userId")
@@ -1048,6 +1063,7 @@ func TestHandlers_UpdateUser(t *testing.T) {
 				strings.NewReader(invalidJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId")
@@ -1071,6 +1087,7 @@ func TestHandlers_UpdateUser(t *testing.T) {
 				strings.NewReader(missingRequiredFields),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId")
@@ -1095,6 +1112,7 @@ func TestHandlers_UpdateUser(t *testing.T) {
 				strings.NewReader(validUpdateJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId")
@@ -1118,6 +1136,7 @@ func TestHandlers_UpdateUser(t *testing.T) {
 				strings.NewReader(invalidPasswordJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId")
@@ -1130,6 +1149,56 @@ func TestHandlers_UpdateUser(t *testing.T) {
 			}
 		},
 	)
+
+	t.Run(
+		"Deve_Retornar_Precondition_Required_Quando_If_Match_Ausente",
+		func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodPatch,
+				"/user/"+userId.String(),
+				strings.NewReader(`{"name": "UpdatedName"}`),
+			)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId")
+			c.SetParamNames("userId")
+			c.SetParamValues(userId.String())
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.UpdateUserHandler(c)) {
+				assert.Equal(t, http.StatusPreconditionRequired, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.PreconditionRequiredMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Precondition_Failed_Quando_Versao_Desatualizada",
+		func(t *testing.T) {
+			user, err := app.QueryUserById(ctx, userId)
+			assert.NoError(t, err)
+
+			req := httptest.NewRequest(
+				http.MethodPatch,
+				"/user/"+userId.String(),
+				strings.NewReader(`{"name": "UpdatedName"}`),
+			)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, `"`+strconv.FormatInt(user.UpdatedAt-1, 10)+`"`)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId")
+			c.SetParamNames("userId")
+			c.SetParamValues(userId.String())
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.UpdateUserHandler(c)) {
+				assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.VersionConflictMessage)
+			}
+		},
+	)
 }
 
 func TestHandlers_UpdateCategory(t *testing.T) {
@@ -1193,6 +1262,7 @@ func TestHandlers_UpdateCategory(t *testing.T) {
 				strings.NewReader(invalidUpdateJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId")
@@ -1216,6 +1286,7 @@ func TestHandlers_UpdateCategory(t *testing.T) {
 				strings.NewReader(missingFieldsJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId")
@@ -1240,6 +1311,7 @@ func TestHandlers_UpdateCategory(t *testing.T) {
 				strings.NewReader(validUpdateJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId")
@@ -1264,6 +1336,7 @@ func TestHandlers_UpdateCategory(t *testing.T) {
 				strings.NewReader(validUpdateJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId")
@@ -1373,6 +1446,7 @@ func TestHandlers_UpdateFile(t *testing.T) {
 				strings.NewReader(invalidUpdateJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1396,6 +1470,7 @@ func TestHandlers_UpdateFile(t *testing.T) {
 				strings.NewReader(missingFieldsJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1419,6 +1494,7 @@ func TestHandlers_UpdateFile(t *testing.T) {
 				strings.NewReader(validUpdateJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1442,6 +1518,7 @@ func TestHandlers_UpdateFile(t *testing.T) {
 				strings.NewReader(validUpdateJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1465,6 +1542,7 @@ func TestHandlers_UpdateFile(t *testing.T) {
 				strings.NewReader(validUpdateJSON),
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1500,6 +1578,7 @@ func TestHandlers_DeleteUser(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId")
@@ -1524,6 +1603,7 @@ func TestHandlers_DeleteUser(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId")
@@ -1547,6 +1627,7 @@ func TestHandlers_DeleteUser(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId")
@@ -1559,6 +1640,33 @@ func TestHandlers_DeleteUser(t *testing.T) {
 			}
 		},
 	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Alvo_Administrador",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/user/"+uuid.Nil.String(), nil)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId")
+			c.SetParamNames("userId")
+			c.SetParamValues(context.GetContext(c).AdminId.String())
+			claims := &auth.CustomClaims{
+				ClaimsData: auth.ClaimsData{
+					Id:          userId,
+					Name:        userData.Name,
+					Permissions: []string{app.PermUsersWrite},
+				},
+			}
+			c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+
+			if assert.NoError(t, h.DeleteUser(c)) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UnauthorizedMessage)
+			}
+		},
+	)
 }
 
 func TestHandlers_DeleteCategory(t *testing.T) {
@@ -1586,6 +1694,7 @@ func TestHandlers_DeleteCategory(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId")
@@ -1610,6 +1719,7 @@ func TestHandlers_DeleteCategory(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId")
@@ -1633,6 +1743,7 @@ func TestHandlers_DeleteCategory(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId")
@@ -1656,6 +1767,7 @@ func TestHandlers_DeleteCategory(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId")
@@ -1679,6 +1791,7 @@ func TestHandlers_DeleteCategory(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId")
@@ -1729,6 +1842,7 @@ func TestHandlers_DeleteFile(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1753,6 +1867,7 @@ func TestHandlers_DeleteFile(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1776,6 +1891,7 @@ func TestHandlers_DeleteFile(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1799,6 +1915,7 @@ func TestHandlers_DeleteFile(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1822,6 +1939,7 @@ func TestHandlers_DeleteFile(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1845,6 +1963,7 @@ func TestHandlers_DeleteFile(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -1868,6 +1987,7 @@ func TestHandlers_DeleteFile(t *testing.T) {
 				nil,
 			)
 			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(h.HeaderIfMatch, "*")
 			rec := httptest.NewRecorder()
 			c := echoNewContext(req, rec)
 			c.SetPath("/user/:userId/category/:categId/file/:fileId")
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"database/sql"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// queryPasswordHash obtém o hash da senha armazenado de um usuário.
+func queryPasswordHash(t *testing.T, ctx *context.Context, userId uuid.UUID) string {
+	schema := &ctx.Config.Database.Schema
+	query := fmt.Sprintf(
+		`SELECT %s FROM %s.%s WHERE %s = :user_id`,
+		schema.UserTable.Columns.Password,
+		schema.Name,
+		schema.UserTable.Name,
+		schema.UserTable.Columns.UserId,
+	)
+	var hash string
+	err := ctx.DB.QueryRow(query, sql.Named("user_id", userId.String())).Scan(&hash)
+	assert.NoError(t, err)
+	return hash
+}
+
+func TestApp_PasswordHasher(t *testing.T) {
+	argon := app.Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+	t.Run(
+		"Deve_Verificar_Senha_Quando_Hash_Argon2id",
+		func(t *testing.T) {
+			hash, err := argon.Hash("Agros#2025")
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+			assert.True(t, app.VerifyPassword(hash, "Agros#2025"))
+			assert.False(t, app.VerifyPassword(hash, "Agros#2026"))
+			assert.False(t, argon.NeedsRehash(hash))
+		},
+	)
+
+	t.Run(
+		"Deve_Verificar_Senha_Quando_Hash_Bcrypt",
+		func(t *testing.T) {
+			hash, err := app.BcryptHasher{Cost: 4}.Hash("Agros#2025")
+			assert.NoError(t, err)
+			assert.True(t, app.VerifyPassword(hash, "Agros#2025"))
+			assert.False(t, app.VerifyPassword(hash, "Agros#2026"))
+			assert.True(t, argon.NeedsRehash(hash))
+			assert.True(t, app.BcryptHasher{Cost: 5}.NeedsRehash(hash))
+		},
+	)
+
+	t.Run(
+		"Deve_Exigir_Novo_Hash_Quando_Parametros_Alterados",
+		func(t *testing.T) {
+			hash, err := argon.Hash("Agros#2025")
+			assert.NoError(t, err)
+			stronger := app.Argon2idHasher{Memory: 2048, Iterations: 1, Parallelism: 1}
+			assert.True(t, stronger.NeedsRehash(hash))
+			assert.True(t, app.VerifyPassword(hash, "Agros#2025"))
+		},
+	)
+
+	t.Run(
+		"Deve_Rejeitar_Senha_Quando_Hash_Invalido",
+		func(t *testing.T) {
+			assert.False(t, app.VerifyPassword("$argon2id$v=19$m=1024$x$y", "Agros#2025"))
+			assert.False(t, app.VerifyPassword("", ""))
+		},
+	)
+}
+
+func TestApp_PasswordRehash(t *testing.T) {
+	// Mock: usuário com hash bcrypt
+	ctx := newContext()
+	ctx.Config.PasswordHash.Algorithm = "bcrypt"
+	userData := app.UserData{Username: "RehashUser", Name: "RehashUser", Password: "123456789"}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(queryPasswordHash(t, ctx, userId), "$2"))
+
+	t.Run(
+		"Deve_Atualizar_Hash_Quando_Algoritmo_Desatualizado",
+		func(t *testing.T) {
+			ctx.Config.PasswordHash.Algorithm = "argon2id"
+			data, err := app.QueryLogin(ctx, app.LoginParams{Username: "RehashUser", Password: "123456789"})
+			assert.NoError(t, err)
+			assert.Equal(t, userId, data.UserId)
+
+			hash := queryPasswordHash(t, ctx, userId)
+			assert.True(t, strings.HasPrefix(hash, "$argon2id$"))
+			assert.False(t, app.PasswordNeedsRehash(ctx, hash))
+
+			// O novo hash continua aceito
+			_, err = app.QueryLogin(ctx, app.LoginParams{Username: "RehashUser", Password: "123456789"})
+			assert.NoError(t, err)
+		},
+	)
+
+	t.Run(
+		"Deve_Manter_Hash_Quando_Senha_Incorreta",
+		func(t *testing.T) {
+			before := queryPasswordHash(t, ctx, userId)
+			ctx.Config.PasswordHash.Argon2Iterations++
+			_, err := app.QueryLogin(ctx, app.LoginParams{Username: "RehashUser", Password: "987654321"})
+			assert.ErrorIs(t, err, app.ErrNotAuthenticated)
+			assert.Equal(t, before, queryPasswordHash(t, ctx, userId))
+		},
+	)
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlers_InboxUpload(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userData := app.UserData{Username: "InboxUser", Name: "InboxUser", Password: "123456789"}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+
+	inboxId, err := app.CreateCategory(ctx, app.CategData{UserId: userId, Name: "Contratos assinados"})
+	assert.NoError(t, err)
+	readOnlyId, err := app.CreateCategory(ctx, app.CategData{UserId: userId, Name: "Materiais"})
+	assert.NoError(t, err)
+
+	upload := func(categId uuid.UUID, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(
+			http.MethodPost,
+			"/user/"+userId.String()+"/category/"+categId.String()+"/file",
+			strings.NewReader(body),
+		)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		c.SetPath("/user/:userId/category/:categId/file")
+		c.SetParamNames("userId", "categId")
+		c.SetParamValues(userId.String(), categId.String())
+		setClaims(c, userId, "InboxUser")
+		assert.NoError(t, h.CreateFileHandler(c))
+		return rec
+	}
+	pdf := `{"name":"contrato","extension":"pdf","mimetype":"application/pdf","content":"JVBERi0xLjQ="}`
+	png := `{"name":"logo","extension":"png","mimetype":"image/png","content":"iVBORw0KGgo="}`
+
+	// Cenários positivos
+	t.Run(
+		"Deve_Retornar_OK_Quando_Categoria_Marcada_Como_Caixa_De_Entrada",
+		func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodPut,
+				"/user/"+userId.String()+"/category/"+inboxId.String()+"/inbox",
+				strings.NewReader(`{"max_file_size":1024,"allowed_types":["pdf"]}`),
+			)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/category/:categId/inbox")
+			c.SetParamNames("userId", "categId")
+			c.SetParamValues(userId.String(), inboxId.String())
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.SetInboxHandler(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UpdatedInboxMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Created_E_Notificar_Quando_Envio_A_Caixa_De_Entrada",
+		func(t *testing.T) {
+			rec := upload(inboxId, pdf)
+			assert.Equal(t, http.StatusCreated, rec.Code)
+			assert.Contains(t, rec.Body.String(), h.CreatedFileMessage)
+
+			notifications, err := app.QueryNotifications(ctx, true)
+			assert.NoError(t, err)
+			if assert.NotEmpty(t, notifications) {
+				assert.Equal(t, app.NotificationInboxUpload, notifications[0].Kind)
+				assert.Equal(t, userId.String(), notifications[0].UserId)
+			}
+		},
+	)
+
+	// Cenários negativos
+	t.Run(
+		"Deve_Retornar_UnsupportedMediaType_Quando_Tipo_Nao_Permitido",
+		func(t *testing.T) {
+			rec := upload(inboxId, png)
+			assert.Equal(t, http.StatusUnsupportedMediaType, rec.Code)
+			assert.Contains(t, rec.Body.String(), h.FileTypeNotAllowedMessage)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Categoria_Somente_Leitura",
+		func(t *testing.T) {
+			rec := upload(readOnlyId, pdf)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Contains(t, rec.Body.String(), h.UnauthorizedMessage)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Envio_De_Outro_Usuario",
+		func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/user/"+userId.String()+"/category/"+inboxId.String()+"/file",
+				strings.NewReader(`{`),
+			)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/category/:categId/file")
+			c.SetParamNames("userId", "categId")
+			c.SetParamValues(userId.String(), inboxId.String())
+			setClaims(c, uuid.New(), "OtherUser")
+
+			// Permissão verificada antes da leitura do corpo
+			if assert.NoError(t, h.CreateFileHandler(c)) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UnauthorizedMessage)
+			}
+		},
+	)
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/keyring"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeKeyPair grava a chave privada (PKCS#8) e a pública (PKIX) em arquivos
+// PEM, retornando seus caminhos.
+func writeKeyPair(t *testing.T, name string, private crypto.Signer) (string, string) {
+	dir := t.TempDir()
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+	privateFile := filepath.Join(dir, name+".pem")
+	assert.NoError(t, os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+
+	der, err = x509.MarshalPKIXPublicKey(private.Public())
+	assert.NoError(t, err)
+	publicFile := filepath.Join(dir, name+".pub.pem")
+	assert.NoError(t, os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o644))
+	return privateFile, publicFile
+}
+
+func TestAuth_Keyring(t *testing.T) {
+	// Mock: chave RSA antiga e chave Ed25519 nova
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	rsaPrivate, rsaPublic := writeKeyPair(t, "rsa", rsaKey)
+	edPrivate, _ := writeKeyPair(t, "ed", edKey)
+
+	newKeyContext := func(keys *keyring.Keyring) echo.Context {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		c := echoNewContext(req, httptest.NewRecorder())
+		context.GetContext(c).Keyring = keys
+		return c
+	}
+	userId := uuid.New()
+
+	// Token assinado pela chave antiga
+	oldKeys, err := keyring.Load(config.JwtKeys{
+		SigningKey: "2026-04",
+		Keys:       []config.JwtKey{{Id: "2026-04", Algorithm: "RS256", PrivateKeyFile: rsaPrivate}},
+	})
+	assert.NoError(t, err)
+	oldToken, err := auth.GenerateMfaToken(newKeyContext(oldKeys), userId)
+	assert.NoError(t, err)
+
+	// Rotação: chave nova de assinatura e chave antiga apenas de verificação
+	newKeys, err := keyring.Load(config.JwtKeys{
+		SigningKey: "2026-10",
+		Keys: []config.JwtKey{
+			{Id: "2026-10", Algorithm: "EdDSA", PrivateKeyFile: edPrivate},
+			{Id: "2026-04", Algorithm: "RS256", PublicKeyFile: rsaPublic},
+		},
+	})
+	assert.NoError(t, err)
+
+	t.Run(
+		"Deve_Validar_Token_Da_Chave_Antiga_Quando_Chave_Rotacionada",
+		func(t *testing.T) {
+			id, err := auth.ParseMfaToken(newKeyContext(newKeys), oldToken)
+			assert.NoError(t, err)
+			assert.Equal(t, userId, id)
+		},
+	)
+
+	t.Run(
+		"Deve_Assinar_Com_Chave_Nova_Quando_Chave_Rotacionada",
+		func(t *testing.T) {
+			c := newKeyContext(newKeys)
+			token, err := auth.GenerateMfaToken(c, userId)
+			assert.NoError(t, err)
+			id, err := auth.ParseMfaToken(c, token)
+			assert.NoError(t, err)
+			assert.Equal(t, userId, id)
+
+			// A chave antiga não valida os tokens da chave nova
+			_, err = auth.ParseMfaToken(newKeyContext(oldKeys), token)
+			assert.Error(t, err)
+		},
+	)
+
+	t.Run(
+		"Deve_Rejeitar_Token_HS256_Quando_Nao_Aceito",
+		func(t *testing.T) {
+			hsToken, err := auth.GenerateMfaToken(newKeyContext(nil), userId)
+			assert.NoError(t, err)
+			_, err = auth.ParseMfaToken(newKeyContext(newKeys), hsToken)
+			assert.Error(t, err)
+		},
+	)
+
+	t.Run(
+		"Deve_Publicar_Chaves_Publicas_Quando_JWKS",
+		func(t *testing.T) {
+			jwks := newKeys.JWKS()
+			if assert.Len(t, jwks.Keys, 2) {
+				assert.Equal(t, "2026-10", jwks.Keys[0].Kid)
+				assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+				assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+				assert.NotEmpty(t, jwks.Keys[1].N)
+			}
+			assert.Empty(t, (*keyring.Keyring)(nil).JWKS().Keys)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Erro_Quando_Chave_De_Assinatura_Sem_Chave_Privada",
+		func(t *testing.T) {
+			_, err := keyring.Load(config.JwtKeys{
+				SigningKey: "2026-04",
+				Keys:       []config.JwtKey{{Id: "2026-04", Algorithm: "RS256", PublicKeyFile: rsaPublic}},
+			})
+			assert.Error(t, err)
+
+			_, err = keyring.Load(config.JwtKeys{
+				SigningKey: "2026-04",
+				Keys:       []config.JwtKey{{Id: "2026-04", Algorithm: "EdDSA", PrivateKeyFile: rsaPrivate}},
+			})
+			assert.Error(t, err)
+		},
+	)
+}
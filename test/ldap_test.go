@@ -0,0 +1,98 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestApp_LdapUserDN(t *testing.T) {
+	template := "uid={username},ou=people,dc=agros,dc=org,dc=br"
+	assert.Equal(t, "uid=joao,ou=people,dc=agros,dc=org,dc=br", app.LdapUserDN(template, "joao"))
+	assert.Equal(t, `uid=a\,b,ou=people,dc=agros,dc=org,dc=br`, app.LdapUserDN(template, "a,b"))
+}
+
+func TestApp_LdapFallback(t *testing.T) {
+	// Mock: servidor LDAP indisponível
+	ctx := newContext()
+	userData := app.UserData{Username: "LdapFallbackUser", Name: "LdapFallbackUser", Password: "123456789"}
+	_, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+	ctx.Config.Ldap = config.Ldap{
+		Enabled:        true,
+		Url:            "ldap://127.0.0.1:1",
+		Timeout:        1,
+		UserDnTemplate: "uid={username},ou=people,dc=agros,dc=org,dc=br",
+	}
+	params := app.LoginParams{Username: userData.Username, Password: userData.Password}
+
+	t.Run(
+		"Deve_Recusar_Login_Quando_Bind_Falha_Sem_Senha_Local",
+		func(t *testing.T) {
+			_, err := app.QueryLogin(ctx, params)
+			assert.ErrorIs(t, err, app.ErrNotAuthenticated)
+		},
+	)
+
+	t.Run(
+		"Deve_Autenticar_Com_Senha_Local_Quando_Bind_Falha",
+		func(t *testing.T) {
+			ctx.Config.Ldap.LocalFallback = true
+			login, err := app.QueryLogin(ctx, params)
+			assert.NoError(t, err)
+			assert.Equal(t, userData.Name, login.Name)
+		},
+	)
+
+	t.Run(
+		"Deve_Recusar_Login_Quando_Senha_Vazia",
+		func(t *testing.T) {
+			_, err := app.LdapAuthenticator{}.Authenticate(ctx, app.LoginParams{Username: userData.Username})
+			assert.ErrorIs(t, err, app.ErrNotAuthenticated)
+		},
+	)
+}
+
+// TestApp_LdapLogin autentica em um servidor LDAP real (ex.: contêiner
+// OpenLDAP ou glauth), informado pelas variáveis LDAP_TEST_URL,
+// LDAP_TEST_USER_DN_TEMPLATE, LDAP_TEST_USERNAME e LDAP_TEST_PASSWORD.
+func TestApp_LdapLogin(t *testing.T) {
+	ldapUrl := os.Getenv("LDAP_TEST_URL")
+	if ldapUrl == "" {
+		t.Skip("LDAP_TEST_URL não definida")
+	}
+	ctx := newContext()
+	ctx.Config.Ldap = config.Ldap{
+		Enabled:        true,
+		Url:            ldapUrl,
+		Timeout:        5,
+		UserDnTemplate: os.Getenv("LDAP_TEST_USER_DN_TEMPLATE"),
+		AutoProvision:  true,
+	}
+	username := os.Getenv("LDAP_TEST_USERNAME")
+	password := os.Getenv("LDAP_TEST_PASSWORD")
+
+	t.Run(
+		"Deve_Criar_Usuario_Quando_Bind_Valido",
+		func(t *testing.T) {
+			login, err := app.QueryLogin(ctx, app.LoginParams{Username: username, Password: password})
+			assert.NoError(t, err)
+			assert.NotEmpty(t, login.UserId)
+
+			// Usuário já criado: mesmo identificador
+			again, err := app.QueryLogin(ctx, app.LoginParams{Username: username, Password: password})
+			assert.NoError(t, err)
+			assert.Equal(t, login.UserId, again.UserId)
+		},
+	)
+
+	t.Run(
+		"Deve_Recusar_Login_Quando_Senha_Invalida",
+		func(t *testing.T) {
+			_, err := app.QueryLogin(ctx, app.LoginParams{Username: username, Password: password + "x"})
+			assert.ErrorIs(t, err, app.ErrNotAuthenticated)
+		},
+	)
+}
@@ -0,0 +1,142 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/lockout"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockout_Tracker(t *testing.T) {
+	policy := config.Lockout{MaxAttempts: 3, IpMaxAttempts: 10, BaseDelay: 30, MaxDelay: 900}
+
+	t.Run(
+		"Deve_Bloquear_Usuario_Quando_Limite_Atingido",
+		func(t *testing.T) {
+			tracker := lockout.New()
+			assert.Empty(t, tracker.Fail(policy, "User", "10.0.0.1"))
+			assert.Empty(t, tracker.Fail(policy, "user", "10.0.0.2"))
+			assert.Zero(t, tracker.Check("user", "10.0.0.3"))
+
+			locks := tracker.Fail(policy, "USER", "10.0.0.3")
+			if assert.Len(t, locks, 1) {
+				assert.Equal(t, lockout.UserKey("user"), locks[0].Key)
+			}
+			assert.Positive(t, tracker.Check("user", "10.0.0.4"))
+		},
+	)
+
+	t.Run(
+		"Deve_Dobrar_Bloqueio_Quando_Novas_Falhas",
+		func(t *testing.T) {
+			tracker := lockout.New()
+			now := time.Now()
+			var locks []lockout.Lock
+			for i := 0; i < policy.MaxAttempts+1; i++ {
+				locks = append(locks, tracker.Fail(policy, "user", "10.0.0.1")...)
+			}
+			if assert.Len(t, locks, 2) {
+				assert.WithinDuration(t, now.Add(30*time.Second), locks[0].Until, time.Second)
+				assert.WithinDuration(t, now.Add(60*time.Second), locks[1].Until, time.Second)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Bloquear_IP_Quando_Limite_Atingido",
+		func(t *testing.T) {
+			tracker := lockout.New()
+			for i := 0; i < policy.IpMaxAttempts; i++ {
+				tracker.Fail(policy, uuid.NewString(), "10.0.0.1")
+			}
+			assert.Positive(t, tracker.Check("other", "10.0.0.1"))
+			assert.Zero(t, tracker.Check("other", "10.0.0.2"))
+		},
+	)
+
+	t.Run(
+		"Deve_Desbloquear_Usuario_Quando_Unlock",
+		func(t *testing.T) {
+			tracker := lockout.New()
+			for i := 0; i < policy.MaxAttempts; i++ {
+				tracker.Fail(policy, "user", uuid.NewString())
+			}
+			assert.True(t, tracker.Unlock("user"))
+			assert.Zero(t, tracker.Check("user", "10.0.0.1"))
+			assert.False(t, tracker.Unlock("user"))
+		},
+	)
+
+	t.Run(
+		"Deve_Ignorar_Quando_Tracker_Nulo",
+		func(t *testing.T) {
+			var tracker *lockout.Tracker
+			assert.Nil(t, tracker.Fail(policy, "user", "10.0.0.1"))
+			assert.Zero(t, tracker.Check("user", "10.0.0.1"))
+		},
+	)
+}
+
+func TestHandlers_LoginLockout(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userData := app.UserData{Username: "LockoutUser", Name: "LockoutUser", Password: "123456789"}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+	tracker := lockout.New()
+
+	login := func(password string) *httptest.ResponseRecorder {
+		body := `{"username":"LockoutUser","password":"` + password + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		context.GetContext(c).Lockout = tracker
+		assert.NoError(t, h.LoginHandler(c))
+		return rec
+	}
+
+	// Cenários
+	t.Run(
+		"Deve_Retornar_Too_Many_Requests_Quando_Usuario_Bloqueado",
+		func(t *testing.T) {
+			for i := 0; i < ctx.Config.Lockout.MaxAttempts; i++ {
+				assert.Equal(t, http.StatusUnauthorized, login("senhaerrada").Code)
+			}
+
+			rec := login("123456789")
+			assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+			assert.NotEmpty(t, rec.Header().Get(echo.HeaderRetryAfter))
+			assert.Contains(t, rec.Body.String(), h.LoginLockedMessage)
+		},
+	)
+
+	t.Run(
+		"Deve_Permitir_Login_Quando_Usuario_Desbloqueado",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/user/"+userId.String()+"/lockout", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			context.GetContext(c).Lockout = tracker
+			c.SetPath("/user/:userId/lockout")
+			c.SetParamNames("userId")
+			c.SetParamValues(userId.String())
+			setClaims(c, uuid.Nil, "admin")
+
+			if assert.NoError(t, h.UnlockUserHandler(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UnlockedUserMessage)
+			}
+			assert.Equal(t, http.StatusOK, login("123456789").Code)
+		},
+	)
+}
@@ -0,0 +1,194 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/mailer"
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"bufio"
+	"github.com/stretchr/testify/assert"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpSink é um servidor SMTP mínimo, que aceita qualquer e-mail e guarda o
+// conteúdo recebido (como o MailHog).
+func smtpSink(t *testing.T) (config.Smtp, <-chan string) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = ln.Close() })
+	received := make(chan string, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = conn.Write([]byte(s + "\r\n")) }
+
+		reply("220 localhost")
+		var data strings.Builder
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 localhost")
+			case cmd == "DATA":
+				reply("354 fim com <CRLF>.<CRLF>")
+				for {
+					line, err = r.ReadString('\n')
+					if err != nil || line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 OK")
+			case cmd == "QUIT":
+				reply("221 tchau")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	return config.Smtp{Enabled: true, Host: host, Port: p, From: "arquivos@agros.org.br", Timeout: 5}, received
+}
+
+func TestMailer_Batcher(t *testing.T) {
+	at := time.Now()
+
+	t.Run(
+		"Deve_Agrupar_Eventos_Quando_Rajada_De_Envios",
+		func(t *testing.T) {
+			var mu sync.Mutex
+			flushed := make(map[string][]mailer.Event)
+			done := make(chan struct{}, 2)
+			b := mailer.NewBatcher(100*time.Millisecond, func(key string, events []mailer.Event) {
+				mu.Lock()
+				flushed[key] = events
+				mu.Unlock()
+				done <- struct{}{}
+			})
+
+			b.Add("a", mailer.Event{FileId: "1", Action: mailer.ActionCreated, At: at})
+			b.Add("a", mailer.Event{FileId: "2", Action: mailer.ActionUpdated, At: at})
+			b.Add("b", mailer.Event{FileId: "3", Action: mailer.ActionCreated, At: at})
+			assert.Equal(t, 2, b.Pending())
+
+			for i := 0; i < 2; i++ {
+				select {
+				case <-done:
+				case <-time.After(2 * time.Second):
+					t.Fatal("eventos não entregues")
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			assert.Len(t, flushed["a"], 2)
+			assert.Len(t, flushed["b"], 1)
+			assert.Zero(t, b.Pending())
+		},
+	)
+
+	t.Run(
+		"Deve_Manter_Criacao_Quando_Arquivo_Alterado_Na_Janela",
+		func(t *testing.T) {
+			var flushed []mailer.Event
+			b := mailer.NewBatcher(time.Hour, func(_ string, events []mailer.Event) { flushed = events })
+
+			b.Add("a", mailer.Event{FileId: "1", FileName: "v1.pdf", Action: mailer.ActionCreated, At: at})
+			b.Add("a", mailer.Event{FileId: "1", FileName: "v2.pdf", Action: mailer.ActionUpdated, At: at})
+			b.Flush()
+
+			if assert.Len(t, flushed, 1) {
+				assert.Equal(t, "v2.pdf", flushed[0].FileName)
+				assert.Equal(t, mailer.ActionCreated, flushed[0].Action)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Ignorar_Eventos_Quando_Batcher_Nulo",
+		func(t *testing.T) {
+			var b *mailer.Batcher
+			b.Add("a", mailer.Event{FileId: "1"})
+			b.Flush()
+			assert.Zero(t, b.Pending())
+		},
+	)
+}
+
+func TestMailer_Render(t *testing.T) {
+	at := time.Date(2025, 3, 10, 14, 30, 0, 0, time.Local)
+	file := mailer.Event{FileId: "1", FileName: "relatorio.pdf", CategName: "Relatórios", Action: mailer.ActionCreated, At: at}
+
+	t.Run(
+		"Deve_Gerar_Email_Quando_Um_Arquivo",
+		func(t *testing.T) {
+			msg, err := mailer.Render("contato@agros.org.br", "Patrocinadora", "https://arquivos.agros.org.br/", []mailer.Event{file})
+			assert.NoError(t, err)
+			assert.Equal(t, "contato@agros.org.br", msg.To)
+			assert.Equal(t, "Arquivo novo: relatorio.pdf", msg.Subject)
+			assert.Contains(t, msg.Body, "Olá, Patrocinadora.")
+			assert.Contains(t, msg.Body, `relatorio.pdf (novo), em "Relatórios", 10/03/2025 14:30`)
+			assert.Contains(t, msg.Body, "Acesse https://arquivos.agros.org.br para")
+			assert.Contains(t, msg.Body, "desabilite as notificações por e-mail")
+		},
+	)
+
+	t.Run(
+		"Deve_Listar_Arquivos_Quando_Varios_Eventos",
+		func(t *testing.T) {
+			updated := file
+			updated.FileId, updated.FileName, updated.Action = "2", "balanco.xlsx", mailer.ActionUpdated
+			msg, err := mailer.Render("contato@agros.org.br", "Patrocinadora", "", []mailer.Event{file, updated})
+			assert.NoError(t, err)
+			assert.Equal(t, "2 arquivos novos ou atualizados", msg.Subject)
+			assert.Contains(t, msg.Body, "balanco.xlsx (atualizado)")
+			assert.NotContains(t, msg.Body, "Acesse")
+		},
+	)
+}
+
+func TestMailer_SmtpSender(t *testing.T) {
+	t.Run(
+		"Deve_Enviar_Email_Quando_Servidor_Disponivel",
+		func(t *testing.T) {
+			cfg, received := smtpSink(t)
+			msg := mailer.Message{To: "contato@agros.org.br", Subject: "Arquivo novo: relatório.pdf", Body: "Olá.\n"}
+			assert.NoError(t, mailer.SmtpSender{Config: cfg}.Send(msg))
+
+			select {
+			case data := <-received:
+				assert.Contains(t, data, "To: contato@agros.org.br")
+				assert.Contains(t, data, "Subject: =?utf-8?q?Arquivo_novo:_relat=C3=B3rio.pdf?=")
+				assert.Contains(t, data, "Content-Transfer-Encoding: quoted-printable")
+			case <-time.After(2 * time.Second):
+				t.Fatal("e-mail não recebido")
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Erro_Quando_Servidor_Indisponivel",
+		func(t *testing.T) {
+			cfg, _ := smtpSink(t)
+			cfg.Port = 1
+			assert.Error(t, mailer.SmtpSender{Config: cfg}.Send(mailer.Message{To: "contato@agros.org.br"}))
+		},
+	)
+}
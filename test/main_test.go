@@ -1,6 +1,7 @@
 package test
 
 import (
+	"agros_arquivos_patrocinadoras/pkg/app"
 	"agros_arquivos_patrocinadoras/pkg/app/config"
 	"agros_arquivos_patrocinadoras/pkg/app/context"
 	"agros_arquivos_patrocinadoras/pkg/app/db"
@@ -9,6 +10,7 @@ import (
 	"fmt"
 	"go.uber.org/zap"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -52,6 +54,128 @@ func TestMain(m *testing.M) {
 		schema.Name,
 		schema.CategTable.Name,
 	)
+	delDownloads := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.DownloadTable.Name,
+	)
+	delAudit := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.AuditTable.Name,
+	)
+	delGrants := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.CategGrantTable.Name,
+	)
+	// Papéis criados pelos testes (os papéis padrão são mantidos)
+	rc := &schema.RoleTable.Columns
+	var defaultNames []string
+	var roleArgs []any
+	for name := range app.DefaultRoles {
+		bind := fmt.Sprintf("role_%d", len(roleArgs))
+		defaultNames = append(defaultNames, ":"+bind)
+		roleArgs = append(roleArgs, sql.Named(bind, name))
+	}
+	testRoles := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE %s NOT IN (%s)",
+		rc.RoleId,
+		schema.Name,
+		schema.RoleTable.Name,
+		rc.Name,
+		strings.Join(defaultNames, ","),
+	)
+	testUsers := fmt.Sprintf(
+		"SELECT %s FROM %s.%s WHERE %s <> :adminName",
+		schema.UserTable.Columns.UserId,
+		schema.Name,
+		schema.UserTable.Name,
+		schema.UserTable.Columns.Name,
+	)
+	delUserRoles := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE %s IN (%s) OR %s IN (%s)",
+		schema.Name,
+		schema.UserRoleTable.Name,
+		schema.UserRoleTable.Columns.UserId,
+		testUsers,
+		schema.UserRoleTable.Columns.RoleId,
+		testRoles,
+	)
+	delRolePermissions := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE %s IN (%s)",
+		schema.Name,
+		schema.RolePermissionTable.Name,
+		schema.RolePermissionTable.Columns.RoleId,
+		testRoles,
+	)
+	delRoles := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE %s IN (%s)",
+		schema.Name,
+		schema.RoleTable.Name,
+		rc.RoleId,
+		testRoles,
+	)
+	delInboxes := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.InboxTable.Name,
+	)
+	delNotifications := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.NotificationTable.Name,
+	)
+	delRefreshTokens := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.RefreshTokenTable.Name,
+	)
+	delSessions := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.SessionTable.Name,
+	)
+	delPasswordResets := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.PasswordResetTable.Name,
+	)
+	delTotp := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.TotpTable.Name,
+	)
+	delRecoveryCodes := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.RecoveryCodeTable.Name,
+	)
+	delIdentities := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.UserIdentityTable.Name,
+	)
+	delApiKeyScopes := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.ApiKeyScopeTable.Name,
+	)
+	delApiKeys := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.ApiKeyTable.Name,
+	)
+	delWebhookDeliveries := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.WebhookDeliveryTable.Name,
+	)
+	delWebhooks := fmt.Sprintf(
+		"DELETE FROM %s.%s WHERE 0 = 0",
+		schema.Name,
+		schema.WebhookTable.Name,
+	)
 	delFiles := fmt.Sprintf(
 		"DELETE FROM %s.%s WHERE 0 = 0",
 		schema.Name,
@@ -62,6 +186,24 @@ func TestMain(m *testing.M) {
 	if err != nil {
 		panic(err)
 	}
+	_, _ = tx.Exec(delAudit)
+	_, _ = tx.Exec(delDownloads)
+	_, _ = tx.Exec(delGrants)
+	_, _ = tx.Exec(delUserRoles, append(roleArgs, sql.Named("adminName", ctx.Config.AdminName))...)
+	_, _ = tx.Exec(delRolePermissions, roleArgs...)
+	_, _ = tx.Exec(delRoles, roleArgs...)
+	_, _ = tx.Exec(delRefreshTokens)
+	_, _ = tx.Exec(delSessions)
+	_, _ = tx.Exec(delPasswordResets)
+	_, _ = tx.Exec(delTotp)
+	_, _ = tx.Exec(delRecoveryCodes)
+	_, _ = tx.Exec(delIdentities)
+	_, _ = tx.Exec(delApiKeyScopes)
+	_, _ = tx.Exec(delApiKeys)
+	_, _ = tx.Exec(delWebhookDeliveries)
+	_, _ = tx.Exec(delWebhooks)
+	_, _ = tx.Exec(delNotifications)
+	_, _ = tx.Exec(delInboxes)
 	_, _ = tx.Exec(delFiles)
 	_, _ = tx.Exec(delCategories)
 	_, _ = tx.Exec(delUsers, sql.Named("adminName", ctx.Config.AdminName))
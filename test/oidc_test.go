@@ -0,0 +1,188 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/oidc"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// mockIdP é um provedor OpenID Connect local, que emite ID tokens para o
+// subject e o e-mail configurados.
+type mockIdP struct {
+	server    *httptest.Server
+	key       *rsa.PrivateKey
+	challenge string
+	nonce     string
+	subject   string
+	email     string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	idp := &mockIdP{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(oidc.Discovery{
+			Issuer:                idp.server.URL,
+			AuthorizationEndpoint: idp.server.URL + "/authorize",
+			TokenEndpoint:         idp.server.URL + "/token",
+			JwksUri:               idp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		// PKCE: o code verifier deve corresponder ao code challenge
+		if r.PostFormValue("code") != "code-ok" || oidc.CodeChallenge(r.PostFormValue("code_verifier")) != idp.challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"iss":            idp.server.URL,
+			"aud":            "agros",
+			"sub":            idp.subject,
+			"email":          idp.email,
+			"email_verified": true,
+			"name":           "Usuário SSO",
+			"nonce":          idp.nonce,
+			"iat":            time.Now().Unix(),
+			"exp":            time.Now().Add(time.Minute).Unix(),
+		})
+		token.Header["kid"] = "test"
+		raw, _ := token.SignedString(key)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id_token": raw, "token_type": "Bearer"})
+	})
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+// oidcConfig configura o contexto da requisição para o provedor local.
+func (idp *mockIdP) oidcConfig(c echo.Context, autoProvision bool) {
+	ctx := context.GetContext(c)
+	ctx.Config.Oidc = config.Oidc{
+		Enabled:           true,
+		Issuer:            idp.server.URL,
+		ClientId:          "agros",
+		RedirectUrl:       "http://localhost/oidc/callback",
+		Scopes:            []string{"openid", "email", "profile"},
+		AutoProvision:     autoProvision,
+		PostLoginRedirect: "/",
+	}
+	ctx.Oidc = oidc.New(idp.server.Client())
+}
+
+// login executa o redirecionamento ao provedor e o retorno, devolvendo a
+// resposta do retorno.
+func (idp *mockIdP) login(t *testing.T, autoProvision bool, code string) *httptest.ResponseRecorder {
+	// Redirecionamento ao provedor
+	req := httptest.NewRequest(http.MethodGet, "/oidc/login", nil)
+	rec := httptest.NewRecorder()
+	c := echoNewContext(req, rec)
+	idp.oidcConfig(c, autoProvision)
+	assert.NoError(t, h.OidcLoginHandler(c))
+	assert.Equal(t, http.StatusFound, rec.Code)
+
+	location, err := url.Parse(rec.Header().Get(echo.HeaderLocation))
+	assert.NoError(t, err)
+	query := location.Query()
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	idp.challenge = query.Get("code_challenge")
+	idp.nonce = query.Get("nonce")
+
+	// Retorno do provedor
+	callback := "/oidc/callback?" + url.Values{"code": {code}, "state": {query.Get("state")}}.Encode()
+	req = httptest.NewRequest(http.MethodGet, callback, nil)
+	for _, cookie := range rec.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	rec = httptest.NewRecorder()
+	c = echoNewContext(req, rec)
+	idp.oidcConfig(c, autoProvision)
+	assert.NoError(t, h.OidcCallbackHandler(c))
+	return rec
+}
+
+func TestHandlers_OidcLogin(t *testing.T) {
+	idp := newMockIdP(t)
+
+	t.Run(
+		"Deve_Criar_Usuario_E_Iniciar_Sessao_Quando_Criacao_Automatica",
+		func(t *testing.T) {
+			idp.subject, idp.email = "sub-1", "sso.user@agros.org.br"
+			rec := idp.login(t, true, "code-ok")
+			assert.Equal(t, http.StatusFound, rec.Code)
+			assert.Equal(t, "/", rec.Header().Get(echo.HeaderLocation))
+
+			var jwtCookie *http.Cookie
+			for _, cookie := range rec.Result().Cookies() {
+				if cookie.Name == h.CookieJwt {
+					jwtCookie = cookie
+				}
+			}
+			if assert.NotNil(t, jwtCookie) {
+				assert.NotEmpty(t, jwtCookie.Value)
+			}
+
+			// Identidade vinculada: novo login sem criação automática
+			rec = idp.login(t, false, "code-ok")
+			assert.Equal(t, "/", rec.Header().Get(echo.HeaderLocation))
+		},
+	)
+
+	t.Run(
+		"Deve_Redirecionar_Com_Erro_Quando_Usuario_Nao_Vinculado",
+		func(t *testing.T) {
+			idp.subject, idp.email = "sub-2", "outro@agros.org.br"
+			rec := idp.login(t, false, "code-ok")
+			assert.Equal(t, http.StatusFound, rec.Code)
+			assert.Equal(t, "/login?oidc_error=not_linked", rec.Header().Get(echo.HeaderLocation))
+		},
+	)
+
+	t.Run(
+		"Deve_Redirecionar_Com_Erro_Quando_Codigo_Recusado",
+		func(t *testing.T) {
+			rec := idp.login(t, true, "code-invalido")
+			assert.Equal(t, "/login?oidc_error=exchange", rec.Header().Get(echo.HeaderLocation))
+		},
+	)
+
+	t.Run(
+		"Deve_Redirecionar_Com_Erro_Quando_State_Divergente",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/oidc/callback?code=code-ok&state=outro", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			idp.oidcConfig(c, true)
+
+			if assert.NoError(t, h.OidcCallbackHandler(c)) {
+				assert.Equal(t, "/login?oidc_error=state", rec.Header().Get(echo.HeaderLocation))
+			}
+		},
+	)
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"agros_arquivos_patrocinadoras/pkg/types/config"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestApp_ValidatePassword(t *testing.T) {
+	policy := config.PasswordPolicy{
+		MinLength:     8,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		Banned:        []string{"Senha@123"},
+	}
+
+	t.Run(
+		"Deve_Aceitar_Senha_Quando_Politica_Atendida",
+		func(t *testing.T) {
+			assert.NoError(t, app.ValidatePassword(policy, "usuario", "Agros#2025"))
+		},
+	)
+
+	t.Run(
+		"Deve_Listar_Regras_Quando_Senha_Fraca",
+		func(t *testing.T) {
+			var policyErr *app.PasswordPolicyError
+			err := app.ValidatePassword(policy, "usuario", "abc")
+			if assert.True(t, errors.As(err, &policyErr)) {
+				assert.Len(t, policyErr.Violations, 4)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Rejeitar_Senha_Quando_Comum",
+		func(t *testing.T) {
+			var policyErr *app.PasswordPolicyError
+			err := app.ValidatePassword(policy, "usuario", "senha@123")
+			if assert.True(t, errors.As(err, &policyErr)) {
+				assert.Equal(t, []string{"deve conter uma letra maiúscula", "é uma senha comum"}, policyErr.Violations)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Rejeitar_Senha_Quando_Igual_Ao_Nome_De_Usuario",
+		func(t *testing.T) {
+			var policyErr *app.PasswordPolicyError
+			err := app.ValidatePassword(config.PasswordPolicy{}, "Usuario", "usuario")
+			if assert.True(t, errors.As(err, &policyErr)) {
+				assert.Equal(t, []string{"não pode ser igual ao nome de usuário"}, policyErr.Violations)
+			}
+		},
+	)
+}
+
+func TestHandlers_PasswordPolicy(t *testing.T) {
+	t.Run(
+		"Deve_Retornar_Erros_Do_Campo_Quando_Senha_Fora_Da_Politica",
+		func(t *testing.T) {
+			body := `{"username":"PolicyUser","name":"PolicyUser","password":"semnumero"}`
+			req := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			context.GetContext(c).Config.PasswordPolicy.RequireDigit = true
+			setClaims(c, uuid.Nil, "admin")
+
+			if assert.NoError(t, h.CreateUserHandler(c)) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.InvalidPasswordMessage)
+				assert.Contains(t, rec.Body.String(), `"fields":{"password":["deve conter um número"]}`)
+			}
+		},
+	)
+}
@@ -0,0 +1,195 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/lockout"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlers_PasswordReset(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	mustChange := true
+	userData := app.UserData{
+		Username:           "ResetUser",
+		Name:               "ResetUser",
+		Password:           "123456789",
+		MustChangePassword: &mustChange,
+	}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+
+	// Cenários positivos
+	t.Run(
+		"Deve_Indicar_Troca_De_Senha_Quando_Login",
+		func(t *testing.T) {
+			login, err := app.QueryLogin(ctx, app.LoginParams{Username: "ResetUser", Password: "123456789"})
+			if assert.NoError(t, err) {
+				assert.True(t, login.MustChangePassword)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Redefinir_Senha_Quando_Token_Valido",
+		func(t *testing.T) {
+			reset, err := app.CreatePasswordReset(ctx, userId, uuid.Nil)
+			assert.NoError(t, err)
+
+			body := `{"token":"` + reset.Token + `","password":"987654321"}`
+			req := httptest.NewRequest(http.MethodPost, "/password-reset", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+
+			if assert.NoError(t, h.RedeemPasswordResetHandler(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.PasswordChangedMessage)
+			}
+
+			login, err := app.QueryLogin(ctx, app.LoginParams{Username: "ResetUser", Password: "987654321"})
+			if assert.NoError(t, err) {
+				assert.False(t, login.MustChangePassword)
+			}
+
+			// Token de uso único
+			_, err = app.RedeemPasswordReset(ctx, reset.Token, "123456789")
+			assert.ErrorIs(t, err, app.ErrInvalidResetToken)
+		},
+	)
+
+	// Cenário negativo
+	t.Run(
+		"Deve_Retornar_Bad_Request_Quando_Token_Invalido",
+		func(t *testing.T) {
+			body := `{"token":"invalido","password":"987654321"}`
+			req := httptest.NewRequest(http.MethodPost, "/password-reset", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+
+			if assert.NoError(t, h.RedeemPasswordResetHandler(c)) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.InvalidResetTokenMessage)
+			}
+		},
+	)
+}
+
+func TestHandlers_ChangePassword(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userData := app.UserData{Username: "ChangeUser", Name: "ChangeUser", Password: "123456789"}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+	tracker := lockout.New()
+
+	changePassword := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/me/password", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		claims := &auth.CustomClaims{
+			ClaimsData: auth.ClaimsData{Id: userId, Name: "ChangeUser", MustChangePassword: true},
+		}
+		c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+		context.GetContext(c).Lockout = tracker
+		assert.NoError(t, h.ChangePasswordHandler(c))
+		return rec
+	}
+
+	// Cenários
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Senha_Atual_Incorreta",
+		func(t *testing.T) {
+			rec := changePassword(`{"current_password":"errada","new_password":"987654321"}`)
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Contains(t, rec.Body.String(), h.WrongPasswordMessage)
+		},
+	)
+
+	t.Run(
+		"Deve_Trocar_Senha_Quando_Senha_Atual_Correta",
+		func(t *testing.T) {
+			rec := changePassword(`{"current_password":"123456789","new_password":"987654321"}`)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), h.PasswordChangedMessage)
+			assert.Contains(t, rec.Body.String(), `"must_change_password":false`)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Too_Many_Requests_Quando_Senha_Atual_Errada_Repetidamente",
+		func(t *testing.T) {
+			for i := 0; i < ctx.Config.Lockout.MaxAttempts; i++ {
+				rec := changePassword(`{"current_password":"errada","new_password":"123456789"}`)
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			}
+
+			rec := changePassword(`{"current_password":"987654321","new_password":"123456789"}`)
+			assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+			assert.Contains(t, rec.Body.String(), h.LoginLockedMessage)
+		},
+	)
+}
+
+func TestHandlers_CreatePasswordReset(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	actorId, err := app.CreateUser(ctx, app.UserData{Username: "ResetActor", Name: "ResetActor", Password: "123456789"})
+	assert.NoError(t, err)
+	targetId, err := app.CreateUser(ctx, app.UserData{Username: "ResetTarget", Name: "ResetTarget", Password: "123456789"})
+	assert.NoError(t, err)
+	roles, err := app.QueryAllRoles(ctx)
+	assert.NoError(t, err)
+	for _, r := range roles {
+		if r.Name == app.RoleAdmin {
+			assert.NoError(t, app.SetUserRoles(ctx, targetId, []uuid.UUID{uuid.MustParse(r.RoleId)}))
+		}
+	}
+	newResetContext := func(permissions []string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/user/"+targetId.String()+"/password-reset", nil)
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		c.SetPath("/user/:userId/password-reset")
+		c.SetParamNames("userId")
+		c.SetParamValues(targetId.String())
+		claims := &auth.CustomClaims{
+			ClaimsData: auth.ClaimsData{Id: actorId, Name: "ResetActor", Permissions: permissions},
+		}
+		c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+		return c, rec
+	}
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Alvo_Com_Mais_Permissoes",
+		func(t *testing.T) {
+			c, rec := newResetContext([]string{app.PermUsersWrite})
+			if assert.NoError(t, h.CreatePasswordResetHandler(c)) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UnauthorizedMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Created_Quando_Permissoes_Do_Alvo_Contidas",
+		func(t *testing.T) {
+			c, rec := newResetContext(app.Permissions)
+			if assert.NoError(t, h.CreatePasswordResetHandler(c)) {
+				assert.Equal(t, http.StatusCreated, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.PasswordResetCreatedMessage)
+			}
+		},
+	)
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"agros_arquivos_patrocinadoras/pkg/types/db"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestApp_ValidateContact(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	t.Run(
+		"Deve_Aceitar_Contato_Quando_Valido_Ou_Vazio",
+		func(t *testing.T) {
+			assert.NoError(t, app.ValidateContact(str("contato@agros.org.br"), str("+55 (31) 3333-4444")))
+			assert.NoError(t, app.ValidateContact(str(""), str("")))
+			assert.NoError(t, app.ValidateContact(nil, nil))
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Erro_Quando_Contato_Invalido",
+		func(t *testing.T) {
+			assert.ErrorIs(t, app.ValidateContact(str("contato"), nil), app.ErrInvalidEmail)
+			assert.ErrorIs(t, app.ValidateContact(str("Contato <contato@agros.org.br>"), nil), app.ErrInvalidEmail)
+			assert.ErrorIs(t, app.ValidateContact(nil, str("3333")), app.ErrInvalidPhone)
+			assert.ErrorIs(t, app.ValidateContact(nil, str("telefone 31 3333-4444")), app.ErrInvalidPhone)
+		},
+	)
+}
+
+func TestHandlers_Profile(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userData := app.UserData{Username: "ProfileUser", Name: "ProfileUser", Password: "123456789"}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+
+	updateProfile := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPatch, "/auth/me", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(h.HeaderIfMatch, "*")
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		setClaims(c, userId, userData.Name)
+		assert.NoError(t, h.UpdateProfileHandler(c))
+		return rec
+	}
+
+	t.Run(
+		"Deve_Atualizar_Perfil_Quando_Dados_Validos",
+		func(t *testing.T) {
+			rec := updateProfile(`{"name":"Patrocinadora","email":"contato@agros.org.br","phone":"+55 31 3333-4444"}`)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+			rec = httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, userId, userData.Name)
+			if assert.NoError(t, h.GetProfile(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				var user db.UserModel
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &user))
+				assert.Equal(t, "Patrocinadora", user.Name)
+				assert.Equal(t, "ProfileUser", user.Username)
+				assert.Equal(t, "contato@agros.org.br", user.Email)
+				assert.Equal(t, "+55 31 3333-4444", user.Phone)
+				assert.Empty(t, user.Password)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Remover_Contato_Quando_Vazio",
+		func(t *testing.T) {
+			rec := updateProfile(`{"phone":""}`)
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			user, err := app.QueryUserById(ctx, userId)
+			assert.NoError(t, err)
+			assert.Empty(t, user.Phone)
+			assert.Equal(t, "contato@agros.org.br", user.Email)
+		},
+	)
+
+	t.Run(
+		"Deve_Desabilitar_Notificacoes_Quando_Solicitado",
+		func(t *testing.T) {
+			user, err := app.QueryUserById(ctx, userId)
+			assert.NoError(t, err)
+			assert.True(t, user.EmailNotifications)
+
+			rec := updateProfile(`{"email_notifications":false}`)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			user, err = app.QueryUserById(ctx, userId)
+			assert.NoError(t, err)
+			assert.False(t, user.EmailNotifications)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_BadRequest_Quando_Email_Invalido",
+		func(t *testing.T) {
+			rec := updateProfile(`{"email":"contato"}`)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), string(h.InvalidEmailMessage))
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_BadRequest_Quando_Campo_Nao_Permitido",
+		func(t *testing.T) {
+			rec := updateProfile(`{"username":"Outro"}`)
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		},
+	)
+}
@@ -0,0 +1,173 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandlers_DownloadReports(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userData := app.UserData{
+		Username: "ReportUser1",
+		Name:     "ReportUser1",
+		Password: "123456789",
+	}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+
+	categParams := app.CategData{UserId: userId, Name: "ReportCateg1"}
+	categId, err := app.CreateCategory(ctx, categParams)
+	assert.NoError(t, err)
+
+	content := []byte("Test")
+	fileParams := app.FileData{
+		CategId:   categId,
+		Name:      "ReportFile1",
+		Extension: ".txt",
+		Mimetype:  "text/plain",
+		Content:   &content,
+	}
+	fileId, err := app.CreateFile(ctx, fileParams)
+	assert.NoError(t, err)
+	unusedParams := app.FileData{
+		CategId:   categId,
+		Name:      "ReportFile2",
+		Extension: ".txt",
+		Mimetype:  "text/plain",
+		Content:   &content,
+	}
+	unusedId, err := app.CreateFile(ctx, unusedParams)
+	assert.NoError(t, err)
+
+	// Download realizado pela patrocinadora
+	req := httptest.NewRequest(
+		http.MethodGet,
+		"/user/"+userId.String()+"/category/"+categId.String()+"/file/"+fileId.String(),
+		nil,
+	)
+	rec := httptest.NewRecorder()
+	c := echoNewContext(req, rec)
+	c.SetPath("/user/:userId/category/:categId/file/:fileId")
+	c.SetParamNames("userId", "categId", "fileId")
+	c.SetParamValues(userId.String(), categId.String(), fileId.String())
+	setClaims(c, userId, userData.Name)
+	assert.NoError(t, h.GetFileById(c))
+
+	// Cenários positivos
+	t.Run(
+		"Deve_Retornar_OK_Quando_Relatorio_Por_Arquivo_Gerado",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/report/download/file", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.GetDownloadsByFile(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"file_id":"`+fileId.String()+`"`)
+				assert.Contains(t, rec.Body.String(), `"downloads":1`)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_CSV_Quando_Formato_CSV_Solicitado",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/report/download/user?format=csv", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.GetDownloadsByUser(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/csv")
+				assert.Contains(t, rec.Body.String(), userId.String()+","+userData.Username)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_OK_Quando_Arquivos_Nao_Baixados_Listados",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/report/download/unused", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.GetUndownloadedFiles(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"file_id":"`+unusedId.String()+`"`)
+				assert.NotContains(t, rec.Body.String(), `"file_id":"`+fileId.String()+`"`)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Agrupar_Downloads_Quando_Relatorio_Por_Mes_Gerado",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/report/download/period?interval=month", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.GetDownloadsByPeriod(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"period":"`+time.Now().Format("2006-01")+`"`)
+			}
+		},
+	)
+
+	// Cenários negativos
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Nao_Administrador",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/report/download/file", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, userId, userData.Name)
+
+			if assert.NoError(t, h.GetDownloadsByFile(c)) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UnauthorizedMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Bad_Request_Quando_Intervalo_Invalido",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/report/download/period?interval=week", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.GetDownloadsByPeriod(c)) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.InvalidIntervalMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Bad_Request_Quando_Periodo_Invalido",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/report/download/file?from=20&to=10", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.GetDownloadsByFile(c)) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.InvalidPeriodMessage)
+			}
+		},
+	)
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlers_Roles(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	assert.NoError(t, app.EnsureDefaultRoles(ctx))
+
+	userData := app.UserData{Username: "RoleUser", Name: "RoleUser", Password: "123456789"}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+
+	// Cenários positivos
+	t.Run(
+		"Deve_Retornar_Created_Quando_Papel_Valido",
+		func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/role",
+				strings.NewReader(`{"name":"auditor","permissions":["audit:read"]}`),
+			)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.CreateRoleHandler(c)) {
+				assert.Equal(t, http.StatusCreated, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.CreatedRoleMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Permissoes_Do_Papel_Quando_Usuario_Com_Papel",
+		func(t *testing.T) {
+			roles, err := app.QueryAllRoles(ctx)
+			assert.NoError(t, err)
+			var viewerId string
+			for _, r := range roles {
+				if r.Name == app.RoleViewer {
+					viewerId = r.RoleId
+				}
+			}
+
+			req := httptest.NewRequest(
+				http.MethodPut,
+				"/user/"+userId.String()+"/role",
+				strings.NewReader(`{"role_ids":["`+viewerId+`"]}`),
+			)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/role")
+			c.SetParamNames("userId")
+			c.SetParamValues(userId.String())
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.SetUserRolesHandler(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+			}
+
+			userRoles, err := app.QueryUserRoles(ctx, userId)
+			assert.NoError(t, err)
+			data := auth.NewClaimsData(userId, "RoleUser", userRoles)
+			assert.Equal(t, []string{app.RoleViewer}, data.Roles)
+			assert.True(t, auth.Allows(ctx, data, app.PermContentRead))
+			assert.False(t, auth.Allows(ctx, data, app.PermContentWrite))
+		},
+	)
+
+	// Cenários negativos
+	t.Run(
+		"Deve_Retornar_BadRequest_Quando_Permissao_Invalida",
+		func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/role",
+				strings.NewReader(`{"name":"invalido","permissions":["files:burn"]}`),
+			)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			setClaims(c, uuid.Nil, "Admin")
+
+			if assert.NoError(t, h.CreateRoleHandler(c)) {
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.InvalidPermissionMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Usuario_Sem_Permissao",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/role", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			claims := &auth.CustomClaims{
+				ClaimsData: auth.ClaimsData{
+					Id:          userId,
+					Name:        "RoleUser",
+					Roles:       []string{app.RoleEditor},
+					Permissions: app.DefaultRoles[app.RoleEditor],
+				},
+			}
+			c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+
+			if assert.NoError(t, h.GetAllRoles(c)) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UnauthorizedMessage)
+			}
+		},
+	)
+}
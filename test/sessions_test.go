@@ -0,0 +1,140 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHandlers_Sessions(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	var userIds []uuid.UUID
+	for _, name := range []string{"SessionUser1", "SessionUser2"} {
+		userData := app.UserData{Username: name, Name: name, Password: "123456789"}
+		userId, err := app.CreateUser(ctx, userData)
+		assert.NoError(t, err)
+		userIds = append(userIds, userId)
+	}
+	userId, otherId := userIds[0], userIds[1]
+
+	sessionData := app.SessionData{UserId: userId, UserAgent: "Mozilla/5.0", ClientIp: "127.0.0.1"}
+	current, err := app.CreateSession(ctx, sessionData)
+	assert.NoError(t, err)
+	other, err := app.CreateSession(ctx, sessionData)
+	assert.NoError(t, err)
+
+	setSessionClaims := func(c echo.Context, id, sessionId uuid.UUID) {
+		claims := &auth.CustomClaims{
+			ClaimsData: auth.ClaimsData{Id: id, SessionId: sessionId},
+		}
+		c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+	}
+
+	// Cenários positivos
+	t.Run(
+		"Deve_Listar_Sessoes_Quando_Proprio_Usuario",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user/"+userId.String()+"/session", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/session")
+			c.SetParamNames("userId")
+			c.SetParamValues(userId.String())
+			setSessionClaims(c, userId, current.FamilyId)
+
+			if assert.NoError(t, h.GetUserSessions(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), `"session_id":"`+other.FamilyId.String()+`"`)
+				assert.Contains(t, rec.Body.String(), `"current":true`)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Encerrar_Sessao_Quando_Proprio_Usuario",
+		func(t *testing.T) {
+			url := "/user/" + userId.String() + "/session/" + other.FamilyId.String()
+			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/session/:sessionId")
+			c.SetParamNames("userId", "sessionId")
+			c.SetParamValues(userId.String(), other.FamilyId.String())
+			setSessionClaims(c, userId, current.FamilyId)
+
+			if assert.NoError(t, h.DeleteSessionHandler(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.RevokedSessionMessage)
+			}
+
+			active, err := app.IsSessionActive(ctx, other.FamilyId)
+			assert.NoError(t, err)
+			assert.False(t, active)
+		},
+	)
+
+	// Cenário negativo
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Sessoes_De_Outro_Usuario",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, "/user/"+userId.String()+"/session", nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/session")
+			c.SetParamNames("userId")
+			c.SetParamValues(userId.String())
+			setSessionClaims(c, otherId, uuid.Nil)
+
+			if assert.NoError(t, h.DeleteUserSessionsHandler(c)) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UnauthorizedMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Sessao_De_Usuario_Com_Mais_Permissoes",
+		func(t *testing.T) {
+			adminId, err := app.CreateUser(ctx, app.UserData{Username: "SessionAdmin", Name: "SessionAdmin", Password: "123456789"})
+			assert.NoError(t, err)
+			roles, err := app.QueryAllRoles(ctx)
+			assert.NoError(t, err)
+			for _, r := range roles {
+				if r.Name == app.RoleAdmin {
+					assert.NoError(t, app.SetUserRoles(ctx, adminId, []uuid.UUID{uuid.MustParse(r.RoleId)}))
+				}
+			}
+			session, err := app.CreateSession(ctx, app.SessionData{UserId: adminId, UserAgent: "Mozilla/5.0", ClientIp: "127.0.0.1"})
+			assert.NoError(t, err)
+
+			url := "/user/" + adminId.String() + "/session/" + session.FamilyId.String()
+			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+			c.SetPath("/user/:userId/session/:sessionId")
+			c.SetParamNames("userId", "sessionId")
+			c.SetParamValues(adminId.String(), session.FamilyId.String())
+			claims := &auth.CustomClaims{
+				ClaimsData: auth.ClaimsData{Id: otherId, Permissions: []string{app.PermUsersWrite}},
+			}
+			c.Set("user", &jwt.Token{Claims: claims, Valid: true})
+
+			if assert.NoError(t, h.DeleteSessionHandler(c)) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.UnauthorizedMessage)
+			}
+
+			active, err := app.IsSessionActive(ctx, session.FamilyId)
+			assert.NoError(t, err)
+			assert.True(t, active)
+		},
+	)
+}
@@ -0,0 +1,87 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestApp_RefreshTokenRotation(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userData := app.UserData{Username: "TokenUser", Name: "TokenUser", Password: "123456789"}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+
+	first, err := app.CreateSession(ctx, app.SessionData{UserId: userId})
+	assert.NoError(t, err)
+
+	// Cenário positivo
+	t.Run(
+		"Deve_Rotacionar_Token_Quando_Token_Ativo",
+		func(t *testing.T) {
+			second, err := app.RotateRefreshToken(ctx, first.Token)
+			if assert.NoError(t, err) {
+				assert.NotEqual(t, first.Token, second.Token)
+				assert.Equal(t, first.FamilyId, second.FamilyId)
+				assert.Equal(t, userId, second.UserId)
+			}
+
+			active, err := app.IsSessionActive(ctx, first.FamilyId)
+			assert.NoError(t, err)
+			assert.True(t, active)
+		},
+	)
+
+	// Cenários negativos
+	t.Run(
+		"Deve_Revogar_Sessao_Quando_Token_Reutilizado",
+		func(t *testing.T) {
+			_, err := app.RotateRefreshToken(ctx, first.Token)
+			assert.ErrorIs(t, err, app.ErrRefreshTokenReuse)
+
+			active, err := app.IsSessionActive(ctx, first.FamilyId)
+			assert.NoError(t, err)
+			assert.False(t, active)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Refresh_Token_Invalido",
+		func(t *testing.T) {
+			req := httptest.NewRequest(
+				http.MethodPost,
+				"/refresh",
+				strings.NewReader(`{"refresh_token":"invalido"}`),
+			)
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+
+			if assert.NoError(t, h.RefreshHandler(c)) {
+				assert.Equal(t, http.StatusUnauthorized, rec.Code)
+				assert.Contains(t, rec.Body.String(), h.InvalidRefreshTokenMessage)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Revogar_Sessoes_Quando_Senha_Alterada",
+		func(t *testing.T) {
+			session, err := app.CreateSession(ctx, app.SessionData{UserId: userId})
+			assert.NoError(t, err)
+
+			err = app.UpdateUser(ctx, userId, 0, app.UserData{Password: "987654321"})
+			assert.NoError(t, err)
+
+			active, err := app.IsSessionActive(ctx, session.FamilyId)
+			assert.NoError(t, err)
+			assert.False(t, active)
+		},
+	)
+}
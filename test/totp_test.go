@@ -0,0 +1,185 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/lockout"
+	"agros_arquivos_patrocinadoras/pkg/auth"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// totpNow calcula o código TOTP atual de um segredo em base32, como um
+// aplicativo autenticador.
+func totpNow(secret string) string {
+	key, _ := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1_000_000)
+}
+
+func TestApp_TotpURI(t *testing.T) {
+	uri := app.TotpURI("Agros Arquivos", "usuario", "JBSWY3DPEHPK3PXP")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Agros%20Arquivos:usuario?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=Agros+Arquivos")
+	assert.Contains(t, uri, "digits=6")
+}
+
+func TestHandlers_TotpLogin(t *testing.T) {
+	// Mock
+	ctx := newContext()
+	userData := app.UserData{Username: "TotpUser", Name: "TotpUser", Password: "123456789"}
+	userId, err := app.CreateUser(ctx, userData)
+	assert.NoError(t, err)
+
+	enrollment, err := app.BeginTotpEnrollment(ctx, userId, "TotpUser")
+	assert.NoError(t, err)
+	codes, err := app.ConfirmTotpEnrollment(ctx, userId, totpNow(enrollment.Secret))
+	assert.NoError(t, err)
+	assert.Len(t, codes, 10)
+
+	loginTotp := func(mfaToken, code string) *httptest.ResponseRecorder {
+		body := fmt.Sprintf(`{"mfa_token":%q,"code":%q}`, mfaToken, code)
+		req := httptest.NewRequest(http.MethodPost, "/login/totp", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		assert.NoError(t, h.LoginTotpHandler(c))
+		return rec
+	}
+
+	// Cenários
+	var mfaToken string
+	t.Run(
+		"Deve_Retornar_Token_Intermediario_Quando_Segundo_Fator_Ativo",
+		func(t *testing.T) {
+			body := `{"username":"TotpUser","password":"123456789"}`
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := echoNewContext(req, rec)
+
+			if assert.NoError(t, h.LoginHandler(c)) {
+				assert.Equal(t, http.StatusOK, rec.Code)
+				var res h.MfaRequiredRes
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+				assert.True(t, res.MfaRequired)
+				assert.NotContains(t, rec.Body.String(), `"refresh_token"`)
+				mfaToken = res.MfaToken
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Rejeitar_Token_Intermediario_Quando_Usado_Como_Token_De_Acesso",
+		func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/auth/session", nil)
+			c := echoNewContext(req, httptest.NewRecorder())
+			_, err := auth.ParseToken(c, mfaToken)
+			assert.Error(t, err)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Codigo_Reutilizado",
+		func(t *testing.T) {
+			rec := loginTotp(mfaToken, totpNow(enrollment.Secret))
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Contains(t, rec.Body.String(), h.InvalidTotpCodeMessage)
+		},
+	)
+
+	t.Run(
+		"Deve_Iniciar_Sessao_Quando_Codigo_De_Recuperacao_Valido",
+		func(t *testing.T) {
+			rec := loginTotp(mfaToken, strings.ToLower(codes[0]))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), h.LoginSuccessMessage)
+
+			// Código de recuperação de uso único
+			rec = loginTotp(mfaToken, codes[0])
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Unauthorized_Quando_Token_Intermediario_Invalido",
+		func(t *testing.T) {
+			rec := loginTotp("invalido", codes[1])
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Contains(t, rec.Body.String(), h.InvalidMfaTokenMessage)
+		},
+	)
+
+	t.Run(
+		"Deve_Manter_Falhas_Do_Codigo_Quando_Senha_Correta",
+		func(t *testing.T) {
+			tracker := lockout.New()
+			withTracker := func(req *http.Request) (*httptest.ResponseRecorder, echo.Context) {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+				rec := httptest.NewRecorder()
+				c := echoNewContext(req, rec)
+				context.GetContext(c).Lockout = tracker
+				return rec, c
+			}
+			wrongCode := func() *httptest.ResponseRecorder {
+				body := fmt.Sprintf(`{"mfa_token":%q,"code":"000000"}`, mfaToken)
+				rec, c := withTracker(httptest.NewRequest(http.MethodPost, "/login/totp", strings.NewReader(body)))
+				assert.NoError(t, h.LoginTotpHandler(c))
+				return rec
+			}
+
+			for i := 0; i < ctx.Config.Lockout.MaxAttempts-1; i++ {
+				assert.Equal(t, http.StatusUnauthorized, wrongCode().Code)
+			}
+			body := `{"username":"TotpUser","password":"123456789"}`
+			rec, c := withTracker(httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body)))
+			assert.NoError(t, h.LoginHandler(c))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, http.StatusUnauthorized, wrongCode().Code)
+
+			rec = wrongCode()
+			assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+			assert.Contains(t, rec.Body.String(), h.LoginLockedMessage)
+		},
+	)
+}
+
+func TestApp_IsTotpRequired(t *testing.T) {
+	ctx := newContext()
+	ctx.Config.Totp.EnforceRoles = []string{app.RoleAdmin}
+
+	t.Run(
+		"Deve_Exigir_Segundo_Fator_Quando_Papel_Obrigatorio",
+		func(t *testing.T) {
+			assert.True(t, app.IsTotpRequired(ctx, uuid.New(), []string{app.RoleViewer, app.RoleAdmin}))
+			assert.True(t, app.IsTotpRequired(ctx, ctx.AdminId, nil))
+		},
+	)
+
+	t.Run(
+		"Deve_Dispensar_Segundo_Fator_Quando_Papel_Opcional",
+		func(t *testing.T) {
+			assert.False(t, app.IsTotpRequired(ctx, uuid.New(), []string{app.RoleViewer}))
+		},
+	)
+}
@@ -0,0 +1,305 @@
+package test
+
+import (
+	"agros_arquivos_patrocinadoras/pkg/app"
+	"agros_arquivos_patrocinadoras/pkg/app/context"
+	"agros_arquivos_patrocinadoras/pkg/app/webhook"
+	h "agros_arquivos_patrocinadoras/pkg/handlers"
+	"encoding/json"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhook_Sign(t *testing.T) {
+	body := []byte(`{"event":"file.uploaded"}`)
+
+	t.Run(
+		"Deve_Validar_Assinatura_Quando_Segredo_Correto",
+		func(t *testing.T) {
+			sig := webhook.Sign("segredo", 1700000000, body)
+			assert.True(t, strings.HasPrefix(sig, "sha256="))
+			assert.True(t, webhook.Verify("segredo", 1700000000, body, sig))
+		},
+	)
+
+	t.Run(
+		"Deve_Rejeitar_Assinatura_Quando_Dados_Alterados",
+		func(t *testing.T) {
+			sig := webhook.Sign("segredo", 1700000000, body)
+			assert.False(t, webhook.Verify("outro", 1700000000, body, sig))
+			assert.False(t, webhook.Verify("segredo", 1700000001, body, sig))
+			assert.False(t, webhook.Verify("segredo", 1700000000, []byte(`{}`), sig))
+		},
+	)
+}
+
+func TestWebhook_Deliver(t *testing.T) {
+	policy := webhook.Policy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, Timeout: time.Second}
+	request := func(url string) webhook.Request {
+		return webhook.Request{Url: url, Secret: "segredo", Event: "file.uploaded", DeliveryId: "1", Body: []byte(`{}`)}
+	}
+
+	t.Run(
+		"Deve_Repetir_Quando_Erro_Do_Servidor",
+		func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if calls.Add(1) < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			res := webhook.Deliver(nil, policy, request(srv.URL))
+			assert.NoError(t, res.Err)
+			assert.Equal(t, 3, res.Attempts)
+			assert.Equal(t, http.StatusNoContent, res.StatusCode)
+		},
+	)
+
+	t.Run(
+		"Deve_Desistir_Quando_Recusa_Definitiva",
+		func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(http.StatusBadRequest)
+			}))
+			defer srv.Close()
+
+			res := webhook.Deliver(nil, policy, request(srv.URL))
+			assert.Error(t, res.Err)
+			assert.Equal(t, 1, res.Attempts)
+			assert.Equal(t, int32(1), calls.Load())
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_Erro_Quando_Tentativas_Esgotadas",
+		func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer srv.Close()
+
+			res := webhook.Deliver(nil, policy, request(srv.URL))
+			assert.Error(t, res.Err)
+			assert.Equal(t, policy.MaxAttempts, res.Attempts)
+		},
+	)
+}
+
+func TestWebhook_Address(t *testing.T) {
+	t.Run(
+		"Deve_Identificar_Enderecos_Internos",
+		func(t *testing.T) {
+			for _, ip := range []string{"127.0.0.1", "10.1.2.3", "192.168.0.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fe80::1"} {
+				assert.True(t, webhook.Internal(net.ParseIP(ip)), ip)
+			}
+			for _, ip := range []string{"8.8.8.8", "200.160.2.3", "2001:4860:4860::8888"} {
+				assert.False(t, webhook.Internal(net.ParseIP(ip)), ip)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Recusar_Host_Interno_Quando_Fora_Da_Lista",
+		func(t *testing.T) {
+			assert.ErrorIs(t, webhook.CheckHost("127.0.0.1", nil), webhook.ErrForbiddenAddress)
+			assert.ErrorIs(t, webhook.CheckHost("169.254.169.254", []string{"localhost"}), webhook.ErrForbiddenAddress)
+			assert.NoError(t, webhook.CheckHost("127.0.0.1", []string{"127.0.0.1"}))
+		},
+	)
+
+	t.Run(
+		"Deve_Recusar_Conexao_Interna_Quando_Entregue",
+		func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+			policy := webhook.Policy{MaxAttempts: 1, Timeout: time.Second}
+			request := webhook.Request{Url: srv.URL, Secret: "segredo", Event: "file.uploaded", DeliveryId: "1", Body: []byte(`{}`)}
+
+			res := webhook.Deliver(webhook.NewClient(policy.Timeout, nil), policy, request)
+			assert.ErrorIs(t, res.Err, webhook.ErrForbiddenAddress)
+			assert.Zero(t, res.StatusCode)
+
+			res = webhook.Deliver(webhook.NewClient(policy.Timeout, []string{"127.0.0.1"}), policy, request)
+			assert.NoError(t, res.Err)
+			assert.Equal(t, http.StatusNoContent, res.StatusCode)
+		},
+	)
+}
+
+func TestWebhook_Queue(t *testing.T) {
+	t.Run(
+		"Deve_Executar_Tarefas_Quando_Enfileiradas",
+		func(t *testing.T) {
+			q := webhook.NewQueue(2, 8)
+			var done atomic.Int32
+			for range 5 {
+				assert.True(t, q.Enqueue(func() { done.Add(1) }))
+			}
+			assert.Eventually(t, func() bool { return done.Load() == 5 }, time.Second, 10*time.Millisecond)
+		},
+	)
+
+	t.Run(
+		"Deve_Descartar_Tarefas_Quando_Fila_Cheia",
+		func(t *testing.T) {
+			q := webhook.NewQueue(1, 1)
+			release := make(chan struct{})
+			started := make(chan struct{})
+			assert.True(t, q.Enqueue(func() { close(started); <-release }))
+			<-started
+			assert.True(t, q.Enqueue(func() {}))
+			assert.False(t, q.Enqueue(func() {}))
+			assert.Equal(t, int64(1), q.Dropped())
+			close(release)
+		},
+	)
+
+	t.Run(
+		"Deve_Descartar_Tarefas_Quando_Fila_Nula",
+		func(t *testing.T) {
+			var q *webhook.Queue
+			assert.False(t, q.Enqueue(func() {}))
+			assert.Zero(t, q.Dropped())
+		},
+	)
+}
+
+func TestHandlers_Webhooks(t *testing.T) {
+	// Destino dos eventos, que verifica a assinatura
+	var secret atomic.Value
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		s, _ := secret.Load().(string)
+		if !webhook.Verify(s, ts, body, r.Header.Get(webhook.HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	newAdminContext := func(method, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(h.HeaderIfMatch, "*")
+		rec := httptest.NewRecorder()
+		c := echoNewContext(req, rec)
+		setClaims(c, context.GetContext(c).AdminId, "admin")
+		// O destino de teste está na rede interna (loopback)
+		context.GetContext(c).Config.Webhooks.AllowedHosts = []string{"127.0.0.1"}
+		return c, rec
+	}
+	withWebhookId := func(c echo.Context, id string) {
+		c.SetParamNames("webhookId")
+		c.SetParamValues(id)
+	}
+
+	// Cadastro
+	c, rec := newAdminContext(http.MethodPost, "/auth/webhook", `{"url":"`+srv.URL+`","events":["file.uploaded"]}`)
+	assert.NoError(t, h.CreateWebhookHandler(c))
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	var created h.WebhookRes
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.NotEmpty(t, created.Secret)
+	secret.Store(created.Secret)
+
+	t.Run(
+		"Deve_Retornar_BadRequest_Quando_Evento_Invalido",
+		func(t *testing.T) {
+			c, rec := newAdminContext(http.MethodPost, "/auth/webhook", `{"url":"`+srv.URL+`","events":["file.exploded"]}`)
+			assert.NoError(t, h.CreateWebhookHandler(c))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), string(h.InvalidWebhookEventMessage))
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_BadRequest_Quando_Url_Invalida",
+		func(t *testing.T) {
+			c, rec := newAdminContext(http.MethodPost, "/auth/webhook", `{"url":"ftp://agros.org.br","events":["*"]}`)
+			assert.NoError(t, h.CreateWebhookHandler(c))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), string(h.InvalidWebhookUrlMessage))
+		},
+	)
+
+	t.Run(
+		"Deve_Retornar_BadRequest_Quando_Url_Na_Rede_Interna",
+		func(t *testing.T) {
+			for _, url := range []string{"http://169.254.169.254/latest/meta-data", "http://localhost:8080", "http://10.0.0.1"} {
+				c, rec := newAdminContext(http.MethodPost, "/auth/webhook", `{"url":"`+url+`","events":["*"]}`)
+				assert.NoError(t, h.CreateWebhookHandler(c))
+				assert.Equal(t, http.StatusBadRequest, rec.Code)
+				assert.Contains(t, rec.Body.String(), string(h.InvalidWebhookUrlMessage))
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Entregar_Evento_Assinado_Quando_Teste_Disparado",
+		func(t *testing.T) {
+			c, rec := newAdminContext(http.MethodPost, "/auth/webhook/"+created.Id.String()+"/test", "")
+			withWebhookId(c, created.Id.String())
+			assert.NoError(t, h.TestWebhookHandler(c))
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"success":true`)
+			assert.Equal(t, int32(1), received.Load())
+
+			// Registro da entrega
+			ctx := newContext()
+			deliveries, err := app.QueryWebhookDeliveries(ctx, created.Id, 10)
+			assert.NoError(t, err)
+			if assert.NotEmpty(t, deliveries) {
+				assert.Equal(t, app.EventWebhookTest, deliveries[0].Event)
+				assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+			}
+		},
+	)
+
+	t.Run(
+		"Deve_Desativar_Webhook_Quando_Atualizado",
+		func(t *testing.T) {
+			c, rec := newAdminContext(http.MethodPatch, "/auth/webhook/"+created.Id.String(), `{"active":false}`)
+			withWebhookId(c, created.Id.String())
+			assert.NoError(t, h.UpdateWebhookHandler(c))
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			hook, err := app.QueryWebhookById(newContext(), created.Id)
+			assert.NoError(t, err)
+			assert.False(t, hook.Active)
+		},
+	)
+
+	t.Run(
+		"Deve_Excluir_Webhook_Quando_Existente",
+		func(t *testing.T) {
+			c, rec := newAdminContext(http.MethodDelete, "/auth/webhook/"+created.Id.String(), "")
+			withWebhookId(c, created.Id.String())
+			assert.NoError(t, h.DeleteWebhookHandler(c))
+			assert.Equal(t, http.StatusOK, rec.Code)
+
+			_, err := app.QueryWebhookById(newContext(), created.Id)
+			assert.ErrorIs(t, err, app.ErrEntityNotFound)
+		},
+	)
+}
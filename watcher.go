@@ -4,6 +4,7 @@ import (
 	"agros_arquivos_patrocinadoras/pkg/app/config"
 	"agros_arquivos_patrocinadoras/pkg/app/context"
 	"agros_arquivos_patrocinadoras/pkg/app/db"
+	"agros_arquivos_patrocinadoras/pkg/app/keyring"
 	types "agros_arquivos_patrocinadoras/pkg/types/config"
 	"bytes"
 	"crypto/sha256"
@@ -60,6 +61,13 @@ func GetConfigWatcher(ctx *context.Context, restartChan chan bool) *fsnotify.Wat
 					ctx.Logger.Error("Erro nas novas configurações. Fallback para o backup", zap.Error(err))
 					continue
 				}
+
+				// Chaves JWT, recarregadas para a rotação sem reinício
+				newKeys, err := keyring.Load(newConfig.JwtKeys)
+				if err != nil {
+					ctx.Logger.Error("Erro nas novas chaves JWT. Fallback para o backup", zap.Error(err))
+					continue
+				}
 				ctx.Config = newConfig
 				newDB, err := db.GetSqlDB(&newConfig.Database, ctx.Logger)
 				if err != nil {
@@ -70,6 +78,7 @@ func GetConfigWatcher(ctx *context.Context, restartChan chan bool) *fsnotify.Wat
 				}
 				ctx.Config = newConfig
 				ctx.DB = newDB
+				ctx.Keyring = newKeys
 
 				// Reiniciar servidor caso os parâmetros para echo tenham alterado
 				if serverParamsChanged(bckConfig, newConfig) {
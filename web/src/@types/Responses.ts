@@ -1,9 +1,27 @@
 export interface LoginResponse {
-  token: string
+  token?: string
+  refresh_token?: string
   message: string
   id: string
   name: string
   admin: boolean
+  roles: string[]
+  permissions: string[]
+  must_change_password: boolean
+  totp_enroll_required: boolean
+}
+
+export interface MfaRequiredResponse {
+  mfa_required: true
+  mfa_token: string
+  message: string
+}
+
+export interface RoleModel {
+  role_id: string
+  name: string
+  permissions: string[]
+  updated_at: number
 }
 
 export interface UserModel {
@@ -11,14 +29,17 @@ export interface UserModel {
   username: string
   name: string
   password: string
-  updated_at: string
+  updated_at: number
+  must_change_password: boolean
 }
 
 export interface CategModel {
   categ_id: string
   user_id: string
   name: string
-  updated_at: string
+  updated_at: number
+  shared: boolean
+  inbox: boolean
 }
 export interface FileModel {
   file_id: string
@@ -47,3 +68,29 @@ export interface GetAllResponse<T> extends QueryResponse {
 export interface GetOneResponse<T> extends QueryResponse {
   data: T | null
 }
+
+export interface SessionModel {
+  session_id: string
+  user_id: string
+  user_agent: string
+  client_ip: string
+  created_at: number
+  last_seen: number
+  expires_at: number
+  current: boolean
+}
+
+export interface EventsTicketResponse {
+  ticket: string
+  expires_in: number
+  message: string
+}
+
+export interface ChangeEvent {
+  entity: 'user' | 'category' | 'file'
+  action: 'create' | 'update' | 'delete'
+  id: string
+  user_id?: string
+  categ_id?: string
+  at: number
+}
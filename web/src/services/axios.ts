@@ -7,6 +7,10 @@ const apiClient: AxiosInstance = axios.create({
     'Content-Type': 'application/json',
   },
   withCredentials: true,
+  // Token CSRF (double-submit) do modo de autenticação por cookies
+  xsrfCookieName: '_csrf',
+  xsrfHeaderName: 'X-CSRF-Token',
+  withXSRFToken: true,
 })
 
 // Interceptor para adicionar o token.
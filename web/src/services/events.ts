@@ -0,0 +1,84 @@
+import apiClient from '@/services/axios.ts'
+import type { AxiosError, AxiosResponse } from 'axios'
+import type { ChangeEvent, EventsTicketResponse } from '@/@types/Responses.ts'
+
+// Intervalo, em milissegundos, antes de uma nova conexão ao fluxo de eventos.
+const RECONNECT_DELAY: number = 5000
+
+// Códigos de resposta do ticket que encerram a assinatura: sessão encerrada, acesso negado ou fluxo desabilitado.
+const FINAL_STATUS: number[] = [401, 403, 503]
+
+/**
+ * Assina o fluxo de alterações de usuários, categorias e arquivos (Server-Sent Events). O EventSource não envia o
+ * cabeçalho Authorization; cada conexão é autenticada por um ticket de curta validade, obtido pela API com o token
+ * de acesso ou o cookie da sessão. Quando o servidor encerra o fluxo (ex.: na expiração do token de acesso), a
+ * conexão é refeita com um novo ticket.
+ *
+ * @param {(event: ChangeEvent) => void} onChange - Função chamada a cada alteração recebida.
+ * @param {() => void} [onResync] - Função chamada ao refazer a conexão, já que as alterações do intervalo sem
+ * conexão não são recebidas.
+ * @return {() => void} Uma função que encerra a assinatura.
+ */
+export function subscribeChanges(onChange: (event: ChangeEvent) => void, onResync?: () => void): () => void {
+  let source: EventSource | undefined
+  let timer: ReturnType<typeof setTimeout> | undefined
+  let closed: boolean = false
+  let connected: boolean = false
+
+  /**
+   * Agenda uma nova conexão ao fluxo de eventos.
+   *
+   * @return {void} Este método não retorna valor.
+   */
+  function reconnect(): void {
+    source?.close()
+    source = undefined
+    if (!closed) {
+      timer = setTimeout(connect, RECONNECT_DELAY)
+    }
+  }
+
+  /**
+   * Obtém um ticket e abre a conexão ao fluxo de eventos.
+   *
+   * @return {Promise<void>} Uma promise que é resolvida quando a conexão é aberta ou reagendada.
+   */
+  async function connect(): Promise<void> {
+    let ticket: string
+    try {
+      const res: AxiosResponse<EventsTicketResponse> = await apiClient.post('/auth/events/ticket')
+      ticket = res.data.ticket
+    } catch (e: unknown) {
+      const status: number | undefined = (e as AxiosError).response?.status
+      if (status === undefined || !FINAL_STATUS.includes(status)) {
+        reconnect()
+      }
+      return
+    }
+    if (closed) return
+
+    source = new EventSource(apiClient.getUri({ url: '/auth/events', params: { ticket } }))
+    source.onopen = (): void => {
+      if (connected) {
+        onResync?.()
+      }
+      connected = true
+    }
+    source.addEventListener('change', (e: MessageEvent<string>): void => {
+      try {
+        onChange(JSON.parse(e.data) as ChangeEvent)
+      } catch {
+        // Evento malformado
+      }
+    })
+    // O ticket não é reutilizado: a reconexão automática do EventSource é substituída por uma com um novo ticket
+    source.onerror = reconnect
+  }
+
+  connect()
+  return (): void => {
+    closed = true
+    clearTimeout(timer)
+    source?.close()
+  }
+}
@@ -18,6 +18,16 @@ import type {
 } from '@/@types/Requests.ts'
 import router from '@/router'
 
+/**
+ * Monta o cabeçalho If-Match a partir da versão (updated_at) conhecida da entidade.
+ *
+ * @param {number} version - A versão da entidade, correspondente ao seu updated_at.
+ * @return {Record<string, string>} O cabeçalho da requisição.
+ */
+function ifMatch(version: number): Record<string, string> {
+  return { 'If-Match': `"${version}"` }
+}
+
 /**
  * Lida com erros lançados por requisições Axios e retorna uma resposta padronizada.
  *
@@ -30,6 +40,7 @@ async function handleAxiosError(error: unknown, entityName: string = 'Entidade')
   const SERVER_ERROR_MSG: string = 'Erro no servidor. Tente novamente mais tarde.'
   const UNAUTHORIZED_MSG: string = 'Credenciais inválidas.'
   const CONFLICT_MSG: string = `${entityName} já existe.`
+  const OUTDATED_MSG: string = `${entityName} foi alterado(a) por outro usuário. Recarregue e tente novamente.`
   const UNKNOWN_ERROR_MSG: string = 'Erro desconhecido. Tente novamente mais tarde.'
 
   const axiosError: AxiosError = error as AxiosError
@@ -40,6 +51,8 @@ async function handleAxiosError(error: unknown, entityName: string = 'Entidade')
     return { message: UNAUTHORIZED_MSG, code: 401 }
   } else if (axiosError.response.status === 409) {
     return { message: CONFLICT_MSG, code: 409 }
+  } else if (axiosError.response.status === 412) {
+    return { message: OUTDATED_MSG, code: 412 }
   }
 
   return {
@@ -227,15 +240,22 @@ export async function getFileById(userId: string, categId: string, fileId: strin
  *
  * @param {string} userId - O identificador único do usuário a ser atualizado.
  * @param {Partial<UserRequest>} body - O corpo da requisição contendo as informações atualizadas do usuário.
+ * @param {number} version - A versão (updated_at) do usuário conhecida pelo cliente.
  * @return {Promise<QueryResponse>} Uma Promise que resolve para a resposta contendo o status da atualização e uma
  * mensagem.
  */
-export async function updateUser(userId: string, body: Partial<UserRequest>): Promise<QueryResponse> {
+export async function updateUser(
+  userId: string,
+  body: Partial<UserRequest>,
+  version: number,
+): Promise<QueryResponse> {
   try {
-    const res: AxiosResponse<string, unknown> = await apiClient.patch(`/auth/user/${userId}`, body)
+    const res: AxiosResponse<string, unknown> = await apiClient.patch(`/auth/user/${userId}`, body, {
+      headers: ifMatch(version),
+    })
     return { message: res.data, code: res.status }
   } catch (e: unknown) {
-    return handleAxiosError(e)
+    return handleAxiosError(e, 'Usuário')
   }
 }
 
@@ -245,6 +265,7 @@ export async function updateUser(userId: string, body: Partial<UserRequest>): Pr
  * @param {string} userId - O ID do usuário ao qual a categoria pertence.
  * @param {string} categId - O ID da categoria a ser atualizada.
  * @param {Partial<UpdateCategRequest>} body - Os dados para atualizar a categoria.
+ * @param {number} version - A versão (updated_at) da categoria conhecida pelo cliente.
  * @return {Promise<QueryResponse>} Uma Promise que resolve para a resposta contendo a mensagem e o código de status, ou
  * uma resposta de erro caso a requisição falhe.
  */
@@ -252,12 +273,17 @@ export async function updateCategory(
   userId: string,
   categId: string,
   body: Partial<UpdateCategRequest>,
+  version: number,
 ): Promise<QueryResponse> {
   try {
-    const res: AxiosResponse<string, unknown> = await apiClient.patch(`/auth/user/${userId}/category/${categId}`, body)
+    const res: AxiosResponse<string, unknown> = await apiClient.patch(
+      `/auth/user/${userId}/category/${categId}`,
+      body,
+      { headers: ifMatch(version) },
+    )
     return { message: res.data, code: res.status }
   } catch (e: unknown) {
-    return handleAxiosError(e)
+    return handleAxiosError(e, 'Categoria')
   }
 }
 
@@ -268,6 +294,7 @@ export async function updateCategory(
  * @param {string} categId - O ID da categoria à qual o arquivo pertence.
  * @param {string} fileId - O ID do arquivo a ser atualizado.
  * @param {Partial<UpdateFileRequest>} body - O corpo da requisição contendo os dados do arquivo a serem atualizados.
+ * @param {number} version - A versão (updated_at) do arquivo conhecida pelo cliente.
  * @return {Promise<QueryResponse>} Uma Promise que resolve para um objeto contendo o status da operação, mensagem e
  * código de resposta.
  */
@@ -276,15 +303,17 @@ export async function updateFile(
   categId: string,
   fileId: string,
   body: Partial<UpdateFileRequest>,
+  version: number,
 ): Promise<QueryResponse> {
   try {
     const res: AxiosResponse<string, unknown> = await apiClient.patch(
       `/auth/user/${userId}/category/${categId}/file/${fileId}`,
       body,
+      { headers: ifMatch(version) },
     )
     return { message: res.data, code: res.status }
   } catch (e: unknown) {
-    return handleAxiosError(e)
+    return handleAxiosError(e, 'Arquivo')
   }
 }
 
@@ -292,15 +321,18 @@ export async function updateFile(
  * Exclui um usuário pelo seu identificador único.
  *
  * @param {string} userId - O identificador único do usuário a ser excluído.
+ * @param {number} version - A versão (updated_at) do usuário conhecida pelo cliente.
  * @return {Promise<QueryResponse>} Uma Promise que resolve para um objeto QueryResponse contendo o código de status e a
  * mensagem do servidor.
  */
-export async function deleteUser(userId: string): Promise<QueryResponse> {
+export async function deleteUser(userId: string, version: number): Promise<QueryResponse> {
   try {
-    const res: AxiosResponse<string, unknown> = await apiClient.delete(`/auth/user/${userId}`)
+    const res: AxiosResponse<string, unknown> = await apiClient.delete(`/auth/user/${userId}`, {
+      headers: ifMatch(version),
+    })
     return { message: res.data, code: res.status }
   } catch (e: unknown) {
-    return handleAxiosError(e)
+    return handleAxiosError(e, 'Usuário')
   }
 }
 
@@ -309,15 +341,18 @@ export async function deleteUser(userId: string): Promise<QueryResponse> {
  *
  * @param {string} userId - O identificador único do usuário.
  * @param {string} categId - O identificador único da categoria a ser excluída.
+ * @param {number} version - A versão (updated_at) da categoria conhecida pelo cliente.
  * @return {Promise<QueryResponse>} Uma Promise que resolve para um objeto contendo a mensagem de resposta e o código de
  * status.
  */
-export async function deleteCategory(userId: string, categId: string): Promise<QueryResponse> {
+export async function deleteCategory(userId: string, categId: string, version: number): Promise<QueryResponse> {
   try {
-    const res: AxiosResponse<string, unknown> = await apiClient.delete(`/auth/user/${userId}/category/${categId}`)
+    const res: AxiosResponse<string, unknown> = await apiClient.delete(`/auth/user/${userId}/category/${categId}`, {
+      headers: ifMatch(version),
+    })
     return { message: res.data, code: res.status }
   } catch (e: unknown) {
-    return handleAxiosError(e)
+    return handleAxiosError(e, 'Categoria')
   }
 }
 
@@ -327,16 +362,23 @@ export async function deleteCategory(userId: string, categId: string): Promise<Q
  * @param {string} userId - O identificador único do usuário.
  * @param {string} categId - O identificador único da categoria.
  * @param {string} fileId - O identificador único do arquivo a ser excluído.
+ * @param {number} version - A versão (updated_at) do arquivo conhecida pelo cliente.
  * @return {Promise<QueryResponse>} Uma Promise que resolve para um objeto de resposta contendo uma mensagem de status e
  * um código.
  */
-export async function deleteFile(userId: string, categId: string, fileId: string): Promise<QueryResponse> {
+export async function deleteFile(
+  userId: string,
+  categId: string,
+  fileId: string,
+  version: number,
+): Promise<QueryResponse> {
   try {
     const res: AxiosResponse<string, unknown> = await apiClient.delete(
       `/auth/user/${userId}/category/${categId}/file/${fileId}`,
+      { headers: ifMatch(version) },
     )
     return { message: res.data, code: res.status }
   } catch (e: unknown) {
-    return handleAxiosError(e)
+    return handleAxiosError(e, 'Arquivo')
   }
 }
@@ -3,7 +3,6 @@ import { type Ref, ref } from 'vue'
 import type { UserData } from '@/@types/Entities.ts'
 import apiClient from '@/services/axios.ts'
 import type { AxiosResponse } from 'axios'
-import type { CredentialsRequest } from '@/@types/Requests.ts'
 import type { LoginResponse } from '@/@types/Responses.ts'
 import router from '@/router'
 
@@ -15,9 +14,6 @@ export const useAuthStore = defineStore('auth', () => {
   // Dados do usuário da sessão.
   const user: Ref<UserData | undefined> = ref<UserData>()
 
-  // Parâmetros de login para atualizar token.
-  const loginBody: Ref<CredentialsRequest | undefined> = ref<CredentialsRequest>()
-
   /**
    * Define o token de autenticação e atualiza o local storage de forma correspondente.
    *
@@ -34,27 +30,17 @@ export const useAuthStore = defineStore('auth', () => {
   }
 
   /**
-   * Envia uma solicitação de login para atualizar o token usando o estado atual do corpo de login.
-   * Atualiza o token armazenado se um novo token for recebido, caso contrário, limpa o token e redefine o estado do
-   * corpo de login em caso de falha.
+   * Renova o token de acesso usando o refresh token armazenado no cookie da sessão.
+   * Atualiza o token armazenado se um novo token for recebido, caso contrário, limpa o token.
    *
    * @return {Promise<void>} Uma promise que é resolvida quando a operação de atualização do token é concluída.
    */
   async function refreshToken(): Promise<void> {
     try {
-      const res: AxiosResponse<LoginResponse> = await apiClient.post('/login', JSON.stringify(loginBody.value))
-      const token: string = res.data?.token
-
-      if (token) {
-        setToken(token)
-        return
-      }
-
-      setToken(null)
-      loginBody.value = undefined
+      const res: AxiosResponse<LoginResponse> = await apiClient.post('/refresh')
+      setToken(res.data?.token || null)
     } catch {
       setToken(null)
-      loginBody.value = undefined
     }
   }
 
@@ -71,13 +57,14 @@ export const useAuthStore = defineStore('auth', () => {
     }
 
     try {
-      // Verificar JWT
-      if (!token.value && loginBody.value) {
+      // Tentar retomar sessão, renovando o token de acesso se necessário
+      let res: AxiosResponse<LoginResponse>
+      try {
+        res = await apiClient.get('/auth/session')
+      } catch {
         await refreshToken()
+        res = await apiClient.get('/auth/session')
       }
-
-      // Tentar retomar sessão
-      const res: AxiosResponse<LoginResponse> = await apiClient.get('/auth/session')
       user.value = res.data
 
       if (user.value.admin) {
@@ -97,14 +84,18 @@ export const useAuthStore = defineStore('auth', () => {
   }
 
   /**
-   * Faz o logout do usuário da aplicação, reiniciando os tokens de autenticação, limpando os dados do usuário e
-   * navegando para a página de login.
+   * Faz o logout do usuário da aplicação, encerrando a sessão no servidor, reiniciando os tokens de autenticação,
+   * limpando os dados do usuário e navegando para a página de login.
    *
    * @return {Promise<void>} Uma promise que é resolvida quando o processo de logout é concluído.
    */
   async function logout(): Promise<void> {
+    try {
+      await apiClient.post('/auth/logout')
+    } catch {
+      // A sessão já pode ter sido encerrada no servidor
+    }
     setToken(null)
-    loginBody.value = undefined
     user.value = undefined
     await router.push({ name: 'login' })
   }
@@ -112,7 +103,6 @@ export const useAuthStore = defineStore('auth', () => {
   return {
     token,
     user,
-    loginBody,
     setToken,
     getSession,
     logout,